@@ -0,0 +1,56 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package cache
+
+import (
+	"github.com/snowplow/snowplow-cli/internal/cli"
+	snplog "github.com/snowplow/snowplow-cli/internal/logging"
+	"github.com/spf13/cobra"
+)
+
+var CacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Work with the local cache of console responses",
+	Long: `Console listings are cached on disk so repeated commands are fast.
+
+Validating uses cached responses as is for --cache-ttl, then console is asked
+whether they changed. Publishing, purging and downloading always ask, and
+publishing anything clears the cache. Pass --no-cache to any command to skip it.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return snplog.InitLogging(cmd)
+	},
+}
+
+var infoCmd = &cobra.Command{
+	Use:     "info",
+	Short:   "Show where the cache is and what it holds",
+	Args:    cobra.NoArgs,
+	Example: `  $ snowplow-cli cache info`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := cli.CacheInfo(cli.CacheOptions{})
+		return err
+	},
+}
+
+var clearCmd = &cobra.Command{
+	Use:     "clear",
+	Short:   "Remove every cached console response",
+	Args:    cobra.NoArgs,
+	Example: `  $ snowplow-cli cache clear`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cli.CacheClear(cli.CacheOptions{})
+	},
+}
+
+func init() {
+	CacheCmd.AddCommand(infoCmd)
+	CacheCmd.AddCommand(clearCmd)
+}
@@ -0,0 +1,55 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package catalog
+
+import (
+	"strings"
+
+	"github.com/snowplow/snowplow-cli/internal/catalog"
+	"github.com/snowplow/snowplow-cli/internal/cli"
+	"github.com/snowplow/snowplow-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var buildCmd = &cobra.Command{
+	Use:   "build <out>",
+	Short: "Render the local tracking plan as a static site",
+	Args:  cobra.ExactArgs(1),
+	Long: `Renders local data products, event specifications, triggers, source applications
+and data structures into a static HTML or Markdown site in <out>.
+
+Pages are cross linked, data structures get a property table generated from their
+JSON Schema and the index groups data products by domain and owner. Trigger images
+are copied next to the pages. Existing files in <out> are overwritten, others are kept.
+No console access is needed.`,
+	Example: `  $ snowplow-cli catalog build site
+  $ snowplow-cli catalog build docs/tracking-plan --format markdown`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		dsDir, _ := cmd.Flags().GetString("data-structures-directory")
+		dpDir, _ := cmd.Flags().GetString("data-products-directory")
+
+		return cli.CatalogBuild(cmd.Context(), cli.CatalogBuildOptions{
+			Out:                     args[0],
+			Format:                  format,
+			DataStructuresDirectory: dsDir,
+			DataProductsDirectory:   dpDir,
+		})
+	},
+}
+
+func init() {
+	CatalogCmd.AddCommand(buildCmd)
+
+	buildCmd.Flags().String("format", "html", "Site format ("+strings.Join(catalog.Formats, "|")+")")
+	buildCmd.Flags().String("data-structures-directory", util.DataStructuresFolder, "Directory to read data structures from")
+	buildCmd.Flags().String("data-products-directory", util.DataProductsFolder, "Directory to read data products and source applications from")
+}
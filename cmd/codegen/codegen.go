@@ -0,0 +1,68 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package codegen
+
+import (
+	"strings"
+
+	"github.com/snowplow/snowplow-cli/internal/cli"
+	"github.com/snowplow/snowplow-cli/internal/codegen"
+	snplog "github.com/snowplow/snowplow-cli/internal/logging"
+	"github.com/snowplow/snowplow-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var CodegenCmd = &cobra.Command{
+	Use:   "codegen",
+	Short: "Generate typed tracking code from local data structures and data products",
+	Args:  cobra.NoArgs,
+	Long: `Generates typed code that builds self describing json for local data structures,
+so changes to a schema become compile errors in the apps tracking it.
+
+Every data structure gets a type and a function wrapping it with its iglu uri.
+Every event specification of the local data products gets a function taking its event
+and tracked entities, lists where more than one entity may be sent. Entity counts outside
+the specified cardinalities fail at runtime.
+
+Data structures referenced by event specifications but not available locally are untyped.
+No console access is needed.`,
+	Example: `  $ snowplow-cli codegen --lang typescript
+  $ snowplow-cli codegen --lang go --package tracking --out internal/tracking/snowplow.go
+  $ snowplow-cli codegen --lang kotlin --package com.acme.tracking --out app/src/main/kotlin/Snowplow.kt`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return snplog.InitLogging(cmd)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lang, _ := cmd.Flags().GetString("lang")
+		dsDir, _ := cmd.Flags().GetString("data-structures-directory")
+		dpDir, _ := cmd.Flags().GetString("data-products-directory")
+		pkg, _ := cmd.Flags().GetString("package")
+		out, _ := cmd.Flags().GetString("out")
+
+		return cli.Codegen(cmd.Context(), cli.CodegenOptions{
+			Lang:                    lang,
+			DataStructuresDirectory: dsDir,
+			DataProductsDirectory:   dpDir,
+			Package:                 pkg,
+			Out:                     out,
+		})
+	},
+}
+
+func init() {
+	CodegenCmd.Flags().String("lang", "", "Language to generate ("+strings.Join(codegen.Languages, "|")+")")
+	_ = CodegenCmd.MarkFlagRequired("lang")
+	CodegenCmd.Flags().String("data-structures-directory", util.DataStructuresFolder, "Directory to read data structures from")
+	CodegenCmd.Flags().String("data-products-directory", util.DataProductsFolder, "Directory to read data products from")
+	CodegenCmd.Flags().String("package", "snowplow", "Package of the generated code (go and kotlin)")
+	CodegenCmd.Flags().String("out", "", `File to write, "-" for stdout.
+Defaults to snowplow.ts, snowplow.go, Snowplow.kt or Snowplow.swift`)
+}
@@ -0,0 +1,24 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package dev
+
+import (
+	snplog "github.com/snowplow/snowplow-cli/internal/logging"
+	"github.com/spf13/cobra"
+)
+
+var DevCmd = &cobra.Command{
+	Use:   "dev",
+	Short: "Tools for developing and testing against snowplow-cli",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return snplog.InitLogging(cmd)
+	},
+}
@@ -0,0 +1,58 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package dev
+
+import (
+	"os"
+	"os/signal"
+
+	"github.com/snowplow/snowplow-cli/internal/cli"
+	"github.com/snowplow/snowplow-cli/pkg/console/fakeconsole"
+	"github.com/spf13/cobra"
+)
+
+var fakeConsoleCmd = &cobra.Command{
+	Use:   "fake-console",
+	Short: "Run an in-memory BDP Console for local development and CI",
+	Args:  cobra.NoArgs,
+	Long: `Serves a fake BDP Console API with in-memory state on localhost.
+
+Data structures, data products, event specifications, source applications and
+images published to it can be downloaded again until the process exits.
+Nothing is persisted and nothing is sent to a real organization.`,
+	Example: `  $ snowplow-cli dev fake-console --port 9090
+  $ SNOWPLOW_CONSOLE_HOST=http://127.0.0.1:9090 snowplow-cli ds publish dev`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		port, _ := cmd.Flags().GetInt("port")
+		orgId, _ := cmd.Flags().GetString("org-id")
+		apiKeyId, _ := cmd.Flags().GetString("api-key-id")
+		apiKey, _ := cmd.Flags().GetString("api-key")
+
+		cnx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		return cli.DevFakeConsole(cnx, cli.DevFakeConsoleOptions{
+			Port:     port,
+			OrgId:    orgId,
+			ApiKeyId: apiKeyId,
+			ApiKey:   apiKey,
+		})
+	},
+}
+
+func init() {
+	DevCmd.AddCommand(fakeConsoleCmd)
+
+	fakeConsoleCmd.Flags().Int("port", 9090, "Port to listen on, 0 picks a free port")
+	fakeConsoleCmd.Flags().String("org-id", fakeconsole.DefaultOrgId, "Organization id to accept")
+	fakeConsoleCmd.Flags().String("api-key-id", fakeconsole.DefaultApiKeyId, "Api key id to accept")
+	fakeConsoleCmd.Flags().String("api-key", fakeconsole.DefaultApiKey, "Api key to accept")
+}
@@ -29,27 +29,24 @@ var docsCommand = &cobra.Command{
 	Short:  "Generate markdown documentation for snowplow-cli",
 	Hidden: true,
 	Args:   cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		outputDir := args[0]
 
 		// Clean the output directory if it exists
 		if _, err := os.Stat(outputDir); !os.IsNotExist(err) {
 			if err := os.RemoveAll(outputDir); err != nil {
-				slog.Error("Failed to clean output directory", "error", err)
-				os.Exit(1)
+				return fmt.Errorf("failed to clean output directory: %w", err)
 			}
 		}
 
 		// Create fresh output directory
 		if err := os.MkdirAll(outputDir, 0755); err != nil {
-			slog.Error("Failed to create output directory", "error", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to create output directory: %w", err)
 		}
 
 		// Generate markdown docs
 		if err := doc.GenMarkdownTree(RootCmd, outputDir); err != nil {
-			slog.Error("Failed to generate markdown documentation", "error", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to generate markdown documentation: %w", err)
 		}
 
 		// Collect all documentation content
@@ -94,8 +91,7 @@ var docsCommand = &cobra.Command{
 		})
 
 		if err != nil {
-			slog.Error("Failed to process documentation files", "error", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to process documentation files: %w", err)
 		}
 
 		// Create the combined documentation file
@@ -122,16 +118,11 @@ var docsCommand = &cobra.Command{
 		// Write the combined file
 		outputFile := filepath.Join(outputDir, "index.md")
 		if err := os.WriteFile(outputFile, []byte(combinedContent.String()), 0644); err != nil {
-			slog.Error("Failed to write combined documentation", "error", err)
-			os.Exit(1)
-		}
-
-		if err != nil {
-			slog.Error("Failed to convert documentation to Docusaurus format", "error", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to write combined documentation: %w", err)
 		}
 
 		slog.Info("Documentation generated successfully", "path", filepath.Clean(outputDir))
+		return nil
 	},
 }
 
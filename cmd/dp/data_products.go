@@ -11,9 +11,7 @@
 package dp
 
 import (
-	"log/slog"
-	"os"
-
+	"github.com/snowplow/snowplow-cli/internal/cli"
 	"github.com/snowplow/snowplow-cli/internal/config"
 	snplog "github.com/snowplow/snowplow-cli/internal/logging"
 	"github.com/spf13/cobra"
@@ -29,12 +27,7 @@ var DataProductsCmd = &cobra.Command{
 			return err
 		}
 
-		if err := config.InitConsoleConfig(cmd); err != nil {
-			slog.Error("config failure", "error", err)
-			os.Exit(1)
-		}
-
-		return nil
+		return cli.InitConfig(cmd)
 	},
 }
 
@@ -11,11 +11,7 @@
 package dp
 
 import (
-	"context"
-
-	"github.com/snowplow/snowplow-cli/internal/console"
-	"github.com/snowplow/snowplow-cli/internal/download"
-	snplog "github.com/snowplow/snowplow-cli/internal/logging"
+	"github.com/snowplow/snowplow-cli/internal/cli"
 	"github.com/snowplow/snowplow-cli/internal/util"
 	"github.com/spf13/cobra"
 )
@@ -26,35 +22,35 @@ var downloadCommand = &cobra.Command{
 	Args:  cobra.MaximumNArgs(1),
 	Long: `Downloads the latest versions of all data products, event specs and source apps from BDP Console.
 
-If no directory is provided then defaults to 'data-products' in the current directory. Source apps are stored in the nested 'source-apps' directory`,
+If no directory is provided then defaults to 'data-products' in the current directory. Source apps are stored in the nested 'source-apps' directory
+
+Resources already in the directory are updated in the file holding them, matched by resourceName,
+whatever the file is called. New resources get a file named after them. Updated yaml files
+keep their comments, key order and anchors but not blank lines between entries.
+Use --rename to move existing files to the name of their resource and --prune to delete
+the files of resources that no longer exist in BDP Console. Downloaded resources are listed
+in the '.snowplow-downloaded' file of the directory, only their files are pruned so local
+resources not published yet are kept.`,
 	Example: `  $ snowplow-cli dp download
-  $ snowplow-cli dp download ./my-data-products`,
-	Run: func(cmd *cobra.Command, args []string) {
-		apiKeyId, _ := cmd.Flags().GetString("api-key-id")
-		apiKeySecret, _ := cmd.Flags().GetString("api-key")
-		host, _ := cmd.Flags().GetString("host")
-		org, _ := cmd.Flags().GetString("org-id")
+  $ snowplow-cli dp download ./my-data-products
+  $ snowplow-cli dp download --rename --prune`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		format, _ := cmd.Flags().GetString("output-format")
+		rename, _ := cmd.Flags().GetBool("rename")
+		prune, _ := cmd.Flags().GetBool("prune")
 
 		dataProductsFolder := util.DataProductsFolder
-
 		if len(args) != 0 {
 			dataProductsFolder = args[0]
 		}
 
-		files := util.Files{DataProductsLocation: dataProductsFolder, SourceAppsLocation: util.SourceAppsFolder, ExtentionPreference: format, ImagesLocation: util.ImagesFolder}
-		cnx := context.Background()
-
-		c, err := console.NewApiClient(cnx, host, apiKeyId, apiKeySecret, org)
-		if err != nil {
-			snplog.LogFatal(err)
-		}
-
-		err = download.DownloadDataProductsAndRelatedResources(files, cnx, c)
-		if err != nil {
-			snplog.LogFatal(err)
-		}
-
+		return cli.DPDownload(cmd.Context(), cli.DPDownloadOptions{
+			Console:   cli.ConsoleOptionsFromFlags(cmd),
+			Directory: dataProductsFolder,
+			Format:    format,
+			Rename:    rename,
+			Prune:     prune,
+		})
 	},
 }
 
@@ -62,4 +58,6 @@ func init() {
 	DataProductsCmd.AddCommand(downloadCommand)
 
 	downloadCommand.PersistentFlags().StringP("output-format", "f", "yaml", "Format of the files to read/write. json or yaml are supported")
+	downloadCommand.Flags().Bool("rename", false, "Move existing files to the name of their resource")
+	downloadCommand.Flags().Bool("prune", false, "Delete local files of downloaded resources that no longer exist remotely")
 }
@@ -0,0 +1,53 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package dp
+
+import (
+	"strings"
+
+	"github.com/snowplow/snowplow-cli/internal/cli"
+	"github.com/snowplow/snowplow-cli/internal/config"
+	"github.com/snowplow/snowplow-cli/internal/export"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export [paths...] default: [./data-products]",
+	Short: "Export local data products to spreadsheets",
+	Args:  cobra.ArbitraryArgs,
+	Long: `Flattens local data products into CSV or TSV files for review in a spreadsheet.
+
+One file is written per resource type:
+  data-products, event-specifications, entities, triggers and source-applications.
+Rows name their data product and event specification so files can be joined.
+The event-specifications file lists tracked entities the way dp import reads them.
+Several values in one cell are separated by "; ". No console access is needed.`,
+	Example: `  $ snowplow-cli dp export
+  $ snowplow-cli dp export ./data-products --format tsv --out review`,
+	Annotations: map[string]string{config.LocalOnly: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		out, _ := cmd.Flags().GetString("out")
+
+		return cli.DPExport(cmd.Context(), cli.DPExportOptions{
+			Paths:  args,
+			Format: format,
+			Out:    out,
+		})
+	},
+}
+
+func init() {
+	DataProductsCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().String("format", "csv", "File format ("+strings.Join(export.Formats, "|")+")")
+	exportCmd.Flags().String("out", "tracking-plan", "Directory to write files to")
+}
@@ -11,12 +11,10 @@
 package dp
 
 import (
-	"log/slog"
-	"os"
+	"errors"
 	"path/filepath"
 
-	"github.com/google/uuid"
-	snplog "github.com/snowplow/snowplow-cli/internal/logging"
+	"github.com/snowplow/snowplow-cli/internal/cli"
 	"github.com/snowplow/snowplow-cli/internal/model"
 	"github.com/snowplow/snowplow-cli/internal/util"
 	"github.com/spf13/cobra"
@@ -24,7 +22,7 @@ import (
 
 var generateCmd = &cobra.Command{
 	Use:     "generate [paths...]",
-	Short:   "Generate new data products and source applications locally",
+	Short:   "Generate new data products, source applications and event specifications locally",
 	Aliases: []string{"gen"},
 	Args:    cobra.NoArgs,
 	Long: `Will write new data products and/or source application to file based on the arguments provided.
@@ -35,76 +33,41 @@ Example:
 
   $ snowplow-cli dp gen --data-product "Ad tracking" --output-format json --data-products-directory dir1
   Will result in a new data product getting written to './dir1/ad-tracking.json'
+
+  $ snowplow-cli dp gen --event-spec "Add to cart" --data-product ./data-products/ecommerce.yaml --event com.acme/add_to_cart --entity com.acme/product:1..*
+  Will append a new event specification to './data-products/ecommerce.yaml'
+
+With --event-spec, --data-product is the data product file to add the event specification to.
+Event and entity data structures take a full iglu uri or vendor/name, which picks the latest
+local version. Entities can end with a cardinality, eg. ":1", ":0..1" or ":1..*". Uris which
+are not available locally must be deployed according to BDP Console.
+A trigger is added when any of the --trigger flags is set.
 `,
-	Example: `  $ snowplow-cli dp generate --source-app "Mobile app" --source-app "Web app" --data-product "Signup flow"`,
-	Run: func(cmd *cobra.Command, args []string) {
-		outFmt, _ := cmd.Flags().GetString("output-format")
+	Example: `  $ snowplow-cli dp generate --source-app "Mobile app" --source-app "Web app" --data-product "Signup flow"
+  $ snowplow-cli dp generate --event-spec "Add to cart" --data-product ./data-products/ecommerce.yaml \
+      --event iglu:com.acme/add_to_cart/jsonschema/1-0-0 --entity iglu:com.acme/product/jsonschema/1-0-0:1..1 \
+      --trigger-description "Add to cart button clicked" --trigger-app-id web`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if cmd.Flags().Changed("event-spec") {
+			return generateEventSpec(cmd)
+		}
 
+		outFmt, _ := cmd.Flags().GetString("output-format")
 		sourceAppDirectory, _ := cmd.Flags().GetString("source-apps-directory")
 		sourceApps, _ := cmd.Flags().GetStringArray("source-app")
-
 		dataproductDirectory, _ := cmd.Flags().GetString("data-products-directory")
 		dataProducts, _ := cmd.Flags().GetStringArray("data-product")
 
-		err := os.MkdirAll(sourceAppDirectory, os.ModePerm)
-		if err != nil && !os.IsExist(err) {
-			snplog.LogFatal(err)
-		}
-		err = os.MkdirAll(dataproductDirectory, os.ModePerm)
-		if err != nil && !os.IsExist(err) {
-			snplog.LogFatal(err)
-		}
-
-		for _, app := range sourceApps {
-			appn := util.ResourceNameToFileName(app)
-			fpath, err := util.WriteSerializableToFile(buildSaTpl(app), sourceAppDirectory, appn, outFmt)
-			if err != nil {
-				snplog.LogFatal(err)
-			}
-			slog.Info("generate", "msg", "wrote", "kind", "source app", "file", fpath)
-		}
-
-		for _, app := range dataProducts {
-			appn := util.ResourceNameToFileName(app)
-			if err != nil {
-				snplog.LogFatal(err)
-			}
-			fpath, err := util.WriteSerializableToFile(buildDpTpl(app), dataproductDirectory, appn, outFmt)
-			if err != nil {
-				snplog.LogFatal(err)
-			}
-			slog.Info("generate", "msg", "wrote", "kind", "data product", "file", fpath)
-		}
-
+		return cli.DPGenerate(cli.DPGenerateOptions{
+			Format:                outFmt,
+			SourceApps:            sourceApps,
+			SourceAppsDirectory:   sourceAppDirectory,
+			DataProducts:          dataProducts,
+			DataProductsDirectory: dataproductDirectory,
+		})
 	},
 }
 
-func buildDpTpl(name string) model.CliResource[model.DataProductCanonicalData] {
-	return model.CliResource[model.DataProductCanonicalData]{
-		ApiVersion:   "v1",
-		ResourceType: "data-product",
-		ResourceName: uuid.NewString(),
-		Data: model.DataProductCanonicalData{
-			Name:                name,
-			SourceApplications:  []model.Ref{},
-			EventSpecifications: []model.EventSpecCanonical{},
-		},
-	}
-}
-
-func buildSaTpl(name string) model.CliResource[model.SourceAppData] {
-	return model.CliResource[model.SourceAppData]{
-		ApiVersion:   "v1",
-		ResourceType: "source-application",
-		ResourceName: uuid.NewString(),
-		Data: model.SourceAppData{
-			Name:     name,
-			AppIds:   []string{},
-			Entities: &model.EntitiesDef{},
-		},
-	}
-}
-
 func init() {
 	DataProductsCmd.AddCommand(generateCmd)
 
@@ -115,5 +78,52 @@ func init() {
 	generateCmd.Flags().StringArray("source-app", []string{}, "Name of source app to generate")
 	generateCmd.Flags().StringArray("data-product", []string{}, "Name of data product to generate")
 
+	generateCmd.Flags().String("event-spec", "", "Name of event specification to add to the --data-product file")
+	generateCmd.Flags().String("description", "", "Description of the event specification")
+	generateCmd.Flags().String("event", "", "Event data structure of the event specification")
+	generateCmd.Flags().StringArray("entity", []string{}, "Entity data structure of the event specification, with an optional :min..max cardinality")
+	generateCmd.Flags().String("data-structures-directory", util.DataStructuresFolder, "Directory to resolve data structures from")
+	generateCmd.Flags().String("trigger-description", "", "Description of the event specification trigger")
+	generateCmd.Flags().String("trigger-url", "", "Url where the event specification is triggered")
+	generateCmd.Flags().StringArray("trigger-app-id", []string{}, "App id the trigger applies to")
+	generateCmd.Flags().String("trigger-image", "", "Image file showing the trigger")
+
 	generateCmd.MarkFlagsOneRequired("source-app", "data-product")
+	generateCmd.MarkFlagsMutuallyExclusive("source-app", "event-spec")
+}
+
+func generateEventSpec(cmd *cobra.Command) error {
+	name, _ := cmd.Flags().GetString("event-spec")
+	dataProducts, _ := cmd.Flags().GetStringArray("data-product")
+	description, _ := cmd.Flags().GetString("description")
+	event, _ := cmd.Flags().GetString("event")
+	entities, _ := cmd.Flags().GetStringArray("entity")
+	dsDir, _ := cmd.Flags().GetString("data-structures-directory")
+	triggerDescription, _ := cmd.Flags().GetString("trigger-description")
+	triggerUrl, _ := cmd.Flags().GetString("trigger-url")
+	triggerAppIds, _ := cmd.Flags().GetStringArray("trigger-app-id")
+	triggerImage, _ := cmd.Flags().GetString("trigger-image")
+
+	if len(dataProducts) != 1 {
+		return cli.ConfigError(errors.New("--event-spec requires exactly one --data-product file"))
+	}
+
+	var trigger *model.Trigger
+	if triggerDescription != "" || triggerUrl != "" || len(triggerAppIds) > 0 || triggerImage != "" {
+		trigger = &model.Trigger{Description: triggerDescription, Url: triggerUrl, AppIds: triggerAppIds}
+		if triggerImage != "" {
+			trigger.Image = &model.Ref{Ref: triggerImage}
+		}
+	}
+
+	return cli.DPGenerateEventSpec(cmd.Context(), cli.DPGenerateEventSpecOptions{
+		Console:                 cli.ConsoleOptionsFromFlags(cmd),
+		Name:                    name,
+		Description:             description,
+		DataProduct:             dataProducts[0],
+		Event:                   event,
+		Entities:                entities,
+		Trigger:                 trigger,
+		DataStructuresDirectory: dsDir,
+	})
 }
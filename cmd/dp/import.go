@@ -0,0 +1,65 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package dp
+
+import (
+	"github.com/snowplow/snowplow-cli/internal/cli"
+	"github.com/snowplow/snowplow-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Add event specifications drafted in a spreadsheet to a data product",
+	Args:  cobra.NoArgs,
+	Long: `Reads event specifications from a CSV or TSV file and adds them to a local data product file.
+
+Recognised columns, in any order, are:
+  Event specification (required), Description, Event, Tracked entities (or Entities),
+  Excluded source applications and Data product.
+When a Data product column is present only rows naming the data product are imported.
+The event-specifications file written by dp export can be imported as is.
+
+Event and entity cells take a full iglu uri or vendor/name, which picks the latest
+local version. Several entities are separated by ";" and can set a cardinality as
+"vendor/name (1)", "(0..1)" or "(1..*)", an entity listed twice with different
+cardinalities is rejected. Uris which are not available locally must be deployed
+according to BDP Console.
+
+Existing event specifications with the same name are left untouched.`,
+	Example: `  $ snowplow-cli dp import --from plan.csv --data-product data-products/checkout.yaml
+  $ snowplow-cli dp import --from plan.tsv --data-product data-products/checkout.yaml --dry-run`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		from, _ := cmd.Flags().GetString("from")
+		dataProduct, _ := cmd.Flags().GetString("data-product")
+		dsDir, _ := cmd.Flags().GetString("data-structures-directory")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		return cli.DPImport(cmd.Context(), cli.DPImportOptions{
+			Console:                 cli.ConsoleOptionsFromFlags(cmd),
+			From:                    from,
+			DataProduct:             dataProduct,
+			DataStructuresDirectory: dsDir,
+			DryRun:                  dryRun,
+		})
+	},
+}
+
+func init() {
+	DataProductsCmd.AddCommand(importCmd)
+
+	importCmd.Flags().String("from", "", "CSV or TSV file to read event specifications from")
+	importCmd.Flags().String("data-product", "", "Data product file to add event specifications to")
+	importCmd.Flags().String("data-structures-directory", util.DataStructuresFolder, "Directory to resolve data structures from")
+	importCmd.Flags().BoolP("dry-run", "d", false, "Only print the event specifications that would be added")
+	_ = importCmd.MarkFlagRequired("from")
+	_ = importCmd.MarkFlagRequired("data-product")
+}
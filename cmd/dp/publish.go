@@ -11,16 +11,10 @@
 package dp
 
 import (
-	"context"
-	"fmt"
-	"log/slog"
-	"os"
+	"strings"
 
-	"github.com/snowplow/snowplow-cli/internal/console"
-	snplog "github.com/snowplow/snowplow-cli/internal/logging"
-	"github.com/snowplow/snowplow-cli/internal/publish"
+	"github.com/snowplow/snowplow-cli/internal/cli"
 	"github.com/snowplow/snowplow-cli/internal/util"
-	"github.com/snowplow/snowplow-cli/internal/validation"
 	"github.com/spf13/cobra"
 )
 
@@ -31,56 +25,34 @@ var publishCommand = &cobra.Command{
 
 If no directory is provided then defaults to 'data-products' in the current directory. Source apps are stored in the nested 'source-apps' directory`,
 	Example: `  $ snowplow-cli dp publish
-  $ snowplow-cli dp download ./my-data-products`,
-	Run: func(cmd *cobra.Command, args []string) {
-		apiKeyId, _ := cmd.Flags().GetString("api-key-id")
-		apiKeySecret, _ := cmd.Flags().GetString("api-key")
-		host, _ := cmd.Flags().GetString("host")
-		org, _ := cmd.Flags().GetString("org-id")
+  $ snowplow-cli dp download ./my-data-products
+  $ snowplow-cli dp publish --dry-run --summary-md summary.md`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
 		ghOut, _ := cmd.Flags().GetBool("gh-annotate")
-		managedFrom, _ := cmd.Flags().GetString("managed-from")
-
-		searchPaths := []string{}
-
-		if len(args) == 0 {
-			searchPaths = append(searchPaths, util.DataProductsFolder)
-			slog.Debug("validation", "msg", fmt.Sprintf("no path provided, using default (./%s)", util.DataProductsFolder))
-		}
-
-		searchPaths = append(searchPaths, args...)
-
-		files, err := util.MaybeResourcesfromPaths(searchPaths)
-		if err != nil {
-			snplog.LogFatal(err)
-		}
-
-		basePath, err := os.Getwd()
-		if err != nil {
-			snplog.LogFatal(err)
-		}
-
-		cnx := context.Background()
-
-		c, err := console.NewApiClient(cnx, host, apiKeyId, apiKeySecret, org)
-		if err != nil {
-			snplog.LogFatal(err)
-		}
-
-		changes, err := publish.FindChanges(cnx, c, files)
-		if err != nil {
-			snplog.LogFatal(err)
-		}
-
-		publish.LockChanged(changes, managedFrom)
-
-		validation.Validate(cnx, c, files, searchPaths, basePath, ghOut, false, changes.IdToFileName)
-
-		err = publish.Publish(cnx, c, changes, dryRun)
+		summaryMd, _ := cmd.Flags().GetString("summary-md")
+		compat, _ := cmd.Flags().GetString("compat")
+		dsDir, _ := cmd.Flags().GetString("data-structures-directory")
+		baseline, _ := cmd.Flags().GetString("baseline")
+		since, _ := cmd.Flags().GetString("since")
+		lintRules, err := cli.LintRulesFromFlags(cmd)
 		if err != nil {
-			snplog.LogFatal(err)
+			return err
 		}
 
+		return cli.DPPublish(cmd.Context(), cli.DPPublishOptions{
+			Console:                 cli.ConsoleOptionsFromFlags(cmd),
+			Paths:                   args,
+			DryRun:                  dryRun,
+			GhAnnotate:              ghOut,
+			SummaryMd:               summaryMd,
+			Compat:                  compat,
+			DataStructuresDirectory: dsDir,
+			LintRules:               lintRules,
+			Policies:                cli.PoliciesFromConfig(cmd),
+			Baseline:                baseline,
+			Since:                   since,
+		})
 	},
 }
 
@@ -88,4 +60,9 @@ func init() {
 	DataProductsCmd.AddCommand(publishCommand)
 	publishCommand.PersistentFlags().Bool("gh-annotate", false, "Output suitable for github workflow annotation (ignores -s)")
 	publishCommand.PersistentFlags().BoolP("dry-run", "d", false, "Only print planned changes without performing them")
+	publishCommand.PersistentFlags().String("summary-md", "", "Write a markdown summary of planned changes and validation results to this file")
+	publishCommand.PersistentFlags().String("compat", cli.CompatRemote, "Where to check event specification compatibility ("+strings.Join(cli.CompatModes, "|")+")")
+	publishCommand.PersistentFlags().String("data-structures-directory", util.DataStructuresFolder, "Directory of local data structures to check compatibility against with --compat local")
+	publishCommand.PersistentFlags().String("baseline", "", "File of accepted findings, only new findings are reported")
+	publishCommand.PersistentFlags().String("since", "", "Only publish resources changed since this git ref and their dependants")
 }
@@ -11,34 +11,10 @@
 package dp
 
 import (
-	"context"
-	"fmt"
-	"log/slog"
-
-	"github.com/snowplow/snowplow-cli/internal/console"
-	snplog "github.com/snowplow/snowplow-cli/internal/logging"
-	"github.com/snowplow/snowplow-cli/internal/publish"
-	"github.com/snowplow/snowplow-cli/internal/util"
+	"github.com/snowplow/snowplow-cli/internal/cli"
 	"github.com/spf13/cobra"
 )
 
-type purgeApi struct {
-	client *console.ApiClient
-	cnx    context.Context
-}
-
-func (a purgeApi) DeleteSourceApp(sa console.RemoteSourceApplication) error {
-	return console.DeleteSourceApp(a.cnx, a.client, sa)
-}
-
-func (a purgeApi) DeleteDataProduct(dp console.RemoteDataProduct) error {
-	return console.DeleteDataProduct(a.cnx, a.client, dp)
-}
-
-func (a purgeApi) FetchDataProduct() (*console.DataProductsAndRelatedResources, error) {
-	return console.GetDataProductsAndRelatedResources(a.cnx, a.client)
-}
-
 var purgeCommand = &cobra.Command{
 	Use:   "purge {directory ./data-products}",
 	Short: "Purges (permanently removes) all remote data products and source apps that do not exist locally",
@@ -47,39 +23,14 @@ var purgeCommand = &cobra.Command{
 If no directory is provided then defaults to 'data-products' in the current directory. Source apps are stored in the nested 'source-apps' directory`,
 	Example: `  $ snowplow-cli dp purge
   $ snowplow-cli dp purge ./my-data-products`,
-	Run: func(cmd *cobra.Command, args []string) {
-		apiKeyId, _ := cmd.Flags().GetString("api-key-id")
-		apiKeySecret, _ := cmd.Flags().GetString("api-key")
-		host, _ := cmd.Flags().GetString("host")
-		org, _ := cmd.Flags().GetString("org-id")
+	RunE: func(cmd *cobra.Command, args []string) error {
 		yes, _ := cmd.Flags().GetBool("yes")
 
-		searchPaths := []string{}
-
-		if len(args) == 0 {
-			searchPaths = append(searchPaths, util.DataProductsFolder)
-			slog.Debug("purge", "msg", fmt.Sprintf("no path provided, using default (./%s)", util.DataProductsFolder))
-		}
-
-		searchPaths = append(searchPaths, args...)
-
-		files, err := util.MaybeResourcesfromPaths(searchPaths)
-		if err != nil {
-			snplog.LogFatal(err)
-		}
-
-		cnx := context.Background()
-
-		c, err := console.NewApiClient(cnx, host, apiKeyId, apiKeySecret, org)
-		if err != nil {
-			snplog.LogFatal(err)
-		}
-
-		err = publish.Purge(purgeApi{c, cnx}, files, yes)
-		if err != nil {
-			snplog.LogFatal(err)
-		}
-
+		return cli.DPPurge(cmd.Context(), cli.DPPurgeOptions{
+			Console: cli.ConsoleOptionsFromFlags(cmd),
+			Paths:   args,
+			Yes:     yes,
+		})
 	},
 }
 
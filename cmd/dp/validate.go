@@ -11,16 +11,10 @@
 package dp
 
 import (
-	"context"
-	"fmt"
-	"log/slog"
-	"os"
+	"strings"
 
-	"github.com/snowplow/snowplow-cli/internal/console"
-	snplog "github.com/snowplow/snowplow-cli/internal/logging"
-	"github.com/snowplow/snowplow-cli/internal/publish"
+	"github.com/snowplow/snowplow-cli/internal/cli"
 	"github.com/snowplow/snowplow-cli/internal/util"
-	"github.com/snowplow/snowplow-cli/internal/validation"
 	"github.com/spf13/cobra"
 )
 
@@ -28,49 +22,58 @@ var validateCmd = &cobra.Command{
 	Use:   "validate [paths...]",
 	Short: "Validate data products and source applications with BDP Console",
 	Args:  cobra.ArbitraryArgs,
-	Long:  `Sends all data products and source applications from <path> for validation by BDP Console.`,
-	Example: `  $ snowplow-cli dp validate ./data-products ./source-applications
-  $ snowplow-cli dp validate ./src`,
-	Run: func(cmd *cobra.Command, args []string) {
-		apiKeyId, _ := cmd.Flags().GetString("api-key-id")
-		apiKeySecret, _ := cmd.Flags().GetString("api-key")
-		host, _ := cmd.Flags().GetString("host")
-		org, _ := cmd.Flags().GetString("org-id")
-		ghOut, _ := cmd.Flags().GetBool("gh-annotate")
-		full, _ := cmd.Flags().GetBool("full")
+	Long: `Sends all data products and source applications from <path> for validation by BDP Console.
 
-		searchPaths := []string{}
+Data structures referenced by source applications are looked up with the iglu resolvers
+configured in snowplow.yml. Event specifications are checked for compatibility with their
+data structures by BDP Console, or with --compat local against the data structures in
+--data-structures-directory and the ones the resolvers fetch.
 
-		if len(args) == 0 {
-			searchPaths = append(searchPaths, util.DataProductsFolder)
-			slog.Debug("validation", "msg", fmt.Sprintf("no path provided, using default (./%s)", util.DataProductsFolder))
-		}
+With --offline nothing is fetched over the network: schemas are only looked up with the
+local and built-in resolvers, other configured ones are refused, and compatibility is
+checked locally for every file.
 
-		searchPaths = append(searchPaths, args...)
+Findings a resource accepts are listed with a reason under x-snowplow-cli-ignore in it.
+--write-baseline accepts all current findings at once, pass the file to --baseline later
+so only new findings fail.
 
-		files, err := util.MaybeResourcesfromPaths(searchPaths)
-		if err != nil {
-			snplog.LogFatal(err)
-		}
-
-		basePath, err := os.Getwd()
-		if err != nil {
-			snplog.LogFatal(err)
-		}
-
-		cnx := context.Background()
-
-		c, err := console.NewApiClient(cnx, host, apiKeyId, apiKeySecret, org)
-		if err != nil {
-			snplog.LogFatal(err)
-		}
-
-		changes, err := publish.FindChanges(cnx, c, files)
+With --since only resources changed since a git ref, data products using changed source
+applications and the source applications they need are validated, unless --full.`,
+	Example: `  $ snowplow-cli dp validate ./data-products ./source-applications
+  $ snowplow-cli dp validate ./src
+  $ snowplow-cli dp validate --compat local
+  $ snowplow-cli dp validate --offline
+  $ snowplow-cli dp validate --write-baseline .snowplow-baseline.json
+  $ snowplow-cli dp validate --baseline .snowplow-baseline.json
+  $ snowplow-cli dp validate --since origin/main`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ghOut, _ := cmd.Flags().GetBool("gh-annotate")
+		full, _ := cmd.Flags().GetBool("full")
+		offline, _ := cmd.Flags().GetBool("offline")
+		compat, _ := cmd.Flags().GetString("compat")
+		dsDir, _ := cmd.Flags().GetString("data-structures-directory")
+		baseline, _ := cmd.Flags().GetString("baseline")
+		writeBaseline, _ := cmd.Flags().GetString("write-baseline")
+		since, _ := cmd.Flags().GetString("since")
+		lintRules, err := cli.LintRulesFromFlags(cmd)
 		if err != nil {
-			snplog.LogFatal(err)
+			return err
 		}
 
-		validation.Validate(cnx, c, files, searchPaths, basePath, ghOut, full, changes.IdToFileName)
+		return cli.DPValidate(cmd.Context(), cli.DPValidateOptions{
+			Console:                 cli.ConsoleOptionsFromFlags(cmd),
+			Paths:                   args,
+			GhAnnotate:              ghOut,
+			Full:                    full,
+			Offline:                 offline,
+			Compat:                  compat,
+			DataStructuresDirectory: dsDir,
+			LintRules:               lintRules,
+			Policies:                cli.PoliciesFromConfig(cmd),
+			Baseline:                baseline,
+			WriteBaseline:           writeBaseline,
+			Since:                   since,
+		})
 	},
 }
 
@@ -79,4 +82,10 @@ func init() {
 
 	validateCmd.PersistentFlags().Bool("gh-annotate", false, "Output suitable for github workflow annotation (ignores -s)")
 	validateCmd.PersistentFlags().Bool("full", false, "Perform compatibility check on all files, not only the ones that were changed")
+	validateCmd.PersistentFlags().Bool("offline", false, "Validate without the network, only resolving data structures with local and built-in iglu resolvers")
+	validateCmd.PersistentFlags().String("compat", "", "Where to check event specification compatibility ("+strings.Join(cli.CompatModes, "|")+"), remote unless --offline")
+	validateCmd.PersistentFlags().String("data-structures-directory", util.DataStructuresFolder, "Directory of local data structures to check compatibility against with --compat local")
+	validateCmd.PersistentFlags().String("baseline", "", "File of accepted findings, only new findings are reported")
+	validateCmd.PersistentFlags().String("write-baseline", "", "Write all findings to this file as accepted and succeed")
+	validateCmd.PersistentFlags().String("since", "", "Only validate resources changed since this git ref and their dependants")
 }
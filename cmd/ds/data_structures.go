@@ -11,9 +11,7 @@ OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
 package ds
 
 import (
-	"log/slog"
-	"os"
-
+	"github.com/snowplow/snowplow-cli/internal/cli"
 	"github.com/snowplow/snowplow-cli/internal/config"
 	. "github.com/snowplow/snowplow-cli/internal/logging"
 	"github.com/spf13/cobra"
@@ -31,12 +29,7 @@ var DataStructuresCmd = &cobra.Command{
 			return err
 		}
 
-		if err := config.InitConsoleConfig(cmd); err != nil {
-			slog.Error("config failure", "error", err)
-			os.Exit(1)
-		}
-
-		return nil
+		return cli.InitConfig(cmd)
 	},
 }
 
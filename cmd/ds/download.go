@@ -11,11 +11,7 @@ OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
 package ds
 
 import (
-	"context"
-	"log/slog"
-
-	"github.com/snowplow/snowplow-cli/internal/console"
-	snplog "github.com/snowplow/snowplow-cli/internal/logging"
+	"github.com/snowplow/snowplow-cli/internal/cli"
 	"github.com/snowplow/snowplow-cli/internal/util"
 	"github.com/spf13/cobra"
 )
@@ -27,46 +23,40 @@ var downloadCmd = &cobra.Command{
 	Long: `Downloads the latest versions of all data structures from BDP Console.
 
 Will retrieve schema contents from your development environment.
-If no directory is provided then defaults to 'data-structures' in the current directory.`,
+If no directory is provided then defaults to 'data-structures' in the current directory.
+
+Data structures already in the directory are only fetched and rewritten when their DEV
+deployment or metadata changed, yaml files keep their comments, key order and anchors but
+not blank lines between entries. Files that can't be read are skipped with a warning.
+Use --prune to delete the local files of data structures that no longer exist in BDP Console.
+Downloaded data structures are listed in the '.snowplow-downloaded' file of the directory,
+only their files are pruned so local data structures not published yet are kept.`,
 	Example: `  $ snowplow-cli ds download
 
   Download data structures matching com.example/event_name* or com.example.subdomain*
   $ snowplow-cli ds download --match com.example/event_name --match com.example.subdomain
 
   Download with custom output format and directory
-  $ snowplow-cli ds download --output-format json ./my-data-structures`,
-	Run: func(cmd *cobra.Command, args []string) {
+  $ snowplow-cli ds download --output-format json ./my-data-structures
+
+  Keep a directory in sync, removing deleted data structures
+  $ snowplow-cli ds download --prune`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		dataStructuresFolder := util.DataStructuresFolder
 		if len(args) > 0 {
 			dataStructuresFolder = args[0]
 		}
 		format, _ := cmd.Flags().GetString("output-format")
 		match, _ := cmd.Flags().GetStringArray("match")
-		files := util.Files{DataStructuresLocation: dataStructuresFolder, ExtentionPreference: format}
-
-		apiKeyId, _ := cmd.Flags().GetString("api-key-id")
-		apiKeySecret, _ := cmd.Flags().GetString("api-key")
-		host, _ := cmd.Flags().GetString("host")
-		org, _ := cmd.Flags().GetString("org-id")
-
-		cnx := context.Background()
-
-		c, err := console.NewApiClient(cnx, host, apiKeyId, apiKeySecret, org)
-		if err != nil {
-			snplog.LogFatalMsg("client creation fail", err)
-		}
-
-		dss, err := console.GetAllDataStructures(cnx, c, match)
-		if err != nil {
-			snplog.LogFatalMsg("data structure fetch failed", err)
-		}
-
-		err = files.CreateDataStructures(dss)
-		if err != nil {
-			snplog.LogFatal(err)
-		}
+		prune, _ := cmd.Flags().GetBool("prune")
 
-		slog.Info("wrote data structures", "count", len(dss))
+		return cli.DSDownload(cmd.Context(), cli.DSDownloadOptions{
+			Console:   cli.ConsoleOptionsFromFlags(cmd),
+			Directory: dataStructuresFolder,
+			Format:    format,
+			Match:     match,
+			Prune:     prune,
+		})
 	},
 }
 
@@ -75,4 +65,5 @@ func init() {
 
 	downloadCmd.PersistentFlags().StringP("output-format", "f", "yaml", "Format of the files to read/write. json or yaml are supported")
 	downloadCmd.PersistentFlags().StringArrayP("match", "", []string{}, "Match for specific data structure to download (eg. --match com.example/event_name or --match com.example)")
+	downloadCmd.Flags().Bool("prune", false, "Delete local files of downloaded data structures that no longer exist remotely")
 }
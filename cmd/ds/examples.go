@@ -0,0 +1,70 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package ds
+
+import (
+	"time"
+
+	"github.com/snowplow/snowplow-cli/internal/cli"
+	"github.com/snowplow/snowplow-cli/internal/config"
+	snplog "github.com/snowplow/snowplow-cli/internal/logging"
+	"github.com/snowplow/snowplow-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var examplesCmd = &cobra.Command{
+	Use:   "examples [vendor/name...]",
+	Short: "Generate synthetic example events from local data structures",
+	Long: `Generates random payloads which validate against local data structures, for tracker QA or Snowplow Micro testing.
+
+Payloads honour the types, enums, formats, patterns, bounds and required properties of the schema.
+They are written one per line (ndjson), either bare or wrapped as self describing json with the iglu schema uri.
+
+Runs with the same --seed produce the same payloads. No console access is needed.
+Logs go to stderr so examples written to stdout can be piped.`,
+	Example: `  $ snowplow-cli ds examples com.acme/login_click
+  $ snowplow-cli ds examples com.acme/login_click -n 10 --seed 42 --self-describing
+  $ snowplow-cli ds examples --all --self-describing --out examples.ndjson`,
+	Annotations: map[string]string{config.LocalOnly: "true", snplog.LogToStderr: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		directory, _ := cmd.Flags().GetString("data-structures-directory")
+		all, _ := cmd.Flags().GetBool("all")
+		count, _ := cmd.Flags().GetInt("count")
+		seed, _ := cmd.Flags().GetInt64("seed")
+		selfDescribing, _ := cmd.Flags().GetBool("self-describing")
+		out, _ := cmd.Flags().GetString("out")
+
+		if !cmd.Flags().Changed("seed") {
+			seed = time.Now().UnixNano()
+		}
+
+		return cli.DSExamples(cmd.Context(), cli.DSExamplesOptions{
+			Directory:      directory,
+			Names:          args,
+			All:            all,
+			Count:          count,
+			Seed:           seed,
+			SelfDescribing: selfDescribing,
+			Out:            out,
+		})
+	},
+}
+
+func init() {
+	DataStructuresCmd.AddCommand(examplesCmd)
+
+	examplesCmd.Flags().String("data-structures-directory", util.DataStructuresFolder, "Directory to read data structures from")
+	examplesCmd.Flags().Bool("all", false, "Generate examples of every local data structure")
+	examplesCmd.Flags().IntP("count", "n", 1, "Number of examples per data structure")
+	examplesCmd.Flags().Int64("seed", 0, "Seed for reproducible examples, random when not set")
+	examplesCmd.Flags().Bool("self-describing", false, "Wrap examples in self describing json with the iglu schema uri")
+	examplesCmd.Flags().String("out", "", "File to write examples to, stdout when not set")
+}
@@ -11,24 +11,8 @@ OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
 package ds
 
 import (
-	"encoding/json"
-	"errors"
-	"fmt"
-	"log/slog"
-	"os"
-	"path/filepath"
-	"regexp"
-
-	. "github.com/snowplow/snowplow-cli/internal/logging"
-	"github.com/snowplow/snowplow-cli/internal/model"
-	"github.com/snowplow/snowplow-cli/internal/util"
+	"github.com/snowplow/snowplow-cli/internal/cli"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
-)
-
-var (
-	nameRegexp   = regexp.MustCompile(`^[a-zA-Z0-9-_]+$`)
-	vendorRegexp = regexp.MustCompile(`^[a-zA-Z0-9-_.]+$`)
 )
 
 var generateCmd = &cobra.Command{
@@ -47,96 +31,33 @@ Example:
   Will result in a new data structure getting written to './data-structures/login_click.yaml' with
   an empty vendor field. Note that vendor is a required field and will cause a validation error if not completed.`,
 	Example: `  $ snowplow-cli ds generate my-ds
-  $ snowplow-cli ds generate my-ds ./my-data-structures`,
-	Run: func(cmd *cobra.Command, args []string) {
+  $ snowplow-cli ds generate my-ds ./my-data-structures
+  $ snowplow-cli ds generate login_click --vendor com.acme --from-sample events.ndjson`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		vendor, _ := cmd.Flags().GetString("vendor")
 		outFmt, _ := cmd.Flags().GetString("output-format")
 		event, _ := cmd.Flags().GetBool("event")
 		entity, _ := cmd.Flags().GetBool("entity")
+		fromSample, _ := cmd.Flags().GetStringSlice("from-sample")
 
-		name := args[0]
-
-		if ok := nameRegexp.Match([]byte(name)); !ok {
-			LogFatal(errors.New("name did not match [a-zA-Z0-9-_]+"))
-		}
-
-		if ok := vendorRegexp.Match([]byte(vendor)); vendor != "" && !ok {
-			LogFatal(errors.New("vendor did not match [a-zA-Z0-9-_.]+"))
-		}
-
-		if ok := outFmt == "json" || outFmt == "yaml"; !ok {
-			LogFatal(errors.New("unsupported output format. Was not yaml or json"))
-		}
-
-		outDir := filepath.Join(util.DataStructuresFolder, vendor)
+		directory := ""
 		if len(args) > 1 {
-			outDir = filepath.Join(args[1], vendor)
-		}
-
-		outFile := filepath.Join(outDir, name+"."+outFmt)
-		if _, err := os.Stat(outFile); !os.IsNotExist(err) {
-			LogFatal(fmt.Errorf("file already exists, not writing %s", outFile))
+			directory = args[1]
 		}
 
-		var schemaType string
-		if event {
-			schemaType = "event"
-		}
-		if entity {
-			schemaType = "entity"
-		}
-
-		yamlOut := fmt.Sprintf(yamlTemplate, schemaType, vendor, name)
-
-		ds := model.DataStructure{}
-		err := yaml.Unmarshal([]byte(yamlOut), &ds)
-		if err != nil {
-			LogFatal(err)
-		}
-
-		output := yamlOut
-		if outFmt == "json" {
-			jsonOut, err := json.MarshalIndent(ds, "", "  ")
-			if err != nil {
-				LogFatal(err)
-			}
-			output = string(jsonOut)
-		}
-
-		err = os.Mkdir(outDir, os.ModePerm)
-		if err != nil && !os.IsExist(err) {
-			LogFatal(err)
-		}
-		err = os.WriteFile(outFile, []byte(output), 0644)
-		if err != nil {
-			LogFatal(err)
-		}
-
-		slog.Info("generate", "wrote", outFile)
+		_, err := cli.DSGenerate(cli.DSGenerateOptions{
+			Name:       args[0],
+			Vendor:     vendor,
+			Directory:  directory,
+			Format:     outFmt,
+			Event:      event,
+			Entity:     entity,
+			FromSample: fromSample,
+		})
+		return err
 	},
 }
 
-var yamlTemplate = `# You might not need a custom data structure.
-# Please have a look at the available list of out of the box events and entities:
-# https://docs.snowplow.io/docs/collecting-data/collecting-from-own-applications/snowplow-tracker-protocol/
-apiVersion: v1
-resourceType: data-structure
-meta:
-  hidden: false
-  schemaType: %s
-  customData: {}
-data:
-  $schema: http://iglucentral.com/schemas/com.snowplowanalytics.self-desc/schema/jsonschema/1-0-0#
-  self:
-    vendor: %s
-    name: %s
-    format: jsonschema
-    version: 1-0-0
-  type: object
-  properties: {}
-  additionalProperties: false
-`
-
 func init() {
 	DataStructuresCmd.AddCommand(generateCmd)
 
@@ -147,4 +68,6 @@ Must conform to the regex pattern [a-zA-Z0-9-_.]+`)
 
 	generateCmd.Flags().Bool("event", true, "Generate data structure as an event")
 	generateCmd.Flags().Bool("entity", false, "Generate data structure as an entity")
+	generateCmd.Flags().StringSlice("from-sample", []string{}, `Infer the schema from files of sample json payloads.
+One payload per line (ndjson), a single payload or a json array of payloads`)
 }
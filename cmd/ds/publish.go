@@ -11,15 +11,7 @@ OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
 package ds
 
 import (
-	"context"
-	"errors"
-	"log/slog"
-
-	changesPkg "github.com/snowplow/snowplow-cli/internal/changes"
-	"github.com/snowplow/snowplow-cli/internal/console"
-	. "github.com/snowplow/snowplow-cli/internal/logging"
-	"github.com/snowplow/snowplow-cli/internal/util"
-	"github.com/snowplow/snowplow-cli/internal/validation"
+	"github.com/snowplow/snowplow-cli/internal/cli"
 	"github.com/spf13/cobra"
 )
 
@@ -44,79 +36,24 @@ Changes to it will be published by this command.
 	`,
 	Example: `  $ snowplow-cli ds publish dev
   $ snowplow-cli ds publish dev --dry-run
-  $ snowplow-cli ds publish dev --dry-run ./my-data-structures ./my-other-data-structures`,
-
-	Run: func(cmd *cobra.Command, args []string) {
-		apiKeyId, _ := cmd.Flags().GetString("api-key-id")
-		apiKeySecret, _ := cmd.Flags().GetString("api-key")
-		host, _ := cmd.Flags().GetString("host")
-		org, _ := cmd.Flags().GetString("org-id")
+  $ snowplow-cli ds publish dev --dry-run ./my-data-structures ./my-other-data-structures
+  $ snowplow-cli ds publish dev --dry-run --summary-md summary.md`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
 		ghOut, _ := cmd.Flags().GetBool("gh-annotate")
-		managedFrom, _ := cmd.Flags().GetString("managed-from")
-
-		dataStructureFolders := []string{util.DataStructuresFolder}
-		if len(args) > 0 {
-			dataStructureFolders = args
-		}
-
-		dataStructuresLocal, err := util.DataStructuresFromPaths(dataStructureFolders)
-
-		if err != nil {
-			LogFatal(err)
-		}
-
-		errs := validation.ValidateLocalDs(dataStructuresLocal)
-		if len(errs) > 0 {
-			LogFatalMultiple(errs)
-		}
-
-		slog.Info("publishing to dev from", "paths", dataStructureFolders)
-
-		cnx := context.Background()
-
-		c, err := console.NewApiClient(cnx, host, apiKeyId, apiKeySecret, org)
-		if err != nil {
-			LogFatal(err)
-		}
-
-		remotesListing, err := console.GetDataStructureListing(cnx, c)
-		if err != nil {
-			LogFatal(err)
-		}
-
-		changes, err := changesPkg.GetChanges(dataStructuresLocal, remotesListing, "DEV")
-		if err != nil {
-			LogFatal(err)
-		}
-
-		err = changesPkg.PrintChangeset(changes)
-		if err != nil {
-			LogFatal(err)
-		}
-
-		vr, err := validation.ValidateChanges(cnx, c, changes)
-		if err != nil {
-			LogFatal(err)
-		}
-
-		vr.Slog()
-
-		if ghOut {
-			vr.GithubAnnotate()
-		}
-
-		if !vr.Valid {
-			LogFatal(errors.New(vr.Message))
-		}
-
-		if !dryRun {
-			err = changesPkg.PerformChangesDev(cnx, c, changes, managedFrom)
-			if err != nil {
-				LogFatal(err)
-			}
-			slog.Info("all done!")
-		}
+		summaryMd, _ := cmd.Flags().GetString("summary-md")
+		baseline, _ := cmd.Flags().GetString("baseline")
+		since, _ := cmd.Flags().GetString("since")
+
+		return cli.DSPublishDev(cmd.Context(), cli.DSPublishOptions{
+			Console:    cli.ConsoleOptionsFromFlags(cmd),
+			Paths:      args,
+			DryRun:     dryRun,
+			GhAnnotate: ghOut,
+			SummaryMd:  summaryMd,
+			Baseline:   baseline,
+			Since:      since,
+		})
 	},
 }
 
@@ -134,59 +71,18 @@ environment will be published to your production environment.
 	$ snowplow-cli ds publish prod --dry-run
 	$ snowplow-cli ds publish prod --dry-run ./my-data-structures ./my-other-data-structures
 	`,
-	Run: func(cmd *cobra.Command, args []string) {
-		apiKeyId, _ := cmd.Flags().GetString("api-key-id")
-		apiKeySecret, _ := cmd.Flags().GetString("api-key")
-		host, _ := cmd.Flags().GetString("host")
-		org, _ := cmd.Flags().GetString("org-id")
+	RunE: func(cmd *cobra.Command, args []string) error {
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
-		managedFrom, _ := cmd.Flags().GetString("managed-from")
-
-		dataStructureFolders := []string{util.DataStructuresFolder}
-		if len(args) > 0 {
-			dataStructureFolders = args
-		}
-
-		dataStructuresLocal, err := util.DataStructuresFromPaths(dataStructureFolders)
-		if err != nil {
-			LogFatal(err)
-		}
-
-		errs := validation.ValidateLocalDs(dataStructuresLocal)
-		if len(errs) > 0 {
-			LogFatalMultiple(errs)
-		}
-
-		slog.Info("publishing to prod from", "paths", dataStructureFolders)
-
-		cnx := context.Background()
-
-		c, err := console.NewApiClient(cnx, host, apiKeyId, apiKeySecret, org)
-		if err != nil {
-			LogFatal(err)
-		}
-
-		remotesListing, err := console.GetDataStructureListing(cnx, c)
-		if err != nil {
-			LogFatal(err)
-		}
-
-		changes, err := changesPkg.GetChanges(dataStructuresLocal, remotesListing, "PROD")
-		if err != nil {
-			LogFatal(err)
-		}
-
-		err = changesPkg.PrintChangeset(changes)
-		if err != nil {
-			LogFatal(err)
-		}
-		if !dryRun {
-			err = changesPkg.PerformChangesProd(cnx, c, changes, managedFrom)
-			if err != nil {
-				LogFatal(err)
-			}
-			slog.Info("all done!")
-		}
+		summaryMd, _ := cmd.Flags().GetString("summary-md")
+		since, _ := cmd.Flags().GetString("since")
+
+		return cli.DSPublishProd(cmd.Context(), cli.DSPublishOptions{
+			Console:   cli.ConsoleOptionsFromFlags(cmd),
+			Paths:     args,
+			DryRun:    dryRun,
+			SummaryMd: summaryMd,
+			Since:     since,
+		})
 	},
 }
 
@@ -199,4 +95,11 @@ func init() {
 	prodCmd.PersistentFlags().BoolP("dry-run", "d", false, "Only print planned changes without performing them")
 
 	devCmd.PersistentFlags().Bool("gh-annotate", false, "Output suitable for github workflow annotation (ignores -s)")
+	devCmd.PersistentFlags().String("baseline", "", "File of accepted findings, only new findings are reported")
+
+	devCmd.PersistentFlags().String("summary-md", "", "Write a markdown summary of planned changes and validation results to this file")
+	prodCmd.PersistentFlags().String("summary-md", "", "Write a markdown summary of planned changes to this file")
+
+	devCmd.PersistentFlags().String("since", "", "Only publish data structures changed since this git ref")
+	prodCmd.PersistentFlags().String("since", "", "Only publish data structures changed since this git ref")
 }
@@ -0,0 +1,50 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package ds
+
+import (
+	"github.com/snowplow/snowplow-cli/internal/cli"
+	"github.com/snowplow/snowplow-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var testCmd = &cobra.Command{
+	Use:   "test [paths...] default: [./data-structures]",
+	Short: "Validate example payloads against local data structures",
+	Long: `Validates example payloads against the schema of the local data structure they sit next to.
+
+Examples that should validate go in an examples directory named after the data structure file,
+examples that should not validate go in a counterexamples directory:
+
+  data-structures/com.acme/login_click.yaml
+  data-structures/com.acme/login_click/examples/password.json
+  data-structures/com.acme/login_click/counterexamples/missing_method.json
+
+Examples are either the bare payload or a self describing json referencing the data structure.
+No console access is needed.`,
+	Example: `  $ snowplow-cli ds test
+  $ snowplow-cli ds test ./my-data-structures --gh-annotate`,
+	Annotations: map[string]string{config.LocalOnly: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ghOut, _ := cmd.Flags().GetBool("gh-annotate")
+
+		return cli.DSTest(cmd.Context(), cli.DSTestOptions{
+			Paths:      args,
+			GhAnnotate: ghOut,
+		})
+	},
+}
+
+func init() {
+	DataStructuresCmd.AddCommand(testCmd)
+
+	testCmd.Flags().Bool("gh-annotate", false, "Output suitable for github workflow annotation (ignores -s)")
+}
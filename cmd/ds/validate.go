@@ -11,15 +11,8 @@ OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
 package ds
 
 import (
-	"context"
-	"errors"
-	"log/slog"
-
-	changesPkg "github.com/snowplow/snowplow-cli/internal/changes"
-	"github.com/snowplow/snowplow-cli/internal/console"
-	. "github.com/snowplow/snowplow-cli/internal/logging"
-	"github.com/snowplow/snowplow-cli/internal/util"
-	"github.com/snowplow/snowplow-cli/internal/validation"
+	"github.com/snowplow/snowplow-cli/internal/cli"
+	"github.com/snowplow/snowplow-cli/internal/config"
 	"github.com/spf13/cobra"
 )
 
@@ -27,68 +20,44 @@ var validateCmd = &cobra.Command{
 	Use:   "validate [paths...] default: [./data-structures]",
 	Short: "Validate data structures with BDP Console",
 	Args:  cobra.ArbitraryArgs,
-	Long:  `Sends all data structures from <path> for validation by BDP Console.`,
-	Example: `  $ snowplow-cli ds validate
-  $ snowplow-cli ds validate ./my-data-structures ./my-other-data-structures`,
-	Run: func(cmd *cobra.Command, args []string) {
-		apiKeyId, _ := cmd.Flags().GetString("api-key-id")
-		apiKeySecret, _ := cmd.Flags().GetString("api-key")
-		host, _ := cmd.Flags().GetString("host")
-		org, _ := cmd.Flags().GetString("org-id")
-		ghOut, _ := cmd.Flags().GetBool("gh-annotate")
-
-		dataStructureFolders := []string{util.DataStructuresFolder}
-		if len(args) > 0 {
-			dataStructureFolders = args
-		}
-
-		dataStructuresLocal, err := util.DataStructuresFromPaths(dataStructureFolders)
-		slog.Info("validating from", "paths", dataStructureFolders)
-		if err != nil {
-			LogFatal(err)
-		}
-
-		errs := validation.ValidateLocalDs(dataStructuresLocal)
-		if len(errs) > 0 {
-			LogFatalMultiple(errs)
-		}
-
-		cnx := context.Background()
-
-		c, err := console.NewApiClient(cnx, host, apiKeyId, apiKeySecret, org)
-		if err != nil {
-			LogFatal(err)
-		}
+	Long: `Lints all data structures from <path>, then sends them for validation by BDP Console.
 
-		remotesListing, err := console.GetDataStructureListing(cnx, c)
-		if err != nil {
-			LogFatal(err)
-		}
+Lint rules are configured in the lint section of snowplow.yml, --rule and --disable-rule
+override it for a single run.
 
-		changes, err := changesPkg.GetChanges(dataStructuresLocal, remotesListing, "DEV")
-		if err != nil {
-			LogFatal(err)
-		}
-
-		err = changesPkg.PrintChangeset(changes)
-		if err != nil {
-			LogFatal(err)
-		}
+Findings a data structure accepts are listed with a reason under x-snowplow-cli-ignore in it.
+--write-baseline accepts all current findings at once, pass the file to --baseline later
+so only new findings fail.
 
-		vr, err := validation.ValidateChanges(cnx, c, changes)
+With --since only data structures changed since a git ref are validated.`,
+	Example: `  $ snowplow-cli ds validate
+  $ snowplow-cli ds validate ./my-data-structures ./my-other-data-structures
+  $ snowplow-cli ds validate --rule string-max-length=error --disable-rule snake-case-names
+  $ snowplow-cli ds validate --write-baseline .snowplow-baseline.json
+  $ snowplow-cli ds validate --baseline .snowplow-baseline.json
+  $ snowplow-cli ds validate --since origin/main`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ghOut, _ := cmd.Flags().GetBool("gh-annotate")
+		baseline, _ := cmd.Flags().GetString("baseline")
+		writeBaseline, _ := cmd.Flags().GetString("write-baseline")
+		since, _ := cmd.Flags().GetString("since")
+		lintRules, err := cli.LintRulesFromFlags(cmd)
 		if err != nil {
-			LogFatal(err)
-		}
-
-		vr.Slog()
-
-		if ghOut {
-			vr.GithubAnnotate()
-		}
-
-		if !vr.Valid {
-			LogFatal(errors.New(vr.Message))
+			return err
 		}
+		cfg := config.FromContext(cmd.Context())
+
+		return cli.DSValidate(cmd.Context(), cli.DSValidateOptions{
+			Console:       cli.ConsoleOptionsFromFlags(cmd),
+			Paths:         args,
+			GhAnnotate:    ghOut,
+			LintRules:     lintRules,
+			Policies:      cli.PoliciesFromConfig(cmd),
+			Vendors:       cfg.Lint.Vendors,
+			Baseline:      baseline,
+			WriteBaseline: writeBaseline,
+			Since:         since,
+		})
 	},
 }
 
@@ -96,4 +65,9 @@ func init() {
 	DataStructuresCmd.AddCommand(validateCmd)
 
 	validateCmd.PersistentFlags().Bool("gh-annotate", false, "Output suitable for github workflow annotation (ignores -s)")
+	validateCmd.PersistentFlags().StringArray("rule", []string{}, "Set the severity of a lint rule, eg. --rule string-max-length=error")
+	validateCmd.PersistentFlags().StringArray("disable-rule", []string{}, "Turn a lint rule off")
+	validateCmd.PersistentFlags().String("baseline", "", "File of accepted findings, only new findings are reported")
+	validateCmd.PersistentFlags().String("write-baseline", "", "Write all findings to this file as accepted and succeed")
+	validateCmd.PersistentFlags().String("since", "", "Only validate data structures changed since this git ref")
 }
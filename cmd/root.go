@@ -11,31 +11,126 @@ OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
 	"os"
+	"strings"
 
+	"github.com/snowplow/snowplow-cli/cmd/cache"
+	"github.com/snowplow/snowplow-cli/cmd/catalog"
+	"github.com/snowplow/snowplow-cli/cmd/codegen"
+	"github.com/snowplow/snowplow-cli/cmd/dev"
 	"github.com/snowplow/snowplow-cli/cmd/dp"
 	"github.com/snowplow/snowplow-cli/cmd/ds"
+	"github.com/snowplow/snowplow-cli/internal/cli"
+	"github.com/snowplow/snowplow-cli/internal/tracing"
 	"github.com/snowplow/snowplow-cli/internal/util"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var RootCmd = &cobra.Command{
 	Use:   "snowplow-cli",
 	Short: "Snowplow CLI",
-	Long:  `Work with Snowplow from the command line`,
+	Long: `Work with Snowplow from the command line
+
+` + cli.ExitCodesHelp,
 	Example: `  $ snowplow-cli data-structures download
   $ snowplow-cli ds validate`,
-	Version: util.Version,
+	Version:       util.Version,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	// arguments naming no command are checked here, not by cobra, so they are config errors like other invalid arguments
+	Args:                       unknownCommand,
+	SuggestionsMinimumDistance: 2,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Help()
+	},
+}
+
+// unknownCommand rejects arguments left once subcommands are found, suggesting close commands
+func unknownCommand(cmd *cobra.Command, args []string) error {
+	err := cobra.NoArgs(cmd, args)
+	if err == nil {
+		return nil
+	}
+	if suggestions := cmd.SuggestionsFor(args[0]); len(suggestions) > 0 {
+		return fmt.Errorf("%w\n\nDid you mean this?\n\t%s", err, strings.Join(suggestions, "\n\t"))
+	}
+	return err
 }
 
 func Execute() {
 	err := RootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		for _, e := range cli.Flatten(err) {
+			slog.Error(e.Error())
+		}
+		os.Exit(cli.ExitCode(err))
+	}
+}
+
+// traced runs a command inside a span named after it, exporting the trace when configured
+func traced(run func(*cobra.Command, []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) (err error) {
+		traceFile, _ := cmd.Flags().GetString("trace-file")
+		shutdown, err := tracing.Init(cmd.Context(), traceFile)
+		if err != nil {
+			return cli.ConfigError(fmt.Errorf("tracing setup failed: %w", err))
+		}
+		defer func() {
+			if err := shutdown(context.Background()); err != nil {
+				slog.Warn("failed to export traces", "error", err)
+			}
+		}()
+
+		flags := []string{}
+		cmd.Flags().Visit(func(f *pflag.Flag) {
+			flags = append(flags, f.Name)
+		})
+		cnx, span := tracing.Start(cmd.Context(), cmd.CommandPath(),
+			attribute.String("cli.command", cmd.CommandPath()),
+			attribute.StringSlice("cli.args", args),
+			attribute.StringSlice("cli.flags", flags),
+			attribute.String("cli.version", util.VersionInfo),
+		)
+		defer func() {
+			span.SetAttributes(attribute.Int("cli.exit_code", cli.ExitCode(err)))
+			tracing.End(span, err)
+		}()
+		cmd.SetContext(cnx)
+
+		return run(cmd, args)
+	}
+}
+
+func traceCommands(cmd *cobra.Command) {
+	if cmd.RunE != nil {
+		cmd.RunE = traced(cmd.RunE)
+	}
+	for _, c := range cmd.Commands() {
+		traceCommands(c)
+	}
+}
+
+// configErrors reports invalid arguments with the configuration exit code
+func configErrors(cmd *cobra.Command) {
+	if args := cmd.Args; args != nil {
+		cmd.Args = func(c *cobra.Command, a []string) error {
+			return cli.ConfigError(args(c, a))
+		}
+	}
+	for _, c := range cmd.Commands() {
+		configErrors(c)
 	}
 }
 
 func init() {
+	RootCmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return cli.ConfigError(err)
+	})
 	RootCmd.PersistentFlags().String("config", "",
 		`Config file. Defaults to $HOME/.config/snowplow/snowplow.yml
 Then on:
@@ -47,6 +142,14 @@ Then on:
 	RootCmd.PersistentFlags().BoolP("quiet", "q", false, "Log output level to Warn")
 	RootCmd.PersistentFlags().BoolP("silent", "s", false, "Disable output")
 	RootCmd.PersistentFlags().Bool("json-output", false, "Log output as json")
+	RootCmd.PersistentFlags().String("trace-file", "", `Write OpenTelemetry spans to this file, "-" for stdout.
+Otherwise traces are exported when configured with the standard OTEL_* environment variables`)
 	RootCmd.AddCommand(ds.DataStructuresCmd)
 	RootCmd.AddCommand(dp.DataProductsCmd)
+	RootCmd.AddCommand(dev.DevCmd)
+	RootCmd.AddCommand(codegen.CodegenCmd)
+	RootCmd.AddCommand(catalog.CatalogCmd)
+	RootCmd.AddCommand(cache.CacheCmd)
+	traceCommands(RootCmd)
+	configErrors(RootCmd)
 }
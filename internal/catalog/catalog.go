@@ -0,0 +1,385 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package catalog
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+// Catalog is the tracking plan as rendered, everything cross linked
+type Catalog struct {
+	DataProducts   []*DataProduct
+	SourceApps     []*SourceApp
+	DataStructures []*DataStructure
+	Domains        []Group
+	Owners         []Group
+	// Warnings are about references that could not be resolved
+	Warnings []string
+}
+
+// Group is data products sharing a domain or owner
+type Group struct {
+	Name         string
+	DataProducts []*DataProduct
+}
+
+type DataProduct struct {
+	Name        string
+	Description string
+	Domain      string
+	Owner       string
+	Page        string
+	SourceApps  []*SourceApp
+	EventSpecs  []*EventSpec
+}
+
+type EventSpec struct {
+	Name        string
+	Description string
+	Anchor      string
+	DataProduct *DataProduct
+	// SourceApps are those of the data product less the excluded ones
+	SourceApps []*SourceApp
+	Event      *SchemaRef
+	Entities   []SchemaRef
+	Enriched   []SchemaRef
+	Triggers   []Trigger
+}
+
+type Trigger struct {
+	Description string
+	Url         string
+	AppIds      []string
+	// Image is relative to the output directory, empty when there is none
+	Image string
+	// source is where the image is copied from
+	source string
+}
+
+type SchemaRef struct {
+	Uri         string
+	Cardinality string
+	// DataStructure is nil when it is not available locally
+	DataStructure *DataStructure
+}
+
+type SourceApp struct {
+	Name         string
+	Description  string
+	Owner        string
+	AppIds       []string
+	Page         string
+	Entities     []SchemaRef
+	Enriched     []SchemaRef
+	DataProducts []*DataProduct
+}
+
+type DataStructure struct {
+	Uri         string
+	Vendor      string
+	Name        string
+	Version     string
+	SchemaType  string
+	Description string
+	Hidden      bool
+	Page        string
+	Properties  []Property
+	UsedBy      []Usage
+}
+
+// Usage is an event specification referencing a data structure
+type Usage struct {
+	EventSpec *EventSpec
+	// Role is event or entity
+	Role string
+}
+
+// Property is a row of a data structure property table
+type Property struct {
+	Path        string
+	Type        string
+	Required    bool
+	Description string
+	Constraints []string
+}
+
+type builder struct {
+	catalog *Catalog
+	byUri   map[string]*DataStructure
+	byFile  map[string]*SourceApp
+	slugs   map[string]bool
+}
+
+// Build resolves local data structures and data product resources, keyed by their absolute file path
+func Build(dss map[string]model.DataStructure, resources map[string]map[string]any) (*Catalog, error) {
+	b := &builder{
+		catalog: &Catalog{},
+		byUri:   map[string]*DataStructure{},
+		byFile:  map[string]*SourceApp{},
+		slugs:   map[string]bool{},
+	}
+
+	for _, f := range sortedKeys(dss) {
+		if err := b.dataStructure(dss[f]); err != nil {
+			return nil, fmt.Errorf("file: %s: %w", f, err)
+		}
+	}
+	sort.Slice(b.catalog.DataStructures, func(i, j int) bool {
+		return b.catalog.DataStructures[i].Uri < b.catalog.DataStructures[j].Uri
+	})
+
+	files := sortedKeys(resources)
+	for _, f := range files {
+		if resources[f]["resourceType"] != "source-application" {
+			continue
+		}
+		var sa model.SourceApp
+		if err := mapstructure.Decode(resources[f], &sa); err != nil {
+			return nil, fmt.Errorf("file: %s: %w", f, err)
+		}
+		app := &SourceApp{
+			Name:        sa.Data.Name,
+			Description: sa.Data.Description,
+			Owner:       sa.Data.Owner,
+			AppIds:      sa.Data.AppIds,
+			Page:        b.page("source-applications", sa.Data.Name),
+		}
+		if sa.Data.Entities != nil {
+			app.Entities = b.refs(sa.Data.Entities.Tracked)
+			app.Enriched = b.refs(sa.Data.Entities.Enriched)
+		}
+		b.byFile[f] = app
+		b.catalog.SourceApps = append(b.catalog.SourceApps, app)
+	}
+
+	for _, f := range files {
+		if resources[f]["resourceType"] != "data-product" {
+			continue
+		}
+		var dp model.DataProduct
+		if err := mapstructure.Decode(resources[f], &dp); err != nil {
+			return nil, fmt.Errorf("file: %s: %w", f, err)
+		}
+		b.dataProduct(f, dp)
+	}
+
+	sort.SliceStable(b.catalog.DataProducts, func(i, j int) bool {
+		return strings.ToLower(b.catalog.DataProducts[i].Name) < strings.ToLower(b.catalog.DataProducts[j].Name)
+	})
+	sort.SliceStable(b.catalog.SourceApps, func(i, j int) bool {
+		return strings.ToLower(b.catalog.SourceApps[i].Name) < strings.ToLower(b.catalog.SourceApps[j].Name)
+	})
+	b.catalog.Domains = group(b.catalog.DataProducts, "No domain", func(dp *DataProduct) string { return dp.Domain })
+	b.catalog.Owners = group(b.catalog.DataProducts, "No owner", func(dp *DataProduct) string { return dp.Owner })
+
+	return b.catalog, nil
+}
+
+func (b *builder) dataStructure(ds model.DataStructure) error {
+	data, err := ds.ParseData()
+	if err != nil {
+		return err
+	}
+	uri := data.Self.IgluUri()
+	if _, ok := b.byUri[uri]; ok {
+		return fmt.Errorf("data structure %s is defined more than once", uri)
+	}
+	description, _ := ds.Data["description"].(string)
+	d := &DataStructure{
+		Uri:         uri,
+		Vendor:      data.Self.Vendor,
+		Name:        data.Self.Name,
+		Version:     data.Self.Version,
+		SchemaType:  ds.Meta.SchemaType,
+		Description: description,
+		Hidden:      ds.Meta.Hidden,
+		Page:        b.page("data-structures", data.Self.Vendor+"."+data.Self.Name+"."+data.Self.Version),
+		Properties:  properties(ds.Data, ds.Data, "", 0),
+	}
+	b.byUri[uri] = d
+	b.catalog.DataStructures = append(b.catalog.DataStructures, d)
+	return nil
+}
+
+func (b *builder) dataProduct(file string, dp model.DataProduct) {
+	product := &DataProduct{
+		Name:        dp.Data.Name,
+		Description: dp.Data.Description,
+		Domain:      dp.Data.Domain,
+		Owner:       dp.Data.Owner,
+		Page:        b.page("data-products", dp.Data.Name),
+	}
+	dir := filepath.Dir(file)
+
+	for _, ref := range dp.Data.SourceApplications {
+		if sa := b.sourceApp(dir, ref["$ref"], dp.Data.Name); sa != nil {
+			product.SourceApps = append(product.SourceApps, sa)
+			sa.DataProducts = append(sa.DataProducts, product)
+		}
+	}
+
+	anchors := map[string]bool{}
+	for _, es := range dp.Data.EventSpecifications {
+		spec := &EventSpec{
+			Name:        es.Name,
+			Description: es.Description,
+			Anchor:      unique(anchors, slug(es.Name)),
+			DataProduct: product,
+			Entities:    b.refs(es.Entities.Tracked),
+			Enriched:    b.refs(es.Entities.Enriched),
+		}
+
+		excluded := map[*SourceApp]bool{}
+		for _, ref := range es.ExcludedSourceApplications {
+			if sa := b.sourceApp(dir, ref["$ref"], dp.Data.Name); sa != nil {
+				excluded[sa] = true
+			}
+		}
+		for _, sa := range product.SourceApps {
+			if !excluded[sa] {
+				spec.SourceApps = append(spec.SourceApps, sa)
+			}
+		}
+
+		if es.Event.Source != "" {
+			event := b.ref(es.Event)
+			spec.Event = &event
+			if event.DataStructure != nil {
+				event.DataStructure.UsedBy = append(event.DataStructure.UsedBy, Usage{spec, "event"})
+			}
+		}
+		for _, e := range spec.Entities {
+			if e.DataStructure != nil {
+				e.DataStructure.UsedBy = append(e.DataStructure.UsedBy, Usage{spec, "entity"})
+			}
+		}
+
+		for i, t := range es.Triggers {
+			trigger := Trigger{Description: t.Description, Url: t.Url, AppIds: t.AppIds}
+			if t.Image != nil && t.Image.Ref != "" {
+				trigger.source = filepath.Clean(filepath.Join(dir, t.Image.Ref))
+				trigger.Image = fmt.Sprintf("images/%s-%s-%d%s", path.Base(product.Page), spec.Anchor, i+1, filepath.Ext(t.Image.Ref))
+			}
+			spec.Triggers = append(spec.Triggers, trigger)
+		}
+
+		product.EventSpecs = append(product.EventSpecs, spec)
+	}
+
+	b.catalog.DataProducts = append(b.catalog.DataProducts, product)
+}
+
+func (b *builder) sourceApp(dir string, ref string, dataProduct string) *SourceApp {
+	if ref == "" {
+		return nil
+	}
+	file, err := filepath.Abs(filepath.Join(dir, ref))
+	if err == nil {
+		if sa, ok := b.byFile[file]; ok {
+			return sa
+		}
+	}
+	b.catalog.Warnings = append(b.catalog.Warnings, fmt.Sprintf("%s references source application %s which was not found", dataProduct, ref))
+	return nil
+}
+
+func (b *builder) refs(refs []model.SchemaRef) []SchemaRef {
+	res := []SchemaRef{}
+	for _, r := range refs {
+		res = append(res, b.ref(r))
+	}
+	return res
+}
+
+func (b *builder) ref(r model.SchemaRef) SchemaRef {
+	return SchemaRef{
+		Uri:           r.Source,
+		Cardinality:   cardinality(r.MinCardinality, r.MaxCardinality),
+		DataStructure: b.byUri[r.Source],
+	}
+}
+
+// page is the path of a new page in dir relative to the output directory, without an extension
+func (b *builder) page(dir string, name string) string {
+	return unique(b.slugs, dir+"/"+slug(name))
+}
+
+func cardinality(min *int, max *int) string {
+	switch {
+	case min == nil && max == nil:
+		return ""
+	case max == nil:
+		return fmt.Sprintf("%d..*", *min)
+	case min == nil:
+		return fmt.Sprintf("0..%d", *max)
+	case *min == *max:
+		return fmt.Sprintf("%d", *min)
+	}
+	return fmt.Sprintf("%d..%d", *min, *max)
+}
+
+func group(dps []*DataProduct, none string, key func(*DataProduct) string) []Group {
+	byName := map[string][]*DataProduct{}
+	for _, dp := range dps {
+		k := key(dp)
+		if k == "" {
+			k = none
+		}
+		byName[k] = append(byName[k], dp)
+	}
+	groups := []Group{}
+	for _, name := range sortedKeys(byName) {
+		if name != none {
+			groups = append(groups, Group{name, byName[name]})
+		}
+	}
+	if dps, ok := byName[none]; ok {
+		groups = append(groups, Group{none, dps})
+	}
+	return groups
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9._]+`)
+
+func slug(s string) string {
+	res := strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	if res == "" {
+		return "unnamed"
+	}
+	return res
+}
+
+// unique suffixes s with a counter when it has been seen
+func unique(seen map[string]bool, s string) string {
+	res := s
+	for i := 2; seen[res]; i++ {
+		res = fmt.Sprintf("%s-%d", s, i)
+	}
+	seen[res] = true
+	return res
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,218 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+func testDataStructures() map[string]model.DataStructure {
+	self := func(name string) map[string]any {
+		return map[string]any{"vendor": "com.acme", "name": name, "format": "jsonschema", "version": "1-0-0"}
+	}
+	return map[string]model.DataStructure{
+		"/ds/checkout.yaml": {
+			Meta: model.DataStructureMeta{SchemaType: "event"},
+			Data: map[string]any{
+				"$schema":     "http://iglucentral.com/schemas/com.snowplowanalytics.self-desc/schema/jsonschema/1-0-0#",
+				"self":        self("checkout"),
+				"description": "A checkout",
+				"type":        "object",
+				"definitions": map[string]any{"money": map[string]any{"type": "number", "minimum": 0}},
+				"properties": map[string]any{
+					"step":  map[string]any{"type": "string", "enum": []any{"cart", "payment"}, "description": "Where"},
+					"total": map[string]any{"$ref": "#/definitions/money"},
+					"lines": map[string]any{"type": "array", "items": map[string]any{
+						"type":       "object",
+						"properties": map[string]any{"sku": map[string]any{"type": []any{"string", "null"}, "maxLength": 10}},
+						"required":   []any{"sku"},
+					}},
+				},
+				"required": []any{"step"},
+			},
+		},
+		"/ds/product.yaml": {
+			Meta: model.DataStructureMeta{SchemaType: "entity", Hidden: true},
+			Data: map[string]any{
+				"$schema": "http://iglucentral.com/schemas/com.snowplowanalytics.self-desc/schema/jsonschema/1-0-0#",
+				"self":    self("product"),
+				"type":    "object",
+			},
+		},
+	}
+}
+
+func testResources(dir string) map[string]map[string]any {
+	return map[string]map[string]any{
+		filepath.Join(dir, "source-apps", "web.yaml"): {
+			"resourceType": "source-application",
+			"data":         map[string]any{"name": "Web", "owner": "web@acme.com", "appIds": []any{"web"}},
+		},
+		filepath.Join(dir, "shop.yaml"): {
+			"resourceType": "data-product",
+			"data": map[string]any{
+				"name":   "Shop",
+				"domain": "Commerce",
+				"sourceApplications": []any{
+					map[string]any{"$ref": "./source-apps/web.yaml"},
+					map[string]any{"$ref": "./source-apps/missing.yaml"},
+				},
+				"eventSpecifications": []any{
+					map[string]any{
+						"name":  "Checkout started",
+						"event": map[string]any{"source": "iglu:com.acme/checkout/jsonschema/1-0-0"},
+						"entities": map[string]any{"tracked": []any{
+							map[string]any{"source": "iglu:com.acme/product/jsonschema/1-0-0", "minCardinality": 1},
+							map[string]any{"source": "iglu:com.acme/user/jsonschema/1-0-0", "minCardinality": 0, "maxCardinality": 1},
+						}},
+						"triggers": []any{map[string]any{"description": "Click", "image": map[string]any{"$ref": "./images/click.png"}}},
+					},
+					map[string]any{
+						"name":                       "Checkout started",
+						"excludedSourceApplications": []any{map[string]any{"$ref": "./source-apps/web.yaml"}},
+					},
+				},
+			},
+		},
+		filepath.Join(dir, "admin.yaml"): {
+			"resourceType": "data-product",
+			"data":         map[string]any{"name": "Admin", "owner": "ops@acme.com"},
+		},
+	}
+}
+
+func Test_Build(t *testing.T) {
+	c, err := Build(testDataStructures(), testResources("/dp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c.DataProducts) != 2 || c.DataProducts[0].Name != "Admin" {
+		t.Fatalf("expected data products sorted by name got %+v", c.DataProducts)
+	}
+	shop := c.DataProducts[1]
+	if shop.Page != "data-products/shop" || len(shop.SourceApps) != 1 || shop.SourceApps[0].Name != "Web" {
+		t.Fatalf("unexpected data product %+v", shop)
+	}
+	if len(c.Warnings) != 1 || !strings.Contains(c.Warnings[0], "missing.yaml") {
+		t.Fatalf("expected a warning for the missing source application got %v", c.Warnings)
+	}
+	if web := c.SourceApps[0]; len(web.DataProducts) != 1 || web.DataProducts[0] != shop {
+		t.Fatalf("expected source application to link back to its data product got %+v", web)
+	}
+
+	first, second := shop.EventSpecs[0], shop.EventSpecs[1]
+	if first.Anchor != "checkout-started" || second.Anchor != "checkout-started-2" {
+		t.Fatalf("expected unique anchors got %s %s", first.Anchor, second.Anchor)
+	}
+	if len(first.SourceApps) != 1 || len(second.SourceApps) != 0 {
+		t.Fatal("expected excluded source applications to be left out")
+	}
+	if first.Event.DataStructure == nil || first.Event.DataStructure.Name != "checkout" {
+		t.Fatalf("expected event linked to its data structure got %+v", first.Event)
+	}
+	if first.Entities[0].Cardinality != "1..*" || first.Entities[1].Cardinality != "0..1" || first.Entities[1].DataStructure != nil {
+		t.Fatalf("unexpected entities %+v", first.Entities)
+	}
+	if first.Triggers[0].Image != "images/shop-checkout-started-1.png" || first.Triggers[0].source != filepath.Join("/dp", "images", "click.png") {
+		t.Fatalf("unexpected trigger %+v", first.Triggers[0])
+	}
+
+	domains := []string{}
+	for _, g := range c.Domains {
+		domains = append(domains, g.Name)
+	}
+	if !reflect.DeepEqual(domains, []string{"Commerce", "No domain"}) {
+		t.Fatalf("unexpected domains %v", domains)
+	}
+
+	checkout := c.DataStructures[0]
+	if checkout.Page != "data-structures/com.acme.checkout.1-0-0" || len(checkout.UsedBy) != 1 || checkout.UsedBy[0].Role != "event" {
+		t.Fatalf("unexpected data structure %+v", checkout)
+	}
+	if product := c.DataStructures[1]; len(product.UsedBy) != 1 || product.UsedBy[0].Role != "entity" {
+		t.Fatalf("unexpected data structure %+v", product)
+	}
+
+	expected := []Property{
+		{Path: "lines", Type: "array of object", Constraints: []string{}},
+		{Path: "lines[].sku", Type: "string | null", Required: true, Constraints: []string{"maxLength: 10"}},
+		{Path: "step", Type: "string", Required: true, Description: "Where", Constraints: []string{"one of cart, payment"}},
+		{Path: "total", Type: "number", Constraints: []string{"minimum: 0"}},
+	}
+	if !reflect.DeepEqual(checkout.Properties, expected) {
+		t.Fatalf("unexpected properties\n%+v\n%+v", checkout.Properties, expected)
+	}
+}
+
+func Test_Write(t *testing.T) {
+	dp := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dp, "images"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dp, "images", "click.png"), []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range Formats {
+		c, err := Build(testDataStructures(), testResources(dp))
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := filepath.Join(t.TempDir(), "site")
+		if err := Write(c, out, format); err != nil {
+			t.Fatal(err)
+		}
+
+		ext := ".html"
+		link := `href="../data-structures/com.acme.checkout.1-0-0.html"`
+		if format == "markdown" {
+			ext = ".md"
+			link = "(../data-structures/com.acme.checkout.1-0-0.md)"
+		}
+		for _, f := range []string{"index", "data-products/shop", "data-products/admin", "source-applications/web", "data-structures/com.acme.product.1-0-0"} {
+			if _, err := os.Stat(filepath.Join(out, f+ext)); err != nil {
+				t.Fatalf("%s: expected %s to be written", format, f)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(out, "images", "shop-checkout-started-1.png")); err != nil {
+			t.Fatalf("%s: expected trigger image to be copied", format)
+		}
+		shop, err := os.ReadFile(filepath.Join(out, "data-products", "shop"+ext))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(shop), link) {
+			t.Fatalf("%s: expected link to the event data structure in\n%s", format, shop)
+		}
+	}
+
+	c, err := Build(testDataStructures(), testResources(t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(c, t.TempDir(), "html"); err != nil {
+		t.Fatal(err)
+	}
+	if c.DataProducts[1].EventSpecs[0].Triggers[0].Image != "" || len(c.Warnings) != 2 {
+		t.Fatalf("expected a missing image to be dropped with a warning got %v", c.Warnings)
+	}
+
+	if err := Write(c, t.TempDir(), "pdf"); err == nil {
+		t.Fatal("expected unsupported format to fail")
+	}
+}
@@ -0,0 +1,176 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package catalog
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/snowplow/snowplow-cli/internal/util"
+)
+
+//go:embed templates
+var templates embed.FS
+
+// Formats a catalog can be written as
+var Formats = []string{"html", "markdown"}
+
+// page is what every template is executed with
+type page struct {
+	// Root leads from the page back to the output directory
+	Root    string
+	Ext     string
+	Title   string
+	Catalog *Catalog
+	Item    any
+}
+
+// Link is the relative url of the page at target
+func (p page) Link(target string) string {
+	return p.Root + target + p.Ext
+}
+
+// With passes v to a partial template along with the page
+func (p page) With(v any) partial {
+	return partial{p, v}
+}
+
+type partial struct {
+	Page page
+	V    any
+}
+
+type executor interface {
+	ExecuteTemplate(w io.Writer, name string, data any) error
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`, "#", `\#`,
+)
+
+func markdownText(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+func markdownCell(s string) string {
+	return util.MarkdownCell(markdownText(s))
+}
+
+func parse(format string, name string) (executor, error) {
+	switch format {
+	case "html":
+		return htmltemplate.ParseFS(templates, "templates/html/layout.html", "templates/html/"+name+".html")
+	case "markdown":
+		funcs := texttemplate.FuncMap{"text": markdownText, "cell": markdownCell}
+		return texttemplate.New(name).Funcs(funcs).ParseFS(templates, "templates/markdown/partials.md", "templates/markdown/"+name+".md")
+	}
+	return nil, fmt.Errorf("unsupported format %s, expected one of %s", format, strings.Join(Formats, ", "))
+}
+
+// Write renders the catalog into out. Trigger images that cannot be copied are left out with a warning
+func Write(c *Catalog, out string, format string) error {
+	ext := ".html"
+	entry := "layout"
+	if format == "markdown" {
+		ext = ".md"
+		entry = "page"
+	}
+
+	tpls := map[string]executor{}
+	for _, name := range []string{"index", "data-product", "source-app", "data-structure"} {
+		t, err := parse(format, name)
+		if err != nil {
+			return err
+		}
+		tpls[name] = t
+	}
+
+	for _, dir := range []string{"data-products", "source-applications", "data-structures"} {
+		if err := os.MkdirAll(filepath.Join(out, dir), os.ModePerm); err != nil {
+			return err
+		}
+	}
+
+	for _, dp := range c.DataProducts {
+		for _, es := range dp.EventSpecs {
+			for i, t := range es.Triggers {
+				if t.Image == "" {
+					continue
+				}
+				if err := copyFile(t.source, filepath.Join(out, filepath.FromSlash(t.Image))); err != nil {
+					c.Warnings = append(c.Warnings, fmt.Sprintf("trigger image of %s in %s was not copied: %s", es.Name, dp.Name, err))
+					es.Triggers[i].Image = ""
+				}
+			}
+		}
+	}
+
+	write := func(tpl string, file string, title string, item any) error {
+		p := page{Ext: ext, Title: title, Catalog: c, Item: item}
+		p.Root = strings.Repeat("../", strings.Count(file, "/"))
+		var buf bytes.Buffer
+		if err := tpls[tpl].ExecuteTemplate(&buf, entry, p); err != nil {
+			return err
+		}
+		name := filepath.Join(out, filepath.FromSlash(file)+ext)
+		slog.Debug("catalog", "msg", "wrote", "file", name)
+		return os.WriteFile(name, buf.Bytes(), 0644)
+	}
+
+	if err := write("index", "index", "Tracking plan", nil); err != nil {
+		return err
+	}
+	for _, dp := range c.DataProducts {
+		if err := write("data-product", dp.Page, dp.Name, dp); err != nil {
+			return err
+		}
+	}
+	for _, sa := range c.SourceApps {
+		if err := write("source-app", sa.Page, sa.Name, sa); err != nil {
+			return err
+		}
+	}
+	for _, ds := range c.DataStructures {
+		if err := write("data-structure", ds.Page, ds.Name+" "+ds.Version, ds); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func copyFile(from string, to string) error {
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	if err := os.MkdirAll(filepath.Dir(to), os.ModePerm); err != nil {
+		return err
+	}
+	dst, err := os.Create(to)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
@@ -0,0 +1,152 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// maxDepth stops recursive $refs from nesting forever
+const maxDepth = 8
+
+// properties flattens the properties of schema into table rows, nested ones get dotted paths
+func properties(root map[string]any, schema map[string]any, prefix string, depth int) []Property {
+	schema = resolve(root, schema)
+	if depth > maxDepth {
+		return nil
+	}
+
+	required := []string{}
+	if r, ok := schema["required"].([]any); ok {
+		for _, k := range r {
+			if s, ok := k.(string); ok {
+				required = append(required, s)
+			}
+		}
+	}
+
+	props, _ := schema["properties"].(map[string]any)
+	res := []Property{}
+	for _, key := range sortedKeys(props) {
+		prop, ok := props[key].(map[string]any)
+		if !ok {
+			continue
+		}
+		prop = resolve(root, prop)
+		path := prefix + key
+		description, _ := prop["description"].(string)
+		res = append(res, Property{
+			Path:        path,
+			Type:        typeOf(prop),
+			Required:    slices.Contains(required, key),
+			Description: description,
+			Constraints: constraints(prop),
+		})
+		res = append(res, properties(root, prop, path+".", depth+1)...)
+		if items, ok := prop["items"].(map[string]any); ok {
+			res = append(res, properties(root, items, path+"[].", depth+1)...)
+		}
+	}
+	return res
+}
+
+// resolve follows a local $ref, other refs are left as is
+func resolve(root map[string]any, schema map[string]any) map[string]any {
+	for i := 0; i < maxDepth; i++ {
+		ref, ok := schema["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#/") {
+			return schema
+		}
+		var node any = root
+		for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			m, ok := node.(map[string]any)
+			if !ok {
+				return schema
+			}
+			node = m[strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")]
+		}
+		next, ok := node.(map[string]any)
+		if !ok {
+			return schema
+		}
+		schema = next
+	}
+	return schema
+}
+
+func typeOf(schema map[string]any) string {
+	types := []string{}
+	switch t := schema["type"].(type) {
+	case string:
+		types = append(types, t)
+	case []any:
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+	}
+	for i, t := range types {
+		if t != "array" {
+			continue
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			if item := typeOf(items); item != "" {
+				types[i] = "array of " + item
+			}
+		}
+	}
+	if len(types) == 0 {
+		for _, k := range []string{"oneOf", "anyOf", "allOf"} {
+			if _, ok := schema[k]; ok {
+				return k
+			}
+		}
+	}
+	return strings.Join(types, " | ")
+}
+
+// constraint keywords in the order they are listed
+var constraintKeywords = []string{
+	"format", "pattern", "minLength", "maxLength", "minimum", "exclusiveMinimum", "maximum",
+	"exclusiveMaximum", "multipleOf", "minItems", "maxItems", "uniqueItems", "minProperties", "maxProperties",
+}
+
+func constraints(schema map[string]any) []string {
+	res := []string{}
+	if enum, ok := schema["enum"].([]any); ok {
+		values := []string{}
+		for _, v := range enum {
+			values = append(values, value(v))
+		}
+		res = append(res, "one of "+strings.Join(values, ", "))
+	}
+	for _, k := range constraintKeywords {
+		if v, ok := schema[k]; ok {
+			res = append(res, fmt.Sprintf("%s: %s", k, value(v)))
+		}
+	}
+	return res
+}
+
+func value(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
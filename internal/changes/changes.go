@@ -19,6 +19,9 @@ import (
 	"github.com/r3labs/diff/v3"
 	. "github.com/snowplow/snowplow-cli/internal/console"
 	. "github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type DataStructureWithDiff struct {
@@ -118,10 +121,26 @@ func GetChanges(locals map[string]DataStructure, remoteListing []ListResponse, e
 	return res, nil
 }
 
+// dsSpan traces a single data structure being applied
+func dsSpan(cnx context.Context, operation string, ds DSChangeContext) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		attribute.String("snowplow.operation", operation),
+		attribute.String("snowplow.file", ds.FileName),
+	}
+	if data, err := ds.DS.ParseData(); err == nil {
+		attrs = append(attrs,
+			attribute.String("snowplow.data_structure.vendor", data.Self.Vendor),
+			attribute.String("snowplow.data_structure.name", data.Self.Name),
+			attribute.String("snowplow.data_structure.version", data.Self.Version),
+		)
+	}
+	return tracing.Start(cnx, "data structure "+operation, attrs...)
+}
+
 func PerformChangesDev(cnx context.Context, c *ApiClient, changes Changes, managedFrom string) error {
-	// Create and create new version both follow the same logic
-	validatePublish := append(changes.ToCreate, changes.ToUpdateNewVersion...)
-	for _, ds := range validatePublish {
+	publishDev := func(operation string, ds DSChangeContext, isPatch bool) (err error) {
+		cnx, span := dsSpan(cnx, operation, ds)
+		defer func() { tracing.End(span, err) }()
 		vr, err := Validate(cnx, c, ds.DS)
 		if err != nil {
 			return err
@@ -129,26 +148,26 @@ func PerformChangesDev(cnx context.Context, c *ApiClient, changes Changes, manag
 		if !vr.Valid {
 			return errors.New(vr.Message)
 		}
-		_, err = PublishDev(cnx, c, ds.DS, false, managedFrom)
+		_, err = PublishDev(cnx, c, ds.DS, isPatch, managedFrom)
+		return err
+	}
+
+	// Create and create new version both follow the same logic
+	validatePublish := append(changes.ToCreate, changes.ToUpdateNewVersion...)
+	for _, ds := range validatePublish {
+		err := publishDev("publish", ds, false)
 		if err != nil {
 			return err
 		}
 	}
 	for _, ds := range changes.ToUpdatePatch {
-		vr, err := Validate(cnx, c, ds.DS)
-		if err != nil {
-			return err
-		}
-		if !vr.Valid {
-			return errors.New(vr.Message)
-		}
-		_, err = PublishDev(cnx, c, ds.DS, true, managedFrom)
+		err := publishDev("patch", ds, true)
 		if err != nil {
 			return err
 		}
 	}
 	for _, ds := range changes.ToUpdateMeta {
-		err := MetadateUpdate(cnx, c, &ds.DS, managedFrom)
+		err := updateMeta(cnx, c, ds, managedFrom)
 		if err != nil {
 			return err
 		}
@@ -157,19 +176,30 @@ func PerformChangesDev(cnx context.Context, c *ApiClient, changes Changes, manag
 	return nil
 }
 
+func updateMeta(cnx context.Context, c *ApiClient, ds DSChangeContext, managedFrom string) error {
+	cnx, span := dsSpan(cnx, "metadata update", ds)
+	err := MetadateUpdate(cnx, c, &ds.DS, managedFrom)
+	tracing.End(span, err)
+	return err
+}
+
+var ErrProdPatch = errors.New("patching is not available on prod. You must increment versions on dev before deploying")
+
 func PerformChangesProd(cnx context.Context, c *ApiClient, changes Changes, managedFrom string) error {
 	if len(changes.ToUpdatePatch) != 0 {
-		return errors.New("patching is not available on prod. You must increment versions on dev before deploying")
+		return ErrProdPatch
 	}
 	validatePublish := append(changes.ToCreate, changes.ToUpdateNewVersion...)
 	for _, ds := range validatePublish {
+		cnx, span := dsSpan(cnx, "publish", ds)
 		_, err := PublishProd(cnx, c, ds.DS, managedFrom)
+		tracing.End(span, err)
 		if err != nil {
 			return err
 		}
 	}
 	for _, ds := range changes.ToUpdateMeta {
-		err := MetadateUpdate(cnx, c, &ds.DS, managedFrom)
+		err := updateMeta(cnx, c, ds, managedFrom)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,128 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package changes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/r3labs/diff/v3"
+	. "github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/util"
+	"gopkg.in/yaml.v3"
+)
+
+// RemoteDataFetcher returns the remote data section of a data structure version
+type RemoteDataFetcher = func(self DataStructureSelf, version string) (map[string]any, error)
+
+func MarkdownChangeset(b *strings.Builder, changes Changes, fetch RemoteDataFetcher) error {
+	b.WriteString("### Data structures\n\n")
+
+	total := len(changes.ToCreate) + len(changes.ToUpdateNewVersion) + len(changes.ToUpdatePatch) + len(changes.ToUpdateMeta)
+	if total == 0 {
+		b.WriteString("No changes detected.\n\n")
+		return nil
+	}
+
+	b.WriteString("| Change | Count |\n|---|---|\n")
+	fmt.Fprintf(b, "| create | %d |\n", len(changes.ToCreate))
+	fmt.Fprintf(b, "| new version | %d |\n", len(changes.ToUpdateNewVersion))
+	fmt.Fprintf(b, "| patch | %d |\n", len(changes.ToUpdatePatch))
+	fmt.Fprintf(b, "| meta update | %d |\n\n", len(changes.ToUpdateMeta))
+
+	sections := []struct {
+		title   string
+		changes []DSChangeContext
+		diff    bool
+	}{
+		{"Create", changes.ToCreate, false},
+		{"New version", changes.ToUpdateNewVersion, true},
+		{"Patch", changes.ToUpdatePatch, true},
+	}
+
+	for _, s := range sections {
+		if len(s.changes) == 0 {
+			continue
+		}
+		fmt.Fprintf(b, "#### %s\n\n", s.title)
+		for _, ds := range sortedByFile(s.changes) {
+			data, err := ds.DS.ParseData()
+			if err != nil {
+				return err
+			}
+			uri := fmt.Sprintf("%s/%s/%s", data.Self.Vendor, data.Self.Name, data.Self.Format)
+			version := data.Self.Version
+			if ds.RemoteVersion != "" && ds.RemoteVersion != version {
+				version = fmt.Sprintf("%s → %s", ds.RemoteVersion, version)
+			}
+			fmt.Fprintf(b, "- `%s` %s (`%s`)\n", uri, version, ds.FileName)
+
+			body, lang, err := changeBody(ds, data, s.diff, fetch)
+			if err != nil {
+				return err
+			}
+			if body != "" {
+				b.WriteString("\n")
+				b.WriteString(util.MarkdownDetails(ds.FileName, lang, body))
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	if len(changes.ToUpdateMeta) > 0 {
+		b.WriteString("#### Meta update\n\n")
+		for _, ds := range sortedByFile(changes.ToUpdateMeta) {
+			data, err := ds.DS.ParseData()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(b, "- `%s/%s/%s` (`%s`)\n", data.Self.Vendor, data.Self.Name, data.Self.Format, ds.FileName)
+			meta, err := yaml.Marshal(ds.DS.Meta)
+			if err != nil {
+				return err
+			}
+			b.WriteString("\n")
+			b.WriteString(util.MarkdownDetails(ds.FileName, "yaml", string(meta)))
+		}
+		b.WriteString("\n")
+	}
+
+	return nil
+}
+
+func changeBody(ds DSChangeContext, data DataStructureData, withDiff bool, fetch RemoteDataFetcher) (body string, lang string, err error) {
+	if withDiff && fetch != nil && ds.RemoteVersion != "" {
+		remote, err := fetch(data.Self, ds.RemoteVersion)
+		if err != nil {
+			return "", "", err
+		}
+		changelog, err := diff.Diff(remote, ds.DS.Data)
+		if err != nil {
+			return "", "", err
+		}
+		return util.MarkdownChangelog(changelog), "diff", nil
+	}
+
+	full, err := yaml.Marshal(ds.DS.Data)
+	if err != nil {
+		return "", "", err
+	}
+	return string(full), "yaml", nil
+}
+
+func sortedByFile(dss []DSChangeContext) []DSChangeContext {
+	sorted := append([]DSChangeContext{}, dss...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].FileName < sorted[j].FileName
+	})
+	return sorted
+}
@@ -0,0 +1,72 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package changes
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/snowplow/snowplow-cli/internal/model"
+)
+
+func Test_MarkdownChangeset_Empty(t *testing.T) {
+	var b strings.Builder
+	err := MarkdownChangeset(&b, Changes{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "No changes detected.") {
+		t.Fatalf("expected no changes message, got %s", b.String())
+	}
+}
+
+func Test_MarkdownChangeset_PatchDiff(t *testing.T) {
+	local := DataStructure{
+		Meta: DataStructureMeta{SchemaType: "event"},
+		Data: map[string]any{
+			"self": map[string]any{
+				"vendor":  "com.acme",
+				"name":    "login",
+				"format":  "jsonschema",
+				"version": "1-0-0",
+			},
+			"description": "new",
+		},
+	}
+	changes := Changes{
+		ToUpdatePatch: []DSChangeContext{NewDSChangeContextWithVersionAndHashes(local, "login.yaml", "1-0-0", "a", "b")},
+	}
+
+	fetched := false
+	fetch := func(self DataStructureSelf, version string) (map[string]any, error) {
+		fetched = true
+		if self.Name != "login" || version != "1-0-0" {
+			t.Fatalf("unexpected fetch %+v %s", self, version)
+		}
+		return map[string]any{"self": local.Data["self"], "description": "old"}, nil
+	}
+
+	var b strings.Builder
+	err := MarkdownChangeset(&b, changes, fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := b.String()
+	if !fetched {
+		t.Fatal("expected remote version to be fetched")
+	}
+	for _, expected := range []string{"| patch | 1 |", "`com.acme/login/jsonschema` 1-0-0", "<details><summary>login.yaml</summary>", `- /description: "old"`, `+ /description: "new"`} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected output to contain %q, got:\n%s", expected, out)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package cli
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/snowplow/snowplow-cli/internal/console"
+)
+
+type CacheOptions struct {
+	// Dir defaults to console.DefaultCacheDir
+	Dir string
+}
+
+func (o CacheOptions) dir() (string, error) {
+	if o.Dir != "" {
+		return o.Dir, nil
+	}
+	dir, err := console.DefaultCacheDir()
+	if err != nil {
+		return "", ConfigError(err)
+	}
+	return dir, nil
+}
+
+func CacheInfo(opts CacheOptions) (console.CacheInfo, error) {
+	dir, err := opts.dir()
+	if err != nil {
+		return console.CacheInfo{}, err
+	}
+	info, err := console.ReadCacheInfo(dir)
+	if err != nil {
+		return info, err
+	}
+
+	attrs := []any{"msg", "console responses", "dir", info.Dir, "entries", info.Entries, "bytes", info.Bytes}
+	if info.Entries > 0 {
+		attrs = append(attrs, "oldest", info.Oldest.Format(time.RFC3339), "newest", info.Newest.Format(time.RFC3339))
+	}
+	slog.Info("cache", attrs...)
+	return info, nil
+}
+
+func CacheClear(opts CacheOptions) error {
+	dir, err := opts.dir()
+	if err != nil {
+		return err
+	}
+	if err := console.ClearCache(dir); err != nil {
+		return err
+	}
+	slog.Info("cache", "msg", "cleared", "dir", dir)
+	return nil
+}
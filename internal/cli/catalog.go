@@ -0,0 +1,81 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package cli
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"slices"
+
+	"github.com/snowplow/snowplow-cli/internal/catalog"
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/util"
+)
+
+type CatalogBuildOptions struct {
+	Out                     string
+	Format                  string
+	DataStructuresDirectory string
+	DataProductsDirectory   string
+}
+
+// CatalogBuild renders local data products, source applications and data structures as a static site
+func CatalogBuild(cnx context.Context, opts CatalogBuildOptions) error {
+	if !slices.Contains(catalog.Formats, opts.Format) {
+		return configErrorf("unsupported format %s, expected one of %v", opts.Format, catalog.Formats)
+	}
+
+	dsDir := opts.DataStructuresDirectory
+	if dsDir == "" {
+		dsDir = util.DataStructuresFolder
+	}
+	dss := map[string]model.DataStructure{}
+	if _, err := os.Stat(dsDir); err == nil {
+		dss, err = readLocalDataStructures(cnx, []string{dsDir})
+		if err != nil {
+			return err
+		}
+	} else {
+		slog.Debug("catalog", "msg", "no data structures found", "path", dsDir)
+	}
+
+	dpDir := opts.DataProductsDirectory
+	if dpDir == "" {
+		dpDir = util.DataProductsFolder
+	}
+	resources := map[string]map[string]any{}
+	if _, err := os.Stat(dpDir); err == nil {
+		resources, err = readLocalResources(cnx, []string{dpDir})
+		if err != nil {
+			return err
+		}
+	} else {
+		slog.Debug("catalog", "msg", "no data products found", "path", dpDir)
+	}
+
+	c, err := catalog.Build(dss, resources)
+	if err != nil {
+		return ValidationError(err)
+	}
+
+	err = catalog.Write(c, opts.Out, opts.Format)
+	if err != nil {
+		return err
+	}
+	for _, w := range c.Warnings {
+		slog.Warn("catalog", "msg", w)
+	}
+
+	slog.Info("catalog", "msg", "wrote", "directory", opts.Out,
+		"data products", len(c.DataProducts), "source applications", len(c.SourceApps), "data structures", len(c.DataStructures))
+	return nil
+}
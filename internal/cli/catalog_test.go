@@ -0,0 +1,60 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_CatalogBuild(t *testing.T) {
+	dir := t.TempDir()
+	dsDir := filepath.Join(dir, "data-structures")
+	if err := os.Mkdir(dsDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	_, err := DSGenerate(DSGenerateOptions{
+		Name:      "login_click",
+		Vendor:    "com.acme",
+		Directory: dsDir,
+		Format:    "yaml",
+		Event:     true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(dir, "site")
+	opts := CatalogBuildOptions{
+		Out:                     out,
+		Format:                  "markdown",
+		DataStructuresDirectory: dsDir,
+		DataProductsDirectory:   filepath.Join(dir, "data-products"),
+	}
+
+	err = CatalogBuild(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{"index.md", "data-structures/com.acme.login_click.1-0-0.md"} {
+		if _, err := os.Stat(filepath.Join(out, f)); err != nil {
+			t.Fatalf("expected %s to be written", f)
+		}
+	}
+
+	opts.Format = "pdf"
+	err = CatalogBuild(context.Background(), opts)
+	if ExitCode(err) != ExitConfig {
+		t.Fatalf("expected config error got %v", err)
+	}
+}
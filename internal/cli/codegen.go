@@ -0,0 +1,143 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"sort"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/snowplow/snowplow-cli/internal/codegen"
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/util"
+)
+
+type CodegenOptions struct {
+	Lang                    string
+	DataStructuresDirectory string
+	DataProductsDirectory   string
+	// Package of go and kotlin output
+	Package string
+	// Out is the file written, "-" for stdout and a default per language when empty
+	Out string
+}
+
+var codegenFiles = map[string]string{
+	"typescript": "snowplow.ts",
+	"go":         "snowplow.go",
+	"kotlin":     "Snowplow.kt",
+	"swift":      "Snowplow.swift",
+}
+
+// Codegen writes typed tracking code for local data structures and event specifications
+func Codegen(cnx context.Context, opts CodegenOptions) error {
+	if !slices.Contains(codegen.Languages, opts.Lang) {
+		return configErrorf("unsupported language %s, expected one of %v", opts.Lang, codegen.Languages)
+	}
+
+	dsDir := opts.DataStructuresDirectory
+	if dsDir == "" {
+		dsDir = util.DataStructuresFolder
+	}
+	dataStructuresLocal, err := readLocalDataStructures(cnx, []string{dsDir})
+	if err != nil {
+		return err
+	}
+	dsFiles := make([]string, 0, len(dataStructuresLocal))
+	for f := range dataStructuresLocal {
+		dsFiles = append(dsFiles, f)
+	}
+	sort.Strings(dsFiles)
+	dss := []model.DataStructure{}
+	for _, f := range dsFiles {
+		dss = append(dss, dataStructuresLocal[f])
+	}
+
+	dps, err := readDataProducts(cnx, opts.DataProductsDirectory)
+	if err != nil {
+		return err
+	}
+
+	m, err := codegen.Build(dss, dps)
+	if err != nil {
+		return ValidationError(err)
+	}
+
+	code, err := codegen.Generate(m, opts.Lang, opts.Package)
+	if err != nil {
+		return ConfigError(err)
+	}
+
+	out := opts.Out
+	if out == "" {
+		out = codegenFiles[opts.Lang]
+	}
+
+	// logs share stdout, they only stay quiet when code is written there
+	log := slog.Warn
+	if out == "-" {
+		log = slog.Debug
+	}
+	for _, w := range m.Warnings {
+		log("codegen", "msg", w)
+	}
+
+	if out == "-" {
+		_, err = fmt.Fprint(os.Stdout, code)
+		return err
+	}
+
+	err = os.WriteFile(out, []byte(code), 0644)
+	if err != nil {
+		return err
+	}
+	slog.Info("codegen", "msg", "wrote", "file", out, "data structures", len(m.Schemas), "event specifications", len(m.Specs))
+
+	return nil
+}
+
+// readDataProducts decodes the data products in dir, a missing dir has none
+func readDataProducts(cnx context.Context, dir string) ([]model.DataProduct, error) {
+	if dir == "" {
+		dir = util.DataProductsFolder
+	}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		slog.Debug("codegen", "msg", "no data products found", "path", dir)
+		return nil, nil
+	}
+
+	files, err := readLocalResources(cnx, []string{dir})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(files))
+	for f := range files {
+		names = append(names, f)
+	}
+	sort.Strings(names)
+
+	dps := []model.DataProduct{}
+	for _, f := range names {
+		if files[f]["resourceType"] != "data-product" {
+			continue
+		}
+		var dp model.DataProduct
+		if err := mapstructure.Decode(files[f], &dp); err != nil {
+			return nil, ValidationError(fmt.Errorf("file: %s: %w", f, err))
+		}
+		dps = append(dps, dp)
+	}
+	return dps, nil
+}
@@ -0,0 +1,63 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_Codegen(t *testing.T) {
+	dir := t.TempDir()
+	dsDir := filepath.Join(dir, "data-structures")
+	if err := os.Mkdir(dsDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	_, err := DSGenerate(DSGenerateOptions{
+		Name:      "login_click",
+		Vendor:    "com.acme",
+		Directory: dsDir,
+		Format:    "yaml",
+		Event:     true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(dir, "snowplow.ts")
+	opts := CodegenOptions{
+		Lang:                    "typescript",
+		DataStructuresDirectory: dsDir,
+		DataProductsDirectory:   filepath.Join(dir, "data-products"),
+		Out:                     out,
+	}
+
+	err = Codegen(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(code), "export const LOGIN_CLICK_SCHEMA = \"iglu:com.acme/login_click/jsonschema/1-0-0\";") {
+		t.Fatalf("expected login_click schema in\n%s", code)
+	}
+
+	opts.Lang = "cobol"
+	err = Codegen(context.Background(), opts)
+	if ExitCode(err) != ExitConfig {
+		t.Fatalf("expected config error got %v", err)
+	}
+}
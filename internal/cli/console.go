@@ -0,0 +1,205 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package cli
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/snowplow/snowplow-cli/internal/config"
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/spf13/cobra"
+)
+
+type ConsoleOptions struct {
+	Host         string
+	ApiKeyId     string
+	ApiKeySecret string
+	OrgId        string
+	ManagedFrom  string
+	// Record writes every Console request and response to this directory
+	Record string
+	// Replay answers Console requests from a directory written by Record
+	Replay string
+	// CacheDir keeps Console responses between commands, no caching when empty
+	CacheDir string
+	CacheTTL time.Duration
+	// Resolvers look up the schemas data products refer to, console.DefaultResolvers when empty
+	Resolvers []console.ResolverConfig
+}
+
+// InitConfig reads the config file once, keeping it in the context of cmd for the other helpers reading
+// flags, then fills in console flags from it unless cmd is local only
+func InitConfig(cmd *cobra.Command) error {
+	if config.IsLocalOnly(cmd) {
+		return nil
+	}
+
+	cfg, err := config.Read(cmd)
+	if err != nil {
+		return ConfigError(err)
+	}
+	cmd.SetContext(config.NewContext(cmd.Context(), cfg))
+
+	if _, err := igluResolvers(cfg); err != nil {
+		return err
+	}
+	if err := config.InitConsoleConfig(cmd, cfg); err != nil {
+		return ConfigError(err)
+	}
+	return nil
+}
+
+// igluResolvers are the schema resolvers from the iglu section of the config file, environment variables
+// like ${IGLU_API_KEY} are expanded. console.DefaultResolvers are used when none are set
+func igluResolvers(cfg *config.Config) ([]console.ResolverConfig, error) {
+	if len(cfg.Iglu.Resolvers) == 0 {
+		return console.DefaultResolvers, nil
+	}
+
+	resolvers := []console.ResolverConfig{}
+	for i, r := range cfg.Iglu.Resolvers {
+		resolver := console.ResolverConfig{Type: r.Type, Uri: os.ExpandEnv(r.Uri), ApiKey: os.ExpandEnv(r.ApiKey), Path: os.ExpandEnv(r.Path)}
+		if err := resolver.Validate(); err != nil {
+			return nil, configErrorf("iglu resolver %d: %w", i+1, err)
+		}
+		resolvers = append(resolvers, resolver)
+	}
+	return resolvers, nil
+}
+
+// ConsoleOptionsFromFlags reads the flags registered by config.InitConsoleFlags
+func ConsoleOptionsFromFlags(cmd *cobra.Command) ConsoleOptions {
+	apiKeyId, _ := cmd.Flags().GetString("api-key-id")
+	apiKeySecret, _ := cmd.Flags().GetString("api-key")
+	host, _ := cmd.Flags().GetString("host")
+	org, _ := cmd.Flags().GetString("org-id")
+	managedFrom, _ := cmd.Flags().GetString("managed-from")
+	record, _ := cmd.Flags().GetString("record")
+	replay, _ := cmd.Flags().GetString("replay")
+	noCache, _ := cmd.Flags().GetBool("no-cache")
+	cacheTTL, _ := cmd.Flags().GetDuration("cache-ttl")
+
+	cacheDir := ""
+	if !noCache {
+		dir, err := console.DefaultCacheDir()
+		if err != nil {
+			slog.Debug("no cache directory, not caching console responses", "error", err)
+		}
+		cacheDir = dir
+	}
+
+	resolvers, err := igluResolvers(config.FromContext(cmd.Context()))
+	if err != nil {
+		slog.Warn("ignoring iglu resolvers from config", "error", err)
+	}
+
+	return ConsoleOptions{
+		Host:         host,
+		ApiKeyId:     apiKeyId,
+		ApiKeySecret: apiKeySecret,
+		OrgId:        org,
+		ManagedFrom:  managedFrom,
+		Record:       record,
+		Replay:       replay,
+		CacheDir:     cacheDir,
+		CacheTTL:     cacheTTL,
+		Resolvers:    resolvers,
+	}
+}
+
+// revalidated asks Console whether every cached response changed before using it, for commands which
+// write to Console or to local files from what it returns
+func (o ConsoleOptions) revalidated() ConsoleOptions {
+	o.CacheTTL = 0
+	return o
+}
+
+func (o ConsoleOptions) client(cnx context.Context) (*console.ApiClient, error) {
+	transport, err := o.transport()
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Replay != "" {
+		// credentials are scrubbed from recordings, any value will do
+		if o.ApiKeyId == "" && o.ApiKeySecret == "" {
+			o.ApiKeyId, o.ApiKeySecret = "replay", "replay"
+		}
+	}
+
+	c, err := console.NewApiClientWithTransport(cnx, transport, o.Host, o.ApiKeyId, o.ApiKeySecret, o.OrgId)
+	if err != nil {
+		return nil, RemoteError(err)
+	}
+	return c, nil
+}
+
+// schemaChecker looks schemas up with the configured resolvers, c is nil when working without console
+func (o ConsoleOptions) schemaChecker(cnx context.Context, c *console.ApiClient) (console.SchemaResolver, error) {
+	resolvers := o.Resolvers
+	if len(resolvers) == 0 {
+		resolvers = console.DefaultResolvers
+	}
+	for _, r := range resolvers {
+		if err := r.Validate(); err != nil {
+			return nil, ConfigError(err)
+		}
+	}
+
+	var h *http.Client
+	if c != nil {
+		h = c.Http
+	} else {
+		transport, err := o.transport()
+		if err != nil {
+			return nil, err
+		}
+		h = console.NewHttpClient(transport)
+	}
+
+	sdc, err := console.NewSchemaResolver(cnx, h, c, resolvers)
+	if err != nil {
+		return nil, RemoteError(err)
+	}
+	return sdc, nil
+}
+
+func (o *ConsoleOptions) transport() (http.RoundTripper, error) {
+	switch {
+	case o.Record != "" && o.Replay != "":
+		return nil, configErrorf("--record and --replay can not be used together")
+	case o.Record != "":
+		t, err := console.NewRecordingTransport(http.DefaultTransport, o.Record)
+		if err != nil {
+			return nil, ConfigError(err)
+		}
+		slog.Info("recording console requests", "dir", o.Record)
+		return t, nil
+	case o.Replay != "":
+		interactions, err := console.LoadCassette(o.Replay)
+		if err != nil {
+			return nil, ConfigError(err)
+		}
+		if o.OrgId == "" {
+			o.OrgId = console.CassetteOrgId(interactions)
+		}
+		slog.Info("replaying console requests", "dir", o.Replay, "count", len(interactions))
+		return console.NewReplayTransport(interactions), nil
+	case o.CacheDir != "":
+		return console.NewCachingTransport(http.DefaultTransport, o.CacheDir, o.CacheTTL), nil
+	default:
+		return http.DefaultTransport, nil
+	}
+}
@@ -0,0 +1,52 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/config"
+	"github.com/snowplow/snowplow-cli/internal/console"
+)
+
+func Test_igluResolvers(t *testing.T) {
+	t.Setenv("IGLU_API_KEY", "secret")
+
+	cfg := &config.Config{}
+	resolvers, err := igluResolvers(cfg)
+	if err != nil || !reflect.DeepEqual(resolvers, console.DefaultResolvers) {
+		t.Fatalf("expected the default resolvers got %+v %v", resolvers, err)
+	}
+
+	cfg.Iglu.Resolvers = []config.Resolver{
+		{Type: "local", Path: "./iglu-central"},
+		{Type: "iglu-server", Uri: "https://iglu.acme.com/api", ApiKey: "${IGLU_API_KEY}"},
+		{Type: "console"},
+	}
+	resolvers, err = igluResolvers(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []console.ResolverConfig{
+		{Type: console.ResolverLocal, Path: "./iglu-central"},
+		{Type: console.ResolverIgluServer, Uri: "https://iglu.acme.com/api", ApiKey: "secret"},
+		{Type: console.ResolverConsole},
+	}
+	if !reflect.DeepEqual(resolvers, expected) {
+		t.Fatalf("got %+v want %+v", resolvers, expected)
+	}
+
+	cfg.Iglu.Resolvers = append(cfg.Iglu.Resolvers, config.Resolver{Type: "ftp"})
+	if _, err := igluResolvers(cfg); ExitCode(err) != ExitConfig {
+		t.Fatalf("expected an unknown resolver type to be a config error got %v", err)
+	}
+}
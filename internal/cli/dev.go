@@ -0,0 +1,66 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"github.com/snowplow/snowplow-cli/pkg/console/fakeconsole"
+)
+
+type DevFakeConsoleOptions struct {
+	Port     int
+	OrgId    string
+	ApiKeyId string
+	ApiKey   string
+}
+
+// DevFakeConsole serves an in-memory Console until cnx is cancelled
+func DevFakeConsole(cnx context.Context, opts DevFakeConsoleOptions) error {
+	fake := fakeconsole.New()
+	if opts.OrgId != "" {
+		fake.OrgId = opts.OrgId
+	}
+	if opts.ApiKeyId != "" {
+		fake.ApiKeyId = opts.ApiKeyId
+	}
+	if opts.ApiKey != "" {
+		fake.ApiKey = opts.ApiKey
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", opts.Port))
+	if err != nil {
+		return ConfigError(err)
+	}
+
+	host := fmt.Sprintf("http://%s", listener.Addr().String())
+	slog.Info("fake console listening", "host", host, "org-id", fake.OrgId, "api-key-id", fake.ApiKeyId, "api-key", fake.ApiKey)
+	slog.Info("point snowplow-cli at it with",
+		"env", fmt.Sprintf("SNOWPLOW_CONSOLE_HOST=%s SNOWPLOW_CONSOLE_ORG_ID=%s SNOWPLOW_CONSOLE_API_KEY_ID=%s SNOWPLOW_CONSOLE_API_KEY=%s", host, fake.OrgId, fake.ApiKeyId, fake.ApiKey),
+	)
+
+	server := &http.Server{Handler: fake}
+	go func() {
+		<-cnx.Done()
+		_ = server.Close()
+	}()
+
+	err = server.Serve(listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,780 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/google/uuid"
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/download"
+	"github.com/snowplow/snowplow-cli/internal/export"
+	"github.com/snowplow/snowplow-cli/internal/imports"
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/publish"
+	"github.com/snowplow/snowplow-cli/internal/tracing"
+	"github.com/snowplow/snowplow-cli/internal/util"
+	"github.com/snowplow/snowplow-cli/internal/validation"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type DPValidateOptions struct {
+	Console    ConsoleOptions
+	Paths      []string
+	GhAnnotate bool
+	Full       bool
+	// Offline validates without the network, schemas are only looked up with local and built-in resolvers
+	Offline bool
+	// Compat is where event specification compatibility is checked, CompatLocal or CompatRemote.
+	// Remote by default, local when offline
+	Compat string
+	// DataStructuresDirectory holds local data structures compatibility is checked against first
+	DataStructuresDirectory string
+	// LintRules overrides the severity of lint rules by id
+	LintRules map[string]string
+	// Policies are custom rules every data product and source application must satisfy
+	Policies []validation.Policy
+	// Baseline is a file of accepted findings
+	Baseline string
+	// WriteBaseline is a file all findings are written to, accepting them instead of failing
+	WriteBaseline string
+	// Since is a git ref, only resources changed since then and their dependants are validated unless Full
+	Since string
+}
+
+const (
+	CompatLocal  = "local"
+	CompatRemote = "remote"
+)
+
+var CompatModes = []string{CompatLocal, CompatRemote}
+
+type DPPublishOptions struct {
+	Console                 ConsoleOptions
+	Paths                   []string
+	DryRun                  bool
+	GhAnnotate              bool
+	SummaryMd               string
+	Compat                  string
+	DataStructuresDirectory string
+	LintRules               map[string]string
+	Policies                []validation.Policy
+	Baseline                string
+	// Since is a git ref, only resources changed since then and their dependants are published
+	Since string
+}
+
+type DPDownloadOptions struct {
+	Console   ConsoleOptions
+	Directory string
+	Format    string
+	Rename    bool
+	Prune     bool
+}
+
+type DPPurgeOptions struct {
+	Console ConsoleOptions
+	Paths   []string
+	Yes     bool
+}
+
+type DPGenerateOptions struct {
+	Format                string
+	SourceApps            []string
+	SourceAppsDirectory   string
+	DataProducts          []string
+	DataProductsDirectory string
+}
+
+type DPGenerateEventSpecOptions struct {
+	Console                 ConsoleOptions
+	Name                    string
+	Description             string
+	DataProduct             string
+	Event                   string
+	Entities                []string
+	Trigger                 *model.Trigger
+	DataStructuresDirectory string
+}
+
+type DPExportOptions struct {
+	Paths  []string
+	Format string
+	Out    string
+}
+
+type DPImportOptions struct {
+	Console                 ConsoleOptions
+	From                    string
+	DataProduct             string
+	DataStructuresDirectory string
+	DryRun                  bool
+}
+
+func dataProductSearchPaths(paths []string, cmd string) []string {
+	searchPaths := []string{}
+
+	if len(paths) == 0 {
+		searchPaths = append(searchPaths, util.DataProductsFolder)
+		slog.Debug(cmd, "msg", fmt.Sprintf("no path provided, using default (./%s)", util.DataProductsFolder))
+	}
+
+	return append(searchPaths, paths...)
+}
+
+func readLocalResources(cnx context.Context, searchPaths []string) (map[string]map[string]any, error) {
+	_, span := tracing.Start(cnx, tracing.PhaseDiscovery, attribute.StringSlice("snowplow.paths", searchPaths))
+	files, err := util.MaybeResourcesfromPaths(searchPaths)
+	span.SetAttributes(attribute.Int("snowplow.files", len(files)))
+	tracing.End(span, err)
+	if err != nil {
+		return nil, ConfigError(err)
+	}
+	return files, nil
+}
+
+// DPValidate validates local data products and source applications
+func DPValidate(cnx context.Context, opts DPValidateOptions) error {
+	searchPaths := dataProductSearchPaths(opts.Paths, "validation")
+
+	files, err := readLocalResources(cnx, searchPaths)
+	if err != nil {
+		return err
+	}
+
+	basePath, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	compat, err := compatMode(opts.Compat, opts.Offline)
+	if err != nil {
+		return err
+	}
+	if opts.Offline {
+		opts.Console.Resolvers, err = offlineResolvers(opts.Console.Resolvers)
+		if err != nil {
+			return err
+		}
+	}
+	lint, err := lintConfig(opts.LintRules)
+	if err != nil {
+		return err
+	}
+	policies, err := policyEngine(opts.Policies)
+	if err != nil {
+		return err
+	}
+	checks := validation.Checks{Lint: lint, Policies: policies}
+	if opts.WriteBaseline == "" {
+		checks.Baseline, err = readBaseline(opts.Baseline)
+		if err != nil {
+			return err
+		}
+	}
+
+	var git *util.GitChanges
+	if opts.Full && opts.Since != "" {
+		slog.Info("validation", "msg", "--full validates every file, ignoring --since")
+	} else {
+		git, err = gitChanges(opts.Since, searchPaths)
+		if err != nil {
+			return err
+		}
+	}
+
+	var c *console.ApiClient
+	changed := map[string]string{}
+	full := opts.Full
+	if opts.Offline {
+		slog.Info("validation", "msg", "offline, resolving data structures without console")
+	} else {
+		c, err = opts.Console.client(cnx)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case full:
+		// every file is checked, changes do not matter
+	case git != nil:
+		files, changed = resourcesSince(files, git)
+	case opts.Offline:
+		// nothing to tell changed files apart
+		full = true
+	default:
+		changes, err := dpChanges(cnx, c, files)
+		if err != nil {
+			return err
+		}
+		changed = changes.IdToFileName
+	}
+
+	pcnx, span := tracing.Start(cnx, tracing.PhaseValidation)
+	lookup, err := dpValidate(pcnx, opts.Console, c, compat, opts.DataStructuresDirectory, checks, files, searchPaths, basePath, opts.GhAnnotate, full, changed)
+	tracing.End(span, err)
+	if err != nil {
+		return err
+	}
+
+	if opts.WriteBaseline != "" {
+		baseline, err := lookup.Findings(basePath)
+		if err != nil {
+			return err
+		}
+		return writeBaseline(opts.WriteBaseline, baseline)
+	}
+
+	return ValidationError(lookup.Result())
+}
+
+// offlineResolvers keeps the resolvers which work without the network, only built-in ones when none are configured.
+// Resolvers needing the network are refused rather than contacted, console is skipped
+func offlineResolvers(resolvers []console.ResolverConfig) ([]console.ResolverConfig, error) {
+	if len(resolvers) == 0 {
+		return []console.ResolverConfig{{Type: console.ResolverBuiltIn}}, nil
+	}
+	res := []console.ResolverConfig{}
+	for _, r := range resolvers {
+		switch r.Type {
+		case console.ResolverIgluCentral, console.ResolverIgluServer, console.ResolverStatic:
+			return nil, configErrorf("the %s resolver needs the network, --offline only uses %s and %s resolvers", r, console.ResolverLocal, console.ResolverBuiltIn)
+		case console.ResolverConsole:
+			continue
+		}
+		res = append(res, r)
+	}
+	return res, nil
+}
+
+// compatMode defaults to checking compatibility with console, locally when offline
+func compatMode(compat string, offline bool) (string, error) {
+	switch {
+	case compat == "" && offline:
+		return CompatLocal, nil
+	case compat == "":
+		return CompatRemote, nil
+	case compat == CompatRemote && offline:
+		return "", configErrorf("--compat %s needs console, use --compat %s with --offline", CompatRemote, CompatLocal)
+	case !slices.Contains(CompatModes, compat):
+		return "", configErrorf("unknown --compat %s, expected one of %s", compat, strings.Join(CompatModes, ", "))
+	}
+	return compat, nil
+}
+
+// dpValidate validates files with the configured resolvers, c is nil without console
+func dpValidate(cnx context.Context, consoleOpts ConsoleOptions, c *console.ApiClient, compat string, dsDir string, checks validation.Checks, files map[string]map[string]any, searchPaths []string, basePath string, ghOut bool, validateAll bool, changedIdToFile map[string]string) (*validation.DPLookup, error) {
+	sdc, err := consoleOpts.schemaChecker(cnx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	cc := func(event console.CompatCheckable, entities []console.CompatCheckable) (*console.CompatResult, error) {
+		return console.CompatCheck(cnx, c, event, entities)
+	}
+	if compat == CompatLocal {
+		cc, err = localCompatChecker(sdc, dsDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// console and resolver failures are findings of the lookup, errors are local ones
+	return validation.Validate(sdc, cc, files, searchPaths, basePath, ghOut, validateAll, changedIdToFile, checks)
+}
+
+// localCompatChecker checks compatibility against the data structures in dsDir, when it exists, then the ones sdc fetches
+func localCompatChecker(sdc console.SchemaResolver, dsDir string) (console.CompatChecker, error) {
+	if dsDir == "" {
+		dsDir = util.DataStructuresFolder
+	}
+	locals := map[string]map[string]any{}
+	if _, err := os.Stat(dsDir); err == nil {
+		dss, err := util.DataStructuresFromPaths([]string{dsDir})
+		if err != nil {
+			return nil, ConfigError(err)
+		}
+		for f, ds := range dss {
+			data, err := ds.ParseData()
+			if err != nil {
+				return nil, ValidationError(fmt.Errorf("file: %s: %w", f, err))
+			}
+			locals[data.Self.IgluUri()] = ds.Data
+		}
+	}
+	return validation.NewLocalCompatChecker(locals, sdc.FetchSchema), nil
+}
+
+// DPPublish validates and publishes local data products, event specifications and source applications
+func DPPublish(cnx context.Context, opts DPPublishOptions) error {
+	opts.Console = opts.Console.revalidated()
+	searchPaths := dataProductSearchPaths(opts.Paths, "validation")
+
+	files, err := readLocalResources(cnx, searchPaths)
+	if err != nil {
+		return err
+	}
+
+	basePath, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	compat, err := compatMode(opts.Compat, false)
+	if err != nil {
+		return err
+	}
+	lint, err := lintConfig(opts.LintRules)
+	if err != nil {
+		return err
+	}
+	policies, err := policyEngine(opts.Policies)
+	if err != nil {
+		return err
+	}
+	baseline, err := readBaseline(opts.Baseline)
+	if err != nil {
+		return err
+	}
+	checks := validation.Checks{Lint: lint, Policies: policies, Baseline: baseline}
+
+	git, err := gitChanges(opts.Since, searchPaths)
+	if err != nil {
+		return err
+	}
+	if git != nil {
+		files, _ = resourcesSince(files, git)
+	}
+
+	c, err := opts.Console.client(cnx)
+	if err != nil {
+		return err
+	}
+
+	changes, err := dpChanges(cnx, c, files)
+	if err != nil {
+		return err
+	}
+
+	publish.LockChanged(changes, opts.Console.ManagedFrom)
+
+	pcnx, span := tracing.Start(cnx, tracing.PhaseValidation)
+	lookup, err := dpValidate(pcnx, opts.Console, c, compat, opts.DataStructuresDirectory, checks, files, searchPaths, basePath, opts.GhAnnotate, false, changes.IdToFileName)
+	tracing.End(span, err)
+	if err != nil {
+		return err
+	}
+
+	if opts.SummaryMd != "" {
+		err = util.WriteMarkdownFile(opts.SummaryMd,
+			func(b *strings.Builder) error {
+				return publish.MarkdownChangeset(b, *changes, basePath)
+			},
+			func(b *strings.Builder) error {
+				return lookup.MarkdownValidations(b, basePath)
+			},
+		)
+		if err != nil {
+			return err
+		}
+		slog.Info("wrote summary", "file", opts.SummaryMd)
+	}
+
+	if err := lookup.Result(); err != nil {
+		return ValidationError(err)
+	}
+
+	pcnx, span = tracing.Start(cnx, tracing.PhaseApply, attribute.Bool("snowplow.dry_run", opts.DryRun))
+	err = publish.Publish(pcnx, c, changes, opts.DryRun)
+	tracing.End(span, err)
+	if err != nil {
+		return RemoteError(err)
+	}
+
+	return nil
+}
+
+func dpChanges(cnx context.Context, c *console.ApiClient, files map[string]map[string]any) (*publish.DataProductChangeSet, error) {
+	pcnx, span := tracing.Start(cnx, tracing.PhaseChangeDetection)
+	changes, err := publish.FindChanges(pcnx, c, files)
+	tracing.End(span, err)
+	if err != nil {
+		return nil, RemoteError(err)
+	}
+	return changes, nil
+}
+
+// DPDownload writes all remote data products, event specifications and source applications to disk
+func DPDownload(cnx context.Context, opts DPDownloadOptions) error {
+	opts.Console = opts.Console.revalidated()
+	files := util.Files{
+		DataProductsLocation: opts.Directory,
+		SourceAppsLocation:   util.SourceAppsFolder,
+		ExtentionPreference:  opts.Format,
+		ImagesLocation:       util.ImagesFolder,
+		Rename:               opts.Rename,
+	}
+
+	c, err := opts.Console.client(cnx)
+	if err != nil {
+		return err
+	}
+
+	err = download.DownloadDataProductsAndRelatedResources(files, cnx, c, opts.Prune)
+	if err != nil {
+		return RemoteError(err)
+	}
+
+	return nil
+}
+
+type purgeApi struct {
+	client *console.ApiClient
+	cnx    context.Context
+}
+
+func (a purgeApi) DeleteSourceApp(sa console.RemoteSourceApplication) error {
+	return console.DeleteSourceApp(a.cnx, a.client, sa)
+}
+
+func (a purgeApi) DeleteDataProduct(dp console.RemoteDataProduct) error {
+	return console.DeleteDataProduct(a.cnx, a.client, dp)
+}
+
+func (a purgeApi) FetchDataProduct() (*console.DataProductsAndRelatedResources, error) {
+	return console.GetDataProductsAndRelatedResources(a.cnx, a.client)
+}
+
+// DPPurge removes remote data products and source applications which do not exist locally
+func DPPurge(cnx context.Context, opts DPPurgeOptions) error {
+	opts.Console = opts.Console.revalidated()
+	searchPaths := dataProductSearchPaths(opts.Paths, "purge")
+
+	files, err := readLocalResources(cnx, searchPaths)
+	if err != nil {
+		return err
+	}
+
+	c, err := opts.Console.client(cnx)
+	if err != nil {
+		return err
+	}
+
+	err = publish.Purge(purgeApi{c, cnx}, files, opts.Yes)
+	if err != nil {
+		return RemoteError(err)
+	}
+
+	return nil
+}
+
+// DPGenerate writes new, empty, source applications and data products to disk
+func DPGenerate(opts DPGenerateOptions) error {
+	err := os.MkdirAll(opts.SourceAppsDirectory, os.ModePerm)
+	if err != nil && !os.IsExist(err) {
+		return err
+	}
+	err = os.MkdirAll(opts.DataProductsDirectory, os.ModePerm)
+	if err != nil && !os.IsExist(err) {
+		return err
+	}
+
+	for _, app := range opts.SourceApps {
+		appn := util.ResourceNameToFileName(app)
+		fpath, err := util.WriteSerializableToFile(buildSaTpl(app), opts.SourceAppsDirectory, appn, opts.Format)
+		if err != nil {
+			return err
+		}
+		slog.Info("generate", "msg", "wrote", "kind", "source app", "file", fpath)
+	}
+
+	for _, dp := range opts.DataProducts {
+		dpn := util.ResourceNameToFileName(dp)
+		fpath, err := util.WriteSerializableToFile(buildDpTpl(dp), opts.DataProductsDirectory, dpn, opts.Format)
+		if err != nil {
+			return err
+		}
+		slog.Info("generate", "msg", "wrote", "kind", "data product", "file", fpath)
+	}
+
+	return nil
+}
+
+// DPExport flattens local data products into one spreadsheet per resource type
+func DPExport(cnx context.Context, opts DPExportOptions) error {
+	if !slices.Contains(export.Formats, opts.Format) {
+		return configErrorf("unsupported format %s, expected one of %v", opts.Format, export.Formats)
+	}
+
+	files, err := readLocalResources(cnx, dataProductSearchPaths(opts.Paths, "export"))
+	if err != nil {
+		return err
+	}
+
+	local, err := publish.ReadLocalDataProducts(files)
+	if err != nil {
+		return ValidationError(err)
+	}
+
+	basePath, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	written, err := export.Write(export.Tables(local, basePath), opts.Out, opts.Format)
+	for _, f := range written {
+		slog.Info("export", "msg", "wrote", "file", f)
+	}
+	return err
+}
+
+// DPImport adds event specifications drafted in a spreadsheet to a local data product
+func DPImport(cnx context.Context, opts DPImportOptions) error {
+	comma := ','
+	if strings.EqualFold(filepath.Ext(opts.From), ".tsv") {
+		comma = '\t'
+	}
+	sheet, err := os.Open(opts.From)
+	if err != nil {
+		return ConfigError(err)
+	}
+	defer sheet.Close()
+	rows, err := imports.ReadRows(sheet, comma)
+	if err != nil {
+		return ValidationError(fmt.Errorf("file: %s: %w", opts.From, err))
+	}
+	conflicts := []error{}
+	for _, row := range rows {
+		conflicts = append(conflicts, row.CheckEntities())
+	}
+	if err := errors.Join(conflicts...); err != nil {
+		return ConfigError(fmt.Errorf("file: %s: %w", opts.From, err))
+	}
+
+	dp, sourceApps, err := readDataProductFile(opts.DataProduct)
+	if err != nil {
+		return err
+	}
+
+	resolve, err := dataStructureResolver(cnx, opts.Console, opts.DataStructuresDirectory)
+	if err != nil {
+		return err
+	}
+
+	added, skipped, err := imports.EventSpecs(rows, dp.Data, sourceApps, resolve)
+	if err != nil {
+		return ValidationError(err)
+	}
+	for _, row := range skipped {
+		slog.Warn("import", "msg", "event specification already exists, skipped", "name", row.Name, "line", row.Line)
+	}
+	for _, es := range added {
+		slog.Info("import", "msg", "adding event specification", "name", es.Name)
+	}
+	if len(added) == 0 {
+		slog.Info("import", "msg", "nothing to import", "file", opts.DataProduct)
+		return nil
+	}
+	if opts.DryRun {
+		return nil
+	}
+
+	dp.Data.EventSpecifications = append(dp.Data.EventSpecifications, added...)
+	ext := filepath.Ext(opts.DataProduct)
+	_, err = util.UpdateSerializableFile(
+		dp, filepath.Dir(opts.DataProduct), strings.TrimSuffix(filepath.Base(opts.DataProduct), ext), strings.TrimPrefix(ext, "."),
+	)
+	if err != nil {
+		return err
+	}
+	slog.Info("import", "msg", "wrote", "file", opts.DataProduct, "added", len(added), "skipped", len(skipped))
+	return nil
+}
+
+// DPGenerateEventSpec appends a new event specification to a local data product file
+func DPGenerateEventSpec(cnx context.Context, opts DPGenerateEventSpecOptions) error {
+	row := imports.Row{Name: opts.Name, Description: opts.Description, Event: opts.Event}
+	for _, e := range opts.Entities {
+		entity, err := entityFlag(e)
+		if err != nil {
+			return ConfigError(err)
+		}
+		row.Entities = append(row.Entities, entity)
+	}
+	if err := row.CheckEntities(); err != nil {
+		return ConfigError(err)
+	}
+
+	dp, sourceApps, err := readDataProductFile(opts.DataProduct)
+	if err != nil {
+		return err
+	}
+
+	var trigger *model.Trigger
+	if opts.Trigger != nil {
+		t := *opts.Trigger
+		t.Id = uuid.NewString()
+		if t.Image != nil {
+			if _, err := os.Stat(t.Image.Ref); err != nil {
+				return ConfigError(fmt.Errorf("trigger image: %w", err))
+			}
+			rel, err := filepath.Rel(filepath.Dir(opts.DataProduct), t.Image.Ref)
+			if err != nil {
+				return ConfigError(fmt.Errorf("trigger image: %w", err))
+			}
+			t.Image = &model.Ref{Ref: "./" + filepath.ToSlash(rel)}
+		}
+		trigger = &t
+	}
+
+	resolve, err := dataStructureResolver(cnx, opts.Console, opts.DataStructuresDirectory)
+	if err != nil {
+		return err
+	}
+	added, skipped, err := imports.EventSpecs([]imports.Row{row}, dp.Data, sourceApps, resolve)
+	if err != nil {
+		return ValidationError(err)
+	}
+	if len(skipped) > 0 {
+		return configErrorf("event specification %s already exists in %s", opts.Name, opts.DataProduct)
+	}
+
+	es := added[0]
+	if trigger != nil {
+		es.Triggers = []model.Trigger{*trigger}
+	}
+	dp.Data.EventSpecifications = append(dp.Data.EventSpecifications, es)
+	ext := filepath.Ext(opts.DataProduct)
+	_, err = util.UpdateSerializableFile(
+		dp, filepath.Dir(opts.DataProduct), strings.TrimSuffix(filepath.Base(opts.DataProduct), ext), strings.TrimPrefix(ext, "."),
+	)
+	if err != nil {
+		return err
+	}
+	slog.Info("generate", "msg", "wrote", "kind", "event specification", "name", es.Name, "file", opts.DataProduct)
+	return nil
+}
+
+// entityFlag reads `uri` or `uri:cardinality`, eg. iglu:com.acme/user/jsonschema/1-0-0:1..1
+func entityFlag(s string) (imports.Entity, error) {
+	i := strings.LastIndex(s, ":")
+	if i < 0 {
+		return imports.Entity{Uri: s}, nil
+	}
+	min, max, err := imports.ParseCardinality(s[i+1:])
+	if err == nil {
+		return imports.Entity{Uri: s[:i], Min: min, Max: max}, nil
+	}
+	// the colon after iglu is not followed by a cardinality
+	if !strings.Contains(s[i+1:], "..") {
+		return imports.Entity{Uri: s}, nil
+	}
+	return imports.Entity{}, fmt.Errorf("invalid entity %s: cardinality %w", s, err)
+}
+
+// dataStructureResolver resolves references against the data structures in dsDir, when it exists,
+// before checking deployments in BDP Console
+func dataStructureResolver(cnx context.Context, consoleOpts ConsoleOptions, dsDir string) (imports.Resolver, error) {
+	locals := []model.DataStructureSelf{}
+	if dsDir == "" {
+		dsDir = util.DataStructuresFolder
+	}
+	if _, err := os.Stat(dsDir); err == nil {
+		dss, err := readLocalDataStructures(cnx, []string{dsDir})
+		if err != nil {
+			return nil, err
+		}
+		for _, ds := range dss {
+			data, err := ds.ParseData()
+			if err != nil {
+				return nil, ValidationError(err)
+			}
+			locals = append(locals, data.Self)
+		}
+	}
+
+	return imports.NewResolver(locals, func() (console.SchemaDeployChecker, error) {
+		c, err := consoleOpts.client(cnx)
+		if err != nil {
+			return nil, err
+		}
+		return consoleOpts.schemaChecker(cnx, c)
+	}), nil
+}
+
+// readDataProductFile decodes a data product file along with the names of the source applications it references
+func readDataProductFile(file string) (*model.CliResource[model.DataProductCanonicalData], map[string]model.Ref, error) {
+	files, err := util.MaybeResourcesfromPaths([]string{file})
+	if err != nil {
+		return nil, nil, ConfigError(err)
+	}
+	var dp model.CliResource[model.DataProductCanonicalData]
+	for _, f := range files {
+		if f["resourceType"] != "data-product" {
+			return nil, nil, configErrorf("file: %s: not a data product", file)
+		}
+		if err := mapstructure.Decode(f, &dp); err != nil {
+			return nil, nil, ValidationError(fmt.Errorf("file: %s: %w", file, err))
+		}
+	}
+
+	sourceApps := map[string]model.Ref{}
+	for _, ref := range dp.Data.SourceApplications {
+		saFiles, err := util.MaybeResourcesfromPaths([]string{filepath.Join(filepath.Dir(file), ref.Ref)})
+		if err != nil {
+			return nil, nil, ConfigError(err)
+		}
+		for _, f := range saFiles {
+			var sa model.SourceApp
+			if err := mapstructure.Decode(f, &sa); err != nil {
+				return nil, nil, ValidationError(fmt.Errorf("file: %s: %w", ref.Ref, err))
+			}
+			sourceApps[strings.ToLower(sa.Data.Name)] = ref
+		}
+	}
+
+	return &dp, sourceApps, nil
+}
+
+func buildDpTpl(name string) model.CliResource[model.DataProductCanonicalData] {
+	return model.CliResource[model.DataProductCanonicalData]{
+		ApiVersion:   "v1",
+		ResourceType: "data-product",
+		ResourceName: uuid.NewString(),
+		Data: model.DataProductCanonicalData{
+			Name:                name,
+			SourceApplications:  []model.Ref{},
+			EventSpecifications: []model.EventSpecCanonical{},
+		},
+	}
+}
+
+func buildSaTpl(name string) model.CliResource[model.SourceAppData] {
+	return model.CliResource[model.SourceAppData]{
+		ApiVersion:   "v1",
+		ResourceType: "source-application",
+		ResourceName: uuid.NewString(),
+		Data: model.SourceAppData{
+			Name:     name,
+			AppIds:   []string{},
+			Entities: &model.EntitiesDef{},
+		},
+	}
+}
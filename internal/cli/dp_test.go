@@ -0,0 +1,421 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/imports"
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/validation"
+)
+
+func Test_DPImport(t *testing.T) {
+	dir := t.TempDir()
+	dsDir := filepath.Join(dir, "data-structures")
+	if err := os.Mkdir(dsDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	_, err := DSGenerate(DSGenerateOptions{Name: "checkout", Vendor: "com.acme", Directory: dsDir, Format: "yaml", Event: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = DPGenerate(DPGenerateOptions{
+		Format:                "yaml",
+		SourceApps:            []string{"Web"},
+		SourceAppsDirectory:   filepath.Join(dir, "data-products", "source-apps"),
+		DataProducts:          []string{"Shop"},
+		DataProductsDirectory: filepath.Join(dir, "data-products"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dpFile := filepath.Join(dir, "data-products", "shop.yaml")
+
+	plan := filepath.Join(dir, "plan.csv")
+	if err := os.WriteFile(plan, []byte("Event specification,Event\nCheckout,com.acme/checkout\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts := DPImportOptions{From: plan, DataProduct: dpFile, DataStructuresDirectory: dsDir}
+
+	for i := 0; i < 2; i++ {
+		err = DPImport(context.Background(), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dp, _, err := readDataProductFile(dpFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		specs := dp.Data.EventSpecifications
+		if len(specs) != 1 || specs[0].Event.Source != "iglu:com.acme/checkout/jsonschema/1-0-0" {
+			t.Fatalf("expected the event specification to be imported once got %+v", specs)
+		}
+	}
+
+	// specs already there are left byte for byte, empty defaults are not added to them
+	content, err := os.ReadFile(dpFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := strings.Replace(string(content), "            enriched: []\n", "", 1)
+	if edited == string(content) {
+		t.Fatalf("expected empty enriched entities in\n%s", content)
+	}
+	if err := os.WriteFile(dpFile, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(plan, []byte("Event specification,Event\nCheckout retry,com.acme/checkout\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := DPImport(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+	if content, err := os.ReadFile(dpFile); err != nil || !strings.HasPrefix(string(content), edited) || strings.Count(string(content), "enriched") != 1 {
+		t.Fatalf("expected the existing spec to be left as is got\n%s %v", content, err)
+	}
+
+	if err := os.WriteFile(plan, []byte("Event specification,Event,Entities\nPay,com.acme/checkout,com.acme/checkout (1); com.acme/checkout (0..1)\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = DPImport(context.Background(), opts)
+	if ExitCode(err) != ExitConfig {
+		t.Fatalf("expected an entity with conflicting cardinalities to be a config error got %v", err)
+	}
+
+	if err := os.WriteFile(plan, []byte("Event specification,Event\nCart,com.acme/cart\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = DPImport(context.Background(), opts)
+	if ExitCode(err) != ExitValidation {
+		t.Fatalf("expected unresolved data structure to fail validation got %v", err)
+	}
+}
+
+func Test_DPGenerateEventSpec(t *testing.T) {
+	dir := t.TempDir()
+	dsDir := filepath.Join(dir, "data-structures")
+	if err := os.Mkdir(dsDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	for _, ds := range []DSGenerateOptions{
+		{Name: "add_to_cart", Vendor: "com.acme", Directory: dsDir, Format: "yaml", Event: true},
+		{Name: "product", Vendor: "com.acme", Directory: dsDir, Format: "yaml", Entity: true},
+	} {
+		if _, err := DSGenerate(ds); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := DPGenerate(DPGenerateOptions{
+		Format:                "yaml",
+		SourceAppsDirectory:   filepath.Join(dir, "data-products", "source-apps"),
+		DataProducts:          []string{"Shop"},
+		DataProductsDirectory: filepath.Join(dir, "data-products"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dpFile := filepath.Join(dir, "data-products", "shop.yaml")
+	image := filepath.Join(dir, "data-products", "images", "cart.png")
+	if err := os.MkdirAll(filepath.Dir(image), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(image, []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DPGenerateEventSpecOptions{
+		Name:                    "Add to cart",
+		DataProduct:             dpFile,
+		Event:                   "com.acme/add_to_cart",
+		Entities:                []string{"iglu:com.acme/product/jsonschema/1-0-0:1..*"},
+		Trigger:                 &model.Trigger{Description: "Button clicked", AppIds: []string{"web"}, Image: &model.Ref{Ref: image}},
+		DataStructuresDirectory: dsDir,
+	}
+	if err := DPGenerateEventSpec(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+
+	dp, _, err := readDataProductFile(dpFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	specs := dp.Data.EventSpecifications
+	if len(specs) != 1 {
+		t.Fatalf("expected one event specification got %+v", specs)
+	}
+	es := specs[0]
+	if es.ResourceName == "" || es.Event.Source != "iglu:com.acme/add_to_cart/jsonschema/1-0-0" {
+		t.Fatalf("unexpected event specification %+v", es)
+	}
+	if len(es.Entities.Tracked) != 1 || es.Entities.Tracked[0].Source != "iglu:com.acme/product/jsonschema/1-0-0" ||
+		*es.Entities.Tracked[0].MinCardinality != 1 || es.Entities.Tracked[0].MaxCardinality != nil {
+		t.Fatalf("unexpected entities %+v", es.Entities.Tracked)
+	}
+	if len(es.Triggers) != 1 || es.Triggers[0].Id == "" || es.Triggers[0].Image.Ref != "./images/cart.png" {
+		t.Fatalf("unexpected triggers %+v", es.Triggers)
+	}
+
+	if err := DPGenerateEventSpec(context.Background(), opts); ExitCode(err) != ExitConfig {
+		t.Fatalf("expected an existing name to fail got %v", err)
+	}
+
+	opts.Name = "Checkout"
+	opts.Event = "com.acme/checkout"
+	opts.Trigger = nil
+	if err := DPGenerateEventSpec(context.Background(), opts); ExitCode(err) != ExitValidation {
+		t.Fatalf("expected unresolved data structure to fail validation got %v", err)
+	}
+}
+
+func Test_entityFlag(t *testing.T) {
+	one, many := 1, 0
+	cases := []struct {
+		flag   string
+		entity imports.Entity
+		err    bool
+	}{
+		{"iglu:com.acme/user/jsonschema/1-0-0", imports.Entity{Uri: "iglu:com.acme/user/jsonschema/1-0-0"}, false},
+		{"iglu:com.acme/user/jsonschema/1-0-0:1", imports.Entity{Uri: "iglu:com.acme/user/jsonschema/1-0-0", Min: &one, Max: &one}, false},
+		{"com.acme/user:0..*", imports.Entity{Uri: "com.acme/user", Min: &many}, false},
+		{"com.acme/user:2..1", imports.Entity{}, true},
+	}
+	for _, c := range cases {
+		e, err := entityFlag(c.flag)
+		if (err != nil) != c.err || !reflect.DeepEqual(e, c.entity) {
+			t.Errorf("%s: unexpected %+v %v", c.flag, e, err)
+		}
+	}
+}
+
+func Test_DPValidateOffline(t *testing.T) {
+	inTempDir(t)
+
+	schemas := filepath.Join("iglu", "schemas", "com.acme", "user", "jsonschema")
+	if err := os.MkdirAll(schemas, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(schemas, "1-0-0"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sourceApp := func(source string) {
+		t.Helper()
+		content := `apiVersion: v1
+resourceType: source-application
+resourceName: 791a4198-e1ca-4fcf-9c1f-bc882830a34f
+data:
+  name: Web
+  appIds: [web]
+  entities:
+    enriched: []
+    tracked:
+    - source: ` + source + "\n"
+		if err := os.MkdirAll("data-products", os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join("data-products", "web.yaml"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// no console host, any request to it fails
+	opts := DPValidateOptions{
+		Console: ConsoleOptions{Resolvers: []console.ResolverConfig{{Type: console.ResolverConsole}, {Type: console.ResolverLocal, Path: "iglu"}}},
+		Paths:   []string{"data-products"},
+		Offline: true,
+	}
+
+	sourceApp("iglu:com.acme/user/jsonschema/1-0-0")
+	if err := DPValidate(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+
+	sourceApp("iglu:com.acme/user/jsonschema/2-0-0")
+	if err := DPValidate(context.Background(), opts); ExitCode(err) != ExitValidation {
+		t.Fatalf("expected a validation error got %v", err)
+	}
+
+	// resolvers needing the network are refused rather than contacted
+	for _, r := range []console.ResolverConfig{{Type: console.ResolverIgluCentral}, {Type: console.ResolverStatic, Uri: "https://schemas.acme.com"}} {
+		opts.Console.Resolvers = []console.ResolverConfig{{Type: console.ResolverLocal, Path: "iglu"}, r}
+		if err := DPValidate(context.Background(), opts); ExitCode(err) != ExitConfig {
+			t.Fatalf("expected a config error for %s got %v", r, err)
+		}
+	}
+
+	// misconfigured resolvers are not console failures
+	opts.Console.Resolvers = []console.ResolverConfig{{Type: console.ResolverLocal}}
+	if err := DPValidate(context.Background(), opts); ExitCode(err) != ExitConfig {
+		t.Fatalf("expected a config error for a local resolver without path got %v", err)
+	}
+
+	// neither are local data structures which can not be read
+	if err := os.MkdirAll(filepath.Join("data-structures", "com.acme"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join("data-structures", "com.acme", "broken.yaml"), []byte("data: [unclosed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts.Console.Resolvers = []console.ResolverConfig{{Type: console.ResolverLocal, Path: "iglu"}}
+	if err := DPValidate(context.Background(), opts); err == nil || ExitCode(err) == ExitRemote {
+		t.Fatalf("expected a local error for unreadable data structures got %v", err)
+	}
+	if err := os.RemoveAll("data-structures"); err != nil {
+		t.Fatal(err)
+	}
+
+	// without configured resolvers only built-in schemas are known
+	opts.Console.Resolvers = nil
+	sourceApp("iglu:com.snowplowanalytics.snowplow/page_view/jsonschema/1-0-0")
+	if err := DPValidate(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_DPValidateLocalCompat(t *testing.T) {
+	inTempDir(t)
+
+	schemas := filepath.Join("iglu", "schemas", "com.acme", "user", "jsonschema")
+	if err := os.MkdirAll(schemas, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	schema := `{"type": "object", "properties": {"plan": {"enum": ["free", "pro"]}}, "additionalProperties": false}`
+	if err := os.WriteFile(filepath.Join(schemas, "1-0-0"), []byte(schema), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dataProduct := func(plan string) {
+		t.Helper()
+		content := `apiVersion: v1
+resourceType: data-product
+resourceName: 9a8f9b4e-6a1d-4d55-8b7b-6d3c0e0b6c39
+data:
+  name: Shop
+  sourceApplications: []
+  eventSpecifications:
+  - resourceName: 5f5a2f61-0f4b-4a54-9a11-0b7f2b3f7b1e
+    name: Sign up
+    event:
+      source: iglu:com.acme/user/jsonschema/1-0-0
+      schema:
+        type: object
+        properties:
+          plan:
+            enum: [` + plan + `]
+        additionalProperties: false
+`
+		if err := os.MkdirAll("data-products", os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join("data-products", "shop.yaml"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := DPValidateOptions{
+		Console: ConsoleOptions{Resolvers: []console.ResolverConfig{{Type: console.ResolverLocal, Path: "iglu"}}},
+		Paths:   []string{"data-products"},
+		Offline: true,
+	}
+
+	dataProduct("pro")
+	if err := DPValidate(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+
+	dataProduct("gold")
+	if err := DPValidate(context.Background(), opts); ExitCode(err) != ExitValidation {
+		t.Fatalf("expected a validation error got %v", err)
+	}
+
+	opts.Compat = CompatRemote
+	if err := DPValidate(context.Background(), opts); ExitCode(err) != ExitConfig {
+		t.Fatalf("expected a config error got %v", err)
+	}
+}
+
+func Test_DPValidateUnknownLintRule(t *testing.T) {
+	inTempDir(t)
+	if err := os.Mkdir("data-products", os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	err := DPValidate(context.Background(), DPValidateOptions{
+		Paths:     []string{"data-products"},
+		Offline:   true,
+		LintRules: map[string]string{"no-such-rule": "error"},
+	})
+	if ExitCode(err) != ExitConfig {
+		t.Fatalf("expected a config error got %v", err)
+	}
+}
+
+func Test_DPValidateBaseline(t *testing.T) {
+	inTempDir(t)
+	for _, d := range []string{"data-products", "iglu"} {
+		if err := os.Mkdir(d, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	content := `apiVersion: v1
+resourceType: data-product
+resourceName: 9a8f9b4e-6a1d-4d55-8b7b-6d3c0e0b6c39
+data:
+  name: Shop
+  sourceApplications: []
+  eventSpecifications: []
+`
+	if err := os.WriteFile(filepath.Join("data-products", "shop.yaml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DPValidateOptions{
+		Console:  ConsoleOptions{Resolvers: []console.ResolverConfig{{Type: console.ResolverLocal, Path: "iglu"}}},
+		Paths:    []string{"data-products"},
+		Offline:  true,
+		Policies: []validation.Policy{{Id: "owned", Expression: "has(resource.data.owner)"}},
+	}
+	if err := DPValidate(context.Background(), opts); ExitCode(err) != ExitValidation {
+		t.Fatalf("expected a validation error got %v", err)
+	}
+
+	opts.WriteBaseline = "baseline.json"
+	if err := DPValidate(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+	baseline, err := validation.ReadBaseline("baseline.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(baseline.Findings, validation.BaselineFinding{File: "data-products/shop.yaml", Message: "violates policy owned (owned)"}) {
+		t.Fatalf("expected the policy violation in the baseline got %+v", baseline.Findings)
+	}
+
+	opts.WriteBaseline = ""
+	opts.Baseline = "baseline.json"
+	if err := DPValidate(context.Background(), opts); err != nil {
+		t.Fatalf("expected baseline findings to pass got %v", err)
+	}
+
+	opts.Baseline = "missing.json"
+	if err := DPValidate(context.Background(), opts); ExitCode(err) != ExitConfig {
+		t.Fatalf("expected a config error got %v", err)
+	}
+}
@@ -0,0 +1,940 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	changesPkg "github.com/snowplow/snowplow-cli/internal/changes"
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/inference"
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/synthetic"
+	"github.com/snowplow/snowplow-cli/internal/tracing"
+	"github.com/snowplow/snowplow-cli/internal/util"
+	"github.com/snowplow/snowplow-cli/internal/validation"
+	"go.opentelemetry.io/otel/attribute"
+	"gopkg.in/yaml.v3"
+)
+
+type DSValidateOptions struct {
+	Console    ConsoleOptions
+	Paths      []string
+	GhAnnotate bool
+	// LintRules overrides the severity of lint rules by id
+	LintRules map[string]string
+	// Vendors data structures may use, names or patterns like com.acme.*
+	Vendors []string
+	// Policies are custom rules every data structure must satisfy
+	Policies []validation.Policy
+	// Baseline is a file of accepted findings
+	Baseline string
+	// WriteBaseline is a file all findings are written to, accepting them instead of failing
+	WriteBaseline string
+	// Since is a git ref, only data structures changed since then are validated
+	Since string
+}
+
+type DSPublishOptions struct {
+	Console    ConsoleOptions
+	Paths      []string
+	DryRun     bool
+	GhAnnotate bool
+	SummaryMd  string
+	Baseline   string
+	// Since is a git ref, only data structures changed since then are published
+	Since string
+}
+
+type DSDownloadOptions struct {
+	Console   ConsoleOptions
+	Directory string
+	Format    string
+	Match     []string
+	Prune     bool
+}
+
+type DSGenerateOptions struct {
+	Name      string
+	Vendor    string
+	Directory string
+	Format    string
+	Event     bool
+	Entity    bool
+	// FromSample are files of sample payloads the schema is inferred from
+	FromSample []string
+}
+
+type DSTestOptions struct {
+	Paths      []string
+	GhAnnotate bool
+}
+
+type DSExamplesOptions struct {
+	Directory string
+	// Names are vendor/name of the data structures to generate examples of
+	Names          []string
+	All            bool
+	Count          int
+	Seed           int64
+	SelfDescribing bool
+	// Out is the file examples are written to, stdout when empty
+	Out string
+}
+
+func dataStructureFolders(paths []string) []string {
+	if len(paths) > 0 {
+		return paths
+	}
+	return []string{util.DataStructuresFolder}
+}
+
+func readLocalDataStructures(cnx context.Context, folders []string) (map[string]model.DataStructure, error) {
+	_, span := tracing.Start(cnx, tracing.PhaseDiscovery, attribute.StringSlice("snowplow.paths", folders))
+	dataStructuresLocal, err := util.DataStructuresFromPaths(folders)
+	span.SetAttributes(attribute.Int("snowplow.data_structures", len(dataStructuresLocal)))
+	tracing.End(span, err)
+	if err != nil {
+		return nil, ConfigError(err)
+	}
+
+	_, span = tracing.Start(cnx, tracing.PhaseLocalValidation)
+	err = errors.Join(validation.ValidateLocalDs(dataStructuresLocal)...)
+	tracing.End(span, err)
+	if err != nil {
+		return nil, ValidationError(err)
+	}
+
+	return dataStructuresLocal, nil
+}
+
+// readDataStructuresSince reads local data structures, only the ones changed since a git ref when there is one
+func readDataStructuresSince(cnx context.Context, folders []string, since string) (map[string]model.DataStructure, error) {
+	dataStructuresLocal, err := readLocalDataStructures(cnx, folders)
+	if err != nil {
+		return nil, err
+	}
+	git, err := gitChanges(since, folders)
+	if err != nil || git == nil {
+		return dataStructuresLocal, err
+	}
+	return dataStructuresSince(dataStructuresLocal, git), nil
+}
+
+// suppressDSFindings drops the findings accepted by x-snowplow-cli-ignore annotations of the local files, then by baseline
+func suppressDSFindings(vr *validation.ValidationResults, dss map[string]model.DataStructure, baseline *validation.Baseline, basePath string) error {
+	files := make([]string, 0, len(dss))
+	for f := range dss {
+		files = append(files, f)
+	}
+	ignores, err := util.DataStructureIgnores(files)
+	if err != nil {
+		return ValidationError(err)
+	}
+	return vr.Suppress(ignores, baseline, basePath)
+}
+
+func dsValidateChanges(cnx context.Context, c *console.ApiClient, changes changesPkg.Changes) (*validation.ValidationResults, error) {
+	pcnx, span := tracing.Start(cnx, tracing.PhaseRemoteValidation)
+	vr, err := validation.ValidateChanges(pcnx, c, changes)
+	if err == nil {
+		span.SetAttributes(attribute.Bool("snowplow.valid", vr.Valid))
+	}
+	tracing.End(span, err)
+	if err != nil {
+		return nil, RemoteError(err)
+	}
+	return vr, nil
+}
+
+// DSValidate sends local data structures which differ from their DEV deployment for validation
+func DSValidate(cnx context.Context, opts DSValidateOptions) error {
+	folders := dataStructureFolders(opts.Paths)
+
+	lint, err := lintConfig(opts.LintRules)
+	if err != nil {
+		return err
+	}
+	policies, err := policyEngine(opts.Policies)
+	if err != nil {
+		return err
+	}
+	var baseline *validation.Baseline
+	if opts.WriteBaseline == "" {
+		baseline, err = readBaseline(opts.Baseline)
+		if err != nil {
+			return err
+		}
+	}
+
+	slog.Info("validating from", "paths", folders)
+	dataStructuresLocal, err := readDataStructuresSince(cnx, folders, opts.Since)
+	if err != nil {
+		return err
+	}
+
+	vr := validation.LintDataStructures(dataStructuresLocal, lint, opts.Vendors)
+	if policies != nil {
+		pvr, err := validation.CheckDSPolicies(policies, dataStructuresLocal)
+		if err != nil {
+			return err
+		}
+		vr.Merge(pvr)
+	}
+
+	c, err := opts.Console.client(cnx)
+	if err != nil {
+		return err
+	}
+
+	changes, err := dsChanges(cnx, c, dataStructuresLocal, console.DEV)
+	if err != nil {
+		return err
+	}
+
+	remote, err := dsValidateChanges(cnx, c, changes)
+	if err != nil {
+		return err
+	}
+	vr.Merge(remote)
+	basePath, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if err := suppressDSFindings(vr, dataStructuresLocal, baseline, basePath); err != nil {
+		return err
+	}
+
+	vr.Slog()
+
+	if opts.WriteBaseline != "" {
+		findings, err := vr.Findings(basePath)
+		if err != nil {
+			return err
+		}
+		return writeBaseline(opts.WriteBaseline, findings)
+	}
+
+	if opts.GhAnnotate {
+		vr.GithubAnnotate()
+	}
+
+	if !vr.Valid {
+		return ValidationError(errors.New(vr.Message))
+	}
+
+	return nil
+}
+
+// DSTest validates the examples and counterexamples kept next to local data structures
+func DSTest(cnx context.Context, opts DSTestOptions) error {
+	folders := dataStructureFolders(opts.Paths)
+
+	slog.Info("testing examples from", "paths", folders)
+	dataStructuresLocal, err := readLocalDataStructures(cnx, folders)
+	if err != nil {
+		return err
+	}
+
+	results, err := validation.ValidateExamples(dataStructuresLocal)
+	if err != nil {
+		return ConfigError(err)
+	}
+
+	if len(results.Results) == 0 {
+		slog.Warn("test", "msg", fmt.Sprintf("no examples found, add json files to <data structure>/%s or <data structure>/%s", util.ExamplesFolder, util.CounterexamplesFolder))
+		return nil
+	}
+
+	results.Slog()
+
+	if opts.GhAnnotate {
+		results.GithubAnnotate()
+	}
+
+	if failed := results.Failed(); failed > 0 {
+		return ValidationError(fmt.Errorf("%d of %d examples failed", failed, len(results.Results)))
+	}
+
+	return nil
+}
+
+// attempts at generating a valid instance before giving up on a data structure
+const maxExampleAttempts = 100
+
+// DSExamples writes random instances of local data structures as ndjson
+func DSExamples(cnx context.Context, opts DSExamplesOptions) error {
+	if opts.All && len(opts.Names) > 0 {
+		return configErrorf("specify data structures as vendor/name or --all, not both")
+	}
+	if !opts.All && len(opts.Names) == 0 {
+		return configErrorf("specify data structures as vendor/name or use --all")
+	}
+	if opts.Count < 1 {
+		return configErrorf("count must be at least 1")
+	}
+
+	directory := opts.Directory
+	if directory == "" {
+		directory = util.DataStructuresFolder
+	}
+	dataStructuresLocal, err := readLocalDataStructures(cnx, []string{directory})
+	if err != nil {
+		return err
+	}
+
+	selected, err := selectDataStructures(dataStructuresLocal, opts.Names)
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if opts.Out != "" {
+		out, err = os.Create(opts.Out)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+	slog.Info("generating examples", "seed", opts.Seed, "count", opts.Count, "data structures", len(selected))
+
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	for _, ds := range selected {
+		data, err := ds.ParseData()
+		if err != nil {
+			return err
+		}
+		sch, err := validation.CompileData(ds)
+		if err != nil {
+			return ValidationError(fmt.Errorf("%s: %w", data.Self.IgluUri(), err))
+		}
+
+		g := synthetic.NewGenerator(ds.Data, opts.Seed)
+		for i := 0; i < opts.Count; i++ {
+			instance, err := validExample(g, sch)
+			if err != nil {
+				return ValidationError(fmt.Errorf("%s: %w", data.Self.IgluUri(), err))
+			}
+			if opts.SelfDescribing {
+				instance = selfDescribing{Schema: data.Self.IgluUri(), Data: instance}
+			}
+			err = enc.Encode(instance)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if opts.Out != "" {
+		slog.Info("wrote examples", "file", opts.Out)
+	}
+
+	return nil
+}
+
+type selfDescribing struct {
+	Schema string `json:"schema"`
+	Data   any    `json:"data"`
+}
+
+// selectDataStructures picks data structures by vendor/name, all of them when names is empty
+func selectDataStructures(dss map[string]model.DataStructure, names []string) ([]model.DataStructure, error) {
+	files := make([]string, 0, len(dss))
+	for f := range dss {
+		files = append(files, f)
+	}
+	slices.Sort(files)
+
+	found := map[string]bool{}
+	selected := []model.DataStructure{}
+	for _, f := range files {
+		data, err := dss[f].ParseData()
+		if err != nil {
+			return nil, ValidationError(fmt.Errorf("file: %s: %w", f, err))
+		}
+		name := data.Self.Vendor + "/" + data.Self.Name
+		if len(names) == 0 || slices.Contains(names, name) {
+			found[name] = true
+			selected = append(selected, dss[f])
+		}
+	}
+
+	for _, n := range names {
+		if !found[n] {
+			return nil, configErrorf("no local data structure %s", n)
+		}
+	}
+
+	return selected, nil
+}
+
+func validExample(g *synthetic.Generator, sch *jsonschema.Schema) (any, error) {
+	var messages []string
+	for i := 0; i < maxExampleAttempts; i++ {
+		instance, err := g.Next()
+		if err != nil {
+			return nil, err
+		}
+		messages = validation.InstanceErrors(sch, instance)
+		if len(messages) == 0 {
+			return instance, nil
+		}
+	}
+	return nil, fmt.Errorf("could not generate a valid example in %d attempts, last failure: %s", maxExampleAttempts, strings.Join(messages, ", "))
+}
+
+// DSPublishDev validates and publishes changed local data structures to the development environment
+func DSPublishDev(cnx context.Context, opts DSPublishOptions) error {
+	opts.Console = opts.Console.revalidated()
+	folders := dataStructureFolders(opts.Paths)
+
+	baseline, err := readBaseline(opts.Baseline)
+	if err != nil {
+		return err
+	}
+
+	dataStructuresLocal, err := readDataStructuresSince(cnx, folders, opts.Since)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("publishing to dev from", "paths", folders)
+
+	c, err := opts.Console.client(cnx)
+	if err != nil {
+		return err
+	}
+
+	changes, err := dsChanges(cnx, c, dataStructuresLocal, console.DEV)
+	if err != nil {
+		return err
+	}
+
+	vr, err := dsValidateChanges(cnx, c, changes)
+	if err != nil {
+		return err
+	}
+	basePath, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if err := suppressDSFindings(vr, dataStructuresLocal, baseline, basePath); err != nil {
+		return err
+	}
+
+	vr.Slog()
+
+	if opts.GhAnnotate {
+		vr.GithubAnnotate()
+	}
+
+	if opts.SummaryMd != "" {
+		err = writeDSSummary(cnx, c, opts.SummaryMd, changes, vr)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !vr.Valid {
+		return ValidationError(errors.New(vr.Message))
+	}
+
+	if !opts.DryRun {
+		pcnx, span := tracing.Start(cnx, tracing.PhaseApply, attribute.String("snowplow.env", string(console.DEV)))
+		err = changesPkg.PerformChangesDev(pcnx, c, changes, opts.Console.ManagedFrom)
+		tracing.End(span, err)
+		if err != nil {
+			return RemoteError(err)
+		}
+		slog.Info("all done!")
+	}
+
+	return nil
+}
+
+// DSPublishProd publishes local data structures deployed to development to the production environment
+func DSPublishProd(cnx context.Context, opts DSPublishOptions) error {
+	opts.Console = opts.Console.revalidated()
+	folders := dataStructureFolders(opts.Paths)
+
+	dataStructuresLocal, err := readDataStructuresSince(cnx, folders, opts.Since)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("publishing to prod from", "paths", folders)
+
+	c, err := opts.Console.client(cnx)
+	if err != nil {
+		return err
+	}
+
+	changes, err := dsChanges(cnx, c, dataStructuresLocal, console.PROD)
+	if err != nil {
+		return err
+	}
+
+	if opts.SummaryMd != "" {
+		err = writeDSSummary(cnx, c, opts.SummaryMd, changes, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !opts.DryRun {
+		pcnx, span := tracing.Start(cnx, tracing.PhaseApply, attribute.String("snowplow.env", string(console.PROD)))
+		err = changesPkg.PerformChangesProd(pcnx, c, changes, opts.Console.ManagedFrom)
+		tracing.End(span, err)
+		if errors.Is(err, changesPkg.ErrProdPatch) {
+			return DriftError(err)
+		}
+		if err != nil {
+			return RemoteError(err)
+		}
+		slog.Info("all done!")
+	}
+
+	return nil
+}
+
+func dsChanges(cnx context.Context, c *console.ApiClient, locals map[string]model.DataStructure, env console.DataStructureEnv) (changesPkg.Changes, error) {
+	pcnx, span := tracing.Start(cnx, tracing.PhaseChangeDetection, attribute.String("snowplow.env", string(env)))
+	remotesListing, err := console.GetDataStructureListing(pcnx, c)
+	if err != nil {
+		tracing.End(span, err)
+		return changesPkg.Changes{}, RemoteError(err)
+	}
+
+	changes, err := changesPkg.GetChanges(locals, remotesListing, env)
+	span.SetAttributes(
+		attribute.Int("snowplow.changes.create", len(changes.ToCreate)),
+		attribute.Int("snowplow.changes.new_version", len(changes.ToUpdateNewVersion)),
+		attribute.Int("snowplow.changes.patch", len(changes.ToUpdatePatch)),
+		attribute.Int("snowplow.changes.meta", len(changes.ToUpdateMeta)),
+	)
+	tracing.End(span, err)
+	if err != nil {
+		return changesPkg.Changes{}, err
+	}
+
+	err = changesPkg.PrintChangeset(changes)
+	if err != nil {
+		return changesPkg.Changes{}, err
+	}
+
+	return changes, nil
+}
+
+func writeDSSummary(cnx context.Context, c *console.ApiClient, path string, changes changesPkg.Changes, vr *validation.ValidationResults) error {
+	fetch := func(self model.DataStructureSelf, version string) (map[string]any, error) {
+		return console.GetDataStructureVersion(cnx, c, self, version)
+	}
+	err := util.WriteMarkdownFile(path,
+		func(b *strings.Builder) error {
+			return changesPkg.MarkdownChangeset(b, changes, fetch)
+		},
+		func(b *strings.Builder) error {
+			if vr != nil {
+				vr.Markdown(b)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+	slog.Info("wrote summary", "file", path)
+	return nil
+}
+
+// DSDownload writes the DEV versions of all remote data structures to disk.
+// Local files whose content and metadata match their DEV deployment are left untouched
+func DSDownload(cnx context.Context, opts DSDownloadOptions) error {
+	opts.Console = opts.Console.revalidated()
+	files := util.Files{DataStructuresLocation: opts.Directory, ExtentionPreference: opts.Format}
+
+	c, err := opts.Console.client(cnx)
+	if err != nil {
+		return fmt.Errorf("client creation fail: %w", err)
+	}
+
+	listing, err := console.GetDataStructureListing(cnx, c)
+	if err != nil {
+		return RemoteError(fmt.Errorf("data structure fetch failed: %w", err))
+	}
+
+	local, unreadable, err := localDataStructureIndex(opts.Directory)
+	if err != nil {
+		return err
+	}
+	downloaded, err := util.ReadDownloaded(opts.Directory)
+	if err != nil {
+		return ConfigError(err)
+	}
+	files.ExistingFiles = map[string]string{}
+	for key, l := range local {
+		files.ExistingFiles[key] = l.file
+	}
+
+	var dss []model.DataStructure
+	added, updated, unchanged := 0, 0, 0
+	for _, dsResp := range console.MatchListing(listing, opts.Match) {
+		key := util.DataStructureKey(model.DataStructureSelf{Vendor: dsResp.Vendor, Name: dsResp.Name, Format: dsResp.Format})
+		l, exists := local[key]
+		for _, deployment := range dsResp.Deployments {
+			if deployment.Env != console.DEV {
+				continue
+			}
+			if exists && l.hash == deployment.ContentHash && sameMeta(l.meta, dsResp.Meta) {
+				slog.Debug("unchanged data structure", "ds", key, "file", l.file)
+				unchanged++
+				continue
+			}
+			ds, found, err := console.GetDeployedDataStructure(cnx, c, dsResp, deployment)
+			if err != nil {
+				return RemoteError(fmt.Errorf("data structure fetch failed: %w", err))
+			}
+			if !found {
+				continue
+			}
+			if !exists && unreadable[filepath.Clean(filepath.Join(opts.Directory, dsResp.Vendor, dsResp.Name+"."+opts.Format))] {
+				slog.Warn("not overwriting unreadable file", "ds", key)
+				continue
+			}
+			dss = append(dss, *ds)
+			if exists {
+				updated++
+			} else {
+				added++
+			}
+		}
+	}
+
+	err = files.CreateDataStructures(dss)
+	if err != nil {
+		return err
+	}
+
+	remote := map[string]bool{}
+	for _, dsResp := range listing {
+		remote[util.DataStructureKey(model.DataStructureSelf{Vendor: dsResp.Vendor, Name: dsResp.Name, Format: dsResp.Format})] = true
+	}
+	stale := map[string]string{}
+	for key, l := range local {
+		if remote[key] || !matchesAny(key, opts.Match) {
+			continue
+		}
+		if !downloaded.Has(key) {
+			slog.Debug("data structure was never downloaded, keeping it", "ds", key, "file", l.file)
+			continue
+		}
+		stale[l.file] = key
+	}
+	staleFiles := []string{}
+	for file := range stale {
+		staleFiles = append(staleFiles, file)
+	}
+	slices.Sort(staleFiles)
+	for _, file := range staleFiles {
+		if !opts.Prune {
+			slog.Warn("data structure no longer exists remotely, use --prune to delete it", "file", file)
+			continue
+		}
+		if err := os.Remove(file); err != nil {
+			return err
+		}
+		downloaded.Remove(stale[file])
+		slog.Info("removed data structure deleted remotely", "file", file)
+	}
+
+	for _, dsResp := range console.MatchListing(listing, opts.Match) {
+		downloaded.Add(util.DataStructureKey(model.DataStructureSelf{Vendor: dsResp.Vendor, Name: dsResp.Name, Format: dsResp.Format}))
+	}
+	if err := downloaded.Write(); err != nil {
+		return err
+	}
+
+	if opts.Prune {
+		slog.Info("wrote data structures", "added", added, "updated", updated, "unchanged", unchanged, "removed", len(stale))
+	} else {
+		slog.Info("wrote data structures", "added", added, "updated", updated, "unchanged", unchanged, "stale", len(stale))
+	}
+
+	return nil
+}
+
+type localDataStructure struct {
+	file string
+	hash string
+	meta model.DataStructureMeta
+}
+
+// localDataStructureIndex maps the data structures already downloaded to dir by DataStructureKey.
+// Files that can't be read are skipped with a warning and returned apart
+func localDataStructureIndex(dir string) (map[string]localDataStructure, map[string]bool, error) {
+	index := map[string]localDataStructure{}
+	unreadable := map[string]bool{}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return index, unreadable, nil
+	}
+	dss, skipped, err := util.ReadableDataStructuresFromPaths([]string{dir})
+	if err != nil {
+		return nil, nil, ConfigError(err)
+	}
+	for f, err := range skipped {
+		slog.Warn("skipping unreadable data structure file", "file", f, "error", err)
+		unreadable[filepath.Clean(f)] = true
+	}
+
+	files := []string{}
+	for f := range dss {
+		files = append(files, f)
+	}
+	slices.Sort(files)
+
+	for _, f := range files {
+		ds := dss[f]
+		data, err := ds.ParseData()
+		if err != nil {
+			slog.Warn("skipping unreadable data structure file", "file", f, "error", err)
+			unreadable[filepath.Clean(f)] = true
+			continue
+		}
+		key := util.DataStructureKey(data.Self)
+		if first, ok := index[key]; ok {
+			slog.Warn("data structure is in several files, updating the first one", "ds", key, "file", first.file, "ignored", f)
+			continue
+		}
+		hash, err := ds.GetContentHash()
+		if err != nil {
+			return nil, nil, err
+		}
+		index[key] = localDataStructure{file: f, hash: hash, meta: ds.Meta}
+	}
+	return index, unreadable, nil
+}
+
+// sameMeta compares metadata, missing and empty custom data being the same once written to a file
+func sameMeta(a model.DataStructureMeta, b model.DataStructureMeta) bool {
+	if len(a.CustomData) == 0 && len(b.CustomData) == 0 {
+		a.CustomData, b.CustomData = nil, nil
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func matchesAny(key string, match []string) bool {
+	if len(match) == 0 {
+		return true
+	}
+	for _, m := range match {
+		if strings.HasPrefix(key, m) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	nameRegexp   = regexp.MustCompile(`^[a-zA-Z0-9-_]+$`)
+	vendorRegexp = regexp.MustCompile(`^[a-zA-Z0-9-_.]+$`)
+)
+
+// DSGenerate writes a new data structure from the template, returning the file written
+func DSGenerate(opts DSGenerateOptions) (string, error) {
+	if ok := nameRegexp.Match([]byte(opts.Name)); !ok {
+		return "", configErrorf("name did not match [a-zA-Z0-9-_]+")
+	}
+
+	if ok := vendorRegexp.Match([]byte(opts.Vendor)); opts.Vendor != "" && !ok {
+		return "", configErrorf("vendor did not match [a-zA-Z0-9-_.]+")
+	}
+
+	if ok := opts.Format == "json" || opts.Format == "yaml"; !ok {
+		return "", configErrorf("unsupported output format. Was not yaml or json")
+	}
+
+	directory := opts.Directory
+	if directory == "" {
+		directory = util.DataStructuresFolder
+	}
+	outDir := filepath.Join(directory, opts.Vendor)
+
+	outFile := filepath.Join(outDir, opts.Name+"."+opts.Format)
+	if _, err := os.Stat(outFile); !os.IsNotExist(err) {
+		return "", configErrorf("file already exists, not writing %s", outFile)
+	}
+
+	var schemaType string
+	if opts.Event {
+		schemaType = "event"
+	}
+	if opts.Entity {
+		schemaType = "entity"
+	}
+
+	yamlOut := fmt.Sprintf(dsYamlTemplate, schemaType, opts.Vendor, opts.Name)
+
+	if len(opts.FromSample) > 0 {
+		schema, err := sampleSchema(opts.FromSample)
+		if err != nil {
+			return "", err
+		}
+		yamlOut, err = withInferredSchema(yamlOut, schema)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	ds := model.DataStructure{}
+	err := yaml.Unmarshal([]byte(yamlOut), &ds)
+	if err != nil {
+		return "", err
+	}
+
+	output := yamlOut
+	if opts.Format == "json" {
+		jsonOut, err := json.MarshalIndent(ds, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		output = string(jsonOut)
+	}
+
+	err = os.Mkdir(outDir, os.ModePerm)
+	if err != nil && !os.IsExist(err) {
+		return "", err
+	}
+	err = os.WriteFile(outFile, []byte(output), 0644)
+	if err != nil {
+		return "", err
+	}
+
+	slog.Info("generate", "wrote", outFile)
+
+	return outFile, nil
+}
+
+func sampleSchema(paths []string) (*inference.Schema, error) {
+	samples, err := inference.ReadSamples(paths)
+	if err != nil {
+		return nil, ConfigError(err)
+	}
+	schema, err := inference.InferSchema(samples)
+	if err != nil {
+		return nil, ConfigError(err)
+	}
+	if schema.Type != "object" {
+		return nil, configErrorf("samples must all be json objects, found %v", schema.Type)
+	}
+	slog.Info("generate", "msg", "inferred schema", "samples", len(samples), "properties", len(schema.Properties))
+	return schema, nil
+}
+
+// withInferredSchema replaces the empty properties of the template, keeping its layout and comments.
+// The root is closed like the nested objects of the inferred schema
+func withInferredSchema(template string, schema *inference.Schema) (string, error) {
+	var doc yaml.Node
+	err := yaml.Unmarshal([]byte(template), &doc)
+	if err != nil {
+		return "", err
+	}
+
+	data := mappingValue(doc.Content[0], "data")
+	if data == nil {
+		return "", errors.New("template has no data")
+	}
+
+	for i := 0; i < len(data.Content); i += 2 {
+		if data.Content[i].Value != "properties" {
+			continue
+		}
+		err = data.Content[i+1].Encode(schema.Properties)
+		if err != nil {
+			return "", err
+		}
+		after := i + 2
+		if len(schema.Required) > 0 {
+			var key, value yaml.Node
+			key.SetString("required")
+			err = value.Encode(schema.Required)
+			if err != nil {
+				return "", err
+			}
+			data.Content = slices.Insert(data.Content, after, &key, &value)
+			after += 2
+		}
+		if schema.AdditionalProperties != nil {
+			if value := mappingValue(data, "additionalProperties"); value != nil {
+				err = value.Encode(*schema.AdditionalProperties)
+			} else {
+				var key, value yaml.Node
+				key.SetString("additionalProperties")
+				err = value.Encode(*schema.AdditionalProperties)
+				data.Content = slices.Insert(data.Content, after, &key, &value)
+			}
+			if err != nil {
+				return "", err
+			}
+		}
+		break
+	}
+
+	var b strings.Builder
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	err = enc.Encode(&doc)
+	if err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+var dsYamlTemplate = `# You might not need a custom data structure.
+# Please have a look at the available list of out of the box events and entities:
+# https://docs.snowplow.io/docs/collecting-data/collecting-from-own-applications/snowplow-tracker-protocol/
+apiVersion: v1
+resourceType: data-structure
+meta:
+  hidden: false
+  schemaType: %s
+  customData: {}
+data:
+  $schema: http://iglucentral.com/schemas/com.snowplowanalytics.self-desc/schema/jsonschema/1-0-0#
+  self:
+    vendor: %s
+    name: %s
+    format: jsonschema
+    version: 1-0-0
+  type: object
+  properties: {}
+  additionalProperties: false
+`
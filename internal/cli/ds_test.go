@@ -0,0 +1,355 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/inference"
+	"github.com/snowplow/snowplow-cli/internal/util"
+	"github.com/snowplow/snowplow-cli/internal/validation"
+	"gopkg.in/yaml.v3"
+)
+
+func Test_DSGenerate_Ok(t *testing.T) {
+	dir := t.TempDir()
+
+	file, err := DSGenerate(DSGenerateOptions{
+		Name:      "login_click",
+		Vendor:    "com.acme",
+		Directory: dir,
+		Format:    "yaml",
+		Event:     true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if file != filepath.Join(dir, "com.acme", "login_click.yaml") {
+		t.Fatalf("unexpected file %s", file)
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "schemaType: event") {
+		t.Fatalf("unexpected content %s", content)
+	}
+}
+
+func Test_DSGenerate_ConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	opts := []DSGenerateOptions{
+		{Name: "bad name", Directory: dir, Format: "yaml"},
+		{Name: "ok", Vendor: "bad/vendor", Directory: dir, Format: "yaml"},
+		{Name: "ok", Directory: dir, Format: "toml"},
+	}
+
+	for i, o := range opts {
+		_, err := DSGenerate(o)
+		if ExitCode(err) != ExitConfig {
+			t.Fatalf("case %d: expected config error got %v", i, err)
+		}
+	}
+
+	_, err := DSGenerate(DSGenerateOptions{Name: "twice", Directory: dir, Format: "json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = DSGenerate(DSGenerateOptions{Name: "twice", Directory: dir, Format: "json"})
+	if ExitCode(err) != ExitConfig {
+		t.Fatalf("expected config error for existing file got %v", err)
+	}
+}
+
+func Test_DSGenerate_FromSample(t *testing.T) {
+	dir := t.TempDir()
+	samples := filepath.Join(dir, "events.ndjson")
+	err := os.WriteFile(samples, []byte(`{"id":"3f1c2a4e-6b7d-4c8e-9f0a-1b2c3d4e5f60","method":"sso","attempts":1}
+{"id":"4f1c2a4e-6b7d-4c8e-9f0a-1b2c3d4e5f60","method":"sso","attempts":4,"referrer":"https://acme.com"}
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range []string{"yaml", "json"} {
+		if err := os.Mkdir(filepath.Join(dir, format), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		file, err := DSGenerate(DSGenerateOptions{
+			Name:       "login_click",
+			Vendor:     "com.acme",
+			Directory:  filepath.Join(dir, format),
+			Format:     format,
+			Event:      true,
+			FromSample: []string{samples},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dss, err := util.DataStructuresFromPaths([]string{file})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ds := dss[file]
+		if errs := validation.ValidateLocalDs(dss); len(errs) > 0 {
+			t.Fatalf("%s: generated data structure is invalid %v", format, errs)
+		}
+
+		properties, _ := ds.Data["properties"].(map[string]any)
+		if len(properties) != 4 {
+			t.Errorf("%s: expected 4 properties got %v", format, properties)
+		}
+		if !reflect.DeepEqual(ds.Data["required"], []any{"attempts", "id", "method"}) {
+			t.Errorf("%s: unexpected required %v", format, ds.Data["required"])
+		}
+		if ds.Data["additionalProperties"] != false {
+			t.Errorf("%s: expected closed schema", format)
+		}
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "yaml", "com.acme", "login_click.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(content), "# You might not need a custom data structure.") {
+		t.Errorf("expected the template comments to be kept\n%s", content)
+	}
+}
+
+func Test_withInferredSchema(t *testing.T) {
+	schema, err := inference.InferSchema([]any{
+		map[string]any{"id": "a", "user": map[string]any{"name": "x"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, template := range []string{
+		"data:\n  type: object\n  properties: {}\n",
+		"data:\n  type: object\n  properties: {}\n  additionalProperties: true\n",
+	} {
+		out, err := withInferredSchema(template, schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var doc struct {
+			Data map[string]any `yaml:"data"`
+		}
+		if err := yaml.Unmarshal([]byte(out), &doc); err != nil {
+			t.Fatal(err)
+		}
+		user, _ := doc.Data["properties"].(map[string]any)["user"].(map[string]any)
+		if user["additionalProperties"] != false || doc.Data["additionalProperties"] != false {
+			t.Errorf("expected the root to be closed like nested objects got\n%s", out)
+		}
+	}
+}
+
+func Test_DSGenerate_FromSampleErrors(t *testing.T) {
+	dir := t.TempDir()
+	notObjects := filepath.Join(dir, "numbers.ndjson")
+	err := os.WriteFile(notObjects, []byte("1\n2\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	broken := filepath.Join(dir, "broken.ndjson")
+	err = os.WriteFile(broken, []byte("{\"a\":"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, sample := range []string{notObjects, broken, filepath.Join(dir, "missing.ndjson")} {
+		_, err := DSGenerate(DSGenerateOptions{Name: "ds", Directory: dir, Format: "yaml", FromSample: []string{sample}})
+		if ExitCode(err) != ExitConfig {
+			t.Errorf("%s: expected config error got %v", sample, err)
+		}
+	}
+}
+
+func Test_DSTest(t *testing.T) {
+	dir := t.TempDir()
+	file, err := DSGenerate(DSGenerateOptions{
+		Name:      "login_click",
+		Vendor:    "com.acme",
+		Directory: dir,
+		Format:    "yaml",
+		Event:     true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DSTestOptions{Paths: []string{dir}}
+
+	err = DSTest(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("no examples should not fail %s", err)
+	}
+
+	base := strings.TrimSuffix(file, filepath.Ext(file))
+	examples := filepath.Join(base, util.ExamplesFolder)
+	counterexamples := filepath.Join(base, util.CounterexamplesFolder)
+	for _, d := range []string{examples, counterexamples} {
+		if err := os.MkdirAll(d, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(examples, "empty.json"), []byte(`{}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(counterexamples, "extra.json"), []byte(`{"extra":1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err = DSTest(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("expected examples to pass %s", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(examples, "string.json"), []byte(`"not an object"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err = DSTest(context.Background(), opts)
+	if ExitCode(err) != ExitValidation {
+		t.Fatalf("expected validation failure got %v", err)
+	}
+}
+
+func Test_DSExamples(t *testing.T) {
+	dir := t.TempDir()
+	samples := filepath.Join(dir, "events.ndjson")
+	err := os.WriteFile(samples, []byte(`{"id":"3f1c2a4e-6b7d-4c8e-9f0a-1b2c3d4e5f60","method":"sso","attempts":1}
+{"id":"4f1c2a4e-6b7d-4c8e-9f0a-1b2c3d4e5f60","method":"sso","attempts":4,"referrer":"https://acme.com"}
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dsDir := filepath.Join(dir, "data-structures")
+	if err := os.Mkdir(dsDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	file, err := DSGenerate(DSGenerateOptions{
+		Name:       "login_click",
+		Vendor:     "com.acme",
+		Directory:  dsDir,
+		Format:     "yaml",
+		Event:      true,
+		FromSample: []string{samples},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dss, err := util.DataStructuresFromPaths([]string{file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sch, err := validation.CompileData(dss[file])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	examples := func(out string, opts DSExamplesOptions) []string {
+		opts.Directory = dsDir
+		opts.Out = filepath.Join(dir, out)
+		err := DSExamples(context.Background(), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := os.ReadFile(opts.Out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Split(strings.TrimSpace(string(content)), "\n")
+	}
+
+	lines := examples("a.ndjson", DSExamplesOptions{Names: []string{"com.acme/login_click"}, Count: 5, Seed: 7})
+	if len(lines) != 5 {
+		t.Fatalf("expected 5 examples got %d", len(lines))
+	}
+	for _, l := range lines {
+		var instance any
+		if err := json.Unmarshal([]byte(l), &instance); err != nil {
+			t.Fatal(err)
+		}
+		if messages := validation.InstanceErrors(sch, instance); len(messages) > 0 {
+			t.Errorf("invalid example %s %v", l, messages)
+		}
+	}
+
+	again := examples("b.ndjson", DSExamplesOptions{Names: []string{"com.acme/login_click"}, Count: 5, Seed: 7})
+	if !reflect.DeepEqual(lines, again) {
+		t.Error("same seed should produce the same examples")
+	}
+
+	wrapped := examples("c.ndjson", DSExamplesOptions{All: true, Count: 1, Seed: 7, SelfDescribing: true})
+	if !strings.HasPrefix(wrapped[0], `{"schema":"iglu:com.acme/login_click/jsonschema/1-0-0","data":`+lines[0]) {
+		t.Errorf("unexpected self describing example %s", wrapped[0])
+	}
+}
+
+func Test_DSExamplesErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := DSGenerate(DSGenerateOptions{Name: "login_click", Vendor: "com.acme", Directory: dir, Format: "yaml", Event: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]DSExamplesOptions{
+		"nothing selected": {Count: 1},
+		"both selected":    {All: true, Names: []string{"com.acme/login_click"}, Count: 1},
+		"unknown":          {Names: []string{"com.acme/logout"}, Count: 1},
+		"no count":         {All: true},
+	}
+	for name, opts := range tests {
+		opts.Directory = dir
+		err := DSExamples(context.Background(), opts)
+		if ExitCode(err) != ExitConfig {
+			t.Errorf("%s: expected config error got %v", name, err)
+		}
+	}
+}
+
+func Test_DSValidateLint(t *testing.T) {
+	_, consoleOpts := fakeConsoleOptions(t)
+	inTempDir(t)
+	local := "local"
+	if err := os.Mkdir(local, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DSGenerate(DSGenerateOptions{Name: "login", Vendor: "com.acme", Directory: local, Format: "yaml", Event: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DSValidateOptions{Console: consoleOpts, Paths: []string{local}, Vendors: []string{"com.acme.*"}}
+	if err := DSValidate(context.Background(), opts); ExitCode(err) != ExitValidation {
+		t.Fatalf("expected a validation error got %v", err)
+	}
+
+	opts.LintRules = map[string]string{"vendor-allowed": "warning"}
+	if err := DSValidate(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+
+	opts.LintRules = map[string]string{"vendor-allowed": "fatal"}
+	if err := DSValidate(context.Background(), opts); ExitCode(err) != ExitConfig {
+		t.Fatalf("expected a config error got %v", err)
+	}
+}
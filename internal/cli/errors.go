@@ -0,0 +1,103 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Process exit codes, stable for scripting
+const (
+	ExitOk         = 0
+	ExitFailure    = 1
+	ExitConfig     = 2
+	ExitValidation = 3
+	ExitRemote     = 4
+	ExitDrift      = 5
+)
+
+// ExitCodesHelp documents the exit codes for command help output
+const ExitCodesHelp = `Exit codes:
+  0  success
+  1  unexpected failure
+  2  configuration error (missing or invalid config, flags or arguments)
+  3  validation failure (local or remote validation reported errors)
+  4  remote error (BDP Console could not be reached or rejected a request)
+  5  drift (local and remote state cannot be reconciled by this command)`
+
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+func wrap(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	var existing *ExitError
+	if errors.As(err, &existing) {
+		return err
+	}
+	return &ExitError{Code: code, Err: err}
+}
+
+func ConfigError(err error) error {
+	return wrap(ExitConfig, err)
+}
+
+func ValidationError(err error) error {
+	return wrap(ExitValidation, err)
+}
+
+func RemoteError(err error) error {
+	return wrap(ExitRemote, err)
+}
+
+func DriftError(err error) error {
+	return wrap(ExitDrift, err)
+}
+
+func configErrorf(format string, a ...any) error {
+	return ConfigError(fmt.Errorf(format, a...))
+}
+
+// ExitCode maps an error returned by a command to a process exit code
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOk
+	}
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	return ExitFailure
+}
+
+// Flatten expands joined errors so each can be reported on its own line
+func Flatten(err error) []error {
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		err = exitErr.Err
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func Test_ExitCode(t *testing.T) {
+	base := errors.New("boom")
+
+	cases := []struct {
+		err  error
+		code int
+	}{
+		{nil, ExitOk},
+		{base, ExitFailure},
+		{ConfigError(base), ExitConfig},
+		{ValidationError(base), ExitValidation},
+		{RemoteError(base), ExitRemote},
+		{DriftError(base), ExitDrift},
+		{fmt.Errorf("context: %w", RemoteError(base)), ExitRemote},
+		{ValidationError(ConfigError(base)), ExitConfig},
+	}
+
+	for i, c := range cases {
+		if got := ExitCode(c.err); got != c.code {
+			t.Fatalf("case %d: expected %d got %d", i, c.code, got)
+		}
+	}
+}
+
+func Test_ExitError_KeepsCause(t *testing.T) {
+	base := errors.New("boom")
+	err := RemoteError(base)
+
+	if !errors.Is(err, base) {
+		t.Fatal("expected wrapped error to match cause")
+	}
+	if err.Error() != "boom" {
+		t.Fatalf("unexpected message %s", err.Error())
+	}
+	if ValidationError(nil) != nil {
+		t.Fatal("expected nil for nil error")
+	}
+}
+
+func Test_Flatten(t *testing.T) {
+	a := errors.New("a")
+	b := errors.New("b")
+
+	errs := Flatten(ValidationError(errors.Join(a, b)))
+	if len(errs) != 2 || errs[0] != a || errs[1] != b {
+		t.Fatalf("unexpected %v", errs)
+	}
+
+	errs = Flatten(a)
+	if len(errs) != 1 || errs[0] != a {
+		t.Fatalf("unexpected %v", errs)
+	}
+}
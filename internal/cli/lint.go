@@ -0,0 +1,93 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package cli
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/snowplow/snowplow-cli/internal/config"
+	"github.com/snowplow/snowplow-cli/internal/validation"
+	"github.com/spf13/cobra"
+)
+
+// LintRulesFromFlags reads rule severities from the config file, then from --rule id=severity
+// and --disable-rule id when cmd has them
+func LintRulesFromFlags(cmd *cobra.Command) (map[string]string, error) {
+	rules := config.FromContext(cmd.Context()).LintRules()
+
+	overrides, _ := cmd.Flags().GetStringArray("rule")
+	for _, o := range overrides {
+		id, severity, ok := strings.Cut(o, "=")
+		if !ok {
+			return nil, configErrorf("--rule %s, expected <rule id>=<%s>", o, strings.Join(validation.Severities, "|"))
+		}
+		rules[strings.TrimSpace(id)] = strings.ToLower(strings.TrimSpace(severity))
+	}
+
+	disabled, _ := cmd.Flags().GetStringArray("disable-rule")
+	for _, id := range disabled {
+		rules[id] = validation.SeverityOff
+	}
+
+	return rules, nil
+}
+
+// PoliciesFromConfig reads the custom validation policies from the config file
+func PoliciesFromConfig(cmd *cobra.Command) []validation.Policy {
+	policies := []validation.Policy{}
+	for _, p := range config.FromContext(cmd.Context()).Policies {
+		policies = append(policies, validation.Policy{Id: p.Id, Resources: p.Resources, Expression: p.Expression, Message: p.Message, Severity: p.Severity})
+	}
+	return policies
+}
+
+// lintConfig checks rules against the known ones
+func lintConfig(rules map[string]string) (validation.LintConfig, error) {
+	lint := validation.LintConfig(rules)
+	if err := lint.Check(); err != nil {
+		return nil, ConfigError(err)
+	}
+	return lint, nil
+}
+
+// policyEngine compiles policies, nil when there are none
+func policyEngine(policies []validation.Policy) (*validation.PolicyEngine, error) {
+	if len(policies) == 0 {
+		return nil, nil
+	}
+	engine, err := validation.NewPolicyEngine(policies)
+	if err != nil {
+		return nil, ConfigError(err)
+	}
+	return engine, nil
+}
+
+// readBaseline reads the findings accepted in file, nil without one
+func readBaseline(file string) (*validation.Baseline, error) {
+	if file == "" {
+		return nil, nil
+	}
+	baseline, err := validation.ReadBaseline(file)
+	if err != nil {
+		return nil, ConfigError(err)
+	}
+	return baseline, nil
+}
+
+// writeBaseline saves the findings of a run to file, accepting them for later ones
+func writeBaseline(file string, baseline *validation.Baseline) error {
+	if err := baseline.Write(file); err != nil {
+		return err
+	}
+	slog.Info("wrote baseline", "file", file, "findings", len(baseline.Findings))
+	return nil
+}
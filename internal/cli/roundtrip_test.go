@@ -0,0 +1,513 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/publish"
+	"github.com/snowplow/snowplow-cli/internal/util"
+	"github.com/snowplow/snowplow-cli/pkg/console/consoletest"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// inTempDir runs the test from a fresh working directory, downloads are written relative to it
+func inTempDir(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+}
+
+func fakeConsoleOptions(t *testing.T) (*consoletest.Console, ConsoleOptions) {
+	fake, server := consoletest.NewServer(t)
+	return fake, ConsoleOptions{
+		Host:         server.URL,
+		ApiKeyId:     fake.ApiKeyId,
+		ApiKeySecret: fake.ApiKey,
+		OrgId:        fake.OrgId,
+		ManagedFrom:  "https://github.com/acme/schemas",
+	}
+}
+
+func Test_DSRoundTrip(t *testing.T) {
+	fake, consoleOpts := fakeConsoleOptions(t)
+	cnx := context.Background()
+	inTempDir(t)
+	local := "local"
+	if err := os.Mkdir(local, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := DSGenerate(DSGenerateOptions{Name: "login", Vendor: "com.acme", Directory: local, Format: "yaml", Event: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = DSPublishDev(cnx, DSPublishOptions{Console: consoleOpts, Paths: []string{local}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = DSPublishProd(cnx, DSPublishOptions{Console: consoleOpts, Paths: []string{local}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if listing := fake.DataStructures(); len(listing) != 1 || len(listing[0].Deployments) != 2 {
+		t.Fatalf("unexpected remote state %+v", listing)
+	}
+
+	downloaded := "downloaded"
+	err = DSDownload(cnx, DSDownloadOptions{Console: consoleOpts, Directory: downloaded, Format: "yaml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before, err := util.DataStructuresFromPaths([]string{local})
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err := util.DataStructuresFromPaths([]string{downloaded})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after) != 1 {
+		t.Fatalf("expected one downloaded data structure, got %d", len(after))
+	}
+	for _, a := range after {
+		for _, b := range before {
+			if !reflect.DeepEqual(a.Data, b.Data) || !reflect.DeepEqual(a.Meta, b.Meta) {
+				t.Errorf("downloaded data structure differs\n%+v\n%+v", a, b)
+			}
+		}
+	}
+
+	// republishing unchanged files is a no-op
+	c, err := consoleOpts.client(cnx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, err := dsChanges(cnx, c, after, "DEV")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(changes.ToCreate) + len(changes.ToUpdateMeta) + len(changes.ToUpdateNewVersion) + len(changes.ToUpdatePatch); n != 0 {
+		t.Errorf("expected no changes, got %+v", changes)
+	}
+}
+
+func Test_DPRoundTrip(t *testing.T) {
+	_, consoleOpts := fakeConsoleOptions(t)
+	cnx := context.Background()
+	inTempDir(t)
+	local := "local"
+
+	err := DPGenerate(DPGenerateOptions{
+		Format:                "yaml",
+		SourceApps:            []string{"Web app"},
+		SourceAppsDirectory:   filepath.Join(local, util.SourceAppsFolder),
+		DataProducts:          []string{"Signup flow"},
+		DataProductsDirectory: local,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := util.MaybeResourcesfromPaths([]string{local})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := consoleOpts.client(cnx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, err := publish.FindChanges(cnx, c, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = publish.Publish(cnx, c, changes, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	downloaded := "downloaded"
+	err = DPDownload(cnx, DPDownloadOptions{Console: consoleOpts, Directory: downloaded, Format: "yaml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range []string{"signup-flow.yaml", filepath.Join(util.SourceAppsFolder, "web-app.yaml")} {
+		if _, err := os.Stat(filepath.Join(downloaded, f)); err != nil {
+			t.Errorf("expected %s to be downloaded: %s", f, err)
+		}
+	}
+
+	files, err = util.MaybeResourcesfromPaths([]string{downloaded})
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, err = publish.FindChanges(cnx, c, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	err = publish.MarkdownChangeset(&b, *changes, downloaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "No changes detected.") {
+		t.Errorf("expected no changes after round trip, got %s", b.String())
+	}
+}
+
+func Test_DSDownloadReplay(t *testing.T) {
+	fake, consoleOpts := fakeConsoleOptions(t)
+	cnx := context.Background()
+	inTempDir(t)
+
+	err := fake.AddDataStructure(model.DataStructure{
+		ApiVersion:   "v1",
+		ResourceType: "data-structure",
+		Meta:         model.DataStructureMeta{SchemaType: "event", Hidden: false, CustomData: map[string]string{}},
+		Data: map[string]any{
+			"$schema": "http://iglucentral.com/schemas/com.snowplowanalytics.self-desc/schema/jsonschema/1-0-0#",
+			"self":    map[string]any{"vendor": "com.acme", "name": "click", "format": "jsonschema", "version": "1-0-0"},
+			"type":    "object",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	consoleOpts.Record = "cassette"
+	err = DSDownload(cnx, DSDownloadOptions{Console: consoleOpts, Directory: "recorded", Format: "yaml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replayOpts := ConsoleOptions{Host: "http://replay.invalid", Replay: "cassette"}
+	err = DSDownload(cnx, DSDownloadOptions{Console: replayOpts, Directory: "replayed", Format: "yaml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorded, err := os.ReadFile(filepath.Join("recorded", "com.acme", "click.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := os.ReadFile(filepath.Join("replayed", "com.acme", "click.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(recorded) != string(replayed) {
+		t.Errorf("replayed download differs\n%s\n%s", recorded, replayed)
+	}
+
+	consoleOpts.Replay = "cassette"
+	err = DSDownload(cnx, DSDownloadOptions{Console: consoleOpts, Directory: "both", Format: "yaml"})
+	if ExitCode(err) != ExitConfig {
+		t.Errorf("expected a config error using --record and --replay together, got %v", err)
+	}
+}
+
+func Test_DSDownloadIncremental(t *testing.T) {
+	fake, consoleOpts := fakeConsoleOptions(t)
+	cnx := context.Background()
+	inTempDir(t)
+
+	structure := func(name string, version string, description string) model.DataStructure {
+		return model.DataStructure{
+			ApiVersion:   "v1",
+			ResourceType: "data-structure",
+			Meta:         model.DataStructureMeta{SchemaType: "event", CustomData: map[string]string{}},
+			Data: map[string]any{
+				"$schema":     "http://iglucentral.com/schemas/com.snowplowanalytics.self-desc/schema/jsonschema/1-0-0#",
+				"self":        map[string]any{"vendor": "com.acme", "name": name, "format": "jsonschema", "version": version},
+				"description": description,
+				"type":        "object",
+			},
+		}
+	}
+	for _, ds := range []model.DataStructure{structure("click", "1-0-0", "a click"), structure("view", "1-0-0", "a view")} {
+		if err := fake.AddDataStructure(ds); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := DSDownloadOptions{Console: consoleOpts, Directory: "ds", Format: "yaml", Prune: true}
+	if err := DSDownload(cnx, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	click := filepath.Join("ds", "com.acme", "click.yaml")
+	content, err := os.ReadFile(click)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reviewed := "# reviewed\n" + string(content)
+	if err := os.WriteFile(click, []byte(reviewed), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// old was downloaded before and deleted remotely since, draft was never published
+	old, err := DSGenerate(DSGenerateOptions{Name: "old", Vendor: "com.acme", Directory: "ds", Format: "yaml", Event: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	draft, err := DSGenerate(DSGenerateOptions{Name: "draft", Vendor: "com.acme", Directory: "ds", Format: "yaml", Event: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	downloaded, err := util.ReadDownloaded("ds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	downloaded.Add("com.acme/old/jsonschema")
+	if err := downloaded.Write(); err != nil {
+		t.Fatal(err)
+	}
+	broken := filepath.Join("ds", "com.acme", "broken.yaml")
+	if err := os.WriteFile(broken, []byte("data: [unclosed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.AddDataStructure(structure("view", "1-0-1", "a page view")); err != nil {
+		t.Fatal(err)
+	}
+
+	opts.Console.Record = "requests"
+	if err := DSDownload(cnx, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	requests, err := os.ReadDir("requests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetched := []string{}
+	for _, r := range requests {
+		recorded, err := os.ReadFile(filepath.Join("requests", r.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(recorded), "/versions/") {
+			fetched = append(fetched, r.Name())
+		}
+	}
+	if len(fetched) != 1 {
+		t.Errorf("expected only the changed data structure to be fetched got %v", fetched)
+	}
+
+	content, err = os.ReadFile(click)
+	if err != nil || string(content) != reviewed {
+		t.Errorf("expected the unchanged data structure to be left alone got\n%s %v", content, err)
+	}
+	content, err = os.ReadFile(filepath.Join("ds", "com.acme", "view.yaml"))
+	if err != nil || !strings.Contains(string(content), "version: 1-0-1") {
+		t.Errorf("expected the changed data structure to be updated got\n%s %v", content, err)
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be pruned", old)
+	}
+	if _, err := os.Stat(draft); err != nil {
+		t.Errorf("expected %s never downloaded to be kept got %v", draft, err)
+	}
+	if content, err := os.ReadFile(broken); err != nil || string(content) != "data: [unclosed\n" {
+		t.Errorf("expected the unreadable file to be skipped got\n%s %v", content, err)
+	}
+}
+
+func Test_DSDownloadCached(t *testing.T) {
+	fake, consoleOpts := fakeConsoleOptions(t)
+	cnx := context.Background()
+	inTempDir(t)
+
+	click := func(version string) model.DataStructure {
+		return model.DataStructure{
+			ApiVersion:   "v1",
+			ResourceType: "data-structure",
+			Meta:         model.DataStructureMeta{SchemaType: "event", CustomData: map[string]string{}},
+			Data: map[string]any{
+				"$schema":     "http://iglucentral.com/schemas/com.snowplowanalytics.self-desc/schema/jsonschema/1-0-0#",
+				"self":        map[string]any{"vendor": "com.acme", "name": "click", "format": "jsonschema", "version": version},
+				"description": "a click",
+				"type":        "object",
+			},
+		}
+	}
+	if err := fake.AddDataStructure(click("1-0-0")); err != nil {
+		t.Fatal(err)
+	}
+
+	consoleOpts.CacheDir = "cache"
+	consoleOpts.CacheTTL = time.Hour
+	opts := DSDownloadOptions{Console: consoleOpts, Directory: "ds", Format: "yaml"}
+	if err := DSDownload(cnx, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	downloaded := filepath.Join("ds", "com.acme", "click.yaml")
+	version := func() string {
+		t.Helper()
+		ds, err := util.DataStructuresFromPaths([]string{downloaded})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, d := range ds {
+			self, _ := d.Data["self"].(map[string]any)
+			v, _ := self["version"].(string)
+			return v
+		}
+		return ""
+	}
+
+	// downloads revalidate cached responses, so changes made elsewhere are seen at once
+	if err := fake.AddDataStructure(click("1-0-1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := DSDownload(cnx, opts); err != nil {
+		t.Fatal(err)
+	}
+	if v := version(); v != "1-0-1" {
+		t.Fatalf("expected download to revalidate the cached listing got %s", v)
+	}
+
+	// other commands read cached responses until a mutating request clears the cache
+	c, err := consoleOpts.client(cnx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listed := func() int {
+		t.Helper()
+		listing, err := console.GetDataStructureListing(cnx, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(listing)
+	}
+	if n := listed(); n != 1 {
+		t.Fatalf("expected one data structure got %d", n)
+	}
+	view := click("1-0-0")
+	view.Data["self"] = map[string]any{"vendor": "com.acme", "name": "view", "format": "jsonschema", "version": "1-0-0"}
+	if err := fake.AddDataStructure(view); err != nil {
+		t.Fatal(err)
+	}
+	if n := listed(); n != 1 {
+		t.Fatalf("expected the cached listing to be used got %d data structures", n)
+	}
+
+	if err := os.Mkdir("local", os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DSGenerate(DSGenerateOptions{Name: "login", Vendor: "com.acme", Directory: "local", Format: "yaml", Event: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := DSPublishDev(cnx, DSPublishOptions{Console: consoleOpts, Paths: []string{"local"}}); err != nil {
+		t.Fatal(err)
+	}
+	if n := listed(); n != 3 {
+		t.Fatalf("expected publishing to clear the cache got %d data structures", n)
+	}
+
+	info, err := CacheInfo(CacheOptions{Dir: "cache"})
+	if err != nil || info.Entries == 0 {
+		t.Fatalf("expected cached responses got %+v %v", info, err)
+	}
+	if err := CacheClear(CacheOptions{Dir: "cache"}); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := CacheInfo(CacheOptions{Dir: "cache"}); err != nil || info.Entries != 0 {
+		t.Fatalf("expected an empty cache got %+v %v", info, err)
+	}
+}
+
+func spanAttribute(s sdktrace.ReadOnlySpan, key attribute.Key) string {
+	for _, a := range s.Attributes() {
+		if a.Key == key {
+			return a.Value.Emit()
+		}
+	}
+	return ""
+}
+
+func Test_DSPublishTraced(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	_, consoleOpts := fakeConsoleOptions(t)
+	cnx := context.Background()
+	inTempDir(t)
+	if err := os.Mkdir("local", os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := DSGenerate(DSGenerateOptions{Name: "login", Vendor: "com.acme", Directory: "local", Format: "yaml", Event: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = DSPublishDev(cnx, DSPublishOptions{Console: consoleOpts, Paths: []string{"local"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range exporter.GetSpans().Snapshots() {
+		names[s.Name()] = s
+	}
+	for _, want := range []string{"discovery", "local validation", "change detection", "remote validation", "apply", "data structure publish"} {
+		if _, ok := names[want]; !ok {
+			t.Errorf("missing span %s", want)
+		}
+	}
+
+	publish := names["data structure publish"]
+	if publish != nil {
+		if spanAttribute(publish, "snowplow.data_structure.name") != "login" {
+			t.Errorf("expected data structure name attribute, got %v", publish.Attributes())
+		}
+		if publish.Parent().SpanID() != names["apply"].SpanContext().SpanID() {
+			t.Error("expected data structure spans to be children of apply")
+		}
+	}
+
+	requests := 0
+	for name, s := range names {
+		if strings.HasPrefix(name, "POST ") || strings.HasPrefix(name, "GET ") || strings.HasPrefix(name, "PATCH ") {
+			requests++
+			if spanAttribute(s, "snowplow.resource.type") == "" {
+				t.Errorf("expected resource type on %s", name)
+			}
+		}
+	}
+	if requests == 0 {
+		t.Error("expected spans for console requests")
+	}
+}
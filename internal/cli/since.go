@@ -0,0 +1,102 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package cli
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/util"
+)
+
+// gitChanges asks git what changed since ref, nil without a ref
+func gitChanges(ref string, paths []string) (*util.GitChanges, error) {
+	if ref == "" {
+		return nil, nil
+	}
+	changes, err := util.GitChangesSince(ref)
+	if err != nil {
+		return nil, ConfigError(err)
+	}
+	wd, _ := os.Getwd()
+	for _, f := range changes.DeletedUnder(paths) {
+		if rp, err := filepath.Rel(wd, f); err == nil {
+			f = rp
+		}
+		slog.Info("change detection", "msg", "deleted since "+ref, "file", f)
+	}
+	return changes, nil
+}
+
+// resourcesSince narrows files to the resources changed since the ref of git, the data products using changed
+// or deleted source applications, and the source applications those data products need. Changed returns the
+// files which changed or depend on changes
+func resourcesSince(files map[string]map[string]any, git *util.GitChanges) (narrowed map[string]map[string]any, changed map[string]string) {
+	changed = map[string]string{}
+	for f := range files {
+		if git.Changed(f) {
+			changed[f] = f
+		}
+	}
+
+	needs := map[string][]string{}
+	for f, resource := range files {
+		if resource["resourceType"] != "data-product" {
+			continue
+		}
+		var dp model.DataProduct
+		if err := mapstructure.Decode(resource, &dp); err != nil {
+			// reported by validation when changed
+			continue
+		}
+		refs := dp.Data.SourceApplications
+		for _, es := range dp.Data.EventSpecifications {
+			refs = append(refs, es.ExcludedSourceApplications...)
+		}
+		for _, ref := range refs {
+			sa, err := filepath.Abs(filepath.Join(filepath.Dir(f), ref["$ref"]))
+			if err != nil {
+				continue
+			}
+			needs[f] = append(needs[f], sa)
+			if git.Changed(sa) || git.Deleted(sa) {
+				changed[f] = f
+			}
+		}
+	}
+
+	narrowed = map[string]map[string]any{}
+	for f := range changed {
+		narrowed[f] = files[f]
+		for _, sa := range needs[f] {
+			if resource, ok := files[sa]; ok {
+				narrowed[sa] = resource
+			}
+		}
+	}
+	slog.Info("change detection", "msg", "changed since "+git.Ref, "files", len(changed), "with dependencies", len(narrowed))
+	return narrowed, changed
+}
+
+// dataStructuresSince narrows dss to the data structures changed since the ref of git
+func dataStructuresSince(dss map[string]model.DataStructure, git *util.GitChanges) map[string]model.DataStructure {
+	narrowed := map[string]model.DataStructure{}
+	for f, ds := range dss {
+		if git.Changed(f) {
+			narrowed[f] = ds
+		}
+	}
+	slog.Info("change detection", "msg", "changed since "+git.Ref, "data structures", len(narrowed))
+	return narrowed
+}
@@ -0,0 +1,148 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package cli
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func gitCommitAll(t *testing.T) {
+	t.Helper()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "-A"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "initial"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s", args, out)
+		}
+	}
+}
+
+func sourceApp(name string) string {
+	return `apiVersion: v1
+resourceType: source-application
+resourceName: ` + name + `
+data:
+  name: ` + name + `
+  appIds: [` + name + `]
+`
+}
+
+func dataProduct(name string, sourceApps ...string) string {
+	content := `apiVersion: v1
+resourceType: data-product
+resourceName: ` + name + `
+data:
+  name: ` + name + `
+  sourceApplications:
+`
+	for _, sa := range sourceApps {
+		content += "  - $ref: ../source-apps/" + sa + ".yaml\n"
+	}
+	return content + "  eventSpecifications: []\n"
+}
+
+func Test_ResourcesSince(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	inTempDir(t)
+	writeFiles(t, map[string]string{
+		"source-apps/web.yaml":     sourceApp("web"),
+		"source-apps/mobile.yaml":  sourceApp("mobile"),
+		"source-apps/kiosk.yaml":   sourceApp("kiosk"),
+		"data-products/shop.yaml":  dataProduct("shop", "web", "mobile"),
+		"data-products/blog.yaml":  dataProduct("blog", "mobile"),
+		"data-products/store.yaml": dataProduct("store", "kiosk"),
+	})
+	gitCommitAll(t)
+
+	writeFiles(t, map[string]string{"source-apps/web.yaml": sourceApp("website")})
+	if err := os.Remove("source-apps/kiosk.yaml"); err != nil {
+		t.Fatal(err)
+	}
+
+	searchPaths := []string{"data-products", "source-apps"}
+	files, err := readLocalResources(context.Background(), searchPaths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	git, err := gitChanges("HEAD", searchPaths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	narrowed, changed := resourcesSince(files, git)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rel := func(files []string) []string {
+		res := []string{}
+		for _, f := range files {
+			r, err := filepath.Rel(wd, f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res = append(res, filepath.ToSlash(r))
+		}
+		slices.Sort(res)
+		return res
+	}
+
+	changedFiles := []string{}
+	for f := range changed {
+		changedFiles = append(changedFiles, f)
+	}
+	if got := rel(changedFiles); !reflect.DeepEqual(got, []string{"data-products/shop.yaml", "data-products/store.yaml", "source-apps/web.yaml"}) {
+		t.Fatalf("expected the changed source app and data products using changed or deleted ones got %v", got)
+	}
+	narrowedFiles := []string{}
+	for f := range narrowed {
+		narrowedFiles = append(narrowedFiles, f)
+	}
+	if got := rel(narrowedFiles); !reflect.DeepEqual(got, []string{"data-products/shop.yaml", "data-products/store.yaml", "source-apps/mobile.yaml", "source-apps/web.yaml"}) {
+		t.Fatalf("expected changes with the source apps they need got %v", got)
+	}
+}
+
+func Test_DPValidateSinceUnknownRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	inTempDir(t)
+	writeFiles(t, map[string]string{"data-products/shop.yaml": dataProduct("shop")})
+	gitCommitAll(t)
+
+	err := DPValidate(context.Background(), DPValidateOptions{Paths: []string{"data-products"}, Offline: true, Since: "no-such-ref"})
+	if ExitCode(err) != ExitConfig {
+		t.Fatalf("expected a config error got %v", err)
+	}
+}
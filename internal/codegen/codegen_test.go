@@ -0,0 +1,293 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package codegen
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+const checkoutStarted = `
+apiVersion: v1
+resourceType: data-structure
+meta:
+  hidden: false
+  schemaType: event
+  customData: {}
+data:
+  $schema: http://iglucentral.com/schemas/com.snowplowanalytics.self-desc/schema/jsonschema/1-0-0#
+  self:
+    vendor: com.acme
+    name: checkout_started
+    format: jsonschema
+    version: 1-0-0
+  description: A checkout was started
+  type: object
+  properties:
+    step: {type: string, enum: [cart, payment, "3ds"], description: "where the checkout\nstarted */"}
+    total: {type: number}
+    coupon: {type: [string, "null"]}
+    address:
+      type: object
+      properties:
+        city: {type: string}
+        class: {type: string}
+      required: [city]
+    lines: {type: array, items: {type: object, properties: {sku: {type: string}, qty: {type: integer}}, required: [sku]}}
+    extra: {type: object}
+  required: [step, total, coupon]
+  additionalProperties: false
+`
+
+const product = `
+apiVersion: v1
+resourceType: data-structure
+meta:
+  hidden: false
+  schemaType: entity
+  customData: {}
+data:
+  $schema: http://iglucentral.com/schemas/com.snowplowanalytics.self-desc/schema/jsonschema/1-0-0#
+  self:
+    vendor: com.acme
+    name: product
+    format: jsonschema
+    version: 1-0-0
+  type: object
+  properties:
+    sku: {type: string}
+    price: {type: number}
+  required: [sku]
+  additionalProperties: false
+`
+
+func testModel(t *testing.T) *Model {
+	dss := []model.DataStructure{}
+	for _, y := range []string{checkoutStarted, product} {
+		var ds model.DataStructure
+		if err := yaml.Unmarshal([]byte(y), &ds); err != nil {
+			t.Fatal(err)
+		}
+		dss = append(dss, ds)
+	}
+
+	one, five, zero := 1, 5, 0
+	dp := model.DataProduct{Data: model.DataProductData{
+		Name: "Shop",
+		EventSpecifications: []model.EventSpec{{
+			Name:  "Checkout started",
+			Event: model.SchemaRef{Source: "iglu:com.acme/checkout_started/jsonschema/1-0-0"},
+			Entities: model.EntitiesDef{Tracked: []model.SchemaRef{
+				{Source: "iglu:com.acme/product/jsonschema/1-0-0", MinCardinality: &one, MaxCardinality: &five},
+				{Source: "iglu:com.acme/user/jsonschema/1-0-0", MinCardinality: &zero, MaxCardinality: &one},
+			}},
+		}},
+	}}
+
+	m, err := Build(dss, []model.DataProduct{dp})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func Test_Build(t *testing.T) {
+	m := testModel(t)
+
+	names := []string{}
+	for _, s := range m.Schemas {
+		names = append(names, s.Name)
+	}
+	if strings.Join(names, ",") != "CheckoutStarted,Product,User" {
+		t.Fatalf("unexpected schemas %v", names)
+	}
+	if m.Schemas[2].Local || m.Schemas[2].Type != nil {
+		t.Fatal("missing data structures should be untyped")
+	}
+	if len(m.Warnings) != 1 {
+		t.Fatalf("expected a warning for the missing data structure got %v", m.Warnings)
+	}
+
+	types := map[string]*Type{}
+	for _, typ := range m.Types {
+		types[typ.Name] = typ
+	}
+	if step, ok := types["CheckoutStartedStep"]; !ok || step.Kind != KindEnum || len(step.Values) != 3 {
+		t.Fatalf("expected step enum got %+v", step)
+	}
+	if item, ok := types["CheckoutStartedLinesItem"]; !ok || item.Kind != KindObject {
+		t.Fatalf("expected lines item object got %+v", item)
+	}
+
+	spec := m.Specs[0]
+	if spec.FuncName != "CheckoutStartedSpec" || len(spec.Entities) != 2 {
+		t.Fatalf("unexpected spec %+v", spec)
+	}
+	if e := spec.Entities[0]; e.Param != "Product" || e.Min != 1 || e.Max != 5 || e.Single() {
+		t.Fatalf("unexpected entity %+v", e)
+	}
+	if e := spec.Entities[1]; !e.Single() || !e.optional() {
+		t.Fatalf("unexpected entity %+v", e)
+	}
+}
+
+func Test_BuildErrors(t *testing.T) {
+	var ds model.DataStructure
+	if err := yaml.Unmarshal([]byte(product), &ds); err != nil {
+		t.Fatal(err)
+	}
+	_, err := Build([]model.DataStructure{ds, ds}, nil)
+	if err == nil {
+		t.Fatal("expected duplicate data structures to fail")
+	}
+
+	dp := model.DataProduct{Data: model.DataProductData{
+		EventSpecifications: []model.EventSpec{{Name: "broken", Event: model.SchemaRef{Source: "not a uri"}}},
+	}}
+	_, err = Build([]model.DataStructure{ds}, []model.DataProduct{dp})
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Fatalf("expected bad event source to fail got %v", err)
+	}
+}
+
+func Test_GenerateGo(t *testing.T) {
+	code, err := Generate(testModel(t), "go", "tracking")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "snowplow.go", code, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("%s\n%s", err, code)
+	}
+	if !ast.IsGenerated(file) {
+		t.Fatal("expected generated code marker")
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("tracking", fset, []*ast.File{file}, nil); err != nil {
+		t.Fatalf("%s\n%s", err, code)
+	}
+
+	// gofmt aligns fields so compare with spacing collapsed
+	squashed := strings.Join(strings.Fields(code), " ")
+	for _, want := range []string{
+		"package tracking",
+		"Coupon *string `json:\"coupon\"`",
+		"Lines []CheckoutStartedLinesItem `json:\"lines,omitempty\"`",
+		"// CheckoutStartedStep where the checkout started * /",
+		"CheckoutStartedStepX3ds CheckoutStartedStep = \"3ds\"",
+		"type User map[string]any",
+		"func CheckoutStartedSpec(event CheckoutStarted, entities CheckoutStartedSpecEntities) (SelfDescribingEvent, error) {",
+	} {
+		if !strings.Contains(squashed, want) {
+			t.Errorf("expected %q in\n%s", want, code)
+		}
+	}
+
+	_, err = Generate(testModel(t), "go", "not-a-package")
+	if err == nil {
+		t.Fatal("expected invalid package to fail")
+	}
+}
+
+func Test_GenerateOthers(t *testing.T) {
+	cases := map[string][]string{
+		"typescript": {
+			"export type CheckoutStartedStep = \"cart\" | \"payment\" | \"3ds\";",
+			"  coupon: string | null;",
+			"  address?: CheckoutStartedAddress;",
+			"export const CHECKOUT_STARTED_SCHEMA = \"iglu:com.acme/checkout_started/jsonschema/1-0-0\";",
+			"  user?: User;",
+			"throw new Error(\"Checkout started expects between 1 and 5 Product entities, got \" + entities.product.length);",
+		},
+		"kotlin": {
+			"package com.acme.tracking",
+			"    val coupon: String?,",
+			"    val `class`: String? = null,",
+			"    V_3DS(\"3ds\"),",
+			"        lines?.let { put(\"lines\", it.map { it.toMap() }) }",
+			"    require(product.size >= 1 && product.size <= 5)",
+			"    user: User? = null,",
+		},
+		"swift": {
+			"public enum CheckoutStartedStep: String {",
+			"    public var `class`: String?",
+			"            data[\"coupon\"] = NSNull()",
+			"public func checkoutStartedSpec(event: CheckoutStarted, product: [Product], user: User? = nil) throws -> SelfDescribingEvent {",
+		},
+	}
+
+	for lang, wants := range cases {
+		code, err := Generate(testModel(t), lang, "com.acme.tracking")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(code, generatedHeader) {
+			t.Errorf("%s: expected generated header", lang)
+		}
+		if strings.Contains(code, "checkout\nstarted") {
+			t.Errorf("%s: descriptions should be on one line", lang)
+		}
+		for _, want := range wants {
+			if !strings.Contains(code, want) {
+				t.Errorf("%s: expected %q in\n%s", lang, want, code)
+			}
+		}
+	}
+
+	_, err := Generate(testModel(t), "cobol", "")
+	if err == nil {
+		t.Fatal("expected unsupported language to fail")
+	}
+}
+
+func Test_Names(t *testing.T) {
+	cases := []struct{ in, pascal, camel, snake string }{
+		{"checkout_started", "CheckoutStarted", "checkoutStarted", "CHECKOUT_STARTED"},
+		{"itemsCount", "ItemsCount", "itemsCount", "ITEMS_COUNT"},
+		{"web-page.v2", "WebPageV2", "webPageV2", "WEB_PAGE_V2"},
+		{"3ds", "X3ds", "x3ds", "V_3DS"},
+	}
+	for _, c := range cases {
+		if got := pascal(c.in); got != c.pascal {
+			t.Errorf("pascal(%s) = %s", c.in, got)
+		}
+		if got := camel(c.in); got != c.camel {
+			t.Errorf("camel(%s) = %s", c.in, got)
+		}
+		if got := upperSnake(c.in); got != c.snake {
+			t.Errorf("upperSnake(%s) = %s", c.in, got)
+		}
+	}
+
+	n := newNamer()
+	if a, b, c := n.unique("Event"), n.unique("event"), n.unique("Event"); a != "Event" || b == a || c == a || b == c {
+		t.Fatalf("expected unique names got %s %s %s", a, b, c)
+	}
+}
+
+func Test_Quote(t *testing.T) {
+	if got := quote("a\"$\n\x01", "kotlin"); got != `"a\"\$\n\u0001"` {
+		t.Fatalf("unexpected kotlin %s", got)
+	}
+	if got := quote("a\x01", "swift"); got != `"a\u{1}"` {
+		t.Fatalf("unexpected swift %s", got)
+	}
+}
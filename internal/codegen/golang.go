@@ -0,0 +1,210 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package codegen
+
+import (
+	"fmt"
+	"go/format"
+	"go/token"
+	"strings"
+	"unicode"
+)
+
+func goType(t *Type) string {
+	switch t.Kind {
+	case KindString:
+		return "string"
+	case KindInteger:
+		return "int64"
+	case KindNumber:
+		return "float64"
+	case KindBoolean:
+		return "bool"
+	case KindEnum, KindObject:
+		return t.Name
+	case KindArray:
+		return "[]" + goType(t.Items)
+	case KindMap:
+		return "map[string]any"
+	}
+	return "any"
+}
+
+// goFieldType uses pointers for values that may be absent, unless nil already means absent
+func goFieldType(f Field) string {
+	typ := goType(f.Type)
+	switch f.Type.Kind {
+	case KindArray, KindMap, KindAny:
+		return typ
+	}
+	if f.Optional() {
+		return "*" + typ
+	}
+	return typ
+}
+
+func goDoc(sb *strings.Builder, indent string, name string, text string) {
+	text = comment(text)
+	if text == "" {
+		return
+	}
+	// descriptions read as the rest of a sentence starting with the name
+	if len(text) > 1 && unicode.IsLower(rune(text[1])) {
+		text = lowerFirst(text)
+	}
+	fmt.Fprintf(sb, "%s// %s %s\n", indent, name, text)
+}
+
+func golang(m *Model, pkg string) (string, error) {
+	if pkg == "" {
+		pkg = "snowplow"
+	}
+	if !token.IsIdentifier(pkg) {
+		return "", fmt.Errorf("%s is not a valid go package name", pkg)
+	}
+
+	// every top level name so constants can be kept from clashing with types
+	names := newNamer()
+	for _, t := range m.Types {
+		names.unique(t.Name)
+	}
+	for _, s := range m.Schemas {
+		names.unique(s.Name)
+	}
+	for _, spec := range m.Specs {
+		names.unique(spec.FuncName)
+		names.unique(spec.FuncName + "Entities")
+	}
+	names.unique("SelfDescribingJson")
+	names.unique("SelfDescribingEvent")
+
+	usesFmt := false
+	var body strings.Builder
+
+	for _, t := range m.Types {
+		body.WriteString("\n")
+		goDoc(&body, "", t.Name, t.Description)
+		if t.Kind == KindEnum {
+			fmt.Fprintf(&body, "type %s string\n\nconst (\n", t.Name)
+			for _, v := range t.Values {
+				fmt.Fprintf(&body, "\t%s %s = %s\n", names.unique(t.Name+pascal(v)), t.Name, quote(v, "go"))
+			}
+			body.WriteString(")\n")
+			continue
+		}
+		fields := newNamer()
+		fields.unique("SelfDescribing")
+		fmt.Fprintf(&body, "type %s struct {\n", t.Name)
+		for _, f := range t.Fields {
+			name := fields.unique(pascal(f.Key))
+			goDoc(&body, "\t", name, f.Description)
+			tag := f.Key
+			if !f.Required {
+				tag += ",omitempty"
+			}
+			fmt.Fprintf(&body, "\t%s %s `json:%s`\n", name, goFieldType(f), quote(tag, "go"))
+		}
+		body.WriteString("}\n")
+	}
+
+	for _, s := range m.Schemas {
+		body.WriteString("\n")
+		if s.Type == nil {
+			fmt.Fprintf(&body, "// %s data, %s\n", s.Name, comment(untyped(s)))
+			fmt.Fprintf(&body, "type %s map[string]any\n\n", s.Name)
+		}
+		constant := names.unique(s.Name + "Schema")
+		fmt.Fprintf(&body, "// %s is the iglu uri of %s\n", constant, s.Name)
+		fmt.Fprintf(&body, "const %s = %s\n\n", constant, quote(s.Uri, "go"))
+		fmt.Fprintf(&body, "// SelfDescribing wraps d with its schema\n")
+		fmt.Fprintf(&body, "func (d %s) SelfDescribing() SelfDescribingJson {\n", s.Name)
+		fmt.Fprintf(&body, "\treturn SelfDescribingJson{Schema: %s, Data: d}\n}\n", constant)
+	}
+
+	for _, spec := range m.Specs {
+		body.WriteString("\n")
+		params := fmt.Sprintf("event %s", spec.Event.Name)
+		if len(spec.Entities) > 0 {
+			entities := spec.FuncName + "Entities"
+			fmt.Fprintf(&body, "// %s are the entities sent with the %s event specification\n", entities, comment(spec.Name))
+			fmt.Fprintf(&body, "type %s struct {\n", entities)
+			for _, e := range spec.Entities {
+				typ := e.Schema.Name
+				switch {
+				case !e.Single():
+					typ = "[]" + typ
+				case e.optional():
+					typ = "*" + typ
+				}
+				fmt.Fprintf(&body, "\t%s %s\n", e.Param, typ)
+			}
+			body.WriteString("}\n\n")
+			params += ", entities " + entities
+		}
+
+		fmt.Fprintf(&body, "// %s builds the %s event specification of %s\n", spec.FuncName, comment(spec.Name), comment(spec.DataProduct))
+		fmt.Fprintf(&body, "func %s(%s) (SelfDescribingEvent, error) {\n", spec.FuncName, params)
+		body.WriteString("\tcontext := []SelfDescribingJson{}\n")
+		for _, e := range spec.Entities {
+			value := "entities." + e.Param
+			if e.Single() {
+				if e.optional() {
+					fmt.Fprintf(&body, "\tif %s != nil {\n\t\tcontext = append(context, %s.SelfDescribing())\n\t}\n", value, value)
+				} else {
+					fmt.Fprintf(&body, "\tcontext = append(context, %s.SelfDescribing())\n", value)
+				}
+				continue
+			}
+			if e.checked() {
+				usesFmt = true
+				conditions := []string{}
+				if e.Min > 0 {
+					conditions = append(conditions, fmt.Sprintf("len(%s) < %d", value, e.Min))
+				}
+				if e.Max > 0 {
+					conditions = append(conditions, fmt.Sprintf("len(%s) > %d", value, e.Max))
+				}
+				message := strings.ReplaceAll(fmt.Sprintf("%s expects %s %s entities, got ", spec.Name, cardinality(e), e.Schema.Name), "%", "%%")
+				fmt.Fprintf(&body, "\tif %s {\n\t\treturn SelfDescribingEvent{}, fmt.Errorf(%s, len(%s))\n\t}\n",
+					strings.Join(conditions, " || "), quote(message+"%d", "go"), value)
+			}
+			fmt.Fprintf(&body, "\tfor _, e := range %s {\n\t\tcontext = append(context, e.SelfDescribing())\n\t}\n", value)
+		}
+		body.WriteString("\treturn SelfDescribingEvent{Event: event.SelfDescribing(), Context: context}, nil\n}\n")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(generatedHeader)
+	fmt.Fprintf(&sb, "\npackage %s\n", pkg)
+	if usesFmt {
+		sb.WriteString("\nimport \"fmt\"\n")
+	}
+	sb.WriteString(`
+// SelfDescribingJson is data together with the iglu uri of its schema
+type SelfDescribingJson struct {
+	Schema string ` + "`json:\"schema\"`" + `
+	Data   any    ` + "`json:\"data\"`" + `
+}
+
+// SelfDescribingEvent is an event and the entities sent with it
+type SelfDescribingEvent struct {
+	Event   SelfDescribingJson
+	Context []SelfDescribingJson
+}
+`)
+	sb.WriteString(body.String())
+
+	out, err := format.Source([]byte(sb.String()))
+	if err != nil {
+		return "", fmt.Errorf("generated go does not parse: %w", err)
+	}
+	return string(out), nil
+}
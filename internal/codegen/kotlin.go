@@ -0,0 +1,225 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package codegen
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var ktKeywords = []string{
+	"as", "break", "class", "continue", "do", "else", "false", "for", "fun", "if", "in",
+	"interface", "is", "null", "object", "package", "return", "super", "this", "throw",
+	"true", "try", "typealias", "typeof", "val", "var", "when", "while",
+}
+
+var ktPackage = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+func ktName(name string) string {
+	if slices.Contains(ktKeywords, name) {
+		return "`" + name + "`"
+	}
+	return name
+}
+
+func ktType(t *Type) string {
+	switch t.Kind {
+	case KindString:
+		return "String"
+	case KindInteger:
+		return "Long"
+	case KindNumber:
+		return "Double"
+	case KindBoolean:
+		return "Boolean"
+	case KindEnum, KindObject:
+		return t.Name
+	case KindArray:
+		return "List<" + ktType(t.Items) + ">"
+	case KindMap:
+		return "Map<String, Any?>"
+	}
+	return "Any"
+}
+
+// ktValue converts expr to what a self describing map holds
+func ktValue(t *Type, expr string) string {
+	switch t.Kind {
+	case KindObject:
+		return expr + ".toMap()"
+	case KindEnum:
+		return expr + ".value"
+	case KindArray:
+		if item := ktValue(t.Items, "it"); item != "it" {
+			return expr + ".map { " + item + " }"
+		}
+	}
+	return expr
+}
+
+func ktDoc(sb *strings.Builder, indent string, text string) {
+	if text != "" {
+		fmt.Fprintf(sb, "%s/** %s */\n", indent, comment(text))
+	}
+}
+
+// ktSchemaMembers lets a class be sent as self describing json
+func ktSchemaMembers(sb *strings.Builder, s *Schema) {
+	sb.WriteString("\n    fun toSelfDescribing() = SelfDescribingJson(SCHEMA, toMap())\n\n")
+	sb.WriteString("    companion object {\n")
+	fmt.Fprintf(sb, "        const val SCHEMA = %s\n", quote(s.Uri, "kotlin"))
+	sb.WriteString("    }\n")
+}
+
+func kotlin(m *Model, pkg string) (string, error) {
+	if pkg == "" {
+		pkg = "snowplow"
+	}
+	if !ktPackage.MatchString(pkg) {
+		return "", fmt.Errorf("%s is not a valid kotlin package name", pkg)
+	}
+
+	roots := map[*Type]*Schema{}
+	for _, s := range m.Schemas {
+		if s.Type != nil {
+			roots[s.Type] = s
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(generatedHeader)
+	fmt.Fprintf(&sb, "\npackage %s\n", pkg)
+	sb.WriteString(`
+/** Data together with the iglu uri of its schema */
+data class SelfDescribingJson(val schema: String, val data: Map<String, Any?>)
+
+/** An event and the entities sent with it */
+data class SelfDescribingEvent(val event: SelfDescribingJson, val context: List<SelfDescribingJson>)
+`)
+
+	for _, t := range m.Types {
+		sb.WriteString("\n")
+		ktDoc(&sb, "", t.Description)
+		if t.Kind == KindEnum {
+			fmt.Fprintf(&sb, "enum class %s(val value: String) {\n", t.Name)
+			entries := newNamer()
+			for _, v := range t.Values {
+				fmt.Fprintf(&sb, "    %s(%s),\n", entries.unique(upperSnake(v)), quote(v, "kotlin"))
+			}
+			sb.WriteString("}\n")
+			continue
+		}
+
+		fields := newNamer()
+		fields.unique("toMap")
+		fields.unique("toSelfDescribing")
+		names := []string{}
+		fmt.Fprintf(&sb, "data class %s(\n", t.Name)
+		for _, f := range t.Fields {
+			name := ktName(fields.unique(camel(f.Key)))
+			names = append(names, name)
+			ktDoc(&sb, "    ", f.Description)
+			typ := ktType(f.Type)
+			switch {
+			case !f.Required:
+				typ += "? = null"
+			case f.Type.Nullable:
+				typ += "?"
+			}
+			fmt.Fprintf(&sb, "    val %s: %s,\n", name, typ)
+		}
+		sb.WriteString(") {\n")
+		sb.WriteString("    fun toMap(): Map<String, Any?> = buildMap<String, Any?> {\n")
+		for i, f := range t.Fields {
+			key := quote(f.Key, "kotlin")
+			switch {
+			case !f.Required:
+				fmt.Fprintf(&sb, "        %s?.let { put(%s, %s) }\n", names[i], key, ktValue(f.Type, "it"))
+			case f.Type.Nullable:
+				if value := ktValue(f.Type, "it"); value != "it" {
+					fmt.Fprintf(&sb, "        put(%s, %s?.let { %s })\n", key, names[i], value)
+				} else {
+					fmt.Fprintf(&sb, "        put(%s, %s)\n", key, names[i])
+				}
+			default:
+				fmt.Fprintf(&sb, "        put(%s, %s)\n", key, ktValue(f.Type, names[i]))
+			}
+		}
+		sb.WriteString("    }\n")
+		if s, ok := roots[t]; ok {
+			ktSchemaMembers(&sb, s)
+		}
+		sb.WriteString("}\n")
+	}
+
+	for _, s := range m.Schemas {
+		if s.Type != nil {
+			continue
+		}
+		sb.WriteString("\n")
+		ktDoc(&sb, "", untyped(s))
+		fmt.Fprintf(&sb, "data class %s(val data: Map<String, Any?>) {\n", s.Name)
+		sb.WriteString("    fun toMap(): Map<String, Any?> = data\n")
+		ktSchemaMembers(&sb, s)
+		sb.WriteString("}\n")
+	}
+
+	for _, spec := range m.Specs {
+		sb.WriteString("\n")
+		ktDoc(&sb, "", fmt.Sprintf("%s, an event specification of %s", spec.Name, spec.DataProduct))
+		fmt.Fprintf(&sb, "fun %s(\n    event: %s,\n", camel(spec.FuncName), spec.Event.Name)
+		for _, e := range spec.Entities {
+			typ := e.Schema.Name
+			switch {
+			case !e.Single() && e.optional():
+				typ = "List<" + typ + "> = emptyList()"
+			case !e.Single():
+				typ = "List<" + typ + ">"
+			case e.optional():
+				typ += "? = null"
+			}
+			fmt.Fprintf(&sb, "    %s: %s,\n", ktName(camel(e.Param)), typ)
+		}
+		sb.WriteString("): SelfDescribingEvent {\n")
+		for _, e := range spec.Entities {
+			if !e.checked() {
+				continue
+			}
+			param := ktName(camel(e.Param))
+			conditions := []string{}
+			if e.Min > 0 {
+				conditions = append(conditions, fmt.Sprintf("%s.size >= %d", param, e.Min))
+			}
+			if e.Max > 0 {
+				conditions = append(conditions, fmt.Sprintf("%s.size <= %d", param, e.Max))
+			}
+			message := fmt.Sprintf("%s expects %s %s entities, got ", spec.Name, cardinality(e), e.Schema.Name)
+			fmt.Fprintf(&sb, "    require(%s) { %s + %s.size }\n", strings.Join(conditions, " && "), quote(message, "kotlin"), param)
+		}
+		sb.WriteString("    val context = mutableListOf<SelfDescribingJson>()\n")
+		for _, e := range spec.Entities {
+			param := ktName(camel(e.Param))
+			switch {
+			case !e.Single():
+				fmt.Fprintf(&sb, "    %s.forEach { context.add(it.toSelfDescribing()) }\n", param)
+			case e.optional():
+				fmt.Fprintf(&sb, "    %s?.let { context.add(it.toSelfDescribing()) }\n", param)
+			default:
+				fmt.Fprintf(&sb, "    context.add(%s.toSelfDescribing())\n", param)
+			}
+		}
+		sb.WriteString("    return SelfDescribingEvent(event.toSelfDescribing(), context)\n}\n")
+	}
+
+	return sb.String(), nil
+}
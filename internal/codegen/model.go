@@ -0,0 +1,395 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+// Package codegen generates typed tracking code from data structures and event specifications
+package codegen
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+type Kind int
+
+const (
+	KindAny Kind = iota
+	KindString
+	KindInteger
+	KindNumber
+	KindBoolean
+	KindEnum
+	KindArray
+	KindMap
+	KindObject
+)
+
+// Type is a language independent view of a json schema
+type Type struct {
+	Kind Kind
+	// Name of object and enum types
+	Name        string
+	Description string
+	// Nullable types also accept null
+	Nullable bool
+	Items    *Type
+	Fields   []Field
+	Values   []string
+}
+
+type Field struct {
+	// Key is the property name in the json payload
+	Key         string
+	Description string
+	Type        *Type
+	Required    bool
+}
+
+// Optional fields may be left out or set to null
+func (f Field) Optional() bool {
+	return !f.Required || f.Type.Nullable
+}
+
+// Schema is a data structure code is generated for
+type Schema struct {
+	Uri  string
+	Name string
+	// Local schemas were found among the local data structures
+	Local bool
+	// Type is nil when the data is untyped, because the schema has no properties or is not local
+	Type *Type
+}
+
+// Entity is an entity an event specification sends along with its event
+type Entity struct {
+	Param  string
+	Schema *Schema
+	Min    int
+	// Max is -1 when unbounded
+	Max int
+}
+
+// Single entities are passed as one value, others as a list
+func (e Entity) Single() bool {
+	return e.Max == 1
+}
+
+type EventSpec struct {
+	Name        string
+	DataProduct string
+	FuncName    string
+	Event       *Schema
+	Entities    []Entity
+}
+
+// Model holds everything code is generated for, in a stable order
+type Model struct {
+	Schemas []*Schema
+	// Types are the named object and enum types, children before their parents
+	Types []*Type
+	Specs []*EventSpec
+	// Warnings are about things code could not be generated for
+	Warnings []string
+}
+
+type builder struct {
+	names   *namer
+	model   *Model
+	byUri   map[string]*Schema
+	locals  map[string]model.DataStructure
+	visited map[string]bool
+}
+
+// Build resolves data structures and the event specifications of data products into a model
+func Build(dss []model.DataStructure, dps []model.DataProduct) (*Model, error) {
+	b := &builder{
+		names:  newNamer(),
+		model:  &Model{},
+		byUri:  map[string]*Schema{},
+		locals: map[string]model.DataStructure{},
+	}
+
+	uris := []string{}
+	names := map[string]int{}
+	for _, ds := range dss {
+		data, err := ds.ParseData()
+		if err != nil {
+			return nil, err
+		}
+		uri := data.Self.IgluUri()
+		if _, ok := b.locals[uri]; ok {
+			return nil, fmt.Errorf("data structure %s is defined more than once", uri)
+		}
+		b.locals[uri] = ds
+		uris = append(uris, uri)
+		names[data.Self.Name]++
+	}
+	sort.Strings(uris)
+
+	for _, uri := range uris {
+		if _, err := b.schema(uri, names); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, dp := range dps {
+		for _, es := range dp.Data.EventSpecifications {
+			err := b.eventSpec(dp.Data.Name, es, names)
+			if err != nil {
+				return nil, fmt.Errorf("event specification %s: %w", es.Name, err)
+			}
+		}
+	}
+
+	return b.model, nil
+}
+
+type igluRef struct {
+	vendor  string
+	name    string
+	version string
+}
+
+func parseIgluUri(uri string) (igluRef, error) {
+	parts := strings.Split(strings.TrimPrefix(uri, "iglu:"), "/")
+	if !strings.HasPrefix(uri, "iglu:") || len(parts) != 4 {
+		return igluRef{}, fmt.Errorf("%s is not an iglu uri", uri)
+	}
+	return igluRef{vendor: parts[0], name: parts[1], version: parts[3]}, nil
+}
+
+// schema returns the schema for uri, building its types the first time it is seen
+func (b *builder) schema(uri string, names map[string]int) (*Schema, error) {
+	if s, ok := b.byUri[uri]; ok {
+		return s, nil
+	}
+	ref, err := parseIgluUri(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	// names are only qualified when they would otherwise clash
+	base := pascal(ref.name)
+	if names[ref.name] > 1 {
+		base = pascal(ref.vendor) + base
+	}
+	s := &Schema{Uri: uri, Name: b.names.unique(base)}
+
+	if ds, ok := b.locals[uri]; ok {
+		s.Local = true
+		b.visited = map[string]bool{}
+		t := b.typeOf(ds.Data, ds.Data, s.Name)
+		if t.Kind == KindObject {
+			s.Type = t
+		} else if t.Kind != KindMap {
+			return nil, fmt.Errorf("%s: only object schemas are supported", uri)
+		}
+	} else {
+		b.model.Warnings = append(b.model.Warnings, fmt.Sprintf("no local data structure for %s, its data is untyped", uri))
+	}
+
+	b.byUri[uri] = s
+	b.model.Schemas = append(b.model.Schemas, s)
+	return s, nil
+}
+
+func (b *builder) eventSpec(dataProduct string, es model.EventSpec, names map[string]int) error {
+	if es.Event.Source == "" {
+		b.model.Warnings = append(b.model.Warnings, fmt.Sprintf("event specification %s has no event, skipped", es.Name))
+		return nil
+	}
+	event, err := b.schema(es.Event.Source, names)
+	if err != nil {
+		return err
+	}
+
+	spec := &EventSpec{
+		Name:        es.Name,
+		DataProduct: dataProduct,
+		FuncName:    b.names.unique(pascal(es.Name) + "Spec"),
+		Event:       event,
+	}
+
+	// event and context are taken by the generated functions
+	params := newNamer()
+	params.unique("Event")
+	params.unique("Context")
+	for _, e := range es.Entities.Tracked {
+		if e.Source == "" {
+			continue
+		}
+		s, err := b.schema(e.Source, names)
+		if err != nil {
+			return err
+		}
+		entity := Entity{Schema: s, Max: -1}
+		if e.MinCardinality != nil {
+			entity.Min = *e.MinCardinality
+		}
+		if e.MaxCardinality != nil {
+			entity.Max = *e.MaxCardinality
+		}
+		if entity.Max == 0 {
+			continue
+		}
+		ref, _ := parseIgluUri(e.Source)
+		entity.Param = params.unique(pascal(ref.name))
+		spec.Entities = append(spec.Entities, entity)
+	}
+
+	b.model.Specs = append(b.model.Specs, spec)
+	return nil
+}
+
+func description(schema map[string]any) string {
+	d, _ := schema["description"].(string)
+	return strings.Join(strings.Fields(d), " ")
+}
+
+// typeOf converts a json schema, name is used when it needs a named type
+func (b *builder) typeOf(root map[string]any, schema map[string]any, name string) *Type {
+	if ref, ok := schema["$ref"].(string); ok {
+		target := resolveRef(root, ref)
+		if target == nil || b.visited[ref] {
+			return &Type{Kind: KindAny}
+		}
+		b.visited[ref] = true
+		defer delete(b.visited, ref)
+		return b.typeOf(root, target, name)
+	}
+
+	types := []string{}
+	switch t := schema["type"].(type) {
+	case string:
+		types = append(types, t)
+	case []any:
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+	}
+	nullable := slices.Contains(types, "null")
+	types = slices.DeleteFunc(types, func(t string) bool { return t == "null" })
+	// every integer is a number
+	if slices.Contains(types, "integer") && slices.Contains(types, "number") {
+		types = slices.DeleteFunc(types, func(t string) bool { return t == "integer" })
+	}
+
+	if enum, ok := schema["enum"].([]any); ok && len(enum) > 0 {
+		values := []string{}
+		onlyStrings := true
+		for _, v := range enum {
+			switch v := v.(type) {
+			case nil:
+				nullable = true
+			case string:
+				values = append(values, v)
+			default:
+				onlyStrings = false
+			}
+		}
+		if onlyStrings && len(values) > 0 && (len(types) == 0 || (len(types) == 1 && types[0] == "string")) {
+			t := &Type{Kind: KindEnum, Name: b.names.unique(name), Nullable: nullable, Values: values, Description: description(schema)}
+			b.model.Types = append(b.model.Types, t)
+			return t
+		}
+	}
+
+	if len(types) == 0 && schema["properties"] != nil {
+		types = []string{"object"}
+	}
+	if len(types) != 1 {
+		return &Type{Kind: KindAny, Nullable: nullable}
+	}
+
+	switch types[0] {
+	case "string":
+		return &Type{Kind: KindString, Nullable: nullable}
+	case "integer":
+		return &Type{Kind: KindInteger, Nullable: nullable}
+	case "number":
+		return &Type{Kind: KindNumber, Nullable: nullable}
+	case "boolean":
+		return &Type{Kind: KindBoolean, Nullable: nullable}
+	case "array":
+		items, _ := schema["items"].(map[string]any)
+		if items == nil {
+			return &Type{Kind: KindArray, Nullable: nullable, Items: &Type{Kind: KindAny}}
+		}
+		item := b.typeOf(root, items, name+"Item")
+		// null items are not representable in every language, they are left out
+		item.Nullable = false
+		return &Type{Kind: KindArray, Nullable: nullable, Items: item}
+	case "object":
+		properties, _ := schema["properties"].(map[string]any)
+		if len(properties) == 0 {
+			return &Type{Kind: KindMap, Nullable: nullable}
+		}
+		required := map[string]bool{}
+		if r, ok := schema["required"].([]any); ok {
+			for _, k := range r {
+				if s, ok := k.(string); ok {
+					required[s] = true
+				}
+			}
+		}
+		keys := make([]string, 0, len(properties))
+		for k := range properties {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		t := &Type{Kind: KindObject, Nullable: nullable, Description: description(schema)}
+		if name == "" {
+			name = "Object"
+		}
+		// the root object takes the schema name, which is already reserved
+		if schema["self"] != nil {
+			t.Name = name
+		} else {
+			t.Name = b.names.unique(name)
+		}
+		for _, k := range keys {
+			ps, _ := properties[k].(map[string]any)
+			if ps == nil {
+				ps = map[string]any{}
+			}
+			ft := b.typeOf(root, ps, t.Name+pascal(k))
+			t.Fields = append(t.Fields, Field{Key: k, Description: description(ps), Type: ft, Required: required[k]})
+		}
+		b.model.Types = append(b.model.Types, t)
+		return t
+	}
+
+	return &Type{Kind: KindAny, Nullable: nullable}
+}
+
+func resolveRef(root map[string]any, ref string) map[string]any {
+	if !strings.HasPrefix(ref, "#") {
+		return nil
+	}
+	var current any = root
+	for _, token := range strings.Split(strings.TrimPrefix(strings.TrimPrefix(ref, "#"), "/"), "/") {
+		if token == "" {
+			continue
+		}
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil
+		}
+		current = m[strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")]
+	}
+	target, _ := current.(map[string]any)
+	return target
+}
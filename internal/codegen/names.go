@@ -0,0 +1,113 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package codegen
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// words splits an identifier on anything that is not a letter or digit and on camel case humps
+func words(s string) []string {
+	res := []string{}
+	current := []rune{}
+	var prev rune
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) || r > unicode.MaxASCII {
+			if len(current) > 0 {
+				res = append(res, string(current))
+				current = nil
+			}
+			prev = 0
+			continue
+		}
+		if unicode.IsUpper(r) && unicode.IsLower(prev) && len(current) > 0 {
+			res = append(res, string(current))
+			current = nil
+		}
+		current = append(current, r)
+		prev = r
+	}
+	if len(current) > 0 {
+		res = append(res, string(current))
+	}
+	return res
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+// pascal turns login_click into LoginClick, identifiers never start with a digit
+func pascal(s string) string {
+	var sb strings.Builder
+	for _, w := range words(s) {
+		sb.WriteString(upperFirst(w))
+	}
+	res := sb.String()
+	if res == "" || unicode.IsDigit(rune(res[0])) {
+		res = "X" + res
+	}
+	return res
+}
+
+// camel turns login_click into loginClick
+func camel(s string) string {
+	p := pascal(s)
+	ws := words(p)
+	// leading acronyms are lowered as a whole, URLPath becomes urlPath not uRLPath
+	if len(ws) > 0 && strings.ToUpper(ws[0]) == ws[0] && len(ws[0]) > 1 {
+		return strings.ToLower(ws[0]) + p[len(ws[0]):]
+	}
+	return lowerFirst(p)
+}
+
+// upperSnake turns login_click into LOGIN_CLICK
+func upperSnake(s string) string {
+	ws := words(s)
+	for i, w := range ws {
+		ws[i] = strings.ToUpper(w)
+	}
+	res := strings.Join(ws, "_")
+	if res == "" || unicode.IsDigit(rune(res[0])) {
+		res = "V_" + res
+	}
+	return res
+}
+
+// namer hands out names that have not been used yet
+type namer struct {
+	used map[string]bool
+}
+
+func newNamer() *namer {
+	return &namer{used: map[string]bool{}}
+}
+
+func (n *namer) unique(name string) string {
+	res := name
+	for i := 2; n.used[strings.ToLower(res)]; i++ {
+		res = fmt.Sprintf("%s%d", name, i)
+	}
+	n.used[strings.ToLower(res)] = true
+	return res
+}
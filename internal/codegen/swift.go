@@ -0,0 +1,249 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package codegen
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var swiftKeywords = []string{
+	"associatedtype", "class", "deinit", "enum", "extension", "fileprivate", "func", "import",
+	"init", "inout", "internal", "let", "open", "operator", "private", "protocol", "public",
+	"rethrows", "static", "struct", "subscript", "typealias", "var", "break", "case", "continue",
+	"default", "defer", "do", "else", "fallthrough", "for", "guard", "if", "in", "repeat",
+	"return", "switch", "where", "while", "as", "Any", "catch", "false", "is", "nil", "super",
+	"self", "Self", "throw", "throws", "true", "try",
+}
+
+func swiftName(name string) string {
+	if slices.Contains(swiftKeywords, name) {
+		return "`" + name + "`"
+	}
+	return name
+}
+
+func swiftType(t *Type) string {
+	switch t.Kind {
+	case KindString:
+		return "String"
+	case KindInteger:
+		return "Int"
+	case KindNumber:
+		return "Double"
+	case KindBoolean:
+		return "Bool"
+	case KindEnum, KindObject:
+		return t.Name
+	case KindArray:
+		return "[" + swiftType(t.Items) + "]"
+	case KindMap:
+		return "[String: Any]"
+	}
+	return "Any"
+}
+
+// swiftValue converts expr to what a self describing dictionary holds
+func swiftValue(t *Type, expr string) string {
+	switch t.Kind {
+	case KindObject:
+		return expr + ".toDictionary()"
+	case KindEnum:
+		return expr + ".rawValue"
+	case KindArray:
+		if item := swiftValue(t.Items, "$0"); item != "$0" {
+			return expr + ".map { " + item + " }"
+		}
+	}
+	return expr
+}
+
+func swiftDoc(sb *strings.Builder, indent string, text string) {
+	if text != "" {
+		fmt.Fprintf(sb, "%s/// %s\n", indent, comment(text))
+	}
+}
+
+// swiftSchemaMembers lets a struct be sent as self describing json
+func swiftSchemaMembers(sb *strings.Builder) {
+	sb.WriteString("\n    public func toSelfDescribing() -> SelfDescribingJson {\n")
+	sb.WriteString("        SelfDescribingJson(schema: Self.schema, data: toDictionary())\n    }\n")
+}
+
+func swift(m *Model) string {
+	roots := map[*Type]*Schema{}
+	for _, s := range m.Schemas {
+		if s.Type != nil {
+			roots[s.Type] = s
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(generatedHeader)
+	sb.WriteString(`
+import Foundation
+
+/// Data together with the iglu uri of its schema
+public struct SelfDescribingJson {
+    public let schema: String
+    public let data: [String: Any]
+}
+
+/// An event and the entities sent with it
+public struct SelfDescribingEvent {
+    public let event: SelfDescribingJson
+    public let context: [SelfDescribingJson]
+}
+
+/// Thrown when an event specification is given the wrong number of entities
+public struct CardinalityError: Error, CustomStringConvertible {
+    public let description: String
+}
+`)
+
+	for _, t := range m.Types {
+		sb.WriteString("\n")
+		swiftDoc(&sb, "", t.Description)
+		if t.Kind == KindEnum {
+			fmt.Fprintf(&sb, "public enum %s: String {\n", t.Name)
+			cases := newNamer()
+			for _, v := range t.Values {
+				fmt.Fprintf(&sb, "    case %s = %s\n", swiftName(cases.unique(camel(v))), quote(v, "swift"))
+			}
+			sb.WriteString("}\n")
+			continue
+		}
+
+		fields := newNamer()
+		fields.unique("schema")
+		fields.unique("toDictionary")
+		fields.unique("toSelfDescribing")
+		names := []string{}
+		types := []string{}
+		fmt.Fprintf(&sb, "public struct %s {\n", t.Name)
+		if s, ok := roots[t]; ok {
+			fmt.Fprintf(&sb, "    public static let schema = %s\n\n", quote(s.Uri, "swift"))
+		}
+		for _, f := range t.Fields {
+			name := fields.unique(camel(f.Key))
+			typ := swiftType(f.Type)
+			if f.Optional() {
+				typ += "?"
+			}
+			names = append(names, name)
+			types = append(types, typ)
+			swiftDoc(&sb, "    ", f.Description)
+			fmt.Fprintf(&sb, "    public var %s: %s\n", swiftName(name), typ)
+		}
+
+		params := []string{}
+		for i, f := range t.Fields {
+			param := fmt.Sprintf("%s: %s", swiftName(names[i]), types[i])
+			if !f.Required {
+				param += " = nil"
+			}
+			params = append(params, param)
+		}
+		fmt.Fprintf(&sb, "\n    public init(%s) {\n", strings.Join(params, ", "))
+		for _, n := range names {
+			fmt.Fprintf(&sb, "        self.%s = %s\n", swiftName(n), swiftName(n))
+		}
+		sb.WriteString("    }\n")
+
+		sb.WriteString("\n    public func toDictionary() -> [String: Any] {\n")
+		sb.WriteString("        var data: [String: Any] = [:]\n")
+		for i, f := range t.Fields {
+			key := quote(f.Key, "swift")
+			switch {
+			case !f.Optional():
+				fmt.Fprintf(&sb, "        data[%s] = %s\n", key, swiftValue(f.Type, "self."+swiftName(names[i])))
+			case !f.Required:
+				fmt.Fprintf(&sb, "        if let value = self.%s {\n            data[%s] = %s\n        }\n", swiftName(names[i]), key, swiftValue(f.Type, "value"))
+			default:
+				fmt.Fprintf(&sb, "        if let value = self.%s {\n            data[%s] = %s\n        } else {\n            data[%s] = NSNull()\n        }\n",
+					swiftName(names[i]), key, swiftValue(f.Type, "value"), key)
+			}
+		}
+		sb.WriteString("        return data\n    }\n")
+		if _, ok := roots[t]; ok {
+			swiftSchemaMembers(&sb)
+		}
+		sb.WriteString("}\n")
+	}
+
+	for _, s := range m.Schemas {
+		if s.Type != nil {
+			continue
+		}
+		sb.WriteString("\n")
+		swiftDoc(&sb, "", untyped(s))
+		fmt.Fprintf(&sb, "public struct %s {\n", s.Name)
+		fmt.Fprintf(&sb, "    public static let schema = %s\n\n", quote(s.Uri, "swift"))
+		sb.WriteString("    public var data: [String: Any]\n\n")
+		sb.WriteString("    public init(data: [String: Any]) {\n        self.data = data\n    }\n\n")
+		sb.WriteString("    public func toDictionary() -> [String: Any] {\n        data\n    }\n")
+		swiftSchemaMembers(&sb)
+		sb.WriteString("}\n")
+	}
+
+	for _, spec := range m.Specs {
+		sb.WriteString("\n")
+		swiftDoc(&sb, "", fmt.Sprintf("%s, an event specification of %s", spec.Name, spec.DataProduct))
+		params := []string{"event: " + spec.Event.Name}
+		for _, e := range spec.Entities {
+			param := swiftName(camel(e.Param)) + ": "
+			switch {
+			case !e.Single() && e.optional():
+				param += "[" + e.Schema.Name + "] = []"
+			case !e.Single():
+				param += "[" + e.Schema.Name + "]"
+			case e.optional():
+				param += e.Schema.Name + "? = nil"
+			default:
+				param += e.Schema.Name
+			}
+			params = append(params, param)
+		}
+		fmt.Fprintf(&sb, "public func %s(%s) throws -> SelfDescribingEvent {\n", camel(spec.FuncName), strings.Join(params, ", "))
+		for _, e := range spec.Entities {
+			if !e.checked() {
+				continue
+			}
+			param := swiftName(camel(e.Param))
+			conditions := []string{}
+			if e.Min > 0 {
+				conditions = append(conditions, fmt.Sprintf("%s.count < %d", param, e.Min))
+			}
+			if e.Max > 0 {
+				conditions = append(conditions, fmt.Sprintf("%s.count > %d", param, e.Max))
+			}
+			message := fmt.Sprintf("%s expects %s %s entities, got ", spec.Name, cardinality(e), e.Schema.Name)
+			fmt.Fprintf(&sb, "    if %s {\n        throw CardinalityError(description: %s + String(%s.count))\n    }\n",
+				strings.Join(conditions, " || "), quote(message, "swift"), param)
+		}
+		sb.WriteString("    var context: [SelfDescribingJson] = []\n")
+		for _, e := range spec.Entities {
+			param := swiftName(camel(e.Param))
+			switch {
+			case !e.Single():
+				fmt.Fprintf(&sb, "    context.append(contentsOf: %s.map { $0.toSelfDescribing() })\n", param)
+			case e.optional():
+				fmt.Fprintf(&sb, "    if let entity = %s {\n        context.append(entity.toSelfDescribing())\n    }\n", param)
+			default:
+				fmt.Fprintf(&sb, "    context.append(%s.toSelfDescribing())\n", param)
+			}
+		}
+		sb.WriteString("    return SelfDescribingEvent(event: event.toSelfDescribing(), context: context)\n}\n")
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,184 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package codegen
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var tsReserved = []string{
+	"break", "case", "catch", "class", "const", "continue", "debugger", "default", "delete", "do",
+	"else", "enum", "export", "extends", "false", "finally", "for", "function", "if", "import",
+	"in", "instanceof", "new", "null", "return", "super", "switch", "this", "throw", "true", "try",
+	"typeof", "var", "void", "while", "with", "implements", "interface", "let", "package",
+	"private", "protected", "public", "static", "yield", "await",
+}
+
+// names the generated functions use for their parameters and locals
+var tsLocals = []string{"event", "entities", "context", "data", "e"}
+
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+func tsFunc(name string) string {
+	f := camel(name)
+	if slices.Contains(tsReserved, f) || slices.Contains(tsLocals, f) {
+		return f + "Json"
+	}
+	return f
+}
+
+func tsKey(key string) string {
+	if tsIdentifier.MatchString(key) {
+		return key
+	}
+	return quote(key, "typescript")
+}
+
+func tsType(t *Type) string {
+	switch t.Kind {
+	case KindString:
+		return "string"
+	case KindInteger, KindNumber:
+		return "number"
+	case KindBoolean:
+		return "boolean"
+	case KindEnum, KindObject:
+		return t.Name
+	case KindArray:
+		return tsType(t.Items) + "[]"
+	case KindMap:
+		return "Record<string, unknown>"
+	}
+	return "unknown"
+}
+
+func tsDoc(sb *strings.Builder, indent string, text string) {
+	if text != "" {
+		fmt.Fprintf(sb, "%s/** %s */\n", indent, comment(text))
+	}
+}
+
+func typescript(m *Model) string {
+	var sb strings.Builder
+	sb.WriteString(generatedHeader)
+	sb.WriteString(`
+export type SelfDescribingJson<T = Record<string, unknown>> = {
+  schema: string;
+  data: T;
+};
+
+export type SelfDescribingEvent = {
+  event: SelfDescribingJson;
+  context: SelfDescribingJson[];
+};
+`)
+
+	for _, t := range m.Types {
+		sb.WriteString("\n")
+		tsDoc(&sb, "", t.Description)
+		if t.Kind == KindEnum {
+			values := []string{}
+			for _, v := range t.Values {
+				values = append(values, quote(v, "typescript"))
+			}
+			fmt.Fprintf(&sb, "export type %s = %s;\n", t.Name, strings.Join(values, " | "))
+			continue
+		}
+		fmt.Fprintf(&sb, "export type %s = {\n", t.Name)
+		for _, f := range t.Fields {
+			tsDoc(&sb, "  ", f.Description)
+			optional := ""
+			if !f.Required {
+				optional = "?"
+			}
+			typ := tsType(f.Type)
+			if f.Type.Nullable {
+				typ += " | null"
+			}
+			fmt.Fprintf(&sb, "  %s%s: %s;\n", tsKey(f.Key), optional, typ)
+		}
+		sb.WriteString("};\n")
+	}
+
+	for _, s := range m.Schemas {
+		sb.WriteString("\n")
+		if s.Type == nil {
+			tsDoc(&sb, "", untyped(s))
+			fmt.Fprintf(&sb, "export type %s = Record<string, unknown>;\n\n", s.Name)
+		}
+		constant := upperSnake(s.Name) + "_SCHEMA"
+		fmt.Fprintf(&sb, "export const %s = %s;\n\n", constant, quote(s.Uri, "typescript"))
+		fmt.Fprintf(&sb, "export function %s(data: %s): SelfDescribingJson<%s> {\n", tsFunc(s.Name), s.Name, s.Name)
+		fmt.Fprintf(&sb, "  return { schema: %s, data };\n", constant)
+		sb.WriteString("}\n")
+	}
+
+	for _, spec := range m.Specs {
+		sb.WriteString("\n")
+		params := fmt.Sprintf("event: %s", spec.Event.Name)
+		if len(spec.Entities) > 0 {
+			entities := spec.FuncName + "Entities"
+			fmt.Fprintf(&sb, "export type %s = {\n", entities)
+			for _, e := range spec.Entities {
+				optional := ""
+				if e.optional() {
+					optional = "?"
+				}
+				typ := e.Schema.Name
+				if !e.Single() {
+					typ += "[]"
+				}
+				fmt.Fprintf(&sb, "  %s%s: %s;\n", camel(e.Param), optional, typ)
+			}
+			sb.WriteString("};\n\n")
+			params += ", entities: " + entities
+		}
+
+		tsDoc(&sb, "", fmt.Sprintf("%s, an event specification of %s", spec.Name, spec.DataProduct))
+		fmt.Fprintf(&sb, "export function %s(%s): SelfDescribingEvent {\n", tsFunc(spec.FuncName), params)
+		sb.WriteString("  const context: SelfDescribingJson[] = [];\n")
+		for _, e := range spec.Entities {
+			value := "entities." + camel(e.Param)
+			helper := tsFunc(e.Schema.Name)
+			if e.Single() {
+				if e.optional() {
+					fmt.Fprintf(&sb, "  if (%s !== undefined) {\n    context.push(%s(%s));\n  }\n", value, helper, value)
+				} else {
+					fmt.Fprintf(&sb, "  context.push(%s(%s));\n", helper, value)
+				}
+				continue
+			}
+			if e.optional() {
+				value = fmt.Sprintf("(%s ?? [])", value)
+			}
+			if e.checked() {
+				conditions := []string{}
+				if e.Min > 0 {
+					conditions = append(conditions, fmt.Sprintf("%s.length < %d", value, e.Min))
+				}
+				if e.Max > 0 {
+					conditions = append(conditions, fmt.Sprintf("%s.length > %d", value, e.Max))
+				}
+				message := fmt.Sprintf("%s expects %s %s entities, got ", spec.Name, cardinality(e), e.Schema.Name)
+				fmt.Fprintf(&sb, "  if (%s) {\n    throw new Error(%s + %s.length);\n  }\n",
+					strings.Join(conditions, " || "), quote(message, "typescript"), value)
+			}
+			fmt.Fprintf(&sb, "  context.push(...%s.map((e) => %s(e)));\n", value, helper)
+		}
+		fmt.Fprintf(&sb, "  return { event: %s(event), context };\n", tsFunc(spec.Event.Name))
+		sb.WriteString("}\n")
+	}
+
+	return sb.String()
+}
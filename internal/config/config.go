@@ -11,31 +11,90 @@ OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
 package config
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"gopkg.in/yaml.v3"
 )
 
+// LocalOnly marks commands which never talk to console, they run without console config
+const LocalOnly = "snowplow-cli/local-only"
+
+// IsLocalOnly reports whether cmd is annotated with LocalOnly
+func IsLocalOnly(cmd *cobra.Command) bool {
+	_, ok := cmd.Annotations[LocalOnly]
+	return ok
+}
+
 func InitConsoleFlags(cmd *cobra.Command) {
 	cmd.PersistentFlags().StringP("api-key-id", "a", "", "BDP console api key id")
 	cmd.PersistentFlags().StringP("api-key", "S", "", "BDP console api key")
 	cmd.PersistentFlags().StringP("host", "H", "https://console.snowplowanalytics.com", "BDP console host")
 	cmd.PersistentFlags().StringP("org-id", "o", "", "Your organization id")
 	cmd.PersistentFlags().StringP("managed-from", "m", "", "Link to a github repo where the data structure is managed")
+	cmd.PersistentFlags().String("record", "", "Write every console request and response to this directory, with credentials scrubbed")
+	cmd.PersistentFlags().String("replay", "", "Answer console requests from a directory written by --record instead of the network")
+	cmd.PersistentFlags().Bool("no-cache", false, "Always fetch from console instead of using the local cache")
+	cmd.PersistentFlags().Duration("cache-ttl", 5*time.Minute, "How long validation uses cached console responses before asking console whether they changed")
+}
+
+// Resolver is an iglu resolver as written in the config file
+type Resolver struct {
+	Type   string `yaml:"type"`
+	Uri    string `yaml:"uri,omitempty"`
+	ApiKey string `yaml:"api-key,omitempty"`
+	Path   string `yaml:"path,omitempty"`
+}
+
+// Policy is a custom validation policy as written in the config file
+type Policy struct {
+	Id         string   `yaml:"id"`
+	Resources  []string `yaml:"resources,omitempty"`
+	Expression string   `yaml:"expression"`
+	Message    string   `yaml:"message,omitempty"`
+	Severity   string   `yaml:"severity,omitempty"`
 }
 
-type rawAppConfig struct {
+// Config is the config file, read once per command and kept in its context
+type Config struct {
 	Console map[string]string
+	Iglu    struct {
+		Resolvers []Resolver
+	}
+	Lint struct {
+		Rules   map[string]string
+		Vendors []string
+	}
+	Policies []Policy
 }
 
-func InitConsoleConfig(cmd *cobra.Command) error {
+type configKey struct{}
+
+// NewContext returns a copy of cnx carrying config
+func NewContext(cnx context.Context, config *Config) context.Context {
+	return context.WithValue(cnx, configKey{}, config)
+}
 
+// FromContext is the config kept in cnx by NewContext, an empty config when there is none
+func FromContext(cnx context.Context) *Config {
+	if cnx != nil {
+		if config, ok := cnx.Value(configKey{}).(*Config); ok {
+			return config
+		}
+	}
+	return &Config{}
+}
+
+// Read reads the first config file found, no file is an empty config
+func Read(cmd *cobra.Command) (*Config, error) {
+	var config Config
 	var configBytes []byte
 	var err error
 	var potentialConfigs []string
@@ -46,12 +105,12 @@ func InitConsoleConfig(cmd *cobra.Command) error {
 
 	home, err := os.UserHomeDir()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	userConfigDir, err := os.UserConfigDir()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	configDir := filepath.Join(userConfigDir, "snowplow", "snowplow.yml")
@@ -73,12 +132,25 @@ func InitConsoleConfig(cmd *cobra.Command) error {
 		}
 	}
 
-	var config rawAppConfig
-	err = yaml.Unmarshal(configBytes, &config)
-	if err != nil {
-		return err
+	if err := yaml.Unmarshal(configBytes, &config); err != nil {
+		return nil, err
 	}
+	return &config, nil
+}
 
+// LintRules are the severities lint rules are overridden with in the lint section
+func (config *Config) LintRules() map[string]string {
+	rules := map[string]string{}
+	for id, severity := range config.Lint.Rules {
+		rules[id] = strings.ToLower(severity)
+	}
+	return rules
+}
+
+// InitConsoleConfig sets the console flags not given on the command line from config, then from
+// SNOWPLOW_CONSOLE_* environment variables, and checks the ones needed are set
+func InitConsoleConfig(cmd *cobra.Command, config *Config) error {
+	var err error
 	cmd.Flags().VisitAll(func(f *pflag.Flag) {
 		if value, ok := config.Console[f.Name]; ok && !f.Changed && err == nil {
 			err = cmd.Flags().Set(f.Name, value)
@@ -95,7 +167,16 @@ func InitConsoleConfig(cmd *cobra.Command) error {
 		}
 	})
 
-	for _, f := range []string{"api-key-id", "api-key", "host", "org-id"} {
+	required := []string{"api-key-id", "api-key", "host", "org-id"}
+	if replay, _ := cmd.Flags().GetString("replay"); replay != "" {
+		// recordings carry the organization and have no credentials
+		required = []string{"host"}
+	}
+	if offline, _ := cmd.Flags().GetBool("offline"); offline {
+		required = []string{}
+	}
+
+	for _, f := range required {
 		value, err := cmd.Flags().GetString(f)
 		if err != nil {
 			return err
@@ -12,6 +12,8 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/spf13/cobra"
@@ -20,11 +22,13 @@ import (
 func build() *cobra.Command {
 	var testCmd = &cobra.Command{
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			if err := InitConsoleConfig(cmd); err != nil {
+			config, err := Read(cmd)
+			if err != nil {
 				return err
 			}
+			cmd.SetContext(NewContext(cmd.Context(), config))
 
-			return nil
+			return InitConsoleConfig(cmd, config)
 		},
 		Run: func(cmd *cobra.Command, args []string) {},
 	}
@@ -117,3 +121,145 @@ func Test_ConfigValidate(t *testing.T) {
 		}
 	}
 }
+
+func Test_ConfigReplayNeedsNoCredentials(t *testing.T) {
+	defer func(old []string) { os.Args = old }(os.Args)
+
+	os.Args = []string{"xxx", "--replay", "somewhere", "-a", "", "-S", "", "-o", ""}
+
+	testCmd := build()
+
+	err := testCmd.Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_ConfigIgluResolvers(t *testing.T) {
+	defer func(old []string) { os.Args = old }(os.Args)
+
+	file := filepath.Join(t.TempDir(), "snowplow.yml")
+	content := `console:
+  host: totally a url
+  api-key-id: id
+  api-key: key
+  org-id: org
+iglu:
+  resolvers:
+    - type: local
+      path: ./iglu-central
+    - type: iglu-server
+      uri: https://iglu.acme.com/api
+      api-key: ${IGLU_API_KEY}
+    - type: console
+`
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Args = []string{"xxx", "--config", file}
+
+	testCmd := build()
+	if err := testCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	resolvers := FromContext(testCmd.Context()).Iglu.Resolvers
+	expected := []Resolver{
+		{Type: "local", Path: "./iglu-central"},
+		{Type: "iglu-server", Uri: "https://iglu.acme.com/api", ApiKey: "${IGLU_API_KEY}"},
+		{Type: "console"},
+	}
+	if !reflect.DeepEqual(resolvers, expected) {
+		t.Fatalf("got %+v want %+v", resolvers, expected)
+	}
+}
+
+func Test_ConfigOfflineNeedsNoCredentials(t *testing.T) {
+	defer func(old []string) { os.Args = old }(os.Args)
+
+	os.Args = []string{"xxx", "--offline", "-a", "", "-S", "", "-o", ""}
+
+	testCmd := build()
+	testCmd.Flags().Bool("offline", false, "")
+
+	if err := testCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_ConfigLintRules(t *testing.T) {
+	defer func(old []string) { os.Args = old }(os.Args)
+
+	file := filepath.Join(t.TempDir(), "snowplow.yml")
+	content := `console:
+  host: totally a url
+  api-key-id: id
+  api-key: key
+  org-id: org
+lint:
+  rules:
+    data-product-metadata: off
+    trigger-description: Error
+  vendors:
+    - com.acme
+    - com.acme.*
+`
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Args = []string{"xxx", "--config", file}
+
+	testCmd := build()
+	if err := testCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	rules := FromContext(testCmd.Context()).LintRules()
+	expected := map[string]string{"data-product-metadata": "off", "trigger-description": "error"}
+	if !reflect.DeepEqual(rules, expected) {
+		t.Fatalf("got %+v want %+v", rules, expected)
+	}
+
+	if vendors := FromContext(testCmd.Context()).Lint.Vendors; !reflect.DeepEqual(vendors, []string{"com.acme", "com.acme.*"}) {
+		t.Fatalf("unexpected vendors got %v", vendors)
+	}
+}
+
+func Test_ConfigPolicies(t *testing.T) {
+	defer func(old []string) { os.Args = old }(os.Args)
+
+	file := filepath.Join(t.TempDir(), "snowplow.yml")
+	content := `console:
+  host: totally a url
+  api-key-id: id
+  api-key: key
+  org-id: org
+policies:
+  - id: team-owner
+    resources: [data-product]
+    expression: resource.data.owner.endsWith("@acme.com")
+    message: "{{ .data.name }} is not owned by a team"
+    severity: warning
+`
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Args = []string{"xxx", "--config", file}
+
+	testCmd := build()
+	if err := testCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	policies := FromContext(testCmd.Context()).Policies
+	expected := []Policy{{
+		Id:         "team-owner",
+		Resources:  []string{"data-product"},
+		Expression: `resource.data.owner.endsWith("@acme.com")`,
+		Message:    "{{ .data.name }} is not owned by a team",
+		Severity:   "warning",
+	}}
+	if !reflect.DeepEqual(policies, expected) {
+		t.Fatalf("got %+v want %+v", policies, expected)
+	}
+}
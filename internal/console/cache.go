@@ -0,0 +1,260 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package console
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// read only requests which are not GETs, anything else sent to Console invalidates the cache
+var readOnlyPosts = []string{"/validation-requests", "/compatibility", "/schema-migrations"}
+
+type cacheEntry struct {
+	Url      string           `json:"url"`
+	StoredAt time.Time        `json:"storedAt"`
+	Response RecordedResponse `json:"response"`
+}
+
+// DefaultCacheDir is snowplow-cli in the user cache directory, $XDG_CACHE_HOME/snowplow-cli on linux
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "snowplow-cli"), nil
+}
+
+type cachingRoundTripper struct {
+	Transport http.RoundTripper
+	Dir       string
+	TTL       time.Duration
+	now       func() time.Time
+}
+
+// NewCachingTransport stores JSON responses to GET requests in dir. They are served as is for ttl,
+// then revalidated with their ETag or Last-Modified. Requests changing Console clear the cache
+func NewCachingTransport(transport http.RoundTripper, dir string, ttl time.Duration) http.RoundTripper {
+	return &cachingRoundTripper{Transport: transport, Dir: dir, TTL: ttl, now: time.Now}
+}
+
+func (t *cachingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || !cacheable(req.URL) {
+		resp, err := t.Transport.RoundTrip(req)
+		if err == nil && mutates(req) && resp.StatusCode < 300 {
+			slog.Debug("clearing console cache", "method", req.Method, "url", req.URL.String())
+			if err := ClearCache(t.Dir); err != nil {
+				slog.Warn("could not clear console cache", "dir", t.Dir, "error", err)
+			}
+		}
+		return resp, err
+	}
+
+	file := t.file(req.URL)
+	entry, cached := readCacheEntry(file)
+	if cached && t.now().Sub(entry.StoredAt) < t.TTL {
+		slog.Debug("cache hit", "url", entry.Url)
+		return entry.response(req), nil
+	}
+
+	outgoing := req
+	if cached {
+		outgoing = req.Clone(req.Context())
+		if etag := entry.Response.Header.Get("ETag"); etag != "" && outgoing.Header.Get("If-None-Match") == "" {
+			outgoing.Header.Set("If-None-Match", etag)
+		}
+		if modified := entry.Response.Header.Get("Last-Modified"); modified != "" && outgoing.Header.Get("If-Modified-Since") == "" {
+			outgoing.Header.Set("If-Modified-Since", modified)
+		}
+	}
+
+	resp, err := t.Transport.RoundTrip(outgoing)
+	if err != nil {
+		return resp, err
+	}
+
+	if cached && resp.StatusCode == http.StatusNotModified {
+		_, _ = readBody(resp.Body)
+		slog.Debug("cache revalidated", "url", entry.Url)
+		entry.StoredAt = t.now()
+		t.write(file, entry)
+		return entry.response(req), nil
+	}
+
+	if resp.StatusCode != http.StatusOK || !isJson(resp.Header) {
+		return resp, nil
+	}
+
+	body, err := readBody(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	t.write(file, cacheEntry{
+		Url:      req.URL.String(),
+		StoredAt: t.now(),
+		Response: RecordedResponse{StatusCode: resp.StatusCode, Header: responseHeader(resp.Header), RecordedBody: newRecordedBody(body)},
+	})
+
+	return resp, nil
+}
+
+func (t *cachingRoundTripper) file(u *url.URL) string {
+	return filepath.Join(t.Dir, fmt.Sprintf("%x.json", sha256.Sum256([]byte(u.String()))))
+}
+
+// write stores entries through a temporary file so concurrent commands never read half of one
+func (t *cachingRoundTripper) write(file string, entry cacheEntry) {
+	err := func() error {
+		if err := os.MkdirAll(t.Dir, 0700); err != nil {
+			return err
+		}
+		out, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		tmp, err := os.CreateTemp(t.Dir, "entry-*.tmp")
+		if err != nil {
+			return err
+		}
+		if _, err := tmp.Write(out); err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return err
+		}
+		if err := tmp.Close(); err != nil {
+			os.Remove(tmp.Name())
+			return err
+		}
+		return os.Rename(tmp.Name(), file)
+	}()
+	if err != nil {
+		slog.Warn("could not write to console cache", "dir", t.Dir, "error", err)
+	}
+}
+
+func readCacheEntry(file string) (cacheEntry, bool) {
+	var entry cacheEntry
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return entry, false
+	}
+	if err := json.Unmarshal(content, &entry); err != nil {
+		slog.Debug("ignoring unreadable cache entry", "file", file, "error", err)
+		return entry, false
+	}
+	return entry, true
+}
+
+func (e cacheEntry) response(req *http.Request) *http.Response {
+	body := e.Response.bytes()
+	header := e.Response.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.Response.StatusCode, http.StatusText(e.Response.StatusCode)),
+		StatusCode:    e.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// cacheable leaves out token exchanges, access tokens are never written to disk
+func cacheable(u *url.URL) bool {
+	return !strings.Contains(u.Path, "/credentials/")
+}
+
+func mutates(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	case http.MethodPost:
+		for _, suffix := range readOnlyPosts {
+			if strings.HasSuffix(req.URL.Path, suffix) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isJson(h http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+// CacheInfo describes the responses stored in a cache directory
+type CacheInfo struct {
+	Dir     string
+	Entries int
+	Bytes   int64
+	Oldest  time.Time
+	Newest  time.Time
+}
+
+// ReadCacheInfo summarises dir, a missing directory is an empty cache
+func ReadCacheInfo(dir string) (CacheInfo, error) {
+	info := CacheInfo{Dir: dir}
+	files, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return info, nil
+	}
+	if err != nil {
+		return info, err
+	}
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".json" {
+			continue
+		}
+		fi, err := f.Info()
+		if err != nil {
+			return info, err
+		}
+		entry, ok := readCacheEntry(filepath.Join(dir, f.Name()))
+		if !ok {
+			continue
+		}
+		info.Entries++
+		info.Bytes += fi.Size()
+		if info.Oldest.IsZero() || entry.StoredAt.Before(info.Oldest) {
+			info.Oldest = entry.StoredAt
+		}
+		if entry.StoredAt.After(info.Newest) {
+			info.Newest = entry.StoredAt
+		}
+	}
+	return info, nil
+}
+
+// ClearCache removes every stored response
+func ClearCache(dir string) error {
+	return os.RemoveAll(dir)
+}
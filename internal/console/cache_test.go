@@ -0,0 +1,135 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package console
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_CachingTransport(t *testing.T) {
+	hits := map[string]int{}
+	revalidated := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits[r.Method+" "+r.URL.Path]++
+		switch r.URL.Path {
+		case "/credentials/v3/token":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{"accessToken":"secret-token"}`)
+		case "/listing":
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				revalidated++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Header().Set("ETag", `"v1"`)
+			_, _ = io.WriteString(w, `["a"]`)
+		case "/publish", "/validation-requests":
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer server.Close()
+
+	dir := filepath.Join(t.TempDir(), "cache")
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	transport := &cachingRoundTripper{Transport: http.DefaultTransport, Dir: dir, TTL: time.Minute, now: func() time.Time { return now }}
+	client := &http.Client{Transport: transport}
+
+	get := func(path string) string {
+		t.Helper()
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s got status %d", path, resp.StatusCode)
+		}
+		return string(body)
+	}
+	post := func(path string) {
+		t.Helper()
+		resp, err := client.Post(server.URL+path, "application/json", strings.NewReader("{}"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	for i := 0; i < 3; i++ {
+		if body := get("/listing"); body != `["a"]` {
+			t.Fatalf("unexpected body %s", body)
+		}
+	}
+	if hits["GET /listing"] != 1 {
+		t.Fatalf("expected fresh entries to be served from the cache, got %d requests", hits["GET /listing"])
+	}
+
+	now = now.Add(2 * time.Minute)
+	if body := get("/listing"); body != `["a"]` || revalidated != 1 {
+		t.Fatalf("expected a stale entry to be revalidated got %s after %d", body, revalidated)
+	}
+	get("/listing")
+	if hits["GET /listing"] != 2 {
+		t.Fatalf("expected a revalidated entry to be fresh again, got %d requests", hits["GET /listing"])
+	}
+
+	get("/credentials/v3/token")
+	get("/credentials/v3/token")
+	if hits["GET /credentials/v3/token"] != 2 {
+		t.Fatal("expected tokens not to be cached")
+	}
+	files, _ := os.ReadDir(dir)
+	for _, f := range files {
+		content, _ := os.ReadFile(filepath.Join(dir, f.Name()))
+		if strings.Contains(string(content), "secret-token") {
+			t.Fatalf("%s contains a token", f.Name())
+		}
+	}
+
+	post("/validation-requests")
+	get("/listing")
+	if hits["GET /listing"] != 2 {
+		t.Fatal("expected read only requests to keep the cache")
+	}
+
+	post("/publish")
+	get("/listing")
+	if hits["GET /listing"] != 3 {
+		t.Fatal("expected publishing to clear the cache")
+	}
+
+	info, err := ReadCacheInfo(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Entries != 1 || info.Bytes == 0 || !info.Oldest.Equal(now) {
+		t.Fatalf("unexpected info %+v", info)
+	}
+}
+
+func Test_ReadCacheInfoMissingDir(t *testing.T) {
+	info, err := ReadCacheInfo(filepath.Join(t.TempDir(), "nothing"))
+	if err != nil || info.Entries != 0 {
+		t.Fatalf("expected an empty cache got %+v %v", info, err)
+	}
+}
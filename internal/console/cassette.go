@@ -0,0 +1,315 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package console
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const redacted = "REDACTED"
+
+// headers never written to a cassette
+var scrubbedHeaders = []string{"Authorization", "X-Api-Key", "X-Api-Key-Id", "Apikey", "Cookie", "Set-Cookie"}
+
+// Interaction is a single recorded request and the response it received
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	Url    string      `json:"url"`
+	Header http.Header `json:"header"`
+	RecordedBody
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	RecordedBody
+}
+
+// RecordedBody keeps text bodies readable, anything else is base64 encoded
+type RecordedBody struct {
+	Body       string `json:"body,omitempty"`
+	BodyBase64 []byte `json:"bodyBase64,omitempty"`
+}
+
+func newRecordedBody(b []byte) RecordedBody {
+	if utf8.Valid(b) {
+		return RecordedBody{Body: string(b)}
+	}
+	return RecordedBody{BodyBase64: b}
+}
+
+func (b RecordedBody) bytes() []byte {
+	if b.BodyBase64 != nil {
+		return b.BodyBase64
+	}
+	return []byte(b.Body)
+}
+
+func scrubHeader(h http.Header) http.Header {
+	res := h.Clone()
+	for _, name := range scrubbedHeaders {
+		if res.Get(name) != "" {
+			res.Set(name, redacted)
+		}
+	}
+	return res
+}
+
+// responseHeader drops the length, the recorded body may differ once scrubbed
+func responseHeader(h http.Header) http.Header {
+	res := scrubHeader(h)
+	res.Del("Content-Length")
+	return res
+}
+
+// scrubBody removes access tokens from token exchange responses
+func scrubBody(u *url.URL, body []byte) []byte {
+	if !strings.HasSuffix(u.Path, "/credentials/v3/token") {
+		return body
+	}
+	var token map[string]any
+	if err := json.Unmarshal(body, &token); err != nil {
+		return body
+	}
+	for k := range token {
+		if strings.EqualFold(k, "accessToken") {
+			token[k] = redacted
+		}
+	}
+	scrubbed, err := json.Marshal(token)
+	if err != nil {
+		return body
+	}
+	return scrubbed
+}
+
+func readBody(body io.ReadCloser) ([]byte, error) {
+	if body == nil || body == http.NoBody {
+		return []byte{}, nil
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+type recordingRoundTripper struct {
+	Transport http.RoundTripper
+	Dir       string
+
+	mu sync.Mutex
+	n  int
+}
+
+// NewRecordingTransport writes every request made through transport and its response to dir
+func NewRecordingTransport(transport http.RoundTripper, dir string) (http.RoundTripper, error) {
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("cassette directory %s is not empty", dir)
+	}
+	return &recordingRoundTripper{Transport: transport, Dir: dir}, nil
+}
+
+func (t *recordingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(reqBody))
+
+	resp, err := t.Transport.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	respBody, err := readBody(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method:       req.Method,
+			Url:          req.URL.String(),
+			Header:       scrubHeader(req.Header),
+			RecordedBody: newRecordedBody(reqBody),
+		},
+		Response: RecordedResponse{
+			StatusCode:   resp.StatusCode,
+			Header:       responseHeader(resp.Header),
+			RecordedBody: newRecordedBody(scrubBody(req.URL, respBody)),
+		},
+	}
+
+	err = t.write(interaction)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (t *recordingRoundTripper) write(interaction Interaction) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.n++
+	out, err := json.MarshalIndent(interaction, "", "  ")
+	if err != nil {
+		return err
+	}
+	file := filepath.Join(t.Dir, fmt.Sprintf("%04d.json", t.n))
+	slog.Debug("recorded", "method", interaction.Request.Method, "url", interaction.Request.Url, "file", file)
+	return os.WriteFile(file, out, 0644)
+}
+
+// LoadCassette reads interactions written by a recording transport in the order they were made
+func LoadCassette(dir string) ([]Interaction, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no recorded interactions found in %s", dir)
+	}
+	sort.Strings(files)
+
+	interactions := []Interaction{}
+	for _, f := range files {
+		content, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		var i Interaction
+		err = json.Unmarshal(content, &i)
+		if err != nil {
+			return nil, fmt.Errorf("file: %s: %w", f, err)
+		}
+		interactions = append(interactions, i)
+	}
+
+	return interactions, nil
+}
+
+var orgInPath = regexp.MustCompile(`/organizations/([^/]+)/`)
+
+// CassetteOrgId is the organization a cassette was recorded against
+func CassetteOrgId(interactions []Interaction) string {
+	for _, i := range interactions {
+		u, err := url.Parse(i.Request.Url)
+		if err != nil {
+			continue
+		}
+		if m := orgInPath.FindStringSubmatch(u.Path); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+type replayRoundTripper struct {
+	interactions []Interaction
+
+	mu   sync.Mutex
+	used []bool
+}
+
+// NewReplayTransport answers requests from recorded interactions without touching the network.
+// Requests are matched on method, path, query and body, ignoring the host. When no body
+// matches, the first unused interaction for the same method and url is served
+func NewReplayTransport(interactions []Interaction) http.RoundTripper {
+	return &replayRoundTripper{interactions: interactions, used: make([]bool, len(interactions))}
+}
+
+func requestKey(method string, u *url.URL) string {
+	return method + " " + u.RequestURI()
+}
+
+func (t *replayRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	key := requestKey(req.Method, req.URL)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	fallback := -1
+	match := -1
+	for idx, i := range t.interactions {
+		if t.used[idx] {
+			continue
+		}
+		u, err := url.Parse(i.Request.Url)
+		if err != nil || requestKey(i.Request.Method, u) != key {
+			continue
+		}
+		if bytes.Equal(i.Request.bytes(), reqBody) {
+			match = idx
+			break
+		}
+		if fallback == -1 {
+			fallback = idx
+		}
+	}
+	if match == -1 {
+		match = fallback
+	}
+	if match == -1 {
+		return nil, errors.New("no recorded response for " + key)
+	}
+	t.used[match] = true
+
+	recorded := t.interactions[match].Response
+	body := recorded.bytes()
+	header := recorded.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
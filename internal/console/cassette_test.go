@@ -0,0 +1,156 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package console
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/pkg/console/consoletest"
+)
+
+func Test_Cassette_RecordThenReplay(t *testing.T) {
+	fake, server := consoletest.NewServer(t)
+	cnx := context.Background()
+	dir := filepath.Join(t.TempDir(), "cassette")
+
+	recorder, err := NewRecordingTransport(http.DefaultTransport, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewApiClientWithTransport(cnx, recorder, server.URL, fake.ApiKeyId, fake.ApiKey, fake.OrgId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded, err := GetDataProductsAndRelatedResources(cnx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range []string{"0001.json", "0002.json", "0003.json"} {
+		content, err := os.ReadFile(filepath.Join(dir, f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, secret := range []string{fake.ApiKey, fake.Token} {
+			if strings.Contains(string(content), secret) {
+				t.Errorf("%s contains a credential: %s", f, content)
+			}
+		}
+	}
+
+	interactions, err := LoadCassette(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(interactions) != 3 {
+		t.Fatalf("expected token, data products and source apps requests, got %d", len(interactions))
+	}
+	if org := CassetteOrgId(interactions); org != fake.OrgId {
+		t.Errorf("org id got %s want %s", org, fake.OrgId)
+	}
+
+	server.Close()
+
+	client, err = NewApiClientWithTransport(cnx, NewReplayTransport(interactions), "http://replay.invalid", "x", "x", fake.OrgId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := GetDataProductsAndRelatedResources(cnx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(recorded, replayed) {
+		t.Errorf("replay differs\n%+v\n%+v", recorded, replayed)
+	}
+
+	_, err = GetDataProductsAndRelatedResources(cnx, client)
+	if err == nil || !strings.Contains(err.Error(), "no recorded response for GET") {
+		t.Errorf("expected exhausted cassette to fail, got %v", err)
+	}
+}
+
+func Test_Cassette_RecordScrubsIgluApiKey(t *testing.T) {
+	igluServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`["iglu:com.acme/user/jsonschema/1-0-0"]`))
+	}))
+	defer igluServer.Close()
+	dir := filepath.Join(t.TempDir(), "cassette")
+
+	recorder, err := NewRecordingTransport(http.DefaultTransport, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetIgluListing(context.Background(), NewHttpClient(recorder), igluServer.URL+"/api", "iglu-secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	interactions, err := LoadCassette(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(interactions) != 1 || interactions[0].Request.Header.Get("apikey") != redacted {
+		t.Fatalf("expected the iglu api key to be redacted got %+v", interactions)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "0001.json"))
+	if err != nil || strings.Contains(string(content), "iglu-secret") {
+		t.Fatalf("expected no api key in the cassette got %s %v", content, err)
+	}
+}
+
+func Test_Cassette_RecordRefusesExisting(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "0001.json"), []byte("{}"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewRecordingTransport(http.DefaultTransport, dir)
+	if err == nil {
+		t.Error("expected an error recording over an existing cassette")
+	}
+}
+
+func Test_Cassette_ReplayMatchesBody(t *testing.T) {
+	interactions := []Interaction{
+		{
+			Request:  RecordedRequest{Method: "POST", Url: "https://example.com/a?x=1", RecordedBody: RecordedBody{Body: "first"}},
+			Response: RecordedResponse{StatusCode: 200, RecordedBody: RecordedBody{Body: "one"}},
+		},
+		{
+			Request:  RecordedRequest{Method: "POST", Url: "https://example.com/a?x=1", RecordedBody: RecordedBody{Body: "second"}},
+			Response: RecordedResponse{StatusCode: 201, RecordedBody: RecordedBody{BodyBase64: []byte{0xff, 0x00}}},
+		},
+	}
+	h := &http.Client{Transport: NewReplayTransport(interactions)}
+
+	resp, err := h.Post("http://elsewhere/a?x=1", "text/plain", strings.NewReader("second"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != 201 {
+		t.Errorf("expected the interaction with a matching body, got %d", resp.StatusCode)
+	}
+
+	resp, err = h.Post("http://elsewhere/a?x=1", "text/plain", strings.NewReader("changed"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != 200 {
+		t.Errorf("expected the remaining interaction, got %d", resp.StatusCode)
+	}
+}
@@ -12,15 +12,20 @@ package console
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"regexp"
 	"time"
 
+	"github.com/snowplow/snowplow-cli/internal/tracing"
 	"github.com/snowplow/snowplow-cli/internal/util"
+	sdk "github.com/snowplow/snowplow-cli/pkg/console"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 type ApiClient struct {
@@ -30,10 +35,6 @@ type ApiClient struct {
 	OrgId   string
 }
 
-type tokenResponse struct {
-	AccessToken string
-}
-
 type loggingRoundTripper struct {
 	Transport http.RoundTripper
 }
@@ -53,44 +54,96 @@ func (t *loggingRoundTripper) RoundTrip(req *http.Request) (*http.Response, erro
 	return resp, err
 }
 
-func NewApiClient(ctx context.Context, host string, apiKeyId string, apiKeySecret string, orgid string) (*ApiClient, error) {
+type tracingRoundTripper struct {
+	Transport http.RoundTripper
+}
 
-	h := &http.Client{
-		Transport: &loggingRoundTripper{
-			Transport: http.DefaultTransport,
-		},
-	}
+var orgPrefix = regexp.MustCompile(`^.*/organizations/[^/]+`)
 
-	baseUrl := fmt.Sprintf("%s/api/msc/v1/organizations/%s", host, orgid)
+var resourceInPath = regexp.MustCompile(`/organizations/([^/]+)/([a-z-]+)/v\d+(?:/([^/?]+))?`)
 
-	url := fmt.Sprintf("%s/credentials/v3/token", baseUrl)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, err
+func (t *tracingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	attrs := []attribute.KeyValue{
+		attribute.String("http.request.method", req.Method),
+		attribute.String("url.full", req.URL.Redacted()),
 	}
-	req.Header.Add("X-API-KEY-ID", apiKeyId)
-	req.Header.Add("X-API-KEY", apiKeySecret)
-	req.Header.Add("X-SNOWPLOW-CLI", util.VersionInfo)
-	resp, err := h.Do(req)
+	if m := resourceInPath.FindStringSubmatch(req.URL.Path); m != nil {
+		attrs = append(attrs, attribute.String("snowplow.org_id", m[1]), attribute.String("snowplow.resource.type", m[2]))
+		if m[3] != "" {
+			attrs = append(attrs, attribute.String("snowplow.resource.id", m[3]))
+		}
+	}
+
+	// the organization is an attribute, keep span names short
+	path := orgPrefix.ReplaceAllString(req.URL.Path, "")
+
+	cnx, span := tracing.Start(req.Context(), fmt.Sprintf("%s %s", req.Method, path), attrs...)
+	req = req.Clone(cnx)
+	otel.GetTextMapPropagator().Inject(cnx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := t.Transport.RoundTrip(req)
 	if err != nil {
-		return nil, err
+		tracing.End(span, err)
+		return resp, err
 	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, errors.New("bad token request")
+
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= 400 {
+		span.SetStatus(codes.Error, resp.Status)
 	}
-	body, err := io.ReadAll(resp.Body)
-	defer resp.Body.Close()
+	span.End()
+
+	return resp, nil
+}
+
+func NewApiClient(ctx context.Context, host string, apiKeyId string, apiKeySecret string, orgid string) (*ApiClient, error) {
+	return NewApiClientWithTransport(ctx, http.DefaultTransport, host, apiKeyId, apiKeySecret, orgid)
+}
+
+// NewApiClientWithTransport sends requests through transport, e.g. to record or replay them
+func NewApiClientWithTransport(ctx context.Context, transport http.RoundTripper, host string, apiKeyId string, apiKeySecret string, orgid string) (*ApiClient, error) {
+
+	h := NewHttpClient(transport)
+
+	c, err := sdk.New(
+		sdk.WithHost(host),
+		sdk.WithOrgId(orgid),
+		sdk.WithHTTPClient(h),
+		sdk.WithApiKey(apiKeyId, apiKeySecret),
+		sdk.WithClientInfo(util.VersionInfo),
+	)
 	if err != nil {
 		return nil, err
 	}
 
-	var token tokenResponse
-	err = json.Unmarshal(body, &token)
+	jwt, err := c.Token(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return &ApiClient{Http: h, Jwt: token.AccessToken, BaseUrl: baseUrl, OrgId: orgid}, nil
+	return &ApiClient{Http: h, Jwt: jwt, BaseUrl: c.BaseURL(), OrgId: orgid}, nil
+}
+
+// NewHttpClient logs and traces requests sent through transport, for requests made without console credentials
+func NewHttpClient(transport http.RoundTripper) *http.Client {
+	return &http.Client{
+		Transport: &loggingRoundTripper{
+			Transport: &tracingRoundTripper{
+				Transport: transport,
+			},
+		},
+	}
+}
+
+// sdk exposes the client through the public console package
+func (client *ApiClient) sdk() (*sdk.Client, error) {
+	return sdk.New(
+		sdk.WithBaseURL(client.BaseUrl),
+		sdk.WithOrgId(client.OrgId),
+		sdk.WithHTTPClient(client.Http),
+		sdk.WithTokenSource(sdk.StaticToken(client.Jwt)),
+		sdk.WithClientInfo(util.VersionInfo),
+	)
 }
 
 func ConsoleRequest(method string, path string, client *ApiClient, cnx context.Context, body io.Reader) (*http.Request, error) {
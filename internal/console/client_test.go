@@ -0,0 +1,38 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package console
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/util"
+	"github.com/snowplow/snowplow-cli/pkg/console/fakeconsole"
+)
+
+func Test_NewApiClientSendsClientInfo(t *testing.T) {
+	fake := fakeconsole.New()
+	headers := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Get("X-SNOWPLOW-CLI"))
+		fake.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	if _, err := NewApiClient(context.Background(), server.URL, fake.ApiKeyId, fake.ApiKey, fake.OrgId); err != nil {
+		t.Fatal(err)
+	}
+	if len(headers) != 1 || headers[0] != util.VersionInfo {
+		t.Fatalf("expected the token request to name the cli got %v", headers)
+	}
+}
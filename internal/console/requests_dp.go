@@ -18,102 +18,20 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-)
-
-type DataProductsAndRelatedResources struct {
-	DataProducts      []RemoteDataProduct
-	EventSpecs        []RemoteEventSpec
-	SourceApplication []RemoteSourceApplication
-}
-
-type RemoteDataProduct struct {
-	Id                   string               `json:"id"`
-	Name                 string               `json:"name"`
-	Status               string               `json:"status"`
-	SourceApplicationIds []string             `json:"sourceApplications"`
-	Domain               string               `json:"domain,omitempty"`
-	Owner                string               `json:"owner,omitempty"`
-	Description          string               `json:"description,omitempty"`
-	EventSpecs           []EventSpecReference `json:"eventSpecs"`
-	LockStatus           string               `json:"lockStatus,omitempty"`
-	ManagedFrom          string               `json:"managedFrom,omitempty"`
-}
-
-type EventSpecReference struct {
-	Id string `json:"id"`
-}
-
-type RemoteTrigger struct {
-	Id          string            `json:"id,omitempty"`
-	Description string            `json:"description"`
-	AppIds      []string          `json:"appIds,omitempty"`
-	Url         string            `json:"url,omitempty"`
-	VariantUrls map[string]string `json:"variantUrls,omitempty"`
-}
-
-type RemoteEventSpec struct {
-	Id                   string          `json:"id"`
-	SourceApplicationIds []string        `json:"sourceApplications"`
-	Name                 string          `json:"name"`
-	Description          string          `json:"description"`
-	Triggers             []RemoteTrigger `json:"triggers,omitempty"`
-	Status               string          `json:"status"`
-	Version              int             `json:"version"`
-	Event                *EventWrapper   `json:"event,omitempty"`
-	Entities             Entities        `json:"entities"`
-	DataProductId        string          `json:"dataProductId"`
-	LockStatus           string          `json:"lockStatus,omitempty"`
-	ManagedFrom          string          `json:"managedFrom,omitempty"`
-}
-
-type Event struct {
-	Source string         `json:"source,omitempty"`
-	Schema map[string]any `json:"schema,omitempty"`
-}
-
-type EventWrapper struct {
-	Event
-}
-
-func (ew EventWrapper) MarshalJSON() ([]byte, error) {
-	if ew.Source == "" && (len(ew.Schema) == 0) {
-		return []byte("null"), nil
-	} else {
-		return json.Marshal(ew.Event)
-	}
-}
-
-type dataProductsResponse struct {
-	Data     []RemoteDataProduct `json:"data"`
-	Includes includes            `json:"includes"`
-}
-
-type includes struct {
-	EventSpecs []RemoteEventSpec `json:"eventSpecs"`
-}
 
-type RemoteSourceApplication struct {
-	Id          string   `json:"id"`
-	Name        string   `json:"name"`
-	Description string   `json:"description,omitempty"`
-	Owner       string   `json:"owner,omitempty"`
-	AppIds      []string `json:"appIds"`
-	Entities    Entities `json:"entities"`
-	LockStatus  string   `json:"lockStatus,omitempty"`
-	ManagedFrom string   `json:"managedFrom,omitempty"`
-}
-
-type Entities struct {
-	Tracked  []Entity `json:"tracked"`
-	Enriched []Entity `json:"enriched"`
-}
+	sdk "github.com/snowplow/snowplow-cli/pkg/console"
+)
 
-type Entity struct {
-	Source         string         `json:"source"`
-	MinCardinality *int           `json:"minCardinality"`
-	MaxCardinality *int           `json:"maxCardinality"`
-	Schema         map[string]any `json:"schema,omitempty"`
-}
+type DataProductsAndRelatedResources = sdk.DataProductsAndRelatedResources
+type RemoteDataProduct = sdk.RemoteDataProduct
+type EventSpecReference = sdk.EventSpecReference
+type RemoteTrigger = sdk.RemoteTrigger
+type RemoteEventSpec = sdk.RemoteEventSpec
+type Event = sdk.Event
+type EventWrapper = sdk.EventWrapper
+type RemoteSourceApplication = sdk.RemoteSourceApplication
+type Entities = sdk.Entities
+type Entity = sdk.Entity
 
 type remoteEventSpecPost struct {
 	Spec    RemoteEventSpec `json:"spec"`
@@ -124,127 +42,34 @@ type esData struct {
 	Data []RemoteEventSpec `json:"data"`
 }
 
-type saData struct {
-	Data []RemoteSourceApplication `json:"data"`
-}
-
 func GetDataProductsAndRelatedResources(cnx context.Context, client *ApiClient) (*DataProductsAndRelatedResources, error) {
-
-	resp, err := DoConsoleRequest("GET", fmt.Sprintf("%s/data-products/v2", client.BaseUrl), client, cnx, nil)
+	c, err := client.sdk()
 	if err != nil {
 		return nil, err
 	}
-	rbody, err := io.ReadAll(resp.Body)
-	defer resp.Body.Close()
-	if err != nil {
-		return nil, err
-	}
-
-	var dpResponse dataProductsResponse
-	err = json.Unmarshal(rbody, &dpResponse)
-	if err != nil {
-		return nil, err
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("not expected response code %d", resp.StatusCode)
-	}
-
-	saResp, err := DoConsoleRequest("GET", fmt.Sprintf("%s/source-apps/v1", client.BaseUrl), client, cnx, nil)
-	if err != nil {
-		return nil, err
-	}
-	sarbody, err := io.ReadAll(saResp.Body)
-	defer resp.Body.Close()
-	if err != nil {
-		return nil, err
-	}
-
-	var saResponse saData
-	err = json.Unmarshal(sarbody, &saResponse)
-	if err != nil {
-		return nil, err
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("not expected response code %d", resp.StatusCode)
-	}
-
-	res := DataProductsAndRelatedResources{
-		dpResponse.Data,
-		dpResponse.Includes.EventSpecs,
-		saResponse.Data,
-	}
-	return &res, nil
+	return c.GetDataProductsAndRelatedResources(cnx)
 }
 
-type CompatStatus = string
+type CompatStatus = sdk.CompatStatus
 
 const (
-	CompatCompatible   CompatStatus = "compatible"
-	CompatUndecidable  CompatStatus = "undecidable"
-	CompatIncompatible CompatStatus = "incompatible"
+	CompatCompatible   = sdk.CompatCompatible
+	CompatUndecidable  = sdk.CompatUndecidable
+	CompatIncompatible = sdk.CompatIncompatible
 )
 
-type CompatSource struct {
-	Source     string
-	Status     CompatStatus
-	Properties map[string]string
-}
-
-type CompatResult struct {
-	Status  string
-	Sources []CompatSource
-	Message string
-}
-
-type CompatCheckable struct {
-	Source string         `json:"source"`
-	Schema map[string]any `json:"schema"`
-}
+type CompatSource = sdk.CompatSource
+type CompatResult = sdk.CompatResult
+type CompatCheckable = sdk.CompatCheckable
 
 type CompatChecker = func(event CompatCheckable, entities []CompatCheckable) (*CompatResult, error)
 
 func CompatCheck(cnx context.Context, client *ApiClient, event CompatCheckable, entities []CompatCheckable) (*CompatResult, error) {
-	realArgs := map[string]any{
-		"spec": map[string]any{
-			"event": event,
-			"entities": map[string]any{
-				"tracked": entities,
-			},
-			// this uuid does not reference any existing event spec, I made it up
-			"id":      "312d3987-4874-498d-af6c-162ce0da39d7",
-			"name":    "cli-compat-check",
-			"status":  "draft",
-			"version": "0",
-		},
-	}
-
-	body, err := json.Marshal(realArgs)
+	c, err := client.sdk()
 	if err != nil {
 		return nil, err
 	}
-	resp, err := DoConsoleRequest("POST", fmt.Sprintf("%s/event-specs/v1/compatibility", client.BaseUrl), client, cnx, bytes.NewBuffer(body))
-	if err != nil {
-		return nil, err
-	}
-	rbody, err := io.ReadAll(resp.Body)
-	defer resp.Body.Close()
-	if err != nil {
-		return nil, err
-	}
-
-	var cresp CompatResult
-	err = json.Unmarshal(rbody, &cresp)
-	if err != nil {
-		return nil, err
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("bad response got status: %d with message: %s", resp.StatusCode, cresp.Message)
-	}
-
-	return &cresp, nil
+	return c.CompatCheck(cnx, event, entities)
 }
 
 func CreateSourceApp(cnx context.Context, client *ApiClient, sa RemoteSourceApplication) error {
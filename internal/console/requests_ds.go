@@ -11,11 +11,8 @@ OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
 package console
 
 import (
-	"bytes"
 	"context"
-	"crypto/sha256"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -23,158 +20,76 @@ import (
 	"strings"
 
 	. "github.com/snowplow/snowplow-cli/internal/model"
-	"github.com/snowplow/snowplow-cli/internal/util"
+	sdk "github.com/snowplow/snowplow-cli/pkg/console"
 )
 
 type msgResponse struct {
 	Message string
 }
 
-type pubResponse struct {
-	Success  bool
-	Errors   []string
-	Warnings []string
-	Info     []string
-	msgResponse
-}
-
-type pubError struct {
-	Messages []string
-}
-
-type PublishResponse = pubResponse
+type PublishResponse = sdk.PublishResponse
 type ValidateResponse struct {
-	pubResponse
+	PublishResponse
 	Valid bool
 }
 
-type PublishError = pubError
-
-func (e *pubError) Error() string {
-	return strings.Join(e.Messages, "\n")
-}
+type PublishError = sdk.PublishError
 
-type DataStructureEnv string
+type DataStructureEnv = sdk.DataStructureEnv
 
 const (
-	DEV       DataStructureEnv = "DEV"
-	PROD      DataStructureEnv = "PROD"
-	VALIDATED DataStructureEnv = "VALIDATED"
+	DEV       = sdk.DEV
+	PROD      = sdk.PROD
+	VALIDATED = sdk.VALIDATED
 )
 
-type publishRequest struct {
-	Format  string           `json:"format"`
-	Message string           `json:"message"`
-	Name    string           `json:"name"`
-	Source  DataStructureEnv `json:"source"`
-	Target  DataStructureEnv `json:"target"`
-	Vendor  string           `json:"vendor"`
-	Version string           `json:"version"`
-}
+type Deployment = sdk.Deployment
 
-type fullMeta struct {
-	Hidden      *bool              `json:"hidden,omitempty"`
-	SchemaType  string             `json:"schemaType,omitempty"`
-	CustomData  *map[string]string `json:"customData,omitempty"`
-	LockStatus  string             `json:"lockStatus,omitempty"`
-	ManagedFrom string             `json:"managedFrom,omitempty"`
-}
+type ListResponse = sdk.ListResponse
 
 func Validate(cnx context.Context, client *ApiClient, ds DataStructure) (*ValidateResponse, error) {
-
-	body, err := json.Marshal(ds)
-	if err != nil {
-		return nil, err
-	}
-	req, err := http.NewRequestWithContext(cnx, "POST", fmt.Sprintf("%s/data-structures/v1/validation-requests", client.BaseUrl), bytes.NewBuffer(body))
-	auth := fmt.Sprintf("Bearer %s", client.Jwt)
-	req.Header.Add("authorization", auth)
-	req.Header.Add("X-SNOWPLOW-CLI", util.VersionInfo)
-
-	if err != nil {
-		return nil, err
-	}
-	resp, err := client.Http.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	rbody, err := io.ReadAll(resp.Body)
-	defer resp.Body.Close()
+	c, err := client.sdk()
 	if err != nil {
 		return nil, err
 	}
-
-	var vresp ValidateResponse
-	err = json.Unmarshal(rbody, &vresp)
+	resp, err := c.Validate(cnx, ds)
 	if err != nil {
 		return nil, err
 	}
-
-	if resp.StatusCode != http.StatusCreated {
-		return nil, errors.New(vresp.Message)
-	}
-
-	vresp.Valid = vresp.Success
-
-	return &vresp, nil
+	return &ValidateResponse{PublishResponse: *resp, Valid: resp.Success}, nil
 }
 
 func PublishDev(cnx context.Context, client *ApiClient, ds DataStructure, isPatch bool, managedFrom string) (*PublishResponse, error) {
-	// during first creation we have to publish first, otherwise metatdata patch fails with 404
-	res, err := publish(cnx, client, VALIDATED, DEV, ds, isPatch)
-	if err != nil {
-		return nil, err
-	}
-	err = metadataLock(cnx, client, &ds, managedFrom)
+	c, err := client.sdk()
 	if err != nil {
 		return nil, err
 	}
-	return res, nil
+	return c.PublishDev(cnx, ds, isPatch, managedFrom)
 }
 
 func PublishProd(cnx context.Context, client *ApiClient, ds DataStructure, managedFrom string) (*PublishResponse, error) {
-	err := metadataLock(cnx, client, &ds, managedFrom)
+	c, err := client.sdk()
 	if err != nil {
 		return nil, err
 	}
-	return publish(cnx, client, DEV, PROD, ds, false)
+	return c.PublishProd(cnx, ds, managedFrom)
 }
 
-func publish(cnx context.Context, client *ApiClient, from DataStructureEnv, to DataStructureEnv, ds DataStructure, isPatch bool) (*PublishResponse, error) {
-
-	dsData, err := ds.ParseData()
-	if err != nil {
-		return nil, err
-	}
-
-	pr := &publishRequest{
-		Message: "",
-		Source:  from,
-		Target:  to,
-		Vendor:  dsData.Self.Vendor,
-		Name:    dsData.Self.Name,
-		Format:  dsData.Self.Format,
-		Version: dsData.Self.Version,
-	}
+func GetIgluCentralListing(cnx context.Context, client *ApiClient) ([]string, error) {
+	return GetIgluListing(cnx, client.Http, igluCentralServer, "")
+}
 
-	body, err := json.Marshal(pr)
+// GetIgluListing lists the schema uris of the Iglu Server api at uri, eg. https://iglu.acme.com/api
+func GetIgluListing(cnx context.Context, h *http.Client, uri string, apiKey string) ([]string, error) {
+	req, err := http.NewRequestWithContext(cnx, "GET", strings.TrimSuffix(uri, "/")+"/schemas", nil)
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequestWithContext(cnx, "POST", fmt.Sprintf("%s/data-structures/v1/deployment-requests", client.BaseUrl), bytes.NewBuffer(body))
-	auth := fmt.Sprintf("Bearer %s", client.Jwt)
-	req.Header.Add("authorization", auth)
-	req.Header.Add("X-SNOWPLOW-CLI", util.VersionInfo)
-	if isPatch {
-		q := req.URL.Query()
-		q.Add("patch", "true")
-		req.URL.RawQuery = q.Encode()
+	if apiKey != "" {
+		req.Header.Set("apikey", apiKey)
 	}
 
-	if err != nil {
-		return nil, err
-	}
-	resp, err := client.Http.Do(req)
+	resp, err := h.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -184,52 +99,8 @@ func publish(cnx context.Context, client *ApiClient, from DataStructureEnv, to D
 		return nil, err
 	}
 
-	var dresp PublishResponse
-	err = json.Unmarshal(rbody, &dresp)
-	if err != nil {
-		return nil, err
-	}
-
-	if resp.StatusCode != http.StatusCreated {
-		return nil, errors.New(dresp.Message)
-	}
-
-	if !dresp.Success {
-		return nil, &PublishError{Messages: dresp.Errors}
-	}
-
-	return &dresp, nil
-}
-
-type Deployment struct {
-	Version     string           `json:"version"`
-	Env         DataStructureEnv `json:"env"`
-	ContentHash string           `json:"contentHash"`
-}
-
-type ListResponse struct {
-	Hash        string            `json:"hash"`
-	Vendor      string            `json:"vendor"`
-	Format      string            `json:"format"`
-	Name        string            `json:"name"`
-	Meta        DataStructureMeta `json:"meta"`
-	Deployments []Deployment      `json:"deployments"`
-}
-
-func GetIgluCentralListing(cnx context.Context, client *ApiClient) ([]string, error) {
-	req, err := http.NewRequestWithContext(cnx, "GET", "https://com-iglucentral-eu1-prod.iglu.snplow.net/api/schemas", nil)
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := client.Http.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	rbody, err := io.ReadAll(resp.Body)
-	defer resp.Body.Close()
-	if err != nil {
-		return nil, err
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("not expected response code %d", resp.StatusCode)
 	}
 
 	var list []string
@@ -238,88 +109,26 @@ func GetIgluCentralListing(cnx context.Context, client *ApiClient) ([]string, er
 		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("not expected response code %d", resp.StatusCode)
-	}
-
 	return list, err
 }
 
 func GetDataStructureListing(cnx context.Context, client *ApiClient) ([]ListResponse, error) {
-	req, err := http.NewRequestWithContext(cnx, "GET", fmt.Sprintf("%s/data-structures/v1", client.BaseUrl), nil)
-	auth := fmt.Sprintf("Bearer %s", client.Jwt)
-	req.Header.Add("authorization", auth)
-	req.Header.Add("X-SNOWPLOW-CLI", util.VersionInfo)
-
-	if err != nil {
-		return nil, err
-	}
-	resp, err := client.Http.Do(req)
+	c, err := client.sdk()
 	if err != nil {
 		return nil, err
 	}
-	rbody, err := io.ReadAll(resp.Body)
-	defer resp.Body.Close()
-	if err != nil {
-		return nil, err
-	}
-
-	var listResp []ListResponse
-	err = json.Unmarshal(rbody, &listResp)
-	if err != nil {
-		return nil, err
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("not expected response code %d", resp.StatusCode)
-	}
-	return listResp, nil
+	return c.GetDataStructureListing(cnx)
 }
 
 func GetDataStructureDeployments(cnx context.Context, client *ApiClient, dsHash string) ([]Deployment, error) {
-	req, err := http.NewRequestWithContext(cnx, "GET", fmt.Sprintf("%s/data-structures/v1/%s/deployments?from=0&size=1000000000", client.BaseUrl, dsHash), nil)
-	auth := fmt.Sprintf("Bearer %s", client.Jwt)
-	req.Header.Add("authorization", auth)
-	req.Header.Add("X-SNOWPLOW-CLI", util.VersionInfo)
-
-	if err != nil {
-		return nil, err
-	}
-	resp, err := client.Http.Do(req)
+	c, err := client.sdk()
 	if err != nil {
 		return nil, err
 	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("not expected response code %d", resp.StatusCode)
-	}
-
-	rbody, err := io.ReadAll(resp.Body)
-	defer resp.Body.Close()
-	if err != nil {
-		return nil, err
-	}
-
-	var deploys []Deployment
-	err = json.Unmarshal(rbody, &deploys)
-	if err != nil {
-		return nil, err
-	}
-
-	return deploys, nil
+	return c.GetDataStructureDeployments(cnx, dsHash)
 }
 
 func GetAllDataStructures(cnx context.Context, client *ApiClient, match []string) ([]DataStructure, error) {
-
-	req, err := http.NewRequestWithContext(cnx, "GET", fmt.Sprintf("%s/data-structures/v1", client.BaseUrl), nil)
-	auth := fmt.Sprintf("Bearer %s", client.Jwt)
-	req.Header.Add("authorization", auth)
-	req.Header.Add("X-SNOWPLOW-CLI", util.VersionInfo)
-
-	if err != nil {
-		return nil, err
-	}
-
 	listResp, err := GetDataStructureListing(cnx, client)
 	if err != nil {
 		return nil, err
@@ -327,6 +136,26 @@ func GetAllDataStructures(cnx context.Context, client *ApiClient, match []string
 
 	var res []DataStructure
 
+	for _, dsResp := range MatchListing(listResp, match) {
+		for _, deployment := range dsResp.Deployments {
+			if deployment.Env == DEV {
+				ds, found, err := GetDeployedDataStructure(cnx, client, dsResp, deployment)
+				if err != nil {
+					return nil, err
+				}
+				if found {
+					res = append(res, *ds)
+				}
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// MatchListing keeps the data structures whose vendor/name/format starts with one of match, all of them when match is empty
+func MatchListing(listResp []ListResponse, match []string) []ListResponse {
+	var res []ListResponse
 	for _, dsResp := range listResp {
 		matched := false
 		for _, m := range match {
@@ -341,49 +170,36 @@ func GetAllDataStructures(cnx context.Context, client *ApiClient, match []string
 		if !matched && len(match) > 0 {
 			continue
 		}
+		res = append(res, dsResp)
+	}
+	return res
+}
 
-		for _, deployment := range dsResp.Deployments {
-			if deployment.Env == DEV {
-				req, err := http.NewRequestWithContext(cnx, "GET", fmt.Sprintf("%s/data-structures/v1/%s/versions/%s", client.BaseUrl, dsResp.Hash, deployment.Version), nil)
-				auth := fmt.Sprintf("Bearer %s", client.Jwt)
-				req.Header.Add("authorization", auth)
-				req.Header.Add("X-SNOWPLOW-CLI", util.VersionInfo)
-				slog.Info("fetching data structure", "ds", fmt.Sprintf("%s/%s", dsResp.Vendor, dsResp.Name), "schema", deployment.Version)
-
-				if err != nil {
-					return nil, err
-				}
-				resp, err := client.Http.Do(req)
-				if err != nil {
-					return nil, err
-				}
-				rbody, err := io.ReadAll(resp.Body)
-				defer resp.Body.Close()
-				if err != nil {
-					return nil, err
-				}
-
-				var ds map[string]any
-				err = json.Unmarshal(rbody, &ds)
-				if err != nil {
-					return nil, err
-				}
+// GetDeployedDataStructure fetches the content of a deployment, found is false when the version is gone
+func GetDeployedDataStructure(cnx context.Context, client *ApiClient, dsResp ListResponse, deployment Deployment) (*DataStructure, bool, error) {
+	c, err := client.sdk()
+	if err != nil {
+		return nil, false, err
+	}
+	slog.Info("fetching data structure", "ds", fmt.Sprintf("%s/%s", dsResp.Vendor, dsResp.Name), "schema", deployment.Version)
 
-				if resp.StatusCode == http.StatusNotFound {
-					continue
-				}
+	ds, err := c.GetDataStructureVersion(cnx, dsResp.Hash, deployment.Version)
+	if sdk.IsNotFound(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
 
-				if resp.StatusCode != http.StatusOK {
-					return nil, fmt.Errorf("not expected response code %d", resp.StatusCode)
-				}
+	return &DataStructure{ApiVersion: "v1", ResourceType: "data-structure", Meta: dsResp.Meta, Data: ds}, true, nil
+}
 
-				dataStructure := DataStructure{ApiVersion: "v1", ResourceType: "data-structure", Meta: dsResp.Meta, Data: ds}
-				res = append(res, dataStructure)
-			}
-		}
+func GetDataStructureVersion(cnx context.Context, client *ApiClient, self DataStructureSelf, version string) (map[string]any, error) {
+	c, err := client.sdk()
+	if err != nil {
+		return nil, err
 	}
-
-	return res, nil
+	return c.GetDataStructureVersion(cnx, dataStructureHash(client, &self), version)
 }
 
 func MetadateUpdate(cnx context.Context, client *ApiClient, ds *DataStructure, managedFrom string) error {
@@ -393,7 +209,7 @@ func MetadateUpdate(cnx context.Context, client *ApiClient, ds *DataStructure, m
 		return err
 	}
 
-	body := fullMeta{
+	meta := sdk.Metadata{
 		Hidden:      &ds.Meta.Hidden,
 		SchemaType:  ds.Meta.SchemaType,
 		CustomData:  &ds.Meta.CustomData,
@@ -401,61 +217,14 @@ func MetadateUpdate(cnx context.Context, client *ApiClient, ds *DataStructure, m
 		ManagedFrom: managedFrom,
 	}
 
-	return patchMeta(cnx, client, &data.Self, body)
-}
-
-func metadataLock(cnx context.Context, client *ApiClient, ds *DataStructure, managedFrom string) error {
-
-	data, err := ds.ParseData()
+	c, err := client.sdk()
 	if err != nil {
 		return err
 	}
 
-	body := fullMeta{LockStatus: "locked", ManagedFrom: managedFrom}
-
-	return patchMeta(cnx, client, &data.Self, body)
+	return c.PatchMetadata(cnx, data.Self, meta)
 }
 
-func patchMeta(cnx context.Context, client *ApiClient, ds *DataStructureSelf, fullMeta fullMeta) error {
-
-	toHash := fmt.Sprintf("%s-%s-%s-%s", client.OrgId, ds.Vendor, ds.Name, ds.Format)
-	dsHash := sha256.Sum256([]byte(toHash))
-
-	body, err := json.Marshal(fullMeta)
-	if err != nil {
-		return err
-	}
-	url := fmt.Sprintf("%s/data-structures/v1/%x/meta", client.BaseUrl, dsHash)
-	req, err := http.NewRequestWithContext(cnx, "PATCH", url, bytes.NewBuffer(body))
-	auth := fmt.Sprintf("Bearer %s", client.Jwt)
-	req.Header.Add("authorization", auth)
-	req.Header.Add("X-SNOWPLOW-CLI", util.VersionInfo)
-
-	if err != nil {
-		return err
-	}
-
-	resp, err := client.Http.Do(req)
-	if err != nil {
-		return err
-	}
-
-	if resp.StatusCode != http.StatusOK {
-
-		rbody, err := io.ReadAll(resp.Body)
-		defer resp.Body.Close()
-		if err != nil {
-			return err
-		}
-
-		var dresp msgResponse
-		err = json.Unmarshal(rbody, &dresp)
-		if err != nil {
-			return errors.Join(err, errors.New("bad response with no message"))
-		}
-
-		return fmt.Errorf("bad response: %s", dresp.Message)
-	}
-
-	return nil
+func dataStructureHash(client *ApiClient, ds *DataStructureSelf) string {
+	return sdk.DataStructureHash(client.OrgId, *ds)
 }
@@ -139,83 +139,6 @@ func Test_Validate_FailCompletely(t *testing.T) {
 	}
 }
 
-func Test_publish_Ok(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path == "/api/msc/v1/organizations/orgid/data-structures/v1/deployment-requests" {
-			w.WriteHeader(http.StatusCreated)
-			_, _ = io.WriteString(w, `{"success":true}`)
-			return
-		}
-
-		t.Errorf("Unexpected request, got: %s", r.URL.Path)
-	}))
-	defer server.Close()
-
-	cnx := context.Background()
-	client := &ApiClient{Http: &http.Client{}, Jwt: "token", BaseUrl: fmt.Sprintf("%s/api/msc/v1/organizations/orgid", server.URL)}
-
-	result, err := publish(cnx, client, VALIDATED, DEV, DataStructure{}, false)
-	if err != nil {
-		t.Error(err)
-	}
-
-	if !result.Success {
-		t.Error("expected success, got failure")
-	}
-}
-
-func Test_publish_Fail(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path == "/api/msc/v1/organizations/orgid/data-structures/v1/deployment-requests" {
-			w.WriteHeader(http.StatusCreated)
-			_, _ = io.WriteString(w, `{"success":false, "errors": ["error1"]}`)
-			return
-		}
-
-		t.Errorf("Unexpected request, got: %s", r.URL.Path)
-	}))
-	defer server.Close()
-
-	cnx := context.Background()
-	client := &ApiClient{Http: &http.Client{}, Jwt: "token", BaseUrl: fmt.Sprintf("%s/api/msc/v1/organizations/orgid", server.URL)}
-
-	result, err := publish(cnx, client, VALIDATED, DEV, DataStructure{}, false)
-
-	if result != nil {
-		t.Error(result)
-	}
-
-	if err == nil || err.Error() != "error1" {
-		t.Error("expected failure, got success")
-	}
-}
-
-func Test_publish_FailCompletely(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path == "/api/msc/v1/organizations/orgid/data-structures/v1/deployment-requests" {
-			w.WriteHeader(http.StatusBadRequest)
-			_, _ = io.WriteString(w, `{"message":"very bad"}`)
-			return
-		}
-
-		t.Errorf("Unexpected request, got: %s", r.URL.Path)
-	}))
-	defer server.Close()
-
-	cnx := context.Background()
-	client := &ApiClient{Http: &http.Client{}, Jwt: "token", BaseUrl: fmt.Sprintf("%s/api/msc/v1/organizations/orgid", server.URL)}
-
-	result, err := publish(cnx, client, VALIDATED, DEV, DataStructure{}, false)
-
-	if result != nil {
-		t.Error(result)
-	}
-
-	if err == nil {
-		t.Error("expected failure, got success")
-	}
-}
-
 func Test_GetAllDataStructuresOk(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/api/msc/v1/organizations/orgid/data-structures/v1" {
@@ -659,30 +582,6 @@ func Test_MetadataUpdate_Fail(t *testing.T) {
 	}
 }
 
-func Test_Patch(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path == "/api/msc/v1/organizations/orgid/data-structures/v1/deployment-requests" && r.URL.Query().Get("patch") == "true" {
-			w.WriteHeader(http.StatusCreated)
-			_, _ = io.WriteString(w, `{"success":true}`)
-			return
-		}
-		t.Errorf("Unexpected request, got: %s", r.URL.Path)
-	}))
-	defer server.Close()
-
-	cnx := context.Background()
-	client := &ApiClient{Http: &http.Client{}, Jwt: "token", BaseUrl: fmt.Sprintf("%s/api/msc/v1/organizations/orgid", server.URL)}
-
-	result, err := publish(cnx, client, VALIDATED, DEV, DataStructure{}, true)
-	if err != nil {
-		t.Error(err)
-	}
-
-	if !result.Success {
-		t.Error("expected success, got failure")
-	}
-}
-
 func TestGetAllDataStructures_Matching(t *testing.T) {
 	mockListings := []ListResponse{
 		{
@@ -752,3 +651,33 @@ func TestGetAllDataStructures_Matching(t *testing.T) {
 		t.Errorf("unexpected data structure: %+v", data.Self)
 	}
 }
+
+func Test_GetDataStructureVersion_Ok(t *testing.T) {
+	self := DataStructureSelf{Vendor: "com.acme", Name: "login", Format: "jsonschema", Version: "1-0-0"}
+	client := &ApiClient{Jwt: "token", OrgId: "orgid"}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == fmt.Sprintf("/data-structures/v1/%s/versions/1-0-0", dataStructureHash(client, &self)) {
+			_, _ = io.WriteString(w, `{"self": { "name": "login", "vendor": "com.acme", "version": "1-0-0", "format": "jsonschema" }}`)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	client.Http = server.Client()
+	client.BaseUrl = server.URL
+
+	data, err := GetDataStructureVersion(context.Background(), client, self, "1-0-0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data["self"].(map[string]any)["name"] != "login" {
+		t.Errorf("unexpected data %+v", data)
+	}
+
+	_, err = GetDataStructureVersion(context.Background(), client, self, "2-0-0")
+	if err == nil {
+		t.Error("expected failure for missing version")
+	}
+}
@@ -0,0 +1,342 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package console
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	ResolverConsole     = "console"
+	ResolverIgluCentral = "iglu-central"
+	ResolverIgluServer  = "iglu-server"
+	ResolverStatic      = "static"
+	ResolverLocal       = "local"
+	ResolverBuiltIn     = "built-in"
+)
+
+var ResolverTypes = []string{ResolverConsole, ResolverIgluCentral, ResolverIgluServer, ResolverStatic, ResolverLocal, ResolverBuiltIn}
+
+const igluCentralServer = "https://com-iglucentral-eu1-prod.iglu.snplow.net/api"
+
+var builtInSchema = []string{
+	"iglu:com.snowplowanalytics.snowplow/page_ping/jsonschema/1-0-0",
+	"iglu:com.snowplowanalytics.snowplow/page_view/jsonschema/1-0-0",
+}
+
+// ResolverConfig is one step of the chain schemas referenced by data products and source applications are looked up in
+type ResolverConfig struct {
+	Type string `yaml:"type"`
+	// Uri of an Iglu Server api, eg. https://iglu.acme.com/api, or of a static repository
+	Uri    string `yaml:"uri,omitempty"`
+	ApiKey string `yaml:"api-key,omitempty"`
+	// Path of a local directory laid out like a static repository
+	Path string `yaml:"path,omitempty"`
+}
+
+// DefaultResolvers are used when none are configured
+var DefaultResolvers = []ResolverConfig{{Type: ResolverIgluCentral}, {Type: ResolverBuiltIn}, {Type: ResolverConsole}}
+
+func (r ResolverConfig) String() string {
+	switch r.Type {
+	case ResolverIgluServer, ResolverStatic:
+		return fmt.Sprintf("%s %s", r.Type, r.Uri)
+	case ResolverLocal:
+		return fmt.Sprintf("%s %s", r.Type, r.Path)
+	}
+	return r.Type
+}
+
+func (r ResolverConfig) Validate() error {
+	switch r.Type {
+	case ResolverIgluServer, ResolverStatic:
+		if r.Uri == "" {
+			return fmt.Errorf("%s resolver needs a uri", r.Type)
+		}
+	case ResolverLocal:
+		if r.Path == "" {
+			return fmt.Errorf("%s resolver needs a path", r.Type)
+		}
+	case ResolverConsole, ResolverIgluCentral, ResolverBuiltIn:
+	default:
+		return fmt.Errorf("unknown resolver type %q, expected one of %s", r.Type, strings.Join(ResolverTypes, ", "))
+	}
+	return nil
+}
+
+// SchemaResolver also fetches the schemas it finds
+type SchemaResolver interface {
+	SchemaDeployChecker
+	// FetchSchema returns the first schema found by a resolver able to fetch schemas, built in ones can not
+	FetchSchema(uri string) (schema map[string]any, found bool, err error)
+}
+
+type schemaFetcher interface {
+	FetchSchema(uri string) (schema map[string]any, found bool, err error)
+}
+
+type resolverChain struct {
+	names     []string
+	resolvers []SchemaDeployChecker
+}
+
+// NewSchemaResolver looks schemas up with each resolver in turn, h is used for Iglu repositories.
+// Console resolvers are skipped when c is nil
+func NewSchemaResolver(cnx context.Context, h *http.Client, c *ApiClient, configs []ResolverConfig) (SchemaResolver, error) {
+	chain := &resolverChain{}
+	for _, config := range configs {
+		if err := config.Validate(); err != nil {
+			return nil, err
+		}
+
+		var r SchemaDeployChecker
+		var err error
+		switch config.Type {
+		case ResolverConsole:
+			if c == nil {
+				slog.Debug("validation", "msg", "skipping console resolver without console access")
+				continue
+			}
+			r, err = newConsoleResolver(cnx, c)
+		case ResolverIgluCentral:
+			r, err = newIgluServerResolver(cnx, h, igluCentralServer, "")
+		case ResolverIgluServer:
+			r, err = newIgluServerResolver(cnx, h, config.Uri, config.ApiKey)
+		case ResolverStatic:
+			r = &staticResolver{cnx: cnx, h: h, uri: strings.TrimSuffix(config.Uri, "/"), schemas: map[string]map[string]any{}}
+		case ResolverLocal:
+			r = &localResolver{path: config.Path}
+		case ResolverBuiltIn:
+			r = &listingResolver{builtInSchema}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s resolver: %w", config, err)
+		}
+		chain.names = append(chain.names, config.String())
+		chain.resolvers = append(chain.resolvers, r)
+	}
+	return chain, nil
+}
+
+// IsDSDeployed returns as soon as a resolver finds uri, otherwise the versions found by all of them
+func (chain *resolverChain) IsDSDeployed(uri string) (bool, []string, error) {
+	if len(strings.Split(strings.TrimPrefix(uri, "iglu:"), "/")) != 4 {
+		return false, nil, fmt.Errorf("invalid iglu uri got: %s", uri)
+	}
+
+	alternatives := map[string]bool{}
+	for i, r := range chain.resolvers {
+		found, versions, err := r.IsDSDeployed(uri)
+		if err != nil {
+			return false, nil, fmt.Errorf("%s resolver: %w", chain.names[i], err)
+		}
+		if found {
+			slog.Debug("validation", "msg", fmt.Sprintf("%s resolved %s", chain.names[i], uri))
+			return true, nil, nil
+		}
+		for _, v := range versions {
+			alternatives[v] = true
+		}
+	}
+
+	versions := []string{}
+	for v := range alternatives {
+		versions = append(versions, v)
+	}
+	sort.Strings(versions)
+	return false, versions, nil
+}
+
+func (chain *resolverChain) FetchSchema(uri string) (map[string]any, bool, error) {
+	for i, r := range chain.resolvers {
+		fetcher, ok := r.(schemaFetcher)
+		if !ok {
+			continue
+		}
+		schema, found, err := fetcher.FetchSchema(uri)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s resolver: %w", chain.names[i], err)
+		}
+		if found {
+			slog.Debug("validation", "msg", fmt.Sprintf("%s fetched %s", chain.names[i], uri))
+			return schema, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// listingResolver knows every schema up front, like Iglu Servers listing their schemas
+type listingResolver struct {
+	uris []string
+}
+
+type igluServerResolver struct {
+	listingResolver
+	cnx    context.Context
+	h      *http.Client
+	uri    string
+	apiKey string
+}
+
+func newIgluServerResolver(cnx context.Context, h *http.Client, uri string, apiKey string) (SchemaDeployChecker, error) {
+	uris, err := GetIgluListing(cnx, h, uri, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	return &igluServerResolver{listingResolver{uris}, cnx, h, strings.TrimSuffix(uri, "/"), apiKey}, nil
+}
+
+func (r *igluServerResolver) FetchSchema(uri string) (map[string]any, bool, error) {
+	if !slices.Contains(r.uris, uri) {
+		return nil, false, nil
+	}
+	return fetchSchema(r.cnx, r.h, r.uri+"/schemas/"+strings.TrimPrefix(uri, "iglu:"), r.apiKey)
+}
+
+// fetchSchema gets a schema from an Iglu repository, found is false when it is missing
+func fetchSchema(cnx context.Context, h *http.Client, url string, apiKey string) (map[string]any, bool, error) {
+	req, err := http.NewRequestWithContext(cnx, "GET", url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	if apiKey != "" {
+		req.Header.Set("apikey", apiKey)
+	}
+	resp, err := h.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	// object stores answer forbidden for missing keys
+	case http.StatusNotFound, http.StatusForbidden:
+		return nil, false, nil
+	default:
+		return nil, false, fmt.Errorf("not expected response code %d", resp.StatusCode)
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(body, &schema); err != nil {
+		return nil, false, fmt.Errorf("%s: %w", url, err)
+	}
+	return schema, true, nil
+}
+
+func (r *listingResolver) IsDSDeployed(uri string) (bool, []string, error) {
+	if slices.Contains(r.uris, uri) {
+		return true, nil, nil
+	}
+	prefix := uri[:strings.LastIndex(uri, "/")+1]
+	versions := []string{}
+	for _, u := range r.uris {
+		if strings.HasPrefix(u, prefix) {
+			versions = append(versions, strings.TrimPrefix(u, prefix))
+		}
+	}
+	return false, versions, nil
+}
+
+// staticResolver asks a static repository for each schema, at <uri>/schemas/vendor/name/format/version
+type staticResolver struct {
+	cnx     context.Context
+	h       *http.Client
+	uri     string
+	schemas map[string]map[string]any
+}
+
+func (r *staticResolver) IsDSDeployed(uri string) (bool, []string, error) {
+	_, found, err := r.FetchSchema(uri)
+	return found, nil, err
+}
+
+func (r *staticResolver) FetchSchema(uri string) (map[string]any, bool, error) {
+	if schema, ok := r.schemas[uri]; ok {
+		return schema, schema != nil, nil
+	}
+	schema, _, err := fetchSchema(r.cnx, r.h, r.uri+"/schemas/"+strings.TrimPrefix(uri, "iglu:"), "")
+	if err != nil {
+		return nil, false, err
+	}
+	r.schemas[uri] = schema
+	return schema, schema != nil, nil
+}
+
+// localResolver reads a directory laid out like a static repository, eg. a checkout of iglu-central.
+// The schemas directory itself can be given too
+type localResolver struct {
+	path string
+}
+
+func (r *localResolver) IsDSDeployed(uri string) (bool, []string, error) {
+	parts := strings.Split(strings.TrimPrefix(uri, "iglu:"), "/")
+	for _, root := range []string{filepath.Join(r.path, "schemas"), r.path} {
+		dir := filepath.Join(root, parts[0], parts[1], parts[2])
+		entries, err := os.ReadDir(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return false, nil, err
+		}
+		versions := []string{}
+		for _, e := range entries {
+			if e.IsDir() {
+				continue
+			}
+			if e.Name() == parts[3] {
+				return true, nil, nil
+			}
+			versions = append(versions, e.Name())
+		}
+		return false, versions, nil
+	}
+	if _, err := os.Stat(r.path); err != nil {
+		return false, nil, err
+	}
+	return false, nil, nil
+}
+
+func (r *localResolver) FetchSchema(uri string) (map[string]any, bool, error) {
+	parts := strings.Split(strings.TrimPrefix(uri, "iglu:"), "/")
+	for _, root := range []string{filepath.Join(r.path, "schemas"), r.path} {
+		file := filepath.Join(root, parts[0], parts[1], parts[2], parts[3])
+		content, err := os.ReadFile(file)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		var schema map[string]any
+		if err := json.Unmarshal(content, &schema); err != nil {
+			return nil, false, fmt.Errorf("%s: %w", file, err)
+		}
+		return schema, true, nil
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package console
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_SchemaResolverChain(t *testing.T) {
+	igluServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/schemas" || r.Header.Get("apikey") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]string{
+			"iglu:com.acme/checkout/jsonschema/1-0-0",
+			"iglu:com.acme/user/jsonschema/1-0-0",
+		})
+	}))
+	defer igluServer.Close()
+
+	static := 0
+	staticRepo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		static++
+		if r.URL.Path == "/schemas/com.acme/product/jsonschema/1-0-0" {
+			_, _ = w.Write([]byte("{}"))
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer staticRepo.Close()
+
+	local := t.TempDir()
+	for _, v := range []string{"1-0-0", "1-0-1"} {
+		dir := filepath.Join(local, "schemas", "com.acme", "user", "jsonschema")
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, v), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sdc, err := NewSchemaResolver(context.Background(), http.DefaultClient, nil, []ResolverConfig{
+		{Type: ResolverLocal, Path: local},
+		{Type: ResolverIgluServer, Uri: igluServer.URL + "/api/", ApiKey: "secret"},
+		{Type: ResolverStatic, Uri: staticRepo.URL},
+		{Type: ResolverConsole},
+		{Type: ResolverBuiltIn},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	table := []struct {
+		uri          string
+		found        bool
+		alternatives []string
+	}{
+		{"iglu:com.acme/user/jsonschema/1-0-1", true, nil},
+		{"iglu:com.acme/checkout/jsonschema/1-0-0", true, nil},
+		{"iglu:com.acme/product/jsonschema/1-0-0", true, nil},
+		{"iglu:com.snowplowanalytics.snowplow/page_view/jsonschema/1-0-0", true, nil},
+		{"iglu:com.acme/user/jsonschema/2-0-0", false, []string{"1-0-0", "1-0-1"}},
+		{"iglu:com.acme/cart/jsonschema/1-0-0", false, []string{}},
+	}
+	for _, row := range table {
+		found, alternatives, err := sdc.IsDSDeployed(row.uri)
+		if err != nil {
+			t.Fatalf("%s: %s", row.uri, err)
+		}
+		if found != row.found || !row.found && !reflect.DeepEqual(alternatives, row.alternatives) {
+			t.Errorf("%s got %v %v want %v %v", row.uri, found, alternatives, row.found, row.alternatives)
+		}
+	}
+
+	_, _, _ = sdc.IsDSDeployed("iglu:com.acme/product/jsonschema/1-0-0")
+	if static != 4 {
+		t.Errorf("expected static lookups to be remembered got %d requests", static)
+	}
+
+	if _, _, err := sdc.IsDSDeployed("iglu:com.acme/user"); err == nil {
+		t.Error("expected an invalid uri to fail")
+	}
+}
+
+func Test_SchemaResolverConfig(t *testing.T) {
+	for _, config := range []ResolverConfig{{Type: "ftp"}, {Type: ResolverStatic}, {Type: ResolverLocal}} {
+		if _, err := NewSchemaResolver(context.Background(), http.DefaultClient, nil, []ResolverConfig{config}); err == nil {
+			t.Errorf("expected %+v to be invalid", config)
+		}
+	}
+
+	_, err := NewSchemaResolver(context.Background(), http.DefaultClient, nil, []ResolverConfig{{Type: ResolverIgluServer, Uri: "http://127.0.0.1:1/api"}})
+	if err == nil {
+		t.Error("expected an unreachable iglu server to fail")
+	}
+}
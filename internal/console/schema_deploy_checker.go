@@ -14,21 +14,17 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"slices"
 	"strings"
-)
 
-var builtInSchema = []string{
-	"iglu:com.snowplowanalytics.snowplow/page_ping/jsonschema/1-0-0",
-	"iglu:com.snowplowanalytics.snowplow/page_view/jsonschema/1-0-0",
-}
+	. "github.com/snowplow/snowplow-cli/internal/model"
+)
 
 type SchemaDeployChecker interface {
 	IsDSDeployed(uri string) (found bool, deployedVersions []string, err error)
 }
 
+// schemaDeployCheckProvider resolves schemas deployed with BDP Console
 type schemaDeployCheckProvider struct {
-	igluCentralList  []string
 	dsList           []ListResponse
 	getDsDeployments func(hash string) ([]Deployment, error)
 }
@@ -40,16 +36,6 @@ func (sdc *schemaDeployCheckProvider) IsDSDeployed(uri string) (found bool, depl
 		return false, nil, fmt.Errorf("invalid iglu uri got: %s", uri)
 	}
 
-	if slices.Contains(sdc.igluCentralList, uri) {
-		slog.Debug("validation", "msg", fmt.Sprintf("iglu central resolved %s", uri))
-		return true, nil, nil
-	}
-
-	if slices.Contains(builtInSchema, uri) {
-		slog.Debug("validation", "msg", fmt.Sprintf("built in resolved %s", uri))
-		return true, nil, nil
-	}
-
 	for _, ds := range sdc.dsList {
 		if ds.Vendor == splut[0] && ds.Name == splut[1] && ds.Format == splut[2] {
 
@@ -99,12 +85,19 @@ func (sdc *schemaDeployCheckProvider) IsDSDeployed(uri string) (found bool, depl
 	return false, nil, nil
 }
 
+// NewSchemaDeployChecker resolves schemas with DefaultResolvers
 func NewSchemaDeployChecker(cnx context.Context, c *ApiClient) (SchemaDeployChecker, error) {
-	igluCentral, err := GetIgluCentralListing(cnx, c)
-	if err != nil {
-		return nil, err
-	}
+	return NewSchemaResolver(cnx, c.Http, c, DefaultResolvers)
+}
 
+// consoleResolver fetches the schemas deployed with BDP Console
+type consoleResolver struct {
+	*schemaDeployCheckProvider
+	cnx context.Context
+	c   *ApiClient
+}
+
+func newConsoleResolver(cnx context.Context, c *ApiClient) (SchemaDeployChecker, error) {
 	dsList, err := GetDataStructureListing(cnx, c)
 	if err != nil {
 		return nil, err
@@ -118,5 +111,19 @@ func NewSchemaDeployChecker(cnx context.Context, c *ApiClient) (SchemaDeployChec
 		return deploys, nil
 	}
 
-	return &schemaDeployCheckProvider{igluCentral, dsList, lookup}, nil
+	return &consoleResolver{&schemaDeployCheckProvider{dsList, lookup}, cnx, c}, nil
+}
+
+func (r *consoleResolver) FetchSchema(uri string) (map[string]any, bool, error) {
+	found, _, err := r.IsDSDeployed(uri)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	parts := strings.Split(strings.TrimPrefix(uri, "iglu:"), "/")
+	self := DataStructureSelf{Vendor: parts[0], Name: parts[1], Format: parts[2]}
+	schema, err := GetDataStructureVersion(r.cnx, r.c, self, parts[3])
+	if err != nil {
+		return nil, false, err
+	}
+	return schema, true, nil
 }
@@ -11,6 +11,7 @@
 package console
 
 import (
+	"context"
 	"errors"
 	"slices"
 	"sort"
@@ -21,13 +22,7 @@ func Test_IsDeployed_Iglu(t *testing.T) {
 
 	uri := "iglu:vendor/name/format/version"
 
-	mock := &schemaDeployCheckProvider{
-		[]string{uri},
-		[]ListResponse{},
-		func(h string) ([]Deployment, error) {
-			return nil, nil
-		},
-	}
+	mock := &listingResolver{[]string{uri}}
 
 	found, _, _ := mock.IsDSDeployed(uri)
 
@@ -40,12 +35,9 @@ func Test_IsDeployed_BuiltIn(t *testing.T) {
 
 	uri := "iglu:com.snowplowanalytics.snowplow/page_view/jsonschema/1-0-0"
 
-	mock := &schemaDeployCheckProvider{
-		[]string{},
-		[]ListResponse{},
-		func(h string) ([]Deployment, error) {
-			return nil, nil
-		},
+	mock, err := NewSchemaResolver(context.Background(), nil, nil, []ResolverConfig{{Type: ResolverBuiltIn}})
+	if err != nil {
+		t.Fatal(err)
 	}
 
 	found, _, _ := mock.IsDSDeployed(uri)
@@ -59,13 +51,12 @@ func Test_IsDeployed_ConsoleLucky(t *testing.T) {
 	uri := "iglu:vendor/name/format/1-0-0"
 
 	mock := &schemaDeployCheckProvider{
-		[]string{},
 		[]ListResponse{{
 			Hash:        "hash",
 			Vendor:      "vendor",
 			Name:        "name",
 			Format:      "format",
-			Deployments: []Deployment{{"1-0-0", DEV, ""}},
+			Deployments: []Deployment{{Version: "1-0-0", Env: DEV}},
 		}},
 		func(h string) ([]Deployment, error) {
 			return nil, nil
@@ -83,7 +74,6 @@ func Test_IsDeployed_ConsoleUnLucky(t *testing.T) {
 	uri := "iglu:vendor/name/format/1-0-0"
 
 	mock := &schemaDeployCheckProvider{
-		[]string{},
 		[]ListResponse{{
 			Hash:        "hash",
 			Vendor:      "vendor",
@@ -92,7 +82,7 @@ func Test_IsDeployed_ConsoleUnLucky(t *testing.T) {
 			Deployments: []Deployment{},
 		}},
 		func(h string) ([]Deployment, error) {
-			return []Deployment{{"1-0-0", DEV, ""}}, nil
+			return []Deployment{{Version: "1-0-0", Env: DEV}}, nil
 		},
 	}
 
@@ -107,7 +97,6 @@ func Test_IsDeployed_ConsoleAlternatives(t *testing.T) {
 	uri := "iglu:vendor/name/format/1-0-10"
 
 	mock := &schemaDeployCheckProvider{
-		[]string{},
 		[]ListResponse{{
 			Hash:        "hash",
 			Vendor:      "vendor",
@@ -116,7 +105,7 @@ func Test_IsDeployed_ConsoleAlternatives(t *testing.T) {
 			Deployments: []Deployment{},
 		}},
 		func(h string) ([]Deployment, error) {
-			return []Deployment{{"2-0-0", DEV, ""}, {"1-0-0", DEV, ""}}, nil
+			return []Deployment{{Version: "2-0-0", Env: DEV}, {Version: "1-0-0", Env: DEV}}, nil
 		},
 	}
 
@@ -139,7 +128,6 @@ func Test_IsDeployed_ConsoleAlternativesFail(t *testing.T) {
 	uri := "iglu:vendor/name/format/1-0-10"
 
 	mock := &schemaDeployCheckProvider{
-		[]string{},
 		[]ListResponse{{
 			Hash:        "hash",
 			Vendor:      "vendor",
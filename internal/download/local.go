@@ -0,0 +1,121 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+package download
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/util"
+)
+
+// indexLocalFiles maps the resource names of data products and source applications under dir to their files.
+// Paths start with dir, like the ones files are written to
+func indexLocalFiles(dir string) (map[string]string, error) {
+	index := map[string]string{}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return index, nil
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	found, err := util.MaybeResourcesfromPaths([]string{dir})
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{}
+	for path := range found {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, abs := range paths {
+		resource := found[abs]
+		resourceType, _ := resource["resourceType"].(string)
+		id, _ := resource["resourceName"].(string)
+		if id == "" || resourceType != "data-product" && resourceType != "source-application" {
+			continue
+		}
+		rel, err := filepath.Rel(absDir, abs)
+		if err != nil {
+			return nil, err
+		}
+		path := filepath.Join(dir, rel)
+		if first, ok := index[id]; ok {
+			slog.Warn("download", "msg", "resource is in several files, updating the first one", "resourceName", id, "file", first, "ignored", path)
+			continue
+		}
+		index[id] = path
+	}
+
+	return index, nil
+}
+
+// rebaseRefs makes refs relative to the top level data products directory relative to the directory of the data product file
+func rebaseRefs(dp *model.DataProductCanonicalData, dataProductsDir string, dir string) {
+	if filepath.Clean(dataProductsDir) == filepath.Clean(dir) {
+		return
+	}
+	rebase := func(ref string) string {
+		rel, err := filepath.Rel(dir, filepath.Join(dataProductsDir, ref))
+		if err != nil {
+			return ref
+		}
+		rel = filepath.ToSlash(rel)
+		if !strings.HasPrefix(rel, "../") {
+			rel = "./" + rel
+		}
+		return rel
+	}
+
+	for i := range dp.SourceApplications {
+		dp.SourceApplications[i].Ref = rebase(dp.SourceApplications[i].Ref)
+	}
+	for i := range dp.EventSpecifications {
+		es := &dp.EventSpecifications[i]
+		for j := range es.ExcludedSourceApplications {
+			es.ExcludedSourceApplications[j].Ref = rebase(es.ExcludedSourceApplications[j].Ref)
+		}
+		for j := range es.Triggers {
+			if es.Triggers[j].Image != nil {
+				es.Triggers[j].Image = &model.Ref{Ref: rebase(es.Triggers[j].Image.Ref)}
+			}
+		}
+	}
+}
+
+// pruneFiles deletes the files of previously downloaded resources missing from remote.
+// Local resources never downloaded are kept, they may not have been published yet
+func pruneFiles(existing map[string]string, remoteIds map[string]bool, downloaded *util.Downloaded) (int, error) {
+	pruned := 0
+	for id, path := range existing {
+		if remoteIds[id] {
+			continue
+		}
+		if !downloaded.Has(id) {
+			slog.Warn("download", "msg", "keeping file of resource never downloaded", "file", path, "resourceName", id)
+			continue
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return pruned, err
+		}
+		downloaded.Remove(id)
+		slog.Info("download", "msg", "removed file of deleted resource", "file", path, "resourceName", id)
+		pruned++
+	}
+	return pruned, nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+package download
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/util"
+)
+
+func writeFiles(t *testing.T, files map[string]string) {
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func Test_indexLocalFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data-products")
+	writeFiles(t, map[string]string{
+		filepath.Join(dir, "team", "renamed.yaml"):    "resourceType: data-product\nresourceName: dp-1\n",
+		filepath.Join(dir, "source-apps", "web.json"): `{"resourceType": "source-application", "resourceName": "sa-1"}`,
+		filepath.Join(dir, "z-copy.yaml"):             "resourceType: data-product\nresourceName: dp-1\n",
+		filepath.Join(dir, "notes.yaml"):              "title: not a resource\n",
+		filepath.Join(dir, "images", "cart.png"):      "png",
+	})
+
+	index, err := indexLocalFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"dp-1": filepath.Join(dir, "team", "renamed.yaml"),
+		"sa-1": filepath.Join(dir, "source-apps", "web.json"),
+	}
+	if !reflect.DeepEqual(index, expected) {
+		t.Fatalf("unexpected index %v", index)
+	}
+
+	index, err = indexLocalFiles(filepath.Join(dir, "missing"))
+	if err != nil || len(index) != 0 {
+		t.Fatalf("expected a missing directory to be empty got %v %v", index, err)
+	}
+}
+
+func Test_rebaseRefs(t *testing.T) {
+	dp := model.DataProductCanonicalData{
+		SourceApplications: []model.Ref{{Ref: "./source-apps/web.yaml"}},
+		EventSpecifications: []model.EventSpecCanonical{{
+			ExcludedSourceApplications: []model.Ref{{Ref: "./source-apps/web.yaml"}},
+			Triggers:                   []model.Trigger{{Id: "t1", Image: &model.Ref{Ref: "./images/cart.png"}}, {Id: "t2"}},
+		}},
+	}
+
+	rebaseRefs(&dp, "data-products", filepath.Join("data-products", "team"))
+
+	es := dp.EventSpecifications[0]
+	if dp.SourceApplications[0].Ref != "../source-apps/web.yaml" || es.ExcludedSourceApplications[0].Ref != "../source-apps/web.yaml" {
+		t.Fatalf("unexpected source application refs %+v", dp)
+	}
+	if es.Triggers[0].Image.Ref != "../images/cart.png" || es.Triggers[1].Image != nil {
+		t.Fatalf("unexpected trigger images %+v", es.Triggers)
+	}
+}
+
+func Test_pruneFiles(t *testing.T) {
+	dir := t.TempDir()
+	kept, deleted, local := filepath.Join(dir, "kept.yaml"), filepath.Join(dir, "deleted.yaml"), filepath.Join(dir, "local.yaml")
+	writeFiles(t, map[string]string{kept: "", deleted: "", local: ""})
+
+	downloaded, err := util.ReadDownloaded(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	downloaded.Add("dp-1")
+	downloaded.Add("dp-2")
+
+	existing := map[string]string{"dp-1": kept, "dp-2": deleted, "dp-3": local}
+	pruned, err := pruneFiles(existing, map[string]bool{"dp-1": true}, downloaded)
+	if err != nil || pruned != 1 {
+		t.Fatalf("expected one file to be pruned got %d %v", pruned, err)
+	}
+	for _, path := range []string{kept, local} {
+		if _, err := os.Stat(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(deleted); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be removed", deleted)
+	}
+	if downloaded.Has("dp-2") {
+		t.Fatal("expected the pruned resource to be forgotten")
+	}
+}
@@ -11,18 +11,32 @@ package download
 
 import (
 	"log/slog"
+	"path/filepath"
 
 	"github.com/snowplow/snowplow-cli/internal/console"
 	"github.com/snowplow/snowplow-cli/internal/util"
 	"golang.org/x/net/context"
 )
 
-func DownloadDataProductsAndRelatedResources(files util.Files, cnx context.Context, client *console.ApiClient) error {
+// DownloadDataProductsAndRelatedResources writes remote resources back to the local files already holding them,
+// new ones get files named after them. With prune, files of previously downloaded resources deleted remotely are removed
+func DownloadDataProductsAndRelatedResources(files util.Files, cnx context.Context, client *console.ApiClient, prune bool) error {
 	res, err := console.GetDataProductsAndRelatedResources(cnx, client)
 	if err != nil {
 		return err
 	}
 
+	existing, err := indexLocalFiles(files.DataProductsLocation)
+	if err != nil {
+		return err
+	}
+	files.ExistingFiles = existing
+
+	downloaded, err := util.ReadDownloaded(files.DataProductsLocation)
+	if err != nil {
+		return err
+	}
+
 	sas := remoteSasToLocalResources(res.SourceApplication)
 
 	fileNameToSa, err := files.CreateSourceApps(sas)
@@ -44,12 +58,42 @@ func DownloadDataProductsAndRelatedResources(files util.Files, cnx context.Conte
 		return err
 	}
 
+	for i := range dps {
+		if path, ok := files.ExistingFile(dps[i].ResourceName); ok {
+			rebaseRefs(&dps[i].Data, files.DataProductsLocation, filepath.Dir(path))
+		}
+	}
+
 	_, err = files.CreateDataProducts(dps)
 	if err != nil {
 		return err
 	}
 
 	slog.Info("download", "msg", "wrote data products", "count", len(dps))
+
+	remoteIds := map[string]bool{}
+	for _, sa := range sas {
+		remoteIds[sa.ResourceName] = true
+	}
+	for _, dp := range dps {
+		remoteIds[dp.ResourceName] = true
+	}
+
+	if prune {
+		pruned, err := pruneFiles(existing, remoteIds, downloaded)
+		if err != nil {
+			return err
+		}
+		slog.Info("download", "msg", "pruned deleted resources", "count", pruned)
+	}
+
+	for id := range remoteIds {
+		downloaded.Add(id)
+	}
+	if err := downloaded.Write(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
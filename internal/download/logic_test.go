@@ -0,0 +1,110 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+package download
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/util"
+	"golang.org/x/net/context"
+)
+
+func Test_DownloadKeepsFileMapping(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/msc/v1/organizations/orgid/data-products/v2":
+			_, _ = io.WriteString(w, `{"data": [{"id": "dp-1", "name": "Shop renamed", "sourceApplications": ["sa-1"], "eventSpecs": []}], "includes": {"eventSpecs": []}}`)
+		case "/api/msc/v1/organizations/orgid/source-apps/v1":
+			_, _ = io.WriteString(w, `{"data": [{"id": "sa-1", "name": "Web", "appIds": ["web"], "entities": {"tracked": [], "enriched": []}}]}`)
+		default:
+			t.Errorf("Unexpected request, got: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	shop := filepath.Join("data-products", "team", "shop.yaml")
+	web := filepath.Join("data-products", "source-apps", "web-app.yaml")
+	deleted := filepath.Join("data-products", "old.yaml")
+	unpublished := filepath.Join("data-products", "new.yaml")
+	writeFiles(t, map[string]string{
+		shop: `# the shop
+apiVersion: v1
+resourceType: data-product
+resourceName: dp-1
+data:
+    name: Shop
+    sourceApplications: []
+    eventSpecifications: []
+`,
+		web:         "apiVersion: v1\nresourceType: source-application\nresourceName: sa-1\ndata:\n    name: Web\n",
+		deleted:     "apiVersion: v1\nresourceType: data-product\nresourceName: dp-9\ndata:\n    name: Old\n",
+		unpublished: "apiVersion: v1\nresourceType: data-product\nresourceName: dp-new\ndata:\n    name: New\n",
+		filepath.Join("data-products", util.DownloadedFile): `{"resources": ["dp-1", "dp-9", "sa-1"]}`,
+	})
+
+	files := util.Files{DataProductsLocation: "data-products", SourceAppsLocation: util.SourceAppsFolder, ExtentionPreference: "yaml"}
+	client := &console.ApiClient{Http: &http.Client{}, Jwt: "token", BaseUrl: fmt.Sprintf("%s/api/msc/v1/organizations/orgid", server.URL)}
+
+	if err := DownloadDataProductsAndRelatedResources(files, context.Background(), client, true); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(shop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# the shop", "name: Shop renamed", "$ref: ../source-apps/web-app.yaml"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("expected %q in\n%s", want, content)
+		}
+	}
+
+	written, err := util.MaybeResourcesfromPaths([]string{"data-products"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resources := 0
+	for _, resource := range written {
+		if resource["resourceName"] != nil {
+			resources++
+		}
+	}
+	if resources != 3 {
+		t.Fatalf("expected only the existing files to remain got %v", written)
+	}
+	if _, err := os.Stat(deleted); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be pruned", deleted)
+	}
+	if _, err := os.Stat(unpublished); err != nil {
+		t.Fatalf("expected %s never downloaded to be kept got %v", unpublished, err)
+	}
+
+	downloaded, err := util.ReadDownloaded("data-products")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !downloaded.Has("dp-1") || !downloaded.Has("sa-1") || downloaded.Has("dp-9") || downloaded.Has("dp-new") {
+		t.Fatalf("unexpected downloaded resources %v", downloaded)
+	}
+}
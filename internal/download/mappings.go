@@ -11,6 +11,7 @@ package download
 
 import (
 	"fmt"
+	"path/filepath"
 	"sort"
 	"strings"
 
@@ -153,7 +154,11 @@ func remoteDpToLocal(remoteDp console.RemoteDataProduct, saIdToRef map[string]mo
 func localSasToRefs(fileNameToLocalSa map[string]model.CliResource[model.SourceAppData], dataProductsLocation string) map[string]model.Ref {
 	var saIdToRef = make(map[string]model.Ref)
 	for path, sa := range fileNameToLocalSa {
-		saIdToRef[sa.ResourceName] = model.Ref{Ref: fmt.Sprintf(".%s", strings.TrimPrefix(path, dataProductsLocation))}
+		ref := fmt.Sprintf(".%s", strings.TrimPrefix(path, dataProductsLocation))
+		if rel, err := filepath.Rel(dataProductsLocation, path); err == nil {
+			ref = "./" + filepath.ToSlash(rel)
+		}
+		saIdToRef[sa.ResourceName] = model.Ref{Ref: ref}
 	}
 	return saIdToRef
 }
@@ -0,0 +1,253 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/publish"
+)
+
+// Formats tables can be written as
+var Formats = []string{"csv", "tsv"}
+
+// listSeparator joins several values in one cell
+const listSeparator = "; "
+
+// Table is one spreadsheet, written to its own file
+type Table struct {
+	Name   string
+	Header []string
+	Rows   [][]string
+}
+
+// Tables flattens resolved local data products into one table per resource type, files are shown relative to basePath
+func Tables(local *publish.LocalFilesRefsResolved, basePath string) []Table {
+	file := func(id string) string {
+		f := local.IdToFileName[id]
+		if rel, err := filepath.Rel(basePath, f); err == nil {
+			return rel
+		}
+		return f
+	}
+
+	apps := map[string]model.SourceApp{}
+	for _, sa := range local.SourceApps {
+		apps[sa.ResourceName] = sa
+	}
+	appNames := func(ids []string) []string {
+		names := []string{}
+		for _, id := range ids {
+			if sa, ok := apps[id]; ok {
+				names = append(names, sa.Data.Name)
+			}
+		}
+		return names
+	}
+
+	dps := slices.Clone(local.DataProudcts)
+	// names may repeat, files keep the order stable
+	sort.Slice(dps, func(i, j int) bool {
+		a, b := strings.ToLower(dps[i].Data.Name), strings.ToLower(dps[j].Data.Name)
+		return a < b || a == b && file(dps[i].ResourceName) < file(dps[j].ResourceName)
+	})
+	sas := slices.Clone(local.SourceApps)
+	sort.Slice(sas, func(i, j int) bool {
+		a, b := strings.ToLower(sas[i].Data.Name), strings.ToLower(sas[j].Data.Name)
+		return a < b || a == b && file(sas[i].ResourceName) < file(sas[j].ResourceName)
+	})
+
+	dataProducts := Table{
+		Name:   "data-products",
+		Header: []string{"Data product", "Domain", "Owner", "Description", "Source applications", "Event specifications", "File"},
+	}
+	eventSpecs := Table{
+		Name:   "event-specifications",
+		Header: []string{"Data product", "Event specification", "Description", "Event", "Tracked entities", "Source applications", "Excluded source applications", "App ids", "Triggers"},
+	}
+	entities := Table{
+		Name:   "entities",
+		Header: []string{"Data product", "Event specification", "Entity", "Kind", "Min cardinality", "Max cardinality"},
+	}
+	triggers := Table{
+		Name:   "triggers",
+		Header: []string{"Data product", "Event specification", "Trigger", "Description", "Url", "App ids", "Image"},
+	}
+	sourceApps := Table{
+		Name:   "source-applications",
+		Header: []string{"Source application", "Owner", "Description", "App ids", "Data products", "File"},
+	}
+
+	usedBy := map[string][]string{}
+	for _, dp := range dps {
+		ids := refIds(dp.Data.SourceApplications)
+		for _, id := range ids {
+			usedBy[id] = append(usedBy[id], dp.Data.Name)
+		}
+		dataProducts.Rows = append(dataProducts.Rows, []string{
+			dp.Data.Name,
+			dp.Data.Domain,
+			dp.Data.Owner,
+			dp.Data.Description,
+			strings.Join(appNames(ids), listSeparator),
+			strconv.Itoa(len(dp.Data.EventSpecifications)),
+			file(dp.ResourceName),
+		})
+
+		for _, es := range dp.Data.EventSpecifications {
+			excluded := refIds(es.ExcludedSourceApplications)
+			included := []string{}
+			appIds := []string{}
+			for _, id := range ids {
+				if slices.Contains(excluded, id) {
+					continue
+				}
+				included = append(included, id)
+				for _, appId := range apps[id].Data.AppIds {
+					if !slices.Contains(appIds, appId) {
+						appIds = append(appIds, appId)
+					}
+				}
+			}
+			eventSpecs.Rows = append(eventSpecs.Rows, []string{
+				dp.Data.Name,
+				es.Name,
+				es.Description,
+				es.Event.Source,
+				strings.Join(entityCells(es.Entities.Tracked), listSeparator),
+				strings.Join(appNames(included), listSeparator),
+				strings.Join(appNames(excluded), listSeparator),
+				strings.Join(appIds, listSeparator),
+				strconv.Itoa(len(es.Triggers)),
+			})
+
+			for _, kind := range []struct {
+				name string
+				refs []model.SchemaRef
+			}{{"tracked", es.Entities.Tracked}, {"enriched", es.Entities.Enriched}} {
+				for _, e := range kind.refs {
+					entities.Rows = append(entities.Rows, []string{
+						dp.Data.Name, es.Name, e.Source, kind.name, number(e.MinCardinality), number(e.MaxCardinality),
+					})
+				}
+			}
+
+			for _, t := range es.Triggers {
+				image := ""
+				if t.Image != nil {
+					image = t.Image.Ref
+				}
+				triggers.Rows = append(triggers.Rows, []string{
+					dp.Data.Name, es.Name, t.Id, t.Description, t.Url, strings.Join(t.AppIds, listSeparator), image,
+				})
+			}
+		}
+	}
+
+	for _, sa := range sas {
+		sourceApps.Rows = append(sourceApps.Rows, []string{
+			sa.Data.Name,
+			sa.Data.Owner,
+			sa.Data.Description,
+			strings.Join(sa.Data.AppIds, listSeparator),
+			strings.Join(usedBy[sa.ResourceName], listSeparator),
+			file(sa.ResourceName),
+		})
+	}
+
+	return []Table{dataProducts, eventSpecs, entities, triggers, sourceApps}
+}
+
+// Write writes each table to dir as <name>.<format>, returning the files written
+func Write(tables []Table, dir string, format string) ([]string, error) {
+	comma := ','
+	switch format {
+	case "csv":
+	case "tsv":
+		comma = '\t'
+	default:
+		return nil, fmt.Errorf("unsupported format %s, expected one of %s", format, strings.Join(Formats, ", "))
+	}
+
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	files := []string{}
+	for _, t := range tables {
+		name := filepath.Join(dir, t.Name+"."+format)
+		f, err := os.Create(name)
+		if err != nil {
+			return files, err
+		}
+		w := csv.NewWriter(f)
+		w.Comma = comma
+		if err := w.Write(t.Header); err != nil {
+			f.Close()
+			return files, err
+		}
+		if err := w.WriteAll(t.Rows); err != nil {
+			f.Close()
+			return files, err
+		}
+		if err := f.Close(); err != nil {
+			return files, err
+		}
+		files = append(files, name)
+	}
+	return files, nil
+}
+
+// refIds are the source application ids ReadLocalDataProducts resolved refs to
+func refIds(refs []map[string]string) []string {
+	ids := []string{}
+	for _, r := range refs {
+		if id := r["id"]; id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// entityCells writes entities the way dp import reads them, `uri (min..max)`
+func entityCells(refs []model.SchemaRef) []string {
+	cells := []string{}
+	for _, e := range refs {
+		switch {
+		case e.MinCardinality == nil && e.MaxCardinality == nil:
+			cells = append(cells, e.Source)
+		case e.MaxCardinality == nil:
+			cells = append(cells, fmt.Sprintf("%s (%d..*)", e.Source, *e.MinCardinality))
+		case e.MinCardinality == nil:
+			cells = append(cells, fmt.Sprintf("%s (0..%d)", e.Source, *e.MaxCardinality))
+		case *e.MinCardinality == *e.MaxCardinality:
+			cells = append(cells, fmt.Sprintf("%s (%d)", e.Source, *e.MinCardinality))
+		default:
+			cells = append(cells, fmt.Sprintf("%s (%d..%d)", e.Source, *e.MinCardinality, *e.MaxCardinality))
+		}
+	}
+	return cells
+}
+
+func number(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.Itoa(*n)
+}
@@ -0,0 +1,166 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/imports"
+	"github.com/snowplow/snowplow-cli/internal/publish"
+)
+
+func testLocal(t *testing.T, dir string) *publish.LocalFilesRefsResolved {
+	sourceApp := func(id string, name string, appIds ...any) map[string]any {
+		return map[string]any{
+			"resourceType": "source-application",
+			"resourceName": id,
+			"data":         map[string]any{"name": name, "appIds": appIds},
+		}
+	}
+	files := map[string]map[string]any{
+		filepath.Join(dir, "source-apps", "web.yaml"): sourceApp("sa-web", "Web", "web", "shared"),
+		filepath.Join(dir, "source-apps", "ios.yaml"): sourceApp("sa-ios", "iOS", "ios", "shared"),
+		filepath.Join(dir, "shop.yaml"): {
+			"resourceType": "data-product",
+			"resourceName": "dp-shop",
+			"data": map[string]any{
+				"name":   "Shop",
+				"domain": "Commerce",
+				"sourceApplications": []any{
+					map[string]any{"$ref": "./source-apps/web.yaml"},
+					map[string]any{"$ref": "./source-apps/ios.yaml"},
+				},
+				"eventSpecifications": []any{map[string]any{
+					"resourceName":               "es-checkout",
+					"name":                       "Checkout",
+					"excludedSourceApplications": []any{map[string]any{"$ref": "./source-apps/ios.yaml"}},
+					"event":                      map[string]any{"source": "iglu:com.acme/checkout/jsonschema/1-0-0"},
+					"entities": map[string]any{
+						"tracked":  []any{map[string]any{"source": "iglu:com.acme/product/jsonschema/1-0-0", "minCardinality": 1}},
+						"enriched": []any{map[string]any{"source": "iglu:com.acme/geo/jsonschema/1-0-0"}},
+					},
+					"triggers": []any{map[string]any{"id": "t1", "description": "Click, then pay", "appIds": []any{"web"}}},
+				}},
+			},
+		},
+	}
+	local, err := publish.ReadLocalDataProducts(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return local
+}
+
+func Test_Tables(t *testing.T) {
+	dir := t.TempDir()
+	tables := Tables(testLocal(t, dir), dir)
+
+	expected := map[string][][]string{
+		"data-products": {
+			{"Shop", "Commerce", "", "", "Web; iOS", "1", "shop.yaml"},
+		},
+		"event-specifications": {
+			{"Shop", "Checkout", "", "iglu:com.acme/checkout/jsonschema/1-0-0", "iglu:com.acme/product/jsonschema/1-0-0 (1..*)", "Web", "iOS", "web; shared", "1"},
+		},
+		"entities": {
+			{"Shop", "Checkout", "iglu:com.acme/product/jsonschema/1-0-0", "tracked", "1", ""},
+			{"Shop", "Checkout", "iglu:com.acme/geo/jsonschema/1-0-0", "enriched", "", ""},
+		},
+		"triggers": {
+			{"Shop", "Checkout", "t1", "Click, then pay", "", "web", ""},
+		},
+		"source-applications": {
+			{"iOS", "", "", "ios; shared", "Shop", filepath.Join("source-apps", "ios.yaml")},
+			{"Web", "", "", "web; shared", "Shop", filepath.Join("source-apps", "web.yaml")},
+		},
+	}
+
+	if len(tables) != len(expected) {
+		t.Fatalf("expected %d tables got %d", len(expected), len(tables))
+	}
+	for _, table := range tables {
+		if !reflect.DeepEqual(table.Rows, expected[table.Name]) {
+			t.Errorf("%s:\n%q\nexpected\n%q", table.Name, table.Rows, expected[table.Name])
+		}
+		for _, row := range table.Rows {
+			if len(row) != len(table.Header) {
+				t.Errorf("%s: row %q does not match header %q", table.Name, row, table.Header)
+			}
+		}
+	}
+}
+
+func Test_TablesImportable(t *testing.T) {
+	dir := t.TempDir()
+	files, err := Write(Tables(testLocal(t, dir), dir), filepath.Join(dir, "out"), "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet, err := os.Open(files[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sheet.Close()
+
+	rows, err := imports.ReadRows(sheet, ',')
+	if err != nil {
+		t.Fatal(err)
+	}
+	min := 1
+	expected := []imports.Row{{
+		Line:        2,
+		DataProduct: "Shop",
+		Name:        "Checkout",
+		Event:       "iglu:com.acme/checkout/jsonschema/1-0-0",
+		Entities:    []imports.Entity{{Uri: "iglu:com.acme/product/jsonschema/1-0-0", Min: &min}},
+		Excluded:    []string{"iOS"},
+	}}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Fatalf("expected exported event specifications to import as they are\n%+v\n%+v", rows, expected)
+	}
+}
+
+func Test_Write(t *testing.T) {
+	dir := t.TempDir()
+	tables := []Table{{Name: "triggers", Header: []string{"Trigger", "Description"}, Rows: [][]string{{"t1", "Click, then \"pay\""}}}}
+
+	files, err := Write(tables, filepath.Join(dir, "out"), "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "Trigger,Description\nt1,\"Click, then \"\"pay\"\"\"\n" {
+		t.Fatalf("unexpected csv %q", content)
+	}
+
+	files, err = Write(tables, dir, "tsv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err = os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Ext(files[0]) != ".tsv" || string(content) != "Trigger\tDescription\nt1\t\"Click, then \"\"pay\"\"\"\n" {
+		t.Fatalf("unexpected tsv %s %q", files[0], content)
+	}
+
+	_, err = Write(tables, dir, "xlsx")
+	if err == nil {
+		t.Fatal("expected unsupported format to fail")
+	}
+}
@@ -0,0 +1,297 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package imports
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+// Row is an event specification drafted in a spreadsheet
+type Row struct {
+	// Line is where the row is in the file, the header being line 1
+	Line        int
+	DataProduct string
+	Name        string
+	Description string
+	Event       string
+	Entities    []Entity
+	// Excluded are source application names
+	Excluded []string
+}
+
+// Entity is a data structure reference with an optional cardinality, eg. `vendor/name (1..5)`
+type Entity struct {
+	Uri string
+	Min *int
+	Max *int
+}
+
+// columns maps normalised headers to the fields they fill, export headers are accepted as is
+var columns = map[string]string{
+	"dataproduct":                "data product",
+	"eventspecification":         "name",
+	"name":                       "name",
+	"description":                "description",
+	"event":                      "event",
+	"eventschema":                "event",
+	"entities":                   "entities",
+	"trackedentities":            "entities",
+	"excludedsourceapplications": "excluded",
+}
+
+var nonHeader = regexp.MustCompile(`[^a-z]`)
+
+// ReadRows parses rows of event specifications, lists in a cell are separated by ; or new lines
+func ReadRows(r io.Reader, comma rune) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[int]string{}
+	for i, h := range header {
+		if f, ok := columns[nonHeader.ReplaceAllString(strings.ToLower(h), "")]; ok {
+			fields[i] = f
+		}
+	}
+	found := false
+	for _, f := range fields {
+		found = found || f == "name"
+	}
+	if !found {
+		return nil, errors.New(`missing "Event specification" column`)
+	}
+
+	rows := []Row{}
+	errs := []error{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		row := Row{Line: line}
+		empty := true
+		for i, v := range record {
+			v = strings.TrimSpace(v)
+			empty = empty && v == ""
+			switch fields[i] {
+			case "data product":
+				row.DataProduct = v
+			case "name":
+				row.Name = v
+			case "description":
+				row.Description = v
+			case "event":
+				row.Event = v
+			case "entities":
+				for _, item := range list(v) {
+					e, err := parseEntity(item)
+					if err != nil {
+						errs = append(errs, row.errorf("%w", err))
+					}
+					row.Entities = append(row.Entities, e)
+				}
+			case "excluded":
+				row.Excluded = list(v)
+			}
+		}
+		if empty {
+			continue
+		}
+		if row.Name == "" {
+			errs = append(errs, row.errorf("missing event specification name"))
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, errors.Join(errs...)
+}
+
+func list(cell string) []string {
+	res := []string{}
+	for _, item := range strings.FieldsFunc(cell, func(r rune) bool { return r == ';' || r == '\n' }) {
+		if item = strings.TrimSpace(item); item != "" {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+var entityCardinality = regexp.MustCompile(`^(\S+)\s*\((.*)\)$`)
+
+var cardinality = regexp.MustCompile(`^([0-9]+)(?:\s*\.\.\s*([0-9]+|\*))?$`)
+
+// parseEntity reads `uri` or `uri (cardinality)`
+func parseEntity(s string) (Entity, error) {
+	if !strings.Contains(s, "(") {
+		return Entity{Uri: s}, nil
+	}
+	m := entityCardinality.FindStringSubmatch(s)
+	if m == nil {
+		return Entity{Uri: strings.TrimSpace(strings.SplitN(s, "(", 2)[0])}, fmt.Errorf("invalid entity %s, expected uri (cardinality)", s)
+	}
+	e := Entity{Uri: m[1]}
+	min, max, err := ParseCardinality(m[2])
+	if err != nil {
+		return e, fmt.Errorf("invalid cardinality in %s: %w", s, err)
+	}
+	e.Min, e.Max = min, max
+	return e, nil
+}
+
+// ParseCardinality reads n, min..max or min..* where max is left nil
+func ParseCardinality(s string) (min *int, max *int, err error) {
+	m := cardinality.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return nil, nil, errors.New("expected n, min..max or min..*")
+	}
+	lower, _ := strconv.Atoi(m[1])
+	switch m[2] {
+	case "":
+		upper := lower
+		return &lower, &upper, nil
+	case "*":
+		return &lower, nil, nil
+	}
+	upper, _ := strconv.Atoi(m[2])
+	if upper < lower {
+		return nil, nil, errors.New("max is less than min")
+	}
+	return &lower, &upper, nil
+}
+
+// CheckEntities rejects an entity listed again with a different cardinality, listing it again as is does nothing
+func (r Row) CheckEntities() error {
+	seen := map[string]Entity{}
+	errs := []error{}
+	for _, e := range r.Entities {
+		first, ok := seen[e.Uri]
+		if !ok {
+			seen[e.Uri] = e
+			continue
+		}
+		if !sameCardinality(first.Min, e.Min) || !sameCardinality(first.Max, e.Max) {
+			errs = append(errs, r.errorf("entity %s is listed with different cardinalities", e.Uri))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func sameCardinality(a *int, b *int) bool {
+	return a == nil && b == nil || a != nil && b != nil && *a == *b
+}
+
+// errorf prefixes errors about rows read from a file with their line
+func (r Row) errorf(format string, a ...any) error {
+	err := fmt.Errorf(format, a...)
+	if r.Line > 0 {
+		return fmt.Errorf("line %d: %w", r.Line, err)
+	}
+	return err
+}
+
+// Resolver turns a data structure reference from a spreadsheet into an iglu uri
+type Resolver func(ref string) (string, error)
+
+// EventSpecs converts rows for the data product into event specifications.
+// sourceApps maps the names of the data product's source applications to their refs.
+// Rows naming an existing event specification are skipped and listed
+func EventSpecs(rows []Row, dp model.DataProductCanonicalData, sourceApps map[string]model.Ref, resolve Resolver) (added []model.EventSpecCanonical, skipped []Row, err error) {
+	existing := map[string]bool{}
+	for _, es := range dp.EventSpecifications {
+		existing[strings.ToLower(es.Name)] = true
+	}
+
+	errs := []error{}
+	seen := map[string]int{}
+	for _, row := range rows {
+		if row.DataProduct != "" && !strings.EqualFold(row.DataProduct, dp.Name) {
+			continue
+		}
+		key := strings.ToLower(row.Name)
+		if existing[key] {
+			skipped = append(skipped, row)
+			continue
+		}
+		if line, ok := seen[key]; ok {
+			errs = append(errs, row.errorf("%s is already on line %d", row.Name, line))
+			continue
+		}
+		seen[key] = row.Line
+
+		es := model.EventSpecCanonical{
+			ResourceName: uuid.NewString(),
+			Name:         row.Name,
+			Description:  row.Description,
+			Entities:     model.EntitiesDef{Tracked: []model.SchemaRef{}, Enriched: []model.SchemaRef{}},
+		}
+
+		if row.Event != "" {
+			uri, err := resolve(row.Event)
+			if err != nil {
+				errs = append(errs, row.errorf("event: %w", err))
+			}
+			es.Event = model.SchemaRef{Source: uri}
+		}
+
+		tracked := map[string]Entity{}
+		for _, e := range row.Entities {
+			uri, err := resolve(e.Uri)
+			if err != nil {
+				errs = append(errs, row.errorf("entity: %w", err))
+			}
+			if first, ok := tracked[uri]; ok && uri != "" {
+				if !sameCardinality(first.Min, e.Min) || !sameCardinality(first.Max, e.Max) {
+					errs = append(errs, row.errorf("entity %s is listed with different cardinalities", uri))
+				}
+				continue
+			}
+			tracked[uri] = e
+			es.Entities.Tracked = append(es.Entities.Tracked, model.SchemaRef{Source: uri, MinCardinality: e.Min, MaxCardinality: e.Max})
+		}
+
+		for _, name := range row.Excluded {
+			ref, ok := sourceApps[strings.ToLower(name)]
+			if !ok {
+				errs = append(errs, row.errorf("%s is not a source application of %s", name, dp.Name))
+				continue
+			}
+			es.ExcludedSourceApplications = append(es.ExcludedSourceApplications, ref)
+		}
+
+		added = append(added, es)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, nil, err
+	}
+	return added, skipped, nil
+}
@@ -0,0 +1,208 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package imports
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+func intp(i int) *int {
+	return &i
+}
+
+func Test_ReadRows(t *testing.T) {
+	sheet := "Event specification,Tracked entities,event_schema,Notes\n" +
+		"Checkout,\"com.acme/product (1..*); com.acme/user (1)\ncom.acme/cart (0..3)\",com.acme/checkout,ignored\n" +
+		",,,\n" +
+		"Cart,,,\n"
+
+	rows, err := ReadRows(strings.NewReader(sheet), ',')
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Row{
+		{Line: 2, Name: "Checkout", Event: "com.acme/checkout", Entities: []Entity{
+			{Uri: "com.acme/product", Min: intp(1)},
+			{Uri: "com.acme/user", Min: intp(1), Max: intp(1)},
+			{Uri: "com.acme/cart", Min: intp(0), Max: intp(3)},
+		}},
+		{Line: 4, Name: "Cart"},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Fatalf("unexpected rows\n%+v\n%+v", rows, expected)
+	}
+}
+
+func Test_ReadRowsErrors(t *testing.T) {
+	_, err := ReadRows(strings.NewReader("Description\nnothing\n"), ',')
+	if err == nil {
+		t.Fatal("expected missing name column to fail")
+	}
+
+	_, err = ReadRows(strings.NewReader(""), ',')
+	if err == nil {
+		t.Fatal("expected empty file to fail")
+	}
+
+	sheet := "Event specification\tEntities\n" +
+		"\tcom.acme/product\n" +
+		"Checkout\tcom.acme/product (5..1); com.acme/user (many)\n"
+	_, err = ReadRows(strings.NewReader(sheet), '\t')
+	for _, want := range []string{"line 2: missing event specification name", "line 3: invalid cardinality in com.acme/product (5..1)", "line 3: invalid cardinality in com.acme/user (many)"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+}
+
+func Test_EventSpecs(t *testing.T) {
+	dp := model.DataProductCanonicalData{
+		Name:                "Shop",
+		EventSpecifications: []model.EventSpecCanonical{{Name: "Checkout"}},
+	}
+	web := model.Ref{Ref: "./source-apps/web.yaml"}
+	resolve := func(ref string) (string, error) {
+		if strings.HasPrefix(ref, "iglu:") {
+			return ref, nil
+		}
+		return "iglu:" + ref + "/jsonschema/1-0-0", nil
+	}
+	rows := []Row{
+		{Line: 2, DataProduct: "shop", Name: "checkout"},
+		{Line: 3, DataProduct: "Admin", Name: "Login"},
+		{Line: 4, Name: "Cart", Event: "com.acme/cart", Entities: []Entity{{Uri: "com.acme/product", Min: intp(1)}, {Uri: "iglu:com.acme/product/jsonschema/1-0-0", Min: intp(1)}}, Excluded: []string{"WEB"}},
+	}
+
+	added, skipped, err := EventSpecs(rows, dp, map[string]model.Ref{"web": web}, resolve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped) != 1 || skipped[0].Line != 2 {
+		t.Fatalf("expected existing event specification to be skipped got %+v", skipped)
+	}
+	if len(added) != 1 {
+		t.Fatalf("expected one event specification got %+v", added)
+	}
+	cart := added[0]
+	if cart.ResourceName == "" || cart.Name != "Cart" || cart.Event.Source != "iglu:com.acme/cart/jsonschema/1-0-0" {
+		t.Fatalf("unexpected event specification %+v", cart)
+	}
+	expected := []model.SchemaRef{{Source: "iglu:com.acme/product/jsonschema/1-0-0", MinCardinality: intp(1)}}
+	if !reflect.DeepEqual(cart.Entities.Tracked, expected) || !reflect.DeepEqual(cart.ExcludedSourceApplications, []model.Ref{web}) {
+		t.Fatalf("unexpected event specification %+v", cart)
+	}
+
+	rows = []Row{
+		{Line: 2, Name: "Cart", Excluded: []string{"iOS"}},
+		{Line: 3, Name: "cart"},
+		{Line: 4, Name: "Pay", Event: "bad"},
+	}
+	failing := func(ref string) (string, error) {
+		return ref, errors.New("not found")
+	}
+	_, _, err = EventSpecs(rows, dp, map[string]model.Ref{}, failing)
+	for _, want := range []string{"line 2: iOS is not a source application of Shop", "line 3: cart is already on line 2", "line 4: event: not found"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+}
+
+func Test_CheckEntities(t *testing.T) {
+	row := Row{Line: 2, Name: "Cart", Entities: []Entity{
+		{Uri: "com.acme/product", Min: intp(1)},
+		{Uri: "com.acme/user"},
+		{Uri: "com.acme/product", Min: intp(1)},
+		{Uri: "com.acme/user"},
+	}}
+	if err := row.CheckEntities(); err != nil {
+		t.Fatalf("expected entities listed again as is to be accepted got %v", err)
+	}
+
+	row.Entities = append(row.Entities, Entity{Uri: "com.acme/product", Min: intp(1), Max: intp(1)})
+	err := row.CheckEntities()
+	if err == nil || !strings.Contains(err.Error(), "line 2: entity com.acme/product is listed with different cardinalities") {
+		t.Fatalf("expected conflicting cardinalities to fail got %v", err)
+	}
+}
+
+type fakeChecker struct {
+	deployed map[string][]string
+	calls    int
+}
+
+func (f *fakeChecker) IsDSDeployed(uri string) (bool, []string, error) {
+	f.calls++
+	versions, ok := f.deployed[uri]
+	return ok && versions == nil, versions, nil
+}
+
+func Test_Resolver(t *testing.T) {
+	locals := []model.DataStructureSelf{
+		{Vendor: "com.acme", Name: "product", Format: "jsonschema", Version: "1-0-10"},
+		{Vendor: "com.acme", Name: "product", Format: "jsonschema", Version: "1-0-9"},
+	}
+	fake := &fakeChecker{deployed: map[string][]string{
+		"iglu:com.acme/user/jsonschema/1-0-0": nil,
+		"iglu:com.acme/cart/jsonschema/2-0-0": {"1-0-0"},
+	}}
+	created := 0
+	resolve := NewResolver(locals, func() (console.SchemaDeployChecker, error) {
+		created++
+		return fake, nil
+	})
+
+	cases := []struct {
+		ref, uri, err string
+	}{
+		{"com.acme/product", "iglu:com.acme/product/jsonschema/1-0-10", ""},
+		{"iglu:com.acme/product/jsonschema/1-0-9", "iglu:com.acme/product/jsonschema/1-0-9", ""},
+		{"com.acme/user", "", "not a local data structure"},
+		{"iglu:com.acme/user", "", "invalid iglu uri"},
+		{"iglu:com.acme/user/jsonschema/1-0-0", "iglu:com.acme/user/jsonschema/1-0-0", ""},
+		{"iglu:com.acme/cart/jsonschema/2-0-0", "", "available versions (1-0-0)"},
+	}
+	for _, c := range cases {
+		uri, err := resolve(c.ref)
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("%s: expected %q got %v", c.ref, c.err, err)
+			}
+			continue
+		}
+		if err != nil || uri != c.uri {
+			t.Errorf("%s: expected %s got %s %v", c.ref, c.uri, uri, err)
+		}
+	}
+	if created != 1 || fake.calls != 2 {
+		t.Fatalf("expected the checker to be created once and only asked about remote uris got %d %d", created, fake.calls)
+	}
+
+	offline := NewResolver(nil, func() (console.SchemaDeployChecker, error) {
+		created++
+		return nil, errors.New("no console")
+	})
+	for i := 0; i < 2; i++ {
+		if _, err := offline("iglu:com.acme/user/jsonschema/1-0-0"); err == nil || !strings.Contains(err.Error(), "no console") {
+			t.Fatalf("expected checker error got %v", err)
+		}
+	}
+	if created != 2 {
+		t.Fatal("expected a failing checker to be created once")
+	}
+}
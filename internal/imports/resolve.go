@@ -0,0 +1,86 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package imports
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+var igluUri = regexp.MustCompile(`^iglu:[a-zA-Z0-9-_.]+/[a-zA-Z0-9-_]+/[a-zA-Z0-9-_]+/[0-9]+-[0-9]+-[0-9]+$`)
+
+// NewResolver resolves references against local data structures first.
+// `vendor/name` picks the latest local version, full iglu uris that are not local
+// are checked for a deployment with the checker, which is only created when needed
+func NewResolver(locals []model.DataStructureSelf, checker func() (console.SchemaDeployChecker, error)) Resolver {
+	uris := map[string]bool{}
+	latest := map[string]model.DataStructureSelf{}
+	for _, s := range locals {
+		uris[s.IgluUri()] = true
+		key := s.Vendor + "/" + s.Name
+		if l, ok := latest[key]; !ok || newer(s.Version, l.Version) {
+			latest[key] = s
+		}
+	}
+
+	var sdc console.SchemaDeployChecker
+	var sdcErr error
+	return func(ref string) (string, error) {
+		if !strings.HasPrefix(ref, "iglu:") {
+			if s, ok := latest[ref]; ok {
+				return s.IgluUri(), nil
+			}
+			return ref, fmt.Errorf("%s is not a local data structure, use a full iglu uri for remote ones", ref)
+		}
+		if !igluUri.MatchString(ref) {
+			return ref, fmt.Errorf("invalid iglu uri %s should follow the format iglu:vendor/name/format/version", ref)
+		}
+		if uris[ref] {
+			return ref, nil
+		}
+
+		if sdc == nil && sdcErr == nil {
+			sdc, sdcErr = checker()
+		}
+		if sdcErr != nil {
+			return ref, fmt.Errorf("could not check deployment of %s: %w", ref, sdcErr)
+		}
+		found, alternatives, err := sdc.IsDSDeployed(ref)
+		if err != nil {
+			return ref, fmt.Errorf("error while resolving %s: %w", ref, err)
+		}
+		if !found {
+			if len(alternatives) > 0 {
+				return ref, fmt.Errorf("could not find deployment of %s, available versions (%s)", ref, strings.Join(alternatives, ", "))
+			}
+			return ref, fmt.Errorf("could not find deployment of %s", ref)
+		}
+		return ref, nil
+	}
+}
+
+// newer compares model-revision-addition versions
+func newer(a string, b string) bool {
+	as, bs := strings.Split(a, "-"), strings.Split(b, "-")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		x, _ := strconv.Atoi(as[i])
+		y, _ := strconv.Atoi(bs[i])
+		if x != y {
+			return x > y
+		}
+	}
+	return len(as) > len(bs)
+}
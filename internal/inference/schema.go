@@ -0,0 +1,325 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+// Package inference derives draft-04 JSON Schemas from sample payloads
+package inference
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// strings with at most this many distinct values, each seen at least twice on average, become enums
+const maxEnumValues = 10
+
+// Schema is the subset of draft-04 an inferred schema uses, in the order it is written
+type Schema struct {
+	Type                 any                `json:"type,omitempty" yaml:"type,omitempty"`
+	Format               string             `json:"format,omitempty" yaml:"format,omitempty"`
+	Enum                 []any              `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty" yaml:"maximum,omitempty"`
+	Items                *Schema            `json:"items,omitempty" yaml:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty" yaml:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+}
+
+// observed accumulates every value seen at one location across samples
+type observed struct {
+	types map[string]bool
+
+	count   int
+	strings map[string]int
+	formats map[string]bool
+	min     float64
+	max     float64
+
+	objects    int
+	properties map[string]*observed
+	present    map[string]int
+
+	items *observed
+}
+
+func newObserved() *observed {
+	return &observed{
+		types:      map[string]bool{},
+		strings:    map[string]int{},
+		formats:    map[string]bool{},
+		min:        math.Inf(1),
+		max:        math.Inf(-1),
+		properties: map[string]*observed{},
+		present:    map[string]int{},
+	}
+}
+
+func (o *observed) add(v any) {
+	o.count++
+	switch v := v.(type) {
+	case nil:
+		o.types["null"] = true
+	case bool:
+		o.types["boolean"] = true
+	case json.Number:
+		o.addNumber(v)
+	case float64:
+		o.addNumber(json.Number(strconv.FormatFloat(v, 'f', -1, 64)))
+	case string:
+		o.types["string"] = true
+		o.strings[v]++
+		o.formats[stringFormat(v)] = true
+	case []any:
+		o.types["array"] = true
+		if o.items == nil {
+			o.items = newObserved()
+		}
+		for _, i := range v {
+			o.items.add(i)
+		}
+	case map[string]any:
+		o.types["object"] = true
+		o.objects++
+		for k, pv := range v {
+			p, ok := o.properties[k]
+			if !ok {
+				p = newObserved()
+				o.properties[k] = p
+			}
+			p.add(pv)
+			o.present[k]++
+		}
+	}
+}
+
+func (o *observed) addNumber(n json.Number) {
+	if _, err := n.Int64(); err == nil {
+		o.types["integer"] = true
+	} else {
+		o.types["number"] = true
+	}
+	if f, err := n.Float64(); err == nil {
+		o.min = math.Min(o.min, f)
+		o.max = math.Max(o.max, f)
+	}
+}
+
+var (
+	uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	uriRegexp  = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*://`)
+)
+
+// stringFormat guesses the format of a single string, "" when it has none
+func stringFormat(s string) string {
+	switch {
+	case uuidRegexp.MatchString(s):
+		return "uuid"
+	case isDateTime(s):
+		return "date-time"
+	case uriRegexp.MatchString(s) && isUri(s):
+		return "uri"
+	case strings.Contains(s, "@") && isEmail(s):
+		return "email"
+	}
+	if addr, err := netip.ParseAddr(s); err == nil {
+		if addr.Is4() {
+			return "ipv4"
+		}
+		return "ipv6"
+	}
+	return ""
+}
+
+func isDateTime(s string) bool {
+	_, err := time.Parse(time.RFC3339Nano, s)
+	return err == nil
+}
+
+func isUri(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Host != ""
+}
+
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
+
+// onlyStrings is true when every non null value was a string
+func (o *observed) onlyStrings() bool {
+	for t := range o.types {
+		if t != "string" && t != "null" {
+			return false
+		}
+	}
+	return true
+}
+
+// lowCardinality is true when few distinct strings were each seen repeatedly
+func (o *observed) lowCardinality() bool {
+	seen := 0
+	for _, c := range o.strings {
+		seen += c
+	}
+	return len(o.strings) <= maxEnumValues && seen >= 2*len(o.strings)
+}
+
+// typeOrder keeps type lists stable with null last
+var typeOrder = []string{"object", "array", "string", "number", "integer", "boolean", "null"}
+
+func (o *observed) schema() *Schema {
+	s := &Schema{}
+
+	types := []string{}
+	for _, t := range typeOrder {
+		if o.types[t] {
+			types = append(types, t)
+		}
+	}
+	// every integer is a number
+	if o.types["integer"] && o.types["number"] {
+		types = slices.DeleteFunc(types, func(t string) bool { return t == "integer" })
+	}
+	switch len(types) {
+	case 0:
+	case 1:
+		s.Type = types[0]
+	default:
+		s.Type = types
+	}
+
+	if o.types["string"] {
+		if len(o.formats) == 1 && !o.formats[""] {
+			for f := range o.formats {
+				s.Format = f
+			}
+		}
+		if s.Format == "" && o.onlyStrings() && o.lowCardinality() {
+			values := make([]string, 0, len(o.strings))
+			for v := range o.strings {
+				values = append(values, v)
+			}
+			sort.Strings(values)
+			for _, v := range values {
+				s.Enum = append(s.Enum, v)
+			}
+			if o.types["null"] {
+				s.Enum = append(s.Enum, nil)
+			}
+		}
+	}
+
+	if o.types["integer"] || o.types["number"] {
+		min, max := o.min, o.max
+		s.Minimum = &min
+		s.Maximum = &max
+	}
+
+	if o.items != nil && o.items.count > 0 {
+		s.Items = o.items.schema()
+	}
+
+	if o.types["object"] {
+		s.Properties = map[string]*Schema{}
+		for k, p := range o.properties {
+			s.Properties[k] = p.schema()
+			if o.present[k] == o.objects {
+				s.Required = append(s.Required, k)
+			}
+		}
+		sort.Strings(s.Required)
+		closed := false
+		s.AdditionalProperties = &closed
+	}
+
+	return s
+}
+
+// InferSchema derives a schema accepting every sample
+func InferSchema(samples []any) (*Schema, error) {
+	if len(samples) == 0 {
+		return nil, errors.New("no samples to infer a schema from")
+	}
+	o := newObserved()
+	for _, s := range samples {
+		o.add(s)
+	}
+	return o.schema(), nil
+}
+
+// ReadSamples reads json payloads from files. A file may hold one value per line (ndjson),
+// a single value or an array of values. Self describing payloads are unwrapped to their data
+func ReadSamples(paths []string) ([]any, error) {
+	samples := []any{}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		fileSamples, err := decodeSamples(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("file: %s: %w", path, err)
+		}
+		samples = append(samples, fileSamples...)
+	}
+	return samples, nil
+}
+
+func decodeSamples(r io.Reader) ([]any, error) {
+	samples := []any{}
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	for {
+		var v any
+		err := dec.Decode(&v)
+		if err == io.EOF {
+			return samples, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("sample %d: %w", len(samples)+1, err)
+		}
+		if values, ok := v.([]any); ok {
+			for _, v := range values {
+				samples = append(samples, unwrapSelfDescribing(v))
+			}
+		} else {
+			samples = append(samples, unwrapSelfDescribing(v))
+		}
+	}
+}
+
+func unwrapSelfDescribing(v any) any {
+	m, ok := v.(map[string]any)
+	if !ok || len(m) != 2 {
+		return v
+	}
+	schema, ok := m["schema"].(string)
+	if !ok || !strings.HasPrefix(schema, "iglu:") {
+		return v
+	}
+	if data, ok := m["data"]; ok {
+		return data
+	}
+	return v
+}
@@ -0,0 +1,205 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package inference
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func samples(t *testing.T, ndjson string) []any {
+	res, err := decodeSamples(strings.NewReader(ndjson))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func infer(t *testing.T, ndjson string) *Schema {
+	s, err := InferSchema(samples(t, ndjson))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func Test_stringFormat(t *testing.T) {
+	table := map[string]string{
+		"3f1c2a4e-6b7d-4c8e-9f0a-1b2c3d4e5f60": "uuid",
+		"2024-01-02T03:04:05Z":                 "date-time",
+		"2024-01-02T03:04:05.123+01:00":        "date-time",
+		"https://acme.com/login?a=b":           "uri",
+		"someone@acme.com":                     "email",
+		"10.0.0.1":                             "ipv4",
+		"2001:db8::1":                          "ipv6",
+		"2024-01-02":                           "",
+		"hello":                                "",
+		"mailto:someone@acme.com":              "",
+	}
+	for value, want := range table {
+		if got := stringFormat(value); got != want {
+			t.Errorf("%s got %q want %q", value, got, want)
+		}
+	}
+}
+
+func Test_InferSchema_Required(t *testing.T) {
+	s := infer(t, `{"a":1,"b":"x","nested":{"c":true}}
+{"a":2,"nested":{"c":false,"d":null}}`)
+
+	if s.Type != "object" {
+		t.Errorf("unexpected type %v", s.Type)
+	}
+	if !reflect.DeepEqual(s.Required, []string{"a", "nested"}) {
+		t.Errorf("unexpected required %v", s.Required)
+	}
+	if !reflect.DeepEqual(s.Properties["nested"].Required, []string{"c"}) {
+		t.Errorf("unexpected nested required %v", s.Properties["nested"].Required)
+	}
+	if *s.AdditionalProperties || *s.Properties["nested"].AdditionalProperties {
+		t.Error("expected objects to be closed")
+	}
+	if s.Properties["nested"].Properties["d"].Type != "null" {
+		t.Errorf("unexpected null type %v", s.Properties["nested"].Properties["d"].Type)
+	}
+}
+
+func Test_InferSchema_Numbers(t *testing.T) {
+	s := infer(t, `{"i":3,"n":1,"big":1e40}
+{"i":-2,"n":2.5,"big":1}`)
+
+	i := s.Properties["i"]
+	if i.Type != "integer" || *i.Minimum != -2 || *i.Maximum != 3 {
+		t.Errorf("unexpected integer schema %+v", i)
+	}
+	n := s.Properties["n"]
+	if n.Type != "number" || *n.Minimum != 1 || *n.Maximum != 2.5 {
+		t.Errorf("unexpected number schema %+v", n)
+	}
+	if big := s.Properties["big"]; big.Type != "number" || *big.Maximum != 1e40 {
+		t.Errorf("unexpected big number schema %+v", big)
+	}
+}
+
+func Test_InferSchema_Enums(t *testing.T) {
+	s := infer(t, `{"low":"a","high":"1","nullable":"x","mixed":"a","single":"only"}
+{"low":"b","high":"2","nullable":null,"mixed":1}
+{"low":"a","high":"3","nullable":"x","mixed":"a"}
+{"low":"b","high":"4","nullable":"x","mixed":"a"}`)
+
+	if !reflect.DeepEqual(s.Properties["low"].Enum, []any{"a", "b"}) {
+		t.Errorf("expected an enum got %v", s.Properties["low"].Enum)
+	}
+	if s.Properties["high"].Enum != nil {
+		t.Errorf("expected no enum for distinct values got %v", s.Properties["high"].Enum)
+	}
+	if !reflect.DeepEqual(s.Properties["nullable"].Enum, []any{"x", nil}) {
+		t.Errorf("expected a nullable enum got %v", s.Properties["nullable"].Enum)
+	}
+	if !reflect.DeepEqual(s.Properties["nullable"].Type, []string{"string", "null"}) {
+		t.Errorf("unexpected nullable type %v", s.Properties["nullable"].Type)
+	}
+	if s.Properties["mixed"].Enum != nil {
+		t.Errorf("expected no enum with mixed types got %v", s.Properties["mixed"].Enum)
+	}
+	if s.Properties["single"].Enum != nil {
+		t.Errorf("expected no enum from a single value got %v", s.Properties["single"].Enum)
+	}
+}
+
+func Test_InferSchema_Formats(t *testing.T) {
+	s := infer(t, `{"id":"3f1c2a4e-6b7d-4c8e-9f0a-1b2c3d4e5f60","mixed":"https://acme.com"}
+{"id":"4f1c2a4e-6b7d-4c8e-9f0a-1b2c3d4e5f60","mixed":"not a url"}`)
+
+	if s.Properties["id"].Format != "uuid" {
+		t.Errorf("expected uuid format got %q", s.Properties["id"].Format)
+	}
+	if s.Properties["mixed"].Format != "" {
+		t.Errorf("expected no format when values disagree got %q", s.Properties["mixed"].Format)
+	}
+}
+
+func Test_InferSchema_Arrays(t *testing.T) {
+	s := infer(t, `{"tags":["a"],"points":[{"x":1},{"x":2,"y":3}],"empty":[]}`)
+
+	if s.Properties["tags"].Type != "array" || s.Properties["tags"].Items.Type != "string" {
+		t.Errorf("unexpected tags schema %+v", s.Properties["tags"])
+	}
+	points := s.Properties["points"].Items
+	if points.Type != "object" || !reflect.DeepEqual(points.Required, []string{"x"}) {
+		t.Errorf("unexpected points items %+v", points)
+	}
+	if s.Properties["empty"].Items != nil {
+		t.Errorf("expected no items schema for empty arrays got %+v", s.Properties["empty"].Items)
+	}
+}
+
+func Test_InferSchema_Json(t *testing.T) {
+	s := infer(t, `{"a":"x"}`)
+	out, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"type":"object","properties":{"a":{"type":"string"}},"required":["a"],"additionalProperties":false}`
+	if string(out) != want {
+		t.Errorf("got %s want %s", out, want)
+	}
+}
+
+func Test_InferSchema_NoSamples(t *testing.T) {
+	_, err := InferSchema([]any{})
+	if err == nil {
+		t.Error("expected an error without samples")
+	}
+}
+
+func Test_ReadSamples(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"lines.ndjson": "{\"a\":1}\n\n{\"a\":2}\n",
+		"array.json":   `[{"a":3},{"a":4}]`,
+		"sd.json":      `{"schema":"iglu:com.acme/e/jsonschema/1-0-0","data":{"a":5}}`,
+	}
+	paths := []string{}
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, p)
+	}
+
+	res, err := ReadSamples(paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 5 {
+		t.Fatalf("expected 5 samples got %d: %v", len(res), res)
+	}
+	for _, r := range res {
+		if _, ok := r.(map[string]any)["a"]; !ok {
+			t.Errorf("expected unwrapped sample got %v", r)
+		}
+	}
+
+	broken := filepath.Join(dir, "broken.ndjson")
+	if err := os.WriteFile(broken, []byte("{\"a\":1}\n{oops}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = ReadSamples([]string{broken})
+	if err == nil || !strings.Contains(err.Error(), "sample 2") {
+		t.Errorf("expected the broken sample to be reported got %v", err)
+	}
+}
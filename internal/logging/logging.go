@@ -20,6 +20,9 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// LogToStderr marks commands writing their output to stdout, their logs go to stderr instead
+const LogToStderr = "snowplow-cli/log-to-stderr"
+
 func InitLogging(cmd *cobra.Command) error {
 
 	debug, err := cmd.Flags().GetBool("debug")
@@ -47,14 +50,19 @@ func InitLogging(cmd *cobra.Command) error {
 		return nil
 	}
 
-	handler := log.NewWithOptions(os.Stdout, log.Options{
+	out := os.Stdout
+	if _, ok := cmd.Annotations[LogToStderr]; ok {
+		out = os.Stderr
+	}
+
+	handler := log.NewWithOptions(out, log.Options{
 		ReportTimestamp: true,
 		TimeFormat:      time.Kitchen,
 	})
 
 	var logger *slog.Logger
 	if json {
-		logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+		logger = slog.New(slog.NewJSONHandler(out, nil))
 	} else {
 		logger = slog.New(handler)
 	}
@@ -74,20 +82,3 @@ func InitLogging(cmd *cobra.Command) error {
 	return nil
 
 }
-
-func LogFatal(err error) {
-	slog.Error(err.Error())
-	os.Exit(1)
-}
-
-func LogFatalMsg(msg string, err error) {
-	slog.Error(msg, "error", err.Error()+"\n")
-	os.Exit(1)
-}
-
-func LogFatalMultiple(errs []error) {
-	for _, e := range errs {
-		slog.Error(e.Error())
-	}
-	os.Exit(1)
-}
@@ -16,3 +16,14 @@ type CliResource[A any] struct {
 	ResourceName string `yaml:"resourceName" json:"resourceName"`
 	Data         A      `yaml:"data" json:"data"`
 }
+
+// IgnoreKey annotates resources with the validation findings accepted for them
+const IgnoreKey = "x-snowplow-cli-ignore"
+
+// Ignore suppresses findings of a lint rule or policy, or findings with messages matching a regular expression.
+// It is local to snowplow-cli, never part of what is sent to Console
+type Ignore struct {
+	Rule    string `yaml:"rule,omitempty" json:"rule,omitempty" mapstructure:"rule"`
+	Message string `yaml:"message,omitempty" json:"message,omitempty" mapstructure:"message"`
+	Reason  string `yaml:"reason" json:"reason" mapstructure:"reason"`
+}
@@ -50,6 +50,9 @@ type DataProductChangeSet struct {
 	esDelete     []console.RemoteEventSpec
 	imageCreate  []TriggerImageReference
 	IdToFileName map[string]string
+	saRemote     map[string]console.RemoteSourceApplication
+	dpRemote     map[string]console.RemoteDataProduct
+	esRemote     map[string]console.RemoteEventSpec
 }
 
 func (cs DataProductChangeSet) isEmpty() bool {
@@ -292,6 +295,9 @@ func findChanges(local LocalFilesRefsResolved, remote console.DataProductsAndRel
 		esDelete:     esDelete,
 		imageCreate:  imageCreate,
 		IdToFileName: idToFileName,
+		saRemote:     saRemoteIds,
+		dpRemote:     dpRemoteIds,
+		esRemote:     esRemoteIds,
 	}, nil
 }
 
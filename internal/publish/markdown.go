@@ -0,0 +1,163 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+package publish
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/r3labs/diff/v3"
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/util"
+)
+
+type triggerDiff struct {
+	Id          string
+	Description string
+	AppIds      []string
+	Url         string
+}
+
+func triggersToDiff(ts []console.RemoteTrigger) []triggerDiff {
+	res := []triggerDiff{}
+	for _, t := range ts {
+		res = append(res, triggerDiff{t.Id, t.Description, t.AppIds, t.Url})
+	}
+	return res
+}
+
+func MarkdownChangeset(b *strings.Builder, changes DataProductChangeSet, basePath string) error {
+	b.WriteString("### Data products\n\n")
+
+	if changes.isEmpty() {
+		b.WriteString("No changes detected.\n\n")
+		return nil
+	}
+
+	b.WriteString("| Resource | Create | Update | Delete |\n|---|---|---|---|\n")
+	fmt.Fprintf(b, "| source applications | %d | %d | 0 |\n", len(changes.saCreate), len(changes.saUpdate))
+	fmt.Fprintf(b, "| data products | %d | %d | 0 |\n", len(changes.dpCreate), len(changes.dpUpdate))
+	fmt.Fprintf(b, "| event specifications | %d | %d | %d |\n", len(changes.esCreate), len(changes.esUpdate), len(changes.esDelete))
+	fmt.Fprintf(b, "| images | %d | 0 | 0 |\n\n", len(changes.imageCreate))
+
+	file := func(id string) string {
+		return markdownRelPath(basePath, changes.IdToFileName[id])
+	}
+
+	if len(changes.saCreate)+len(changes.saUpdate) > 0 {
+		b.WriteString("#### Source applications\n\n")
+		for _, sa := range changes.saCreate {
+			if err := markdownCreate(b, sa.Name, sa.Id, file(sa.Id), sa); err != nil {
+				return err
+			}
+		}
+		for _, sa := range changes.saUpdate {
+			changelog, err := diff.Diff(saToDiff(changes.saRemote[sa.Id]), saToDiff(sa))
+			if err != nil {
+				return err
+			}
+			markdownUpdate(b, sa.Name, sa.Id, file(sa.Id), changelog)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(changes.dpCreate)+len(changes.dpUpdate) > 0 {
+		b.WriteString("#### Data products\n\n")
+		for _, dp := range changes.dpCreate {
+			if err := markdownCreate(b, dp.Name, dp.Id, file(dp.Id), dp); err != nil {
+				return err
+			}
+		}
+		for _, dp := range changes.dpUpdate {
+			changelog, err := diff.Diff(dpToDiff(changes.dpRemote[dp.Id]), dpToDiff(dp))
+			if err != nil {
+				return err
+			}
+			markdownUpdate(b, dp.Name, dp.Id, file(dp.Id), changelog)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(changes.esCreate)+len(changes.esUpdate)+len(changes.esDelete) > 0 {
+		b.WriteString("#### Event specifications\n\n")
+		for _, es := range changes.esCreate {
+			if err := markdownCreate(b, es.Name, es.Id, file(es.Id), es); err != nil {
+				return err
+			}
+		}
+		for _, es := range changes.esUpdate {
+			remote := changes.esRemote[es.Id]
+			remoteDiff, err := esToDiff(remote)
+			if err != nil {
+				return err
+			}
+			localDiff, err := esToDiff(es)
+			if err != nil {
+				return err
+			}
+			changelog, err := diff.Diff(*remoteDiff, *localDiff)
+			if err != nil {
+				return err
+			}
+			triggerChangelog, err := diff.Diff(triggersToDiff(remote.Triggers), triggersToDiff(es.Triggers))
+			if err != nil {
+				return err
+			}
+			for _, c := range triggerChangelog {
+				c.Path = append([]string{"Triggers"}, c.Path...)
+				changelog = append(changelog, c)
+			}
+			markdownUpdate(b, es.Name, es.Id, file(es.Id), changelog)
+		}
+		for _, es := range changes.esDelete {
+			// for deletions the lookup holds the data product name, the file no longer exists
+			fmt.Fprintf(b, "- delete **%s** (`%s`) from data product %s\n", es.Name, es.Id, changes.IdToFileName[es.Id])
+		}
+		b.WriteString("\n")
+	}
+
+	if len(changes.imageCreate) > 0 {
+		b.WriteString("#### Images\n\n")
+		for _, img := range changes.imageCreate {
+			fmt.Fprintf(b, "- upload `%s`\n", markdownRelPath(basePath, img.fname))
+		}
+		b.WriteString("\n")
+	}
+
+	return nil
+}
+
+func markdownCreate(b *strings.Builder, name string, id string, file string, resource any) error {
+	fmt.Fprintf(b, "- create **%s** (`%s`)\n", name, file)
+	body, err := json.MarshalIndent(resource, "", "  ")
+	if err != nil {
+		return err
+	}
+	b.WriteString("\n")
+	b.WriteString(util.MarkdownDetails(id, "json", string(body)))
+	return nil
+}
+
+func markdownUpdate(b *strings.Builder, name string, id string, file string, changelog diff.Changelog) {
+	fmt.Fprintf(b, "- update **%s** (`%s`)\n", name, file)
+	if len(changelog) > 0 {
+		b.WriteString("\n")
+		b.WriteString(util.MarkdownDetails(id, "diff", util.MarkdownChangelog(changelog)))
+	}
+}
+
+func markdownRelPath(basePath string, f string) string {
+	if rel, err := filepath.Rel(basePath, f); err == nil && filepath.IsAbs(f) {
+		return rel
+	}
+	return f
+}
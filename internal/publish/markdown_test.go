@@ -0,0 +1,86 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+package publish
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+func Test_MarkdownChangeset_UpdatesAndCreates(t *testing.T) {
+	local := LocalFilesRefsResolved{
+		SourceApps: []model.SourceApp{{
+			ResourceName: "9b6e4e4c-c34a-483c-a5e7-f728d66a53b3",
+			Data:         model.SourceAppData{Name: "Source App 1 Updated"},
+		}},
+		DataProudcts: []model.DataProduct{{
+			ResourceName: "d9967fb4-6233-49c1-94d2-6cc417abd7ed",
+			Data: model.DataProductData{
+				Name: "Data Product 1",
+				EventSpecifications: []model.EventSpec{{
+					ResourceName: "6557ceee-a1c7-4ea9-910a-2a97194fb3f6",
+					Name:         "Event Spec 1",
+				}},
+			},
+		}},
+		IdToFileName: map[string]string{
+			"9b6e4e4c-c34a-483c-a5e7-f728d66a53b3": "/repo/data-products/source-apps/sa.yaml",
+			"d9967fb4-6233-49c1-94d2-6cc417abd7ed": "/repo/data-products/dp.yaml",
+			"6557ceee-a1c7-4ea9-910a-2a97194fb3f6": "/repo/data-products/dp.yaml",
+		},
+	}
+	remote := console.DataProductsAndRelatedResources{
+		SourceApplication: []console.RemoteSourceApplication{{
+			Id:   "9b6e4e4c-c34a-483c-a5e7-f728d66a53b3",
+			Name: "Source App 1",
+		}},
+	}
+
+	changes, err := findChanges(local, remote, map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	err = MarkdownChangeset(&b, *changes, "/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := b.String()
+	for _, expected := range []string{
+		"| source applications | 0 | 1 | 0 |",
+		"| data products | 1 | 0 | 0 |",
+		"| event specifications | 1 | 0 | 0 |",
+		"- update **Source App 1 Updated** (`data-products/source-apps/sa.yaml`)",
+		`- /Name: "Source App 1"`,
+		`+ /Name: "Source App 1 Updated"`,
+		"- create **Data Product 1** (`data-products/dp.yaml`)",
+		"- create **Event Spec 1** (`data-products/dp.yaml`)",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected output to contain %q, got:\n%s", expected, out)
+		}
+	}
+}
+
+func Test_MarkdownChangeset_Empty(t *testing.T) {
+	var b strings.Builder
+	err := MarkdownChangeset(&b, DataProductChangeSet{}, "/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "No changes detected.") {
+		t.Fatalf("expected no changes message, got %s", b.String())
+	}
+}
@@ -0,0 +1,570 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+// Package synthetic generates random instances of draft-04 JSON Schemas
+package synthetic
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"math/rand"
+	"net/netip"
+	"regexp/syntax"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// how deep recursive references are followed before only required properties are generated
+	maxDepth = 8
+	// upper bound on unbounded repetition in patterns and arrays
+	maxRepeat = 4
+	// numbers are kept within the range a float64 represents exactly
+	maxSafe = 1 << 53
+)
+
+// Generator produces instances of a schema. Given the same seed it produces the same instances
+type Generator struct {
+	rand *rand.Rand
+	root map[string]any
+}
+
+func NewGenerator(schema map[string]any, seed int64) *Generator {
+	return &Generator{rand: rand.New(rand.NewSource(seed)), root: schema}
+}
+
+// Next generates one instance. Instances honour types, enums, formats, patterns, bounds and
+// required properties, but combinations of keywords may still yield invalid instances so
+// callers are expected to validate them
+func (g *Generator) Next() (any, error) {
+	return g.value(g.root, 0)
+}
+
+func (g *Generator) value(schema map[string]any, depth int) (any, error) {
+	schema, err := g.resolve(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	if enum, ok := schema["enum"].([]any); ok && len(enum) > 0 {
+		return enum[g.rand.Intn(len(enum))], nil
+	}
+
+	for _, k := range []string{"oneOf", "anyOf"} {
+		if options, ok := schema[k].([]any); ok && len(options) > 0 {
+			option, ok := options[g.rand.Intn(len(options))].(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a list of schemas", k)
+			}
+			merged := merge(without(schema, k), option)
+			return g.value(merged, depth)
+		}
+	}
+
+	switch g.pickType(schema) {
+	case "null":
+		return nil, nil
+	case "boolean":
+		return g.rand.Intn(2) == 1, nil
+	case "integer":
+		return g.integer(schema)
+	case "number":
+		return g.number(schema)
+	case "array":
+		return g.array(schema, depth)
+	case "object":
+		return g.object(schema, depth)
+	default:
+		return g.string(schema)
+	}
+}
+
+// resolve follows local references and folds allOf into a single schema
+func (g *Generator) resolve(schema map[string]any) (map[string]any, error) {
+	for i := 0; ; i++ {
+		ref, ok := schema["$ref"].(string)
+		if !ok {
+			break
+		}
+		if i > maxDepth {
+			return nil, fmt.Errorf("$ref %s is circular", ref)
+		}
+		target, err := g.pointer(ref)
+		if err != nil {
+			return nil, err
+		}
+		schema = target
+	}
+
+	if all, ok := schema["allOf"].([]any); ok {
+		merged := without(schema, "allOf")
+		for _, s := range all {
+			part, ok := s.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("allOf must be a list of schemas")
+			}
+			part, err := g.resolve(part)
+			if err != nil {
+				return nil, err
+			}
+			merged = merge(merged, part)
+		}
+		return merged, nil
+	}
+
+	return schema, nil
+}
+
+func (g *Generator) pointer(ref string) (map[string]any, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("$ref %s is not supported, only references within the schema are", ref)
+	}
+	var current any = g.root
+	for _, token := range strings.Split(strings.TrimPrefix(strings.TrimPrefix(ref, "#"), "/"), "/") {
+		if token == "" {
+			continue
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("$ref %s not found", ref)
+		}
+		current, ok = m[token]
+		if !ok {
+			return nil, fmt.Errorf("$ref %s not found", ref)
+		}
+	}
+	target, ok := current.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("$ref %s is not a schema", ref)
+	}
+	return target, nil
+}
+
+func without(schema map[string]any, key string) map[string]any {
+	res := make(map[string]any, len(schema))
+	for k, v := range schema {
+		if k != key {
+			res[k] = v
+		}
+	}
+	return res
+}
+
+// merge combines two schemas an instance must satisfy, joining properties and required
+func merge(a map[string]any, b map[string]any) map[string]any {
+	res := maps.Clone(a)
+	for k, v := range b {
+		switch k {
+		case "properties":
+			props := map[string]any{}
+			if existing, ok := res[k].(map[string]any); ok {
+				for pk, pv := range existing {
+					props[pk] = pv
+				}
+			}
+			if more, ok := v.(map[string]any); ok {
+				for pk, pv := range more {
+					props[pk] = pv
+				}
+			}
+			res[k] = props
+		case "required":
+			required, _ := res[k].([]any)
+			required = slices.Clone(required)
+			if more, ok := v.([]any); ok {
+				for _, r := range more {
+					if !slices.Contains(required, r) {
+						required = append(required, r)
+					}
+				}
+			}
+			res[k] = required
+		default:
+			res[k] = v
+		}
+	}
+	return res
+}
+
+// pickType chooses one of the allowed types, null only occasionally
+func (g *Generator) pickType(schema map[string]any) string {
+	types := []string{}
+	switch t := schema["type"].(type) {
+	case string:
+		types = append(types, t)
+	case []any:
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+	}
+
+	if len(types) == 0 {
+		switch {
+		case schema["properties"] != nil || schema["required"] != nil:
+			return "object"
+		case schema["items"] != nil:
+			return "array"
+		case schema["minimum"] != nil || schema["maximum"] != nil || schema["multipleOf"] != nil:
+			return "number"
+		default:
+			return "string"
+		}
+	}
+
+	nonNull := slices.DeleteFunc(slices.Clone(types), func(t string) bool { return t == "null" })
+	if len(nonNull) == 0 || (len(nonNull) < len(types) && g.rand.Intn(5) == 0) {
+		return "null"
+	}
+	return nonNull[g.rand.Intn(len(nonNull))]
+}
+
+func float(schema map[string]any, key string) (float64, bool) {
+	switch v := schema[key].(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func count(schema map[string]any, key string) (int, bool) {
+	f, ok := float(schema, key)
+	if !ok || f < 0 {
+		return 0, false
+	}
+	return int(math.Min(f, math.MaxInt32)), true
+}
+
+// bounds are the inclusive range a number may take, defaulting to a window around any given bound
+func bounds(schema map[string]any) (float64, float64, bool, bool) {
+	lo, hasLo := float(schema, "minimum")
+	hi, hasHi := float(schema, "maximum")
+	exLo, _ := schema["exclusiveMinimum"].(bool)
+	exHi, _ := schema["exclusiveMaximum"].(bool)
+	lo = math.Max(lo, -maxSafe)
+	hi = math.Min(hi, maxSafe)
+	// a lone bound is often a storage limit, plausible values sit near zero
+	switch {
+	case !hasLo && !hasHi:
+		lo, hi = 0, 1000
+	case !hasLo:
+		lo = math.Min(0, hi-1000)
+		hi = math.Min(hi, lo+1000)
+	case !hasHi:
+		hi = math.Max(lo, 0) + 1000
+	}
+	return lo, hi, exLo && hasLo, exHi && hasHi
+}
+
+func (g *Generator) integer(schema map[string]any) (any, error) {
+	lo, hi, exLo, exHi := bounds(schema)
+	step := 1.0
+	if m, ok := float(schema, "multipleOf"); ok && m > 0 {
+		step = m
+	}
+	from := math.Ceil(lo / step)
+	if exLo && from*step == lo {
+		from++
+	}
+	to := math.Floor(hi / step)
+	if exHi && to*step == hi {
+		to--
+	}
+	if from > to {
+		return nil, fmt.Errorf("no integer between %v and %v", lo, hi)
+	}
+	k := from + float64(g.rand.Int63n(int64(to-from)+1))
+	return json.Number(strconv.FormatFloat(k*step, 'f', -1, 64)), nil
+}
+
+func (g *Generator) number(schema map[string]any) (any, error) {
+	if m, ok := float(schema, "multipleOf"); ok && m > 0 {
+		return g.integer(schema)
+	}
+	lo, hi, _, _ := bounds(schema)
+	if lo > hi {
+		return nil, fmt.Errorf("no number between %v and %v", lo, hi)
+	}
+	v := lo + g.rand.Float64()*(hi-lo)
+	// two decimals reads like real data, fall back to full precision on narrow ranges
+	if rounded := math.Round(v*100) / 100; rounded > lo && rounded < hi {
+		v = rounded
+	}
+	return json.Number(strconv.FormatFloat(v, 'f', -1, 64)), nil
+}
+
+func (g *Generator) array(schema map[string]any, depth int) (any, error) {
+	min, _ := count(schema, "minItems")
+	max, hasMax := count(schema, "maxItems")
+	if !hasMax {
+		max = min + maxRepeat
+	}
+	if depth >= maxDepth {
+		max = min
+	}
+	if max < min {
+		return nil, fmt.Errorf("maxItems is less than minItems")
+	}
+	n := min + g.rand.Intn(max-min+1)
+	unique, _ := schema["uniqueItems"].(bool)
+
+	itemSchema := func(i int) map[string]any {
+		switch items := schema["items"].(type) {
+		case map[string]any:
+			return items
+		case []any:
+			if i < len(items) {
+				if s, ok := items[i].(map[string]any); ok {
+					return s
+				}
+			}
+			if s, ok := schema["additionalItems"].(map[string]any); ok {
+				return s
+			}
+		}
+		return map[string]any{}
+	}
+
+	res := []any{}
+	seen := map[string]bool{}
+	for i := 0; i < n; i++ {
+		var item any
+		for attempt := 0; ; attempt++ {
+			v, err := g.value(itemSchema(i), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			key, _ := json.Marshal(v)
+			if !unique || !seen[string(key)] || attempt > maxRepeat {
+				seen[string(key)] = true
+				item = v
+				break
+			}
+		}
+		res = append(res, item)
+	}
+	return res, nil
+}
+
+func (g *Generator) object(schema map[string]any, depth int) (any, error) {
+	properties, _ := schema["properties"].(map[string]any)
+	required := map[string]bool{}
+	if r, ok := schema["required"].([]any); ok {
+		for _, k := range r {
+			if s, ok := k.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+
+	// sorted so a seed always yields the same instance
+	names := make([]string, 0, len(properties))
+	for k := range properties {
+		names = append(names, k)
+	}
+	for k := range required {
+		if _, ok := properties[k]; !ok {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+
+	minProperties, _ := count(schema, "minProperties")
+	res := map[string]any{}
+	for i, k := range names {
+		remaining := len(names) - i
+		include := required[k] ||
+			(depth < maxDepth && g.rand.Intn(2) == 1) ||
+			len(res)+remaining <= minProperties
+		if !include {
+			continue
+		}
+		ps, _ := properties[k].(map[string]any)
+		if ps == nil {
+			ps = map[string]any{}
+		}
+		v, err := g.value(ps, depth+1)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+		res[k] = v
+	}
+	return res, nil
+}
+
+const alphabet = "abcdefghijklmnopqrstuvwxyz"
+
+func (g *Generator) string(schema map[string]any) (any, error) {
+	if format, ok := schema["format"].(string); ok {
+		if s, ok := g.format(format); ok {
+			return s, nil
+		}
+	}
+
+	if pattern, ok := schema["pattern"].(string); ok {
+		return g.pattern(pattern)
+	}
+
+	min, _ := count(schema, "minLength")
+	max, hasMax := count(schema, "maxLength")
+	if !hasMax {
+		max = min + 10
+	}
+	if min == 0 && max > 0 {
+		min = 1
+	}
+	if max < min {
+		return nil, fmt.Errorf("maxLength is less than minLength")
+	}
+	// long strings are capped, their content carries no information
+	max = min + int(math.Min(float64(max-min), 20))
+	n := min + g.rand.Intn(max-min+1)
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphabet[g.rand.Intn(len(alphabet))]
+	}
+	return string(b), nil
+}
+
+var words = []string{"acme", "snowplow", "example", "shop", "blog", "news", "docs", "app"}
+
+func (g *Generator) word() string {
+	return words[g.rand.Intn(len(words))]
+}
+
+func (g *Generator) format(format string) (string, bool) {
+	// instants spread over a few years before a fixed date, independent of when the cli runs
+	instant := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(-time.Duration(g.rand.Int63n(int64(3 * 365 * 24 * time.Hour))))
+
+	switch format {
+	case "date-time":
+		return instant.Format(time.RFC3339), true
+	case "date":
+		return instant.Format(time.DateOnly), true
+	case "time":
+		return instant.Format(time.TimeOnly) + "Z", true
+	case "email":
+		return fmt.Sprintf("%s.%d@%s.com", g.word(), g.rand.Intn(1000), g.word()), true
+	case "hostname":
+		return fmt.Sprintf("%s.%s.com", g.word(), g.word()), true
+	case "uri", "uri-reference", "iri", "iri-reference":
+		return fmt.Sprintf("https://%s.com/%s/%d", g.word(), g.word(), g.rand.Intn(1000)), true
+	case "ipv4":
+		var b [4]byte
+		g.rand.Read(b[:])
+		return netip.AddrFrom4(b).String(), true
+	case "ipv6":
+		var b [16]byte
+		g.rand.Read(b[:])
+		return netip.AddrFrom16(b).String(), true
+	case "uuid":
+		var b [16]byte
+		g.rand.Read(b[:])
+		b[6] = (b[6] & 0x0f) | 0x40
+		b[8] = (b[8] & 0x3f) | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), true
+	}
+	return "", false
+}
+
+// pattern generates a string matching a regular expression
+func (g *Generator) pattern(pattern string) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", fmt.Errorf("pattern %s: %w", pattern, err)
+	}
+	var sb strings.Builder
+	g.regexp(&sb, re.Simplify())
+	return sb.String(), nil
+}
+
+func (g *Generator) regexp(sb *strings.Builder, re *syntax.Regexp) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			sb.WriteRune(r)
+		}
+	case syntax.OpCharClass:
+		sb.WriteRune(g.charClass(re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		sb.WriteByte(alphabet[g.rand.Intn(len(alphabet))])
+	case syntax.OpCapture:
+		g.regexp(sb, re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			g.regexp(sb, sub)
+		}
+	case syntax.OpAlternate:
+		g.regexp(sb, re.Sub[g.rand.Intn(len(re.Sub))])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			min, max = 0, maxRepeat
+		case syntax.OpPlus:
+			min, max = 1, maxRepeat
+		case syntax.OpQuest:
+			min, max = 0, 1
+		}
+		if max < 0 {
+			max = min + maxRepeat
+		}
+		for i := min + g.rand.Intn(max-min+1); i > 0; i-- {
+			g.regexp(sb, re.Sub[0])
+		}
+	}
+}
+
+// charClass picks a rune from [lo, hi] pairs, preferring printable ascii
+func (g *Generator) charClass(ranges []rune) rune {
+	printable := []rune{}
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := max(ranges[i], ' '), min(ranges[i+1], '~')
+		if lo <= hi {
+			printable = append(printable, lo, hi)
+		}
+	}
+	if len(printable) > 0 {
+		ranges = printable
+	}
+	if len(ranges) < 2 {
+		return 'a'
+	}
+
+	total := 0
+	for i := 0; i+1 < len(ranges); i += 2 {
+		total += int(ranges[i+1]-ranges[i]) + 1
+	}
+	n := g.rand.Intn(total)
+	for i := 0; i+1 < len(ranges); i += 2 {
+		size := int(ranges[i+1]-ranges[i]) + 1
+		if n < size {
+			return ranges[i] + rune(n)
+		}
+		n -= size
+	}
+	return ranges[0]
+}
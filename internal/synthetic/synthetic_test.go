@@ -0,0 +1,161 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package synthetic
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+const schema = `{
+  "type": "object",
+  "definitions": {
+    "money": {"type": "number", "minimum": 0, "exclusiveMinimum": true, "maximum": 100, "multipleOf": 0.5}
+  },
+  "properties": {
+    "method": {"type": "string", "enum": ["sso", "password"]},
+    "id": {"type": "string", "format": "uuid"},
+    "email": {"type": "string", "format": "email"},
+    "ts": {"type": "string", "format": "date-time"},
+    "day": {"type": "string", "format": "date"},
+    "url": {"type": "string", "format": "uri"},
+    "ipv4": {"type": "string", "format": "ipv4"},
+    "ipv6": {"type": "string", "format": "ipv6"},
+    "host": {"type": "string", "format": "hostname"},
+    "code": {"type": "string", "pattern": "^[A-Z]{2,3}-\\d+(\\.[a-f0-9]{2})?$"},
+    "name": {"type": ["string", "null"], "minLength": 3, "maxLength": 5},
+    "attempts": {"type": "integer", "minimum": 1, "maximum": 3},
+    "big": {"type": "integer", "maximum": 9223372036854775807},
+    "ratio": {"type": "number", "minimum": 0.1, "maximum": 0.2},
+    "price": {"$ref": "#/definitions/money"},
+    "tags": {"type": "array", "items": {"type": "string", "enum": ["a", "b", "c", "d"]}, "uniqueItems": true, "minItems": 1, "maxItems": 3},
+    "nested": {
+      "allOf": [
+        {"type": "object", "properties": {"a": {"type": "boolean"}}, "required": ["a"]},
+        {"properties": {"b": {"type": "integer", "minimum": -5, "maximum": -1}}, "required": ["b"]}
+      ]
+    },
+    "choice": {"oneOf": [{"type": "integer", "minimum": 10, "maximum": 20}, {"type": "string", "maxLength": 2}]}
+  },
+  "required": ["method", "id", "code", "attempts", "nested"],
+  "additionalProperties": false
+}`
+
+func compile(t *testing.T, s string) (*jsonschema.Schema, map[string]any) {
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft4
+	compiler.AssertFormat = true
+	if err := compiler.AddResource("schema.json", strings.NewReader(s)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := compiler.Compile("schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal([]byte(s), &m); err != nil {
+		t.Fatal(err)
+	}
+	return sch, m
+}
+
+func Test_GeneratorValid(t *testing.T) {
+	sch, m := compile(t, schema)
+	g := NewGenerator(m, 1)
+
+	seen := map[string]bool{}
+	for i := 0; i < 200; i++ {
+		instance, err := g.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sch.Validate(instance); err != nil {
+			out, _ := json.Marshal(instance)
+			t.Fatalf("invalid instance %s: %s", out, err)
+		}
+		for k := range instance.(map[string]any) {
+			seen[k] = true
+		}
+	}
+
+	// optional properties should all show up eventually
+	if len(seen) != 18 {
+		t.Errorf("expected every property to be generated, got %v", seen)
+	}
+}
+
+func Test_GeneratorSeed(t *testing.T) {
+	_, m := compile(t, schema)
+
+	generate := func(seed int64) []any {
+		g := NewGenerator(m, seed)
+		res := []any{}
+		for i := 0; i < 10; i++ {
+			instance, err := g.Next()
+			if err != nil {
+				t.Fatal(err)
+			}
+			res = append(res, instance)
+		}
+		return res
+	}
+
+	if !reflect.DeepEqual(generate(42), generate(42)) {
+		t.Error("same seed should produce the same instances")
+	}
+	if reflect.DeepEqual(generate(42), generate(43)) {
+		t.Error("different seeds should produce different instances")
+	}
+}
+
+func Test_GeneratorPattern(t *testing.T) {
+	patterns := []string{
+		`^[a-z]+$`,
+		`^\d{4}-\d{2}$`,
+		`^(foo|bar)_[^_\s]{3}$`,
+		`^[\w.-]+@example\.com$`,
+		`abc`,
+	}
+	g := NewGenerator(map[string]any{}, 3)
+	for _, p := range patterns {
+		re := regexp.MustCompile(p)
+		for i := 0; i < 20; i++ {
+			s, err := g.pattern(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !re.MatchString(s) {
+				t.Fatalf("%q does not match %s", s, p)
+			}
+		}
+	}
+}
+
+func Test_GeneratorErrors(t *testing.T) {
+	tests := map[string]map[string]any{
+		"remote ref":    {"$ref": "iglu:com.acme/thing/jsonschema/1-0-0"},
+		"missing ref":   {"$ref": "#/definitions/missing"},
+		"no integer":    {"type": "integer", "minimum": 1.2, "maximum": 1.8},
+		"bad pattern":   {"type": "string", "pattern": "("},
+		"bad lengths":   {"type": "string", "minLength": 5, "maxLength": 2},
+		"circular refs": {"$ref": "#"},
+	}
+	for name, s := range tests {
+		if _, err := NewGenerator(s, 1).Next(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+// Package tracing exports OpenTelemetry traces of CLI runs.
+//
+// Tracing is off unless configured, either with the standard OTEL_* environment
+// variables (OTEL_TRACES_EXPORTER, OTEL_EXPORTER_OTLP_ENDPOINT, ...) or with a
+// trace file written by the stdout exporter.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/snowplow/snowplow-cli/internal/util"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/snowplow/snowplow-cli"
+
+// Phases of a publish or validate run
+const (
+	PhaseDiscovery        = "discovery"
+	PhaseLocalValidation  = "local validation"
+	PhaseChangeDetection  = "change detection"
+	PhaseRemoteValidation = "remote validation"
+	PhaseValidation       = "validation"
+	PhaseApply            = "apply"
+)
+
+// Init installs a global tracer provider when tracing is configured. traceFile, when set,
+// receives spans as json lines, "-" meaning stdout. The returned function flushes and
+// stops exporting, it is safe to call when tracing is off
+func Init(cnx context.Context, traceFile string) (func(context.Context) error, error) {
+	exporter, closer, err := newExporter(cnx, traceFile)
+	if err != nil || exporter == nil {
+		return func(context.Context) error { return nil }, err
+	}
+
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceName("snowplow-cli"),
+			semconv.ServiceVersion(util.VersionInfo),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES take precedence
+	envRes, err := resource.New(cnx, resource.WithFromEnv())
+	if err != nil {
+		return nil, err
+	}
+	res, err = resource.Merge(res, envRes)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return func(cnx context.Context) error {
+		err := provider.Shutdown(cnx)
+		if closer != nil {
+			if cerr := closer.Close(); err == nil {
+				err = cerr
+			}
+		}
+		return err
+	}, nil
+}
+
+func newExporter(cnx context.Context, traceFile string) (sdktrace.SpanExporter, io.Closer, error) {
+	if traceFile == "-" {
+		exp, err := stdouttrace.New()
+		return exp, nil, err
+	}
+	if traceFile != "" {
+		f, err := os.Create(traceFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		return exp, f, err
+	}
+
+	exporter := strings.TrimSpace(os.Getenv("OTEL_TRACES_EXPORTER"))
+	if exporter == "" && (os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "") {
+		exporter = "otlp"
+	}
+
+	switch exporter {
+	case "", "none":
+		return nil, nil, nil
+	case "console":
+		exp, err := stdouttrace.New()
+		return exp, nil, err
+	case "otlp":
+		protocol := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL")
+		if protocol == "" {
+			protocol = os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+		}
+		if protocol != "" && protocol != "http/protobuf" {
+			return nil, nil, fmt.Errorf("unsupported OTEL_EXPORTER_OTLP_PROTOCOL %s, only http/protobuf is available", protocol)
+		}
+		// endpoint, headers and timeouts are read from the environment by the exporter
+		exp, err := otlptracehttp.New(cnx)
+		return exp, nil, err
+	default:
+		return nil, nil, fmt.Errorf("unsupported OTEL_TRACES_EXPORTER %s, expected otlp, console or none", exporter)
+	}
+}
+
+// Start begins a span as a child of any span in cnx
+func Start(cnx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(cnx, name, trace.WithAttributes(attrs...))
+}
+
+// End finishes span, marking it failed when err is not nil
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,85 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_Init_Off(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+
+	shutdown, err := Init(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_Init_Unsupported(t *testing.T) {
+	table := map[string]string{
+		"OTEL_TRACES_EXPORTER":        "zipkin",
+		"OTEL_EXPORTER_OTLP_PROTOCOL": "grpc",
+	}
+	for env, value := range table {
+		t.Run(env, func(t *testing.T) {
+			t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+			t.Setenv(env, value)
+			_, err := Init(context.Background(), "")
+			if err == nil || !strings.Contains(err.Error(), value) {
+				t.Errorf("expected unsupported %s error, got %v", value, err)
+			}
+		})
+	}
+}
+
+func Test_Init_TraceFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "trace.json")
+	cnx := context.Background()
+
+	shutdown, err := Init(cnx, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cnx, parent := Start(cnx, "snowplow-cli ds publish dev")
+	_, child := Start(cnx, PhaseDiscovery)
+	End(child, errors.New("no files"))
+	End(parent, nil)
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"Name":"discovery"`,
+		`"Name":"snowplow-cli ds publish dev"`,
+		`"Description":"no files"`,
+		`"Value":"snowplow-cli"`,
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("trace file missing %s\n%s", want, content)
+		}
+	}
+}
@@ -14,3 +14,5 @@ const DataStructuresFolder = "data-structures"
 const DataProductsFolder = "data-products"
 const SourceAppsFolder = "source-apps"
 const ImagesFolder = "images"
+const ExamplesFolder = "examples"
+const CounterexamplesFolder = "counterexamples"
@@ -0,0 +1,82 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// DownloadedFile lists the resources download wrote to a directory.
+// It has no .json or .yaml extension so it is never read as a resource
+const DownloadedFile = ".snowplow-downloaded"
+
+// Downloaded is the set of resources download wrote to a directory,
+// prune only removes files of those so local resources never published are kept
+type Downloaded struct {
+	path string
+	ids  map[string]bool
+}
+
+type downloadedFile struct {
+	Resources []string `json:"resources"`
+}
+
+// ReadDownloaded reads the resources downloaded to dir, none if nothing was downloaded there yet
+func ReadDownloaded(dir string) (*Downloaded, error) {
+	d := &Downloaded{path: filepath.Join(dir, DownloadedFile), ids: map[string]bool{}}
+	bytes, err := os.ReadFile(d.path)
+	if os.IsNotExist(err) {
+		return d, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file downloadedFile
+	if err := json.Unmarshal(bytes, &file); err != nil {
+		return nil, err
+	}
+	for _, id := range file.Resources {
+		d.ids[id] = true
+	}
+	return d, nil
+}
+
+func (d *Downloaded) Has(id string) bool {
+	return d.ids[id]
+}
+
+func (d *Downloaded) Add(id string) {
+	d.ids[id] = true
+}
+
+func (d *Downloaded) Remove(id string) {
+	delete(d.ids, id)
+}
+
+// Write saves the downloaded resources next to them
+func (d *Downloaded) Write() error {
+	file := downloadedFile{Resources: []string{}}
+	for id := range d.ids {
+		file.Resources = append(file.Resources, id)
+	}
+	sort.Strings(file.Resources)
+	bytes, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(d.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(d.path, append(bytes, '\n'), 0644)
+}
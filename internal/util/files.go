@@ -31,6 +31,116 @@ type Files struct {
 	SourceAppsLocation     string
 	ImagesLocation         string
 	ExtentionPreference    string
+	// ExistingFiles maps resource names, or DataStructureKey for data structures,
+	// to the files already holding them, updates are written back to those files
+	ExistingFiles map[string]string
+	// Rename moves existing files to the name derived from their resource
+	Rename bool
+}
+
+// DataStructureKey identifies a data structure across versions, eg. com.acme/login/jsonschema
+func DataStructureKey(self DataStructureSelf) string {
+	return fmt.Sprintf("%s/%s/%s", self.Vendor, self.Name, self.Format)
+}
+
+type target struct {
+	dir  string
+	name string
+	ext  string
+}
+
+// ExistingFile is the file a resource is kept in, if any
+func (f Files) ExistingFile(id string) (string, bool) {
+	path, ok := f.ExistingFiles[id]
+	return path, ok && !f.Rename
+}
+
+// targets picks the file each resource is written to.
+// New names never take over a file that holds another resource
+func (f Files) targets(names []idFileName, dir string) map[string]target {
+	writing := map[string]bool{}
+	for _, n := range names {
+		writing[n.Id] = true
+	}
+	claimed := map[string]bool{}
+	for id, path := range f.ExistingFiles {
+		if !(writing[id] && f.Rename) {
+			claimed[filepath.Clean(path)] = true
+		}
+	}
+
+	res := map[string]target{}
+	for _, n := range names {
+		if path, ok := f.ExistingFile(n.Id); ok {
+			ext := filepath.Ext(path)
+			res[n.Id] = target{filepath.Dir(path), strings.TrimSuffix(filepath.Base(path), ext), strings.TrimPrefix(ext, ".")}
+			continue
+		}
+		name := n.FileName
+		for i := 2; claimed[filepath.Join(dir, name+"."+f.ExtentionPreference)]; i++ {
+			name = fmt.Sprintf("%s-%d", n.FileName, i)
+		}
+		claimed[filepath.Join(dir, name+"."+f.ExtentionPreference)] = true
+		res[n.Id] = target{dir, name, f.ExtentionPreference}
+	}
+	return res
+}
+
+func (t target) path() string {
+	return filepath.Join(t.dir, t.name+"."+t.ext)
+}
+
+// replaced clears path when it holds another resource being renamed, so the resource written there
+// starts afresh rather than merging into it
+func (f Files) replaced(id string, path string) error {
+	if !f.Rename {
+		return nil
+	}
+	for other, old := range f.ExistingFiles {
+		if other != id && samePath(old, path) {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// moved removes the previous files of renamed resources once every resource is written,
+// keeping the ones another resource now lives in, eg. when two swap names
+func (f Files) moved(targets map[string]target) error {
+	ids := []string{}
+	for id := range targets {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		old, ok := f.ExistingFiles[id]
+		if !ok || samePath(old, targets[id].path()) {
+			continue
+		}
+		slog.Info("renamed", "from", old, "to", targets[id].path())
+		taken := false
+		for _, t := range targets {
+			taken = taken || samePath(old, t.path())
+		}
+		if taken {
+			continue
+		}
+		if err := os.Remove(old); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func samePath(a string, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return absA == absB
 }
 
 func (f Files) CreateDataStructures(dss []DataStructure) error {
@@ -40,12 +150,20 @@ func (f Files) CreateDataStructures(dss []DataStructure) error {
 		if err != nil {
 			return err
 		}
+		if path, ok := f.ExistingFile(DataStructureKey(data.Self)); ok {
+			ext := filepath.Ext(path)
+			_, err = UpdateSerializableFile(ds, filepath.Dir(path), strings.TrimSuffix(filepath.Base(path), ext), strings.TrimPrefix(ext, "."))
+			if err != nil {
+				return err
+			}
+			continue
+		}
 		vendorPath := filepath.Join(dataStructuresPath, data.Self.Vendor)
 		err = os.MkdirAll(vendorPath, os.ModePerm)
 		if err != nil {
 			return err
 		}
-		_, err = WriteSerializableToFile(ds, vendorPath, data.Self.Name, f.ExtentionPreference)
+		_, err = UpdateSerializableFile(ds, vendorPath, data.Self.Name, f.ExtentionPreference)
 		if err != nil {
 			return err
 		}
@@ -99,18 +217,27 @@ func (f Files) CreateSourceApps(sas []CliResource[SourceAppData]) (map[string]Cl
 	}
 
 	uniqueNames := createUniqueNames(idToFileName)
+	targets := f.targets(uniqueNames, sourceAppsPath)
 
 	var res = make(map[string]CliResource[SourceAppData])
 
 	for _, idToName := range uniqueNames {
 		sa := idToSa[idToName.Id]
-		abs, err := WriteSerializableToFile(sa, sourceAppsPath, idToName.FileName, f.ExtentionPreference)
+		t := targets[idToName.Id]
+		if err := f.replaced(idToName.Id, t.path()); err != nil {
+			return nil, err
+		}
+		abs, err := UpdateSerializableFile(sa, t.dir, t.name, t.ext)
 		if err != nil {
 			return nil, err
 		}
 		res[abs] = sa
 	}
 
+	if err := f.moved(targets); err != nil {
+		return nil, err
+	}
+
 	return res, nil
 }
 
@@ -130,18 +257,27 @@ func (f Files) CreateDataProducts(dps []CliResource[DataProductCanonicalData]) (
 	}
 
 	uniqueNames := createUniqueNames(idToFileName)
+	targets := f.targets(uniqueNames, dataProductsPath)
 
 	var res = make(map[string]CliResource[DataProductCanonicalData])
 
 	for _, idToName := range uniqueNames {
 		dp := idToDp[idToName.Id]
-		abs, err := WriteSerializableToFile(dp, dataProductsPath, idToName.FileName, f.ExtentionPreference)
+		t := targets[idToName.Id]
+		if err := f.replaced(idToName.Id, t.path()); err != nil {
+			return nil, err
+		}
+		abs, err := UpdateSerializableFile(dp, t.dir, t.name, t.ext)
 		if err != nil {
 			return nil, err
 		}
 		res[abs] = dp
 	}
 
+	if err := f.moved(targets); err != nil {
+		return nil, err
+	}
+
 	return res, nil
 }
 
@@ -183,7 +319,7 @@ func WriteSerializableToFile(body any, dir string, name string, ext string) (str
 	var bytes []byte
 	var err error
 
-	if ext == "yaml" {
+	if ext == "yaml" || ext == "yml" {
 		bytes, err = yaml.Marshal(body)
 		if err != nil {
 			return "", err
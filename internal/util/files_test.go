@@ -16,6 +16,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 
 	. "github.com/snowplow/snowplow-cli/internal/model"
@@ -172,3 +173,99 @@ func Test_createUniqueNames_OK(t *testing.T) {
 		t.Fatalf("Not expected result, expected: %+v, actual: %+v", expected1, res)
 	}
 }
+
+func Test_targetsKeepExistingFiles(t *testing.T) {
+	files := Files{
+		ExtentionPreference: "yaml",
+		ExistingFiles: map[string]string{
+			"dp-1": filepath.Join("dps", "team", "old-name.json"),
+			"dp-2": filepath.Join("dps", "shop.yaml"),
+		},
+	}
+	names := []idFileName{{Id: "dp-1", FileName: "new-name"}, {Id: "dp-3", FileName: "shop"}}
+
+	targets := files.targets(names, "dps")
+	expected := map[string]target{
+		"dp-1": {filepath.Join("dps", "team"), "old-name", "json"},
+		"dp-3": {"dps", "shop-2", "yaml"},
+	}
+	if !reflect.DeepEqual(targets, expected) {
+		t.Fatalf("unexpected targets %+v", targets)
+	}
+
+	files.Rename = true
+	targets = files.targets(names, "dps")
+	expected["dp-1"] = target{"dps", "new-name", "yaml"}
+	if !reflect.DeepEqual(targets, expected) {
+		t.Fatalf("unexpected targets when renaming %+v", targets)
+	}
+}
+
+func Test_CreateDataProductsRename(t *testing.T) {
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	dir := "data-products"
+	if err := os.Mkdir(dir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	old := filepath.Join(dir, "old-name.yaml")
+	if err := os.WriteFile(old, []byte("resourceName: dp-1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dp := CliResource[DataProductCanonicalData]{ResourceType: "data-product", ResourceName: "dp-1", Data: DataProductCanonicalData{Name: "New name"}}
+
+	files := Files{DataProductsLocation: dir, ExtentionPreference: "yaml", ExistingFiles: map[string]string{"dp-1": old}, Rename: true}
+	res, err := files.CreateDataProducts([]CliResource[DataProductCanonicalData]{dp})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := res[filepath.Join(dir, "new-name.yaml")]; !ok {
+		t.Fatalf("expected the data product to be written to its new name got %v", res)
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Fatalf("expected the old file to be removed got %v", err)
+	}
+}
+
+func Test_CreateDataProductsRenameSwap(t *testing.T) {
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	dir := "data-products"
+	if err := os.Mkdir(dir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	shop := filepath.Join(dir, "shop.yaml")
+	blog := filepath.Join(dir, "blog.yaml")
+	if err := os.WriteFile(shop, []byte("resourceName: dp-1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(blog, []byte("resourceName: dp-2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dps := []CliResource[DataProductCanonicalData]{
+		{ResourceType: "data-product", ResourceName: "dp-1", Data: DataProductCanonicalData{Name: "Blog"}},
+		{ResourceType: "data-product", ResourceName: "dp-2", Data: DataProductCanonicalData{Name: "Shop"}},
+	}
+
+	files := Files{DataProductsLocation: dir, ExtentionPreference: "yaml", ExistingFiles: map[string]string{"dp-1": shop, "dp-2": blog}, Rename: true}
+	if _, err := files.CreateDataProducts(dps); err != nil {
+		t.Fatal(err)
+	}
+	for file, expected := range map[string]string{blog: "resourceName: dp-1", shop: "resourceName: dp-2"} {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("expected %s to be kept got %v", file, err)
+		}
+		if !strings.Contains(string(content), expected) || strings.Count(string(content), "resourceName") != 1 {
+			t.Fatalf("expected %s to hold only %s got\n%s", file, expected, content)
+		}
+	}
+}
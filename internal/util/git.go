@@ -0,0 +1,118 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package util
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// GitChanges are the files of the working tree which differ from a git ref
+type GitChanges struct {
+	Ref     string
+	changed map[string]bool
+	deleted map[string]bool
+}
+
+// GitChangesSince asks the git binary which files changed or were deleted since ref, untracked files count as changed
+func GitChangesSince(ref string) (*GitChanges, error) {
+	out, err := git("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	root := strings.TrimSpace(out)
+
+	changes := &GitChanges{Ref: ref, changed: map[string]bool{}, deleted: map[string]bool{}}
+
+	out, err = git("-C", root, "diff", "--name-status", "--no-renames", "-z", ref, "--")
+	if err != nil {
+		return nil, err
+	}
+	entries := strings.Split(strings.TrimSuffix(out, "\x00"), "\x00")
+	for i := 0; i+1 < len(entries); i += 2 {
+		file := realPath(filepath.Join(root, filepath.FromSlash(entries[i+1])))
+		if entries[i] == "D" {
+			changes.deleted[file] = true
+		} else {
+			changes.changed[file] = true
+		}
+	}
+
+	out, err = git("-C", root, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range strings.Split(out, "\x00") {
+		if name != "" {
+			changes.changed[realPath(filepath.Join(root, filepath.FromSlash(name)))] = true
+		}
+	}
+
+	return changes, nil
+}
+
+// Changed tells whether file was added or modified since the ref
+func (g *GitChanges) Changed(file string) bool {
+	return g.changed[realPath(file)]
+}
+
+// Deleted tells whether file was deleted since the ref
+func (g *GitChanges) Deleted(file string) bool {
+	return g.deleted[realPath(file)]
+}
+
+// DeletedUnder lists the files deleted since the ref under any of paths, sorted
+func (g *GitChanges) DeletedUnder(paths []string) []string {
+	dirs := []string{}
+	for _, p := range paths {
+		dirs = append(dirs, realPath(p))
+	}
+	res := []string{}
+	for f := range g.deleted {
+		for _, d := range dirs {
+			if rel, err := filepath.Rel(d, f); err == nil && !strings.HasPrefix(rel, "..") {
+				res = append(res, f)
+				break
+			}
+		}
+	}
+	slices.Sort(res)
+	return res
+}
+
+func git(args ...string) (string, error) {
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
+
+// realPath resolves symlinks in the directory of file, which may not exist anymore, so paths from git and
+// from walking the file system compare
+func realPath(file string) string {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return file
+	}
+	if dir, err := filepath.EvalSymlinks(filepath.Dir(abs)); err == nil {
+		return filepath.Join(dir, filepath.Base(abs))
+	}
+	return abs
+}
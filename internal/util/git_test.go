@@ -0,0 +1,106 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package util
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func gitRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "-A"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "initial"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s", args, out)
+		}
+	}
+	return dir
+}
+
+func Test_GitChangesSince(t *testing.T) {
+	gitRepo(t, map[string]string{
+		"data-products/shop.yaml":    "name: shop",
+		"data-products/blog.yaml":    "name: blog",
+		"source-apps/web.yaml":       "name: web",
+		"data-structures/login.yaml": "name: login",
+	})
+	if err := os.WriteFile("source-apps/web.yaml", []byte("name: website"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove("data-products/blog.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("source-apps/mobile.yaml", []byte("name: mobile"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := GitChangesSince("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for file, expected := range map[string]bool{
+		"source-apps/web.yaml":       true,
+		"source-apps/mobile.yaml":    true,
+		"data-products/shop.yaml":    false,
+		"data-products/blog.yaml":    false,
+		"data-structures/login.yaml": false,
+	} {
+		if changes.Changed(file) != expected {
+			t.Errorf("expected changed %s to be %v", file, expected)
+		}
+	}
+	if !changes.Deleted("data-products/blog.yaml") || changes.Deleted("data-products/shop.yaml") {
+		t.Error("expected only blog.yaml to be deleted")
+	}
+
+	deleted := changes.DeletedUnder([]string{"data-products"})
+	if !reflect.DeepEqual(deleted, []string{realPath("data-products/blog.yaml")}) {
+		t.Errorf("unexpected deleted files got %v", deleted)
+	}
+	if len(changes.DeletedUnder([]string{"source-apps"})) != 0 {
+		t.Error("expected no deleted source applications")
+	}
+}
+
+func Test_GitChangesSinceUnknownRef(t *testing.T) {
+	gitRepo(t, map[string]string{"a.yaml": "a"})
+	if _, err := GitChangesSince("no-such-ref"); err == nil {
+		t.Fatal("expected an unknown ref to fail")
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/r3labs/diff/v3"
+)
+
+// MarkdownDetails renders a collapsible <details> block around a fenced code block
+func MarkdownDetails(summary string, lang string, body string) string {
+	return fmt.Sprintf(
+		"<details><summary>%s</summary>\n\n```%s\n%s\n```\n\n</details>\n",
+		summary, lang, strings.TrimRight(body, "\n"),
+	)
+}
+
+// MarkdownChangelog renders a structural diff as the body of a ```diff block
+func MarkdownChangelog(changelog diff.Changelog) string {
+	var b strings.Builder
+	for _, c := range changelog {
+		path := "/" + strings.Join(c.Path, "/")
+		switch c.Type {
+		case diff.CREATE:
+			fmt.Fprintf(&b, "+ %s: %s\n", path, markdownValue(c.To))
+		case diff.DELETE:
+			fmt.Fprintf(&b, "- %s: %s\n", path, markdownValue(c.From))
+		default:
+			fmt.Fprintf(&b, "- %s: %s\n", path, markdownValue(c.From))
+			fmt.Fprintf(&b, "+ %s: %s\n", path, markdownValue(c.To))
+		}
+	}
+	return b.String()
+}
+
+// MarkdownCell escapes a value so it can be placed inside a table cell
+func MarkdownCell(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "|", "\\|"), "\n", "<br>")
+}
+
+func markdownValue(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+// WriteMarkdownFile writes the output of each section, in order, to path
+func WriteMarkdownFile(path string, sections ...func(b *strings.Builder) error) error {
+	var b strings.Builder
+	for _, s := range sections {
+		if err := s(&b); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
@@ -23,11 +23,23 @@ import (
 	"slices"
 	"strings"
 
+	"github.com/go-viper/mapstructure/v2"
 	. "github.com/snowplow/snowplow-cli/internal/model"
 	"gopkg.in/yaml.v3"
 )
 
 func DataStructuresFromPaths(paths []string) (map[string]DataStructure, error) {
+	ds, _, err := dataStructuresFromPaths(paths, false)
+	return ds, err
+}
+
+// ReadableDataStructuresFromPaths is DataStructuresFromPaths skipping the files that can't be read.
+// The skipped files are returned with their error
+func ReadableDataStructuresFromPaths(paths []string) (map[string]DataStructure, map[string]error, error) {
+	return dataStructuresFromPaths(paths, true)
+}
+
+func dataStructuresFromPaths(paths []string, skipUnreadable bool) (map[string]DataStructure, map[string]error, error) {
 
 	files := map[string]bool{}
 
@@ -36,17 +48,22 @@ func DataStructuresFromPaths(paths []string) (map[string]DataStructure, error) {
 			if err != nil {
 				return err
 			}
+			// example payloads live next to data structures
+			if di.IsDir() && (di.Name() == ExamplesFolder || di.Name() == CounterexamplesFolder) {
+				return filepath.SkipDir
+			}
 			if !di.IsDir() {
 				files[path] = true
 			}
 			return nil
 		})
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
 	ds := make(map[string]DataStructure)
+	skipped := map[string]error{}
 
 	exts := []string{".yaml", ".yml", ".json"}
 
@@ -55,8 +72,10 @@ func DataStructuresFromPaths(paths []string) (map[string]DataStructure, error) {
 	for k := range files {
 		if slices.Index(exts, filepath.Ext(k)) != -1 {
 			d, err := dataStructureFromFileName(k)
-			if err != nil {
-				return nil, errors.Join(err, fmt.Errorf("file: %s", k))
+			if err != nil && skipUnreadable {
+				skipped[k] = err
+			} else if err != nil {
+				return nil, nil, errors.Join(err, fmt.Errorf("file: %s", k))
 			} else {
 				ds[k] = *d
 			}
@@ -64,10 +83,10 @@ func DataStructuresFromPaths(paths []string) (map[string]DataStructure, error) {
 	}
 
 	if len(wrongVersions) > 0 {
-		return nil, errors.New(strings.Join(wrongVersions, "\n"))
+		return nil, nil, errors.New(strings.Join(wrongVersions, "\n"))
 	}
 
-	return ds, nil
+	return ds, skipped, nil
 }
 
 func dataStructureFromFileName(f string) (*DataStructure, error) {
@@ -97,6 +116,26 @@ func dataStructureFromFileName(f string) (*DataStructure, error) {
 	return &ds, nil
 }
 
+// DataStructureIgnores reads the x-snowplow-cli-ignore annotations of data structure files, they are
+// not part of DataStructure as Console never sees them
+func DataStructureIgnores(files []string) (map[string][]Ignore, error) {
+	res := map[string][]Ignore{}
+	for _, f := range files {
+		data, err := dataFromFileName(f)
+		if err != nil {
+			return nil, fmt.Errorf("file: %s: %w", f, err)
+		}
+		var ignores []Ignore
+		if err := mapstructure.Decode(data[IgnoreKey], &ignores); err != nil {
+			return nil, fmt.Errorf("file: %s: %s: %w", f, IgnoreKey, err)
+		}
+		if len(ignores) > 0 {
+			res[f] = ignores
+		}
+	}
+	return res, nil
+}
+
 func dataFromFileName(f string) (map[string]any, error) {
 	file, err := os.Open(f)
 	if err != nil {
@@ -17,6 +17,8 @@ import (
 	"slices"
 	"strings"
 	"testing"
+
+	. "github.com/snowplow/snowplow-cli/internal/model"
 )
 
 func Test_DataStructuresFromPaths(t *testing.T) {
@@ -61,6 +63,42 @@ func Test_DataStructuresFromPathsFailNotASchema(t *testing.T) {
 	}
 }
 
+func Test_DataStructureIgnores(t *testing.T) {
+	dir := t.TempDir()
+	annotated := filepath.Join(dir, "login.yaml")
+	plain := filepath.Join(dir, "user.json")
+	content := `apiVersion: v1
+resourceType: data-structure
+x-snowplow-cli-ignore:
+  - rule: snake-case-names
+    reason: legacy tracking
+data: {}
+`
+	if err := os.WriteFile(annotated, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(plain, []byte(`{"apiVersion": "v1", "data": {}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ignores, err := DataStructureIgnores([]string{annotated, plain})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]Ignore{annotated: {{Rule: "snake-case-names", Reason: "legacy tracking"}}}
+	if !reflect.DeepEqual(ignores, expected) {
+		t.Fatalf("unexpected annotations %+v", ignores)
+	}
+
+	ds, err := DataStructuresFromPaths([]string{annotated})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds[annotated].Data == nil {
+		t.Fatalf("expected the data structure to read without its annotation got %+v", ds)
+	}
+}
+
 func Test_MaybeResourcesfromPaths(t *testing.T) {
 	saPath, _ := filepath.Abs(filepath.Join("testdata", "data-products", "source-application.yml"))
 	dp1Path, _ := filepath.Abs(filepath.Join("testdata", "data-products", "data-product.yml"))
@@ -0,0 +1,444 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package util
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// identityKeys pick out list items that should be matched up between versions of a document, by order of preference
+var identityKeys = []string{"resourceName", "id", "$ref", "source", "name"}
+
+const defaultYamlIndent = 4
+
+// UpdateSerializableFile writes body like WriteSerializableToFile but when a yaml file already exists
+// only the values that changed are rewritten, keeping comments, key order, styles, anchors and merge keys.
+// The yaml encoder does not keep blank lines, a changed file loses the ones between its entries
+// while an unchanged one is left byte for byte
+func UpdateSerializableFile(body any, dir string, name string, ext string) (string, error) {
+	filePath := filepath.Join(dir, fmt.Sprintf("%s.%s", name, ext))
+	if ext != "yaml" && ext != "yml" {
+		return WriteSerializableToFile(body, dir, name, ext)
+	}
+	existing, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return WriteSerializableToFile(body, dir, name, ext)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	merged, err := MergeYaml(existing, body)
+	if err != nil {
+		slog.Debug("could not update in place, rewriting", "file", filePath, "error", err)
+		return WriteSerializableToFile(body, dir, name, ext)
+	}
+	if bytes.Equal(merged, existing) {
+		slog.Debug("unchanged", "file", filePath)
+		return filePath, nil
+	}
+
+	if err := os.WriteFile(filePath, merged, 0644); err != nil {
+		return "", err
+	}
+	slog.Debug("updated", "file", filePath)
+	return filePath, nil
+}
+
+// MergeYaml applies body to an existing yaml document with as few changes as possible.
+// Existing documents are returned as is when they already hold body. Keys body only has
+// with an empty value are not added, a missing key reads the same
+func MergeYaml(existing []byte, body any) ([]byte, error) {
+	var src yaml.Node
+	if err := src.Encode(body); err != nil {
+		return nil, err
+	}
+
+	doc, err := decodeSingle(existing)
+	if err != nil {
+		return nil, err
+	}
+	if equivalent(doc.Content[0], &src) {
+		return existing, nil
+	}
+	indent := indentOf(doc.Content[0])
+
+	// merge keys are kept when the document they end up in still holds body, otherwise their
+	// values are written out
+	m := &merger{mergeKeys: true}
+	m.apply(doc, &src)
+	if !equivalent(doc.Content[0], &src) {
+		// the first attempt took nodes from both, start again from fresh ones
+		if doc, err = decodeSingle(existing); err != nil {
+			return nil, err
+		}
+		var fresh yaml.Node
+		if err := fresh.Encode(body); err != nil {
+			return nil, err
+		}
+		m = &merger{}
+		m.apply(doc, &fresh)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(indent)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeSingle(existing []byte) (*yaml.Node, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(existing))
+	var doc, next yaml.Node
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 || dec.Decode(&next) != io.EOF {
+		return nil, errors.New("expected a single yaml document")
+	}
+	return &doc, nil
+}
+
+type merger struct {
+	// mergeKeys keeps << keys, only writing the values they do not already provide
+	mergeKeys bool
+	// aliases are only decided once the whole document is merged, their anchors may change after them
+	aliases []pendingAlias
+}
+
+type pendingAlias struct {
+	node *yaml.Node
+	src  *yaml.Node
+}
+
+// apply merges src into the document doc, then resolves aliases
+func (m *merger) apply(doc *yaml.Node, src *yaml.Node) {
+	doc.Content[0] = m.merge(doc.Content[0], src)
+	for _, a := range m.aliases {
+		if !same(a.node, a.src) {
+			a.src.HeadComment, a.src.LineComment, a.src.FootComment = a.node.HeadComment, a.node.LineComment, a.node.FootComment
+			*a.node = *a.src
+		}
+	}
+	inlineDangling(doc, map[*yaml.Node]bool{})
+}
+
+// merge returns dst updated to hold src, reusing dst nodes where possible
+func (m *merger) merge(dst *yaml.Node, src *yaml.Node) *yaml.Node {
+	if equivalent(dst, src) {
+		return dst
+	}
+	if dst.Kind == yaml.AliasNode {
+		m.aliases = append(m.aliases, pendingAlias{dst, src})
+		return dst
+	}
+	if dst.Kind != src.Kind {
+		src.HeadComment, src.LineComment, src.FootComment = dst.HeadComment, dst.LineComment, dst.FootComment
+		return src
+	}
+
+	switch dst.Kind {
+	case yaml.ScalarNode:
+		if dst.ShortTag() != src.ShortTag() {
+			dst.Style = src.Style
+		}
+		dst.Tag, dst.Value = src.Tag, src.Value
+	case yaml.MappingNode:
+		dst.Content = m.mergeMapping(dst.Content, src.Content)
+	case yaml.SequenceNode:
+		if len(dst.Content) == 0 {
+			dst.Style = src.Style
+		}
+		dst.Content = m.mergeSequence(dst.Content, src.Content)
+	}
+	return dst
+}
+
+// mergeMapping keeps the order of existing keys, new keys go after the key preceding them in src.
+// Extension keys starting with x- only exist locally, they are kept. So are merge keys, keys with the
+// value they pull in are not written again, nor are new keys with an empty value
+func (m *merger) mergeMapping(dst []*yaml.Node, src []*yaml.Node) []*yaml.Node {
+	wanted := map[string]*yaml.Node{}
+	for i := 0; i+1 < len(src); i += 2 {
+		wanted[src[i].Value] = src[i+1]
+	}
+
+	res := []*yaml.Node{}
+	at := map[string]int{}
+	for i := 0; i+1 < len(dst); i += 2 {
+		key := dst[i].Value
+		if isMergeKey(dst[i]) {
+			if m.mergeKeys {
+				// the encoder writes the resolved tag out as !!merge <<, both decode the same
+				dst[i].Tag = ""
+				res = append(res, dst[i], dst[i+1])
+			}
+			continue
+		}
+		value, ok := wanted[key]
+		if !ok {
+			if strings.HasPrefix(key, "x-") {
+				at[key] = len(res)
+				res = append(res, dst[i], dst[i+1])
+			}
+			continue
+		}
+		at[key] = len(res)
+		res = append(res, dst[i], m.merge(dst[i+1], value))
+	}
+
+	inherited := map[string]*yaml.Node{}
+	if m.mergeKeys {
+		inherited = mergedValues(dst)
+	}
+
+	insert := 0
+	for i := 0; i+1 < len(src); i += 2 {
+		key := src[i].Value
+		if pos, ok := at[key]; ok {
+			insert = pos + 2
+			continue
+		}
+		if value, ok := inherited[key]; ok && same(value, src[i+1]) {
+			continue
+		}
+		if isEmpty(src[i+1]) {
+			continue
+		}
+		res = append(res[:insert], append([]*yaml.Node{src[i], src[i+1]}, res[insert:]...)...)
+		for k, pos := range at {
+			if pos >= insert {
+				at[k] = pos + 2
+			}
+		}
+		at[key] = insert
+		insert += 2
+	}
+	return res
+}
+
+func isMergeKey(n *yaml.Node) bool {
+	return n.Kind == yaml.ScalarNode && n.Value == "<<" && (n.Tag == "" || n.ShortTag() == "!!merge")
+}
+
+// mergedValues are the values the merge keys of a mapping pull in, the first mapping merged wins
+func mergedValues(content []*yaml.Node) map[string]*yaml.Node {
+	res := map[string]*yaml.Node{}
+	for i := 0; i+1 < len(content); i += 2 {
+		if !isMergeKey(content[i]) {
+			continue
+		}
+		sources := []*yaml.Node{content[i+1]}
+		if content[i+1].Kind == yaml.SequenceNode {
+			sources = content[i+1].Content
+		}
+		for _, source := range sources {
+			for source.Kind == yaml.AliasNode && source.Alias != nil {
+				source = source.Alias
+			}
+			if source.Kind != yaml.MappingNode {
+				continue
+			}
+			values := mergedValues(source.Content)
+			for j := 0; j+1 < len(source.Content); j += 2 {
+				if !isMergeKey(source.Content[j]) {
+					values[source.Content[j].Value] = source.Content[j+1]
+				}
+			}
+			for k, v := range values {
+				if _, ok := res[k]; !ok {
+					res[k] = v
+				}
+			}
+		}
+	}
+	return res
+}
+
+// inlineDangling replaces aliases whose anchor is gone or now comes after them with a copy of their value
+func inlineDangling(n *yaml.Node, seen map[*yaml.Node]bool) {
+	if n.Kind == yaml.AliasNode && n.Alias != nil && !seen[n.Alias] {
+		value := *n.Alias
+		value.Anchor = ""
+		value.HeadComment, value.LineComment, value.FootComment = n.HeadComment, n.LineComment, n.FootComment
+		*n = value
+	}
+	if n.Anchor != "" {
+		seen[n] = true
+	}
+	for _, c := range n.Content {
+		inlineDangling(c, seen)
+	}
+}
+
+// mergeSequence follows the order of src, items are matched by identity first and by position otherwise
+func (m *merger) mergeSequence(dst []*yaml.Node, src []*yaml.Node) []*yaml.Node {
+	byIdentity := map[string]int{}
+	for i, n := range dst {
+		if id := identity(n); id != "" {
+			if _, ok := byIdentity[id]; !ok {
+				byIdentity[id] = i
+			}
+		}
+	}
+	wanted := map[string]bool{}
+	for _, n := range src {
+		if id := identity(n); id != "" {
+			wanted[id] = true
+		}
+	}
+
+	used := make([]bool, len(dst))
+	res := make([]*yaml.Node, len(src))
+	for i, n := range src {
+		if j, ok := byIdentity[identity(n)]; ok && !used[j] {
+			used[j] = true
+			res[i] = m.merge(dst[j], n)
+		}
+	}
+	for i, n := range src {
+		if res[i] != nil {
+			continue
+		}
+		// items of dst src still refers to by identity are not reused for another one
+		if i < len(dst) && !used[i] {
+			if id := identity(dst[i]); id == "" || !wanted[id] {
+				used[i] = true
+				res[i] = m.merge(dst[i], n)
+				continue
+			}
+		}
+		res[i] = n
+	}
+	return res
+}
+
+// identity of list items is their value for scalars or the first identity key of mappings
+func identity(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		return "=" + n.Value
+	case yaml.MappingNode:
+		for _, key := range identityKeys {
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				if n.Content[i].Value == key && n.Content[i+1].Kind == yaml.ScalarNode {
+					return key + "=" + n.Content[i+1].Value
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// same compares the values nodes decode to, whatever their style
+func same(a *yaml.Node, b *yaml.Node) bool {
+	var x, y any
+	if a.Decode(&x) != nil || b.Decode(&y) != nil {
+		return false
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+// equivalent is same with missing keys and keys holding an empty value reading the same
+func equivalent(a *yaml.Node, b *yaml.Node) bool {
+	var x, y any
+	if a.Decode(&x) != nil || b.Decode(&y) != nil {
+		return false
+	}
+	return reflect.DeepEqual(withoutEmpty(x), withoutEmpty(y))
+}
+
+// isEmpty is true for null, empty lists and mappings holding nothing but empty values
+func isEmpty(n *yaml.Node) bool {
+	var v any
+	if n.Decode(&v) != nil {
+		return false
+	}
+	return withoutEmpty(v) == nil
+}
+
+// withoutEmpty drops the keys holding an empty value, empty values become nil
+func withoutEmpty(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		res := map[string]any{}
+		for k, value := range v {
+			if value = withoutEmpty(value); value != nil {
+				res[k] = value
+			}
+		}
+		if len(res) == 0 {
+			return nil
+		}
+		return res
+	case []any:
+		if len(v) == 0 {
+			return nil
+		}
+		res := make([]any, len(v))
+		for i, item := range v {
+			res[i] = withoutEmpty(item)
+		}
+		return res
+	}
+	return v
+}
+
+// indentOf guesses the indentation of a document from its first nested block mapping or sequence
+func indentOf(n *yaml.Node) int {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if (value.Kind == yaml.MappingNode || value.Kind == yaml.SequenceNode) && value.Style&yaml.FlowStyle == 0 &&
+				len(value.Content) > 0 && value.Content[0].Line > key.Line {
+				if indent := blockIndent(value, key.Column); indent > 0 {
+					return indent
+				}
+			}
+			if indent := indentOf(value); indent != defaultYamlIndent {
+				return indent
+			}
+		}
+	case yaml.SequenceNode:
+		for _, item := range n.Content {
+			if indent := indentOf(item); indent != defaultYamlIndent {
+				return indent
+			}
+		}
+	}
+	return defaultYamlIndent
+}
+
+// blockIndent is how far the entries of a block mapping or sequence are from the key they belong to.
+// Sequence items are at their value, past the "- " indicator, sequences flush with their key have none
+func blockIndent(value *yaml.Node, keyColumn int) int {
+	indent := value.Content[0].Column - keyColumn
+	if value.Kind == yaml.SequenceNode {
+		indent -= 2
+	}
+	return indent
+}
@@ -0,0 +1,312 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/snowplow/snowplow-cli/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+const commentedDataProduct = `# owned by the web team
+apiVersion: v1
+resourceType: data-product
+resourceName: dp-1
+data:
+  name: Shop # renamed in 2024
+  description: "Checkout funnel"
+  sourceApplications:
+    - $ref: ./source-apps/web.yaml
+  eventSpecifications:
+    # keep first
+    - resourceName: es-1
+      name: Checkout
+      event:
+        source: iglu:com.acme/checkout/jsonschema/1-0-0
+      entities:
+        tracked: []
+        enriched: []
+    - resourceName: es-2
+      name: Cart # the old one
+      entities: {tracked: [], enriched: []}
+`
+
+func testDataProduct(t *testing.T) CliResource[DataProductCanonicalData] {
+	var dp CliResource[DataProductCanonicalData]
+	if err := yaml.Unmarshal([]byte(commentedDataProduct), &dp); err != nil {
+		t.Fatal(err)
+	}
+	return dp
+}
+
+func Test_MergeYamlUnchanged(t *testing.T) {
+	merged, err := MergeYaml([]byte(commentedDataProduct), testDataProduct(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(merged) != commentedDataProduct {
+		t.Fatalf("expected an unchanged document to be left as is got\n%s", merged)
+	}
+}
+
+func Test_MergeYamlLeavesOutEmptyDefaults(t *testing.T) {
+	existing := strings.Replace(commentedDataProduct, "        enriched: []\n", "", 1)
+	merged, err := MergeYaml([]byte(existing), testDataProduct(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(merged) != existing {
+		t.Fatalf("expected an unchanged document without empty defaults to be left as is got\n%s", merged)
+	}
+
+	dp := testDataProduct(t)
+	dp.Data.Name = "Store"
+	merged, err = MergeYaml([]byte(existing), dp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(merged), "name: Store") || strings.Count(string(merged), "enriched") != 1 {
+		t.Fatalf("expected empty defaults not to be added got\n%s", merged)
+	}
+}
+
+func Test_MergeYaml(t *testing.T) {
+	dp := testDataProduct(t)
+	dp.Data.Name = "Store"
+	dp.Data.Owner = "web@acme.com"
+	dp.Data.EventSpecifications = []EventSpecCanonical{
+		{ResourceName: "es-3", Name: "Pay"},
+		dp.Data.EventSpecifications[0],
+	}
+
+	merged, err := MergeYaml([]byte(commentedDataProduct), dp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(merged)
+
+	expected := `# owned by the web team
+apiVersion: v1
+resourceType: data-product
+resourceName: dp-1
+data:
+  name: Store # renamed in 2024
+  description: "Checkout funnel"
+  sourceApplications:
+    - $ref: ./source-apps/web.yaml
+  owner: web@acme.com
+  eventSpecifications:
+    - resourceName: es-3
+      name: Pay
+      entities:
+        tracked: []
+        enriched: []
+    # keep first
+    - resourceName: es-1
+      name: Checkout
+      event:
+        source: iglu:com.acme/checkout/jsonschema/1-0-0
+      entities:
+        tracked: []
+        enriched: []
+`
+	if got != expected {
+		t.Fatalf("unexpected merge\n%s\nexpected\n%s", got, expected)
+	}
+}
+
+func Test_MergeYamlKeepsExtensions(t *testing.T) {
+	existing := `apiVersion: v1
+x-snowplow-cli-ignore:
+  - rule: trigger-description
+    reason: described in the tracking plan
+data:
+  name: Shop
+  x-note: kept
+  owner: web@acme.com
+`
+	merged, err := MergeYaml([]byte(existing), map[string]any{"apiVersion": "v1", "data": map[string]any{"name": "Store"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `apiVersion: v1
+x-snowplow-cli-ignore:
+  - rule: trigger-description
+    reason: described in the tracking plan
+data:
+  name: Store
+  x-note: kept
+`
+	if string(merged) != expected {
+		t.Fatalf("unexpected merge\n%s\nexpected\n%s", merged, expected)
+	}
+}
+
+func Test_MergeYamlAnchors(t *testing.T) {
+	existing := `shared: &ids
+    - web
+    - ios
+web: *ids
+mobile: *ids
+`
+	merged, err := MergeYaml([]byte(existing), map[string]any{
+		"shared": []string{"web", "ios"},
+		"web":    []string{"web", "ios"},
+		"mobile": []string{"ios"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(merged)
+	if !strings.Contains(got, "shared: &ids") || !strings.Contains(got, "web: *ids") || strings.Contains(got, "mobile: *ids") {
+		t.Fatalf("expected unchanged aliases to be kept got\n%s", got)
+	}
+	var decoded map[string][]string
+	if err := yaml.Unmarshal(merged, &decoded); err != nil || len(decoded["mobile"]) != 1 {
+		t.Fatalf("unexpected document %v %v", decoded, err)
+	}
+
+	if _, err := MergeYaml([]byte("a: 1\n---\nb: 2\n"), map[string]any{}); err == nil {
+		t.Fatal("expected several documents to fail")
+	}
+}
+
+func Test_MergeYamlMergeKeys(t *testing.T) {
+	existing := `defaults: &defaults
+  enriched: []
+  tracked: []
+web:
+  <<: *defaults
+  name: Web
+`
+	app := func(name string, tracked []string, enriched []string) map[string]any {
+		res := map[string]any{"name": name, "tracked": tracked}
+		if enriched != nil {
+			res["enriched"] = enriched
+		}
+		return res
+	}
+	defaults := map[string]any{"enriched": []string{}, "tracked": []string{}}
+
+	merged, err := MergeYaml([]byte(existing), map[string]any{"defaults": defaults, "web": app("Website", []string{}, []string{})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `defaults: &defaults
+  enriched: []
+  tracked: []
+web:
+  <<: *defaults
+  name: Website
+`
+	if string(merged) != expected {
+		t.Fatalf("expected the merge key to be kept got\n%s", merged)
+	}
+
+	merged, err = MergeYaml([]byte(existing), map[string]any{"defaults": defaults, "web": app("Web", []string{"iglu:com.acme/user/jsonschema/1-0-0"}, []string{})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(merged); !strings.Contains(got, "<<: *defaults") || !strings.Contains(got, "tracked:\n    - iglu:com.acme/user/jsonschema/1-0-0") {
+		t.Fatalf("expected the merge key to be kept with an override got\n%s", got)
+	}
+
+	// a merge key pulling in a value body does not have can not be kept
+	owned := "defaults: &defaults\n  owner: web team\nweb:\n  <<: *defaults\n  name: Web\n"
+	merged, err = MergeYaml([]byte(owned), map[string]any{"defaults": map[string]any{"owner": "web team"}, "web": map[string]any{"name": "Web"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]map[string]any
+	if err := yaml.Unmarshal(merged, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := decoded["web"]["owner"]; ok || strings.Contains(string(merged), "<<") {
+		t.Fatalf("expected the merge key to be written out got\n%s", merged)
+	}
+}
+
+func Test_MergeYamlDanglingAlias(t *testing.T) {
+	existing := `shared: &ids
+  - web
+  - ios
+web: *ids
+`
+	merged, err := MergeYaml([]byte(existing), map[string]any{"web": []string{"web", "ios"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `web:
+  - web
+  - ios
+`
+	if string(merged) != expected {
+		t.Fatalf("expected the alias of a removed anchor to be written out got\n%s", merged)
+	}
+}
+
+func Test_MergeYamlSequenceIndent(t *testing.T) {
+	existing := `name: Web
+appIds:
+  - web
+  - ios
+`
+	merged, err := MergeYaml([]byte(existing), map[string]any{"name": "Website", "appIds": []string{"web", "ios"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `name: Website
+appIds:
+  - web
+  - ios
+`
+	if string(merged) != expected {
+		t.Fatalf("expected the indentation of sequences to be kept got\n%s", merged)
+	}
+}
+
+func Test_CreateDataProductsKeepsComments(t *testing.T) {
+	dir := t.TempDir()
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	if err := os.MkdirAll("data-products", os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join("data-products", "shop.yaml")
+	if err := os.WriteFile(file, []byte(commentedDataProduct), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dp := testDataProduct(t)
+	dp.Data.Description = "Checkout and cart"
+	files := Files{DataProductsLocation: "data-products", ExtentionPreference: "yaml"}
+	if _, err := files.CreateDataProducts([]CliResource[DataProductCanonicalData]{dp}); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Replace(commentedDataProduct, `"Checkout funnel"`, `"Checkout and cart"`, 1)
+	if string(content) != expected {
+		t.Fatalf("unexpected file\n%s\nexpected\n%s", content, expected)
+	}
+}
@@ -0,0 +1,344 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package validation
+
+import (
+	"encoding/json"
+	"log/slog"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+
+	"github.com/snowplow/snowplow-cli/internal/console"
+)
+
+// keywords the local compatibility check does not reason about, constraints using them are undecidable
+var undecidableKeywords = []string{"$ref", "allOf", "anyOf", "oneOf", "not", "if", "then", "else", "patternProperties", "dependencies"}
+
+var allTypes = []string{"array", "boolean", "integer", "null", "number", "object", "string"}
+
+// NewLocalCompatChecker checks event specification schemas against data structures without BDP Console.
+// Data structures are looked up in locals, keyed by iglu uri, then with fetch
+func NewLocalCompatChecker(locals map[string]map[string]any, fetch func(uri string) (map[string]any, bool, error)) console.CompatChecker {
+	return func(event console.CompatCheckable, entities []console.CompatCheckable) (*console.CompatResult, error) {
+		result := &console.CompatResult{Status: console.CompatCompatible}
+		for _, checkable := range append([]console.CompatCheckable{event}, entities...) {
+			source, found := locals[checkable.Source]
+			if !found && fetch != nil {
+				var err error
+				source, found, err = fetch(checkable.Source)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			compat := console.CompatSource{Source: checkable.Source, Status: console.CompatUndecidable, Properties: map[string]string{}}
+			if found {
+				compat.Status, compat.Properties = SchemaCompat(checkable.Schema, source)
+			} else {
+				slog.Debug("validation", "msg", "no schema to check compatibility against", "source", checkable.Source)
+			}
+			result.Status = worse(result.Status, compat.Status)
+			result.Sources = append(result.Sources, compat)
+		}
+		return result, nil
+	}
+}
+
+// SchemaCompat tells whether instances can satisfy both the constraints of an event specification and
+// the schema of its data structure. Properties are keyed by their path in spec, eg. properties/a/items.
+// Undecidable properties are left to the caller, only incompatible ones make spec incompatible
+func SchemaCompat(spec map[string]any, source map[string]any) (console.CompatStatus, map[string]string) {
+	c := compatCheck{map[string]string{}}
+	if c.object("", normalize(spec), normalize(source)) == console.CompatIncompatible {
+		return console.CompatIncompatible, c.props
+	}
+	return console.CompatCompatible, c.props
+}
+
+type compatCheck struct {
+	props map[string]string
+}
+
+// object checks the properties spec constrains, recording their status under prefix
+func (c compatCheck) object(prefix string, spec map[string]any, source map[string]any) console.CompatStatus {
+	status := console.CompatCompatible
+	for _, k := range undecidableKeywords {
+		if _, ok := spec[k]; ok {
+			c.props[prefix+k] = console.CompatUndecidable
+		}
+	}
+
+	specProps, _ := spec["properties"].(map[string]any)
+	sourceProps, _ := source["properties"].(map[string]any)
+	closed := source["additionalProperties"] == false && !hasUndecidable(source)
+
+	names := []string{}
+	for name := range specProps {
+		names = append(names, name)
+	}
+	for _, r := range stringsOf(spec["required"]) {
+		if _, ok := specProps[r]; !ok && !slices.Contains(names, r) {
+			names = append(names, r)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		key := prefix + "properties/" + name
+		s, inSpec := specProps[name]
+		d, inSource := sourceProps[name]
+
+		var st console.CompatStatus
+		switch {
+		case !inSource && closed:
+			st = console.CompatIncompatible
+		case !inSource:
+			st = console.CompatUndecidable
+		case !inSpec:
+			continue
+		default:
+			st = c.value(key, s, d)
+		}
+		c.props[key] = st
+		status = worse(status, st)
+	}
+
+	if status == console.CompatUndecidable {
+		return console.CompatCompatible
+	}
+	return status
+}
+
+// value checks a property, it is compatible when at least one value satisfies both schemas
+func (c compatCheck) value(key string, spec any, source any) console.CompatStatus {
+	s, sOk := spec.(map[string]any)
+	d, dOk := source.(map[string]any)
+	if !sOk || !dOk {
+		if spec == false || source == false {
+			return console.CompatIncompatible
+		}
+		if spec == true || source == true {
+			return console.CompatCompatible
+		}
+		return console.CompatUndecidable
+	}
+	if hasUndecidable(s) || hasUndecidable(d) {
+		return console.CompatUndecidable
+	}
+
+	if values, ok := enumOf(s); ok {
+		return anySatisfies(values, d)
+	}
+	if values, ok := enumOf(d); ok {
+		return anySatisfies(values, s)
+	}
+
+	best := console.CompatIncompatible
+	for _, t := range intersectTypes(typesOf(s), typesOf(d)) {
+		best = better(best, c.typed(key, t, s, d))
+	}
+	return best
+}
+
+// typed checks the constraints both schemas put on values of type t
+func (c compatCheck) typed(key string, t string, s map[string]any, d map[string]any) console.CompatStatus {
+	switch t {
+	case "integer", "number":
+		lo, loEx := lowerBound(s, d)
+		hi, hiEx := upperBound(s, d)
+		if lo > hi || lo == hi && (loEx || hiEx) {
+			return console.CompatIncompatible
+		}
+		if t == "integer" {
+			first, last := math.Ceil(lo), math.Floor(hi)
+			if loEx && first == lo {
+				first++
+			}
+			if hiEx && last == hi {
+				last--
+			}
+			if first > last {
+				return console.CompatIncompatible
+			}
+		}
+		if differ(s, d, "multipleOf") {
+			return console.CompatUndecidable
+		}
+	case "string":
+		if max(number(s, "minLength", 0), number(d, "minLength", 0)) > min(number(s, "maxLength", math.Inf(1)), number(d, "maxLength", math.Inf(1))) {
+			return console.CompatIncompatible
+		}
+		if differ(s, d, "pattern") || differ(s, d, "format") {
+			return console.CompatUndecidable
+		}
+	case "array":
+		minItems := max(number(s, "minItems", 0), number(d, "minItems", 0))
+		if minItems > min(number(s, "maxItems", math.Inf(1)), number(d, "maxItems", math.Inf(1))) {
+			return console.CompatIncompatible
+		}
+		si, sOk := s["items"]
+		di, dOk := d["items"]
+		if sOk && dOk {
+			st := c.value(key+"/items", si, di)
+			c.props[key+"/items"] = st
+			// empty arrays satisfy any items
+			if st == console.CompatIncompatible && minItems > 0 {
+				return console.CompatIncompatible
+			}
+		}
+	case "object":
+		return c.object(key+"/", s, d)
+	}
+	return console.CompatCompatible
+}
+
+func hasUndecidable(schema map[string]any) bool {
+	for _, k := range undecidableKeywords {
+		if _, ok := schema[k]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func enumOf(schema map[string]any) ([]any, bool) {
+	if v, ok := schema["const"]; ok {
+		return []any{v}, true
+	}
+	values, ok := schema["enum"].([]any)
+	return values, ok
+}
+
+// anySatisfies validates values against schema, undecidable when it can not be compiled, eg. for patterns go does not support
+func anySatisfies(values []any, schema map[string]any) console.CompatStatus {
+	sch, err := compileSchema(schema)
+	if err != nil {
+		return console.CompatUndecidable
+	}
+	for _, v := range values {
+		if sch.Validate(v) == nil {
+			return console.CompatCompatible
+		}
+	}
+	return console.CompatIncompatible
+}
+
+func typesOf(schema map[string]any) []string {
+	switch t := schema["type"].(type) {
+	case string:
+		return []string{t}
+	case []any:
+		return stringsOf(t)
+	}
+	return allTypes
+}
+
+// intersectTypes keeps the types both sides allow, integers are numbers
+func intersectTypes(a []string, b []string) []string {
+	res := []string{}
+	for _, x := range a {
+		for _, y := range b {
+			switch {
+			case x == y:
+				res = append(res, x)
+			case x == "integer" && y == "number", x == "number" && y == "integer":
+				res = append(res, "integer")
+			}
+		}
+	}
+	slices.Sort(res)
+	return slices.Compact(res)
+}
+
+func lowerBound(schemas ...map[string]any) (float64, bool) {
+	bound, exclusive := math.Inf(-1), false
+	for _, s := range schemas {
+		v, ex := number(s, "minimum", math.Inf(-1)), s["exclusiveMinimum"] == true
+		// exclusive bounds are numbers after draft 4
+		if e, ok := s["exclusiveMinimum"].(float64); ok && e >= v {
+			v, ex = e, true
+		}
+		if v > bound || v == bound && ex {
+			bound, exclusive = v, ex
+		}
+	}
+	return bound, exclusive
+}
+
+func upperBound(schemas ...map[string]any) (float64, bool) {
+	bound, exclusive := math.Inf(1), false
+	for _, s := range schemas {
+		v, ex := number(s, "maximum", math.Inf(1)), s["exclusiveMaximum"] == true
+		if e, ok := s["exclusiveMaximum"].(float64); ok && e <= v {
+			v, ex = e, true
+		}
+		if v < bound || v == bound && ex {
+			bound, exclusive = v, ex
+		}
+	}
+	return bound, exclusive
+}
+
+func number(schema map[string]any, key string, def float64) float64 {
+	if v, ok := schema[key].(float64); ok {
+		return v
+	}
+	return def
+}
+
+// differ is true when both schemas set key to different values
+func differ(a map[string]any, b map[string]any, key string) bool {
+	x, xOk := a[key]
+	y, yOk := b[key]
+	return xOk && yOk && !reflect.DeepEqual(x, y)
+}
+
+func stringsOf(v any) []string {
+	res := []string{}
+	values, _ := v.([]any)
+	for _, s := range values {
+		if str, ok := s.(string); ok {
+			res = append(res, str)
+		}
+	}
+	return res
+}
+
+var compatOrder = []console.CompatStatus{console.CompatCompatible, console.CompatUndecidable, console.CompatIncompatible}
+
+func worse(a console.CompatStatus, b console.CompatStatus) console.CompatStatus {
+	if slices.Index(compatOrder, b) > slices.Index(compatOrder, a) {
+		return b
+	}
+	return a
+}
+
+func better(a console.CompatStatus, b console.CompatStatus) console.CompatStatus {
+	if slices.Index(compatOrder, b) < slices.Index(compatOrder, a) {
+		return b
+	}
+	return a
+}
+
+// normalize gives schemas decoded from yaml the types of decoded json, numbers as float64
+func normalize(schema map[string]any) map[string]any {
+	body, err := json.Marshal(schema)
+	if err != nil {
+		return schema
+	}
+	var res map[string]any
+	if err := json.Unmarshal(body, &res); err != nil {
+		return schema
+	}
+	return res
+}
@@ -0,0 +1,135 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"gopkg.in/yaml.v3"
+)
+
+const compatSource = `
+$schema: http://iglucentral.com/schemas/com.snowplowanalytics.self-desc/schema/jsonschema/1-0-0#
+self: {vendor: com.acme, name: checkout, format: jsonschema, version: 1-0-0}
+type: object
+additionalProperties: false
+properties:
+  step: {type: integer, minimum: 1, maximum: 5}
+  method: {type: string, enum: [card, paypal]}
+  coupon: {type: [string, "null"], maxLength: 8}
+  total: {type: number, minimum: 0}
+  currency: {type: string, pattern: "^[A-Z]{3}$"}
+  items:
+    type: array
+    items:
+      type: object
+      properties:
+        sku: {type: string}
+`
+
+func yamlMap(t *testing.T, s string) map[string]any {
+	t.Helper()
+	var m map[string]any
+	if err := yaml.Unmarshal([]byte(s), &m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func Test_SchemaCompat(t *testing.T) {
+	source := yamlMap(t, compatSource)
+
+	table := []struct {
+		name     string
+		spec     string
+		status   console.CompatStatus
+		property string
+		want     console.CompatStatus
+	}{
+		{"narrower range", `properties: {step: {type: integer, minimum: 2}}`, console.CompatCompatible, "properties/step", console.CompatCompatible},
+		{"disjoint range", `properties: {step: {minimum: 6}}`, console.CompatIncompatible, "properties/step", console.CompatIncompatible},
+		{"exclusive bounds", `properties: {step: {exclusiveMinimum: 4, exclusiveMaximum: 5}}`, console.CompatIncompatible, "properties/step", console.CompatIncompatible},
+		{"enum subset", `properties: {method: {enum: [card]}}`, console.CompatCompatible, "properties/method", console.CompatCompatible},
+		{"enum outside", `properties: {method: {const: cash}}`, console.CompatIncompatible, "properties/method", console.CompatIncompatible},
+		{"source enum against spec", `properties: {method: {type: string, maxLength: 3}}`, console.CompatIncompatible, "properties/method", console.CompatIncompatible},
+		{"disjoint types", `properties: {total: {type: string}}`, console.CompatIncompatible, "properties/total", console.CompatIncompatible},
+		{"disjoint number range", `properties: {total: {exclusiveMaximum: 0}}`, console.CompatIncompatible, "properties/total", console.CompatIncompatible},
+		{"integer of number", `properties: {total: {type: integer}}`, console.CompatCompatible, "properties/total", console.CompatCompatible},
+		{"null alternative", `properties: {coupon: {type: "null"}}`, console.CompatCompatible, "properties/coupon", console.CompatCompatible},
+		{"lengths", `properties: {coupon: {type: string, minLength: 9}}`, console.CompatIncompatible, "properties/coupon", console.CompatIncompatible},
+		{"other pattern", `properties: {currency: {pattern: "^EUR$"}}`, console.CompatCompatible, "properties/currency", console.CompatUndecidable},
+		{"value matching pattern", `properties: {currency: {const: EUR}}`, console.CompatCompatible, "properties/currency", console.CompatCompatible},
+		{"unknown property", `properties: {discount: {type: number}}`, console.CompatIncompatible, "properties/discount", console.CompatIncompatible},
+		{"required unknown property", `required: [discount]`, console.CompatIncompatible, "properties/discount", console.CompatIncompatible},
+		{"nested items", `properties: {items: {items: {properties: {sku: {type: integer}}}}}`, console.CompatCompatible, "properties/items/items/properties/sku", console.CompatIncompatible},
+		{"non empty nested items", `properties: {items: {minItems: 1, items: {properties: {sku: {type: integer}}}}}`, console.CompatIncompatible, "properties/items/items", console.CompatIncompatible},
+		{"combinators", `properties: {step: {anyOf: [{minimum: 2}, {minimum: 3}]}}`, console.CompatCompatible, "properties/step", console.CompatUndecidable},
+	}
+
+	for _, row := range table {
+		t.Run(row.name, func(t *testing.T) {
+			status, props := SchemaCompat(yamlMap(t, row.spec), source)
+			if status != row.status {
+				t.Errorf("status got %s want %s %v", status, row.status, props)
+			}
+			if props[row.property] != row.want {
+				t.Errorf("%s got %s want %s %v", row.property, props[row.property], row.want, props)
+			}
+		})
+	}
+}
+
+func Test_LocalCompatChecker(t *testing.T) {
+	local := "iglu:com.acme/checkout/jsonschema/1-0-0"
+	remote := "iglu:com.acme/user/jsonschema/1-0-0"
+	fetched := []string{}
+	fetch := func(uri string) (map[string]any, bool, error) {
+		fetched = append(fetched, uri)
+		switch uri {
+		case remote:
+			return yamlMap(t, `{type: object, properties: {id: {type: string}}}`), true, nil
+		case "iglu:com.acme/broken/jsonschema/1-0-0":
+			return nil, false, errors.New("unreachable")
+		}
+		return nil, false, nil
+	}
+	cc := NewLocalCompatChecker(map[string]map[string]any{local: yamlMap(t, compatSource)}, fetch)
+
+	result, err := cc(
+		console.CompatCheckable{Source: local, Schema: yamlMap(t, `properties: {step: {minimum: 9}}`)},
+		[]console.CompatCheckable{
+			{Source: remote, Schema: yamlMap(t, `properties: {id: {type: string}}`)},
+			{Source: "iglu:com.acme/missing/jsonschema/1-0-0", Schema: yamlMap(t, `properties: {}`)},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := []console.CompatStatus{}
+	for _, s := range result.Sources {
+		statuses = append(statuses, s.Status)
+	}
+	expected := []console.CompatStatus{console.CompatIncompatible, console.CompatCompatible, console.CompatUndecidable}
+	if result.Status != console.CompatIncompatible || !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("got %s %v", result.Status, statuses)
+	}
+	if !reflect.DeepEqual(fetched, []string{remote, "iglu:com.acme/missing/jsonschema/1-0-0"}) {
+		t.Errorf("expected local data structures not to be fetched got %v", fetched)
+	}
+
+	if _, err := cc(console.CompatCheckable{Source: "iglu:com.acme/broken/jsonschema/1-0-0"}, nil); err == nil {
+		t.Error("expected fetch errors to be returned")
+	}
+}
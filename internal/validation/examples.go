@@ -0,0 +1,258 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	. "github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/util"
+)
+
+type exampleResult struct {
+	File          string
+	DataStructure string
+	Counter       bool
+	Passed        bool
+	Messages      []string
+}
+
+func (r exampleResult) expectation() string {
+	if r.Counter {
+		return "invalid"
+	}
+	return "valid"
+}
+
+type ExampleResults struct {
+	Results []exampleResult
+}
+
+// Failed counts examples which did not validate and counterexamples which did
+func (er *ExampleResults) Failed() int {
+	failed := 0
+	for _, r := range er.Results {
+		if !r.Passed {
+			failed++
+		}
+	}
+	return failed
+}
+
+func (er *ExampleResults) Slog() {
+	for _, r := range er.Results {
+		if r.Passed {
+			slog.Info("test", "result", "pass", "file", r.File, "expected", r.expectation())
+		} else {
+			slog.Error("test", "result", "fail", "file", r.File, "expected", r.expectation(), "messages", strings.Join(r.Messages, "\n"))
+		}
+	}
+	slog.Info("test", "examples", len(er.Results), "failed", er.Failed())
+}
+
+func (er *ExampleResults) GithubAnnotate() {
+	for _, r := range er.Results {
+		if r.Passed {
+			fmt.Printf("::notice file=%s::%s example passed\n", r.File, r.expectation())
+		} else {
+			fmt.Printf("::error file=%s::expected %s%%0A%s\n", r.File, r.expectation(), strings.Join(r.Messages, "%0A"))
+		}
+	}
+}
+
+// ExampleFiles finds the examples and counterexamples kept next to a data structure file,
+// eg. com.acme/login.yaml has com.acme/login/examples/*.json and com.acme/login/counterexamples/*.json
+func ExampleFiles(dsFile string) (examples []string, counterexamples []string, err error) {
+	base := strings.TrimSuffix(dsFile, filepath.Ext(dsFile))
+	examples, err = jsonFiles(filepath.Join(base, util.ExamplesFolder))
+	if err != nil {
+		return nil, nil, err
+	}
+	counterexamples, err = jsonFiles(filepath.Join(base, util.CounterexamplesFolder))
+	if err != nil {
+		return nil, nil, err
+	}
+	return examples, counterexamples, nil
+}
+
+func jsonFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	files := []string{}
+	for _, e := range entries {
+		if !e.IsDir() && filepath.Ext(e.Name()) == ".json" {
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	return files, nil
+}
+
+// CompileData compiles the schema of a data structure for local validation
+func CompileData(ds DataStructure) (*jsonschema.Schema, error) {
+	schema := maps.Clone(ds.Data)
+	// the self describing meta schema is not needed to validate instances
+	delete(schema, "$schema")
+	delete(schema, "self")
+
+	return compileSchema(schema)
+}
+
+func compileSchema(schema map[string]any) (*jsonschema.Schema, error) {
+	body, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft4
+	compiler.AssertFormat = true
+	err = compiler.AddResource("data://schema.json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	return compiler.Compile("data://schema.json")
+}
+
+// InstanceErrors validates instance against sch, returning messages prefixed by their location
+func InstanceErrors(sch *jsonschema.Schema, instance any) []string {
+	err := sch.Validate(instance)
+	if err == nil {
+		return nil
+	}
+	ve, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return []string{err.Error()}
+	}
+	messages := []string{}
+	for _, e := range ve.BasicOutput().Errors {
+		if e.Error == "" {
+			continue
+		}
+		location := e.InstanceLocation
+		if location == "" {
+			location = "/"
+		}
+		messages = append(messages, fmt.Sprintf("%s: %s", location, e.Error))
+	}
+	if len(messages) == 0 {
+		messages = append(messages, ve.Error())
+	}
+	return messages
+}
+
+func readExample(file string) (any, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	dec.UseNumber()
+	var instance any
+	err = dec.Decode(&instance)
+	return instance, err
+}
+
+// unwrapExample returns the data of self describing examples, checking they target self
+func unwrapExample(instance any, self DataStructureSelf) (any, error) {
+	m, ok := instance.(map[string]any)
+	if !ok || len(m) != 2 {
+		return instance, nil
+	}
+	uri, ok := m["schema"].(string)
+	data, hasData := m["data"]
+	if !ok || !hasData || !strings.HasPrefix(uri, "iglu:") {
+		return instance, nil
+	}
+	want := self.IgluUri()
+	if uri != want {
+		return nil, fmt.Errorf("example is for %s, expected %s", uri, want)
+	}
+	return data, nil
+}
+
+// ValidateExamples checks examples validate and counterexamples do not for each data structure
+func ValidateExamples(dss map[string]DataStructure) (*ExampleResults, error) {
+	files := make([]string, 0, len(dss))
+	for f := range dss {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+
+	results := &ExampleResults{Results: []exampleResult{}}
+
+	for _, dsFile := range files {
+		examples, counterexamples, err := ExampleFiles(dsFile)
+		if err != nil {
+			return nil, err
+		}
+		if len(examples)+len(counterexamples) == 0 {
+			slog.Debug("test", "msg", "no examples", "file", dsFile)
+			continue
+		}
+
+		ds := dss[dsFile]
+		data, err := ds.ParseData()
+		if err != nil {
+			return nil, fmt.Errorf("file: %s: %w", dsFile, err)
+		}
+		sch, err := CompileData(ds)
+		if err != nil {
+			return nil, fmt.Errorf("file: %s: %w", dsFile, err)
+		}
+
+		test := func(file string, counter bool) exampleResult {
+			r := exampleResult{File: file, DataStructure: dsFile, Counter: counter}
+			instance, err := readExample(file)
+			if err == nil {
+				instance, err = unwrapExample(instance, data.Self)
+			}
+			if err != nil {
+				r.Messages = []string{err.Error()}
+				return r
+			}
+			r.Messages = InstanceErrors(sch, instance)
+			r.Passed = (len(r.Messages) == 0) != counter
+			if counter && r.Passed {
+				// the reasons it was rejected are informative, not failures
+				slog.Debug("test", "file", file, "rejected", strings.Join(r.Messages, "\n"))
+				r.Messages = nil
+			}
+			if counter && !r.Passed {
+				r.Messages = []string{"counterexample is valid"}
+			}
+			return r
+		}
+
+		for _, f := range examples {
+			results.Results = append(results.Results, test(f, false))
+		}
+		for _, f := range counterexamples {
+			results.Results = append(results.Results, test(f, true))
+		}
+	}
+
+	return results, nil
+}
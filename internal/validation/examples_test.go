@@ -0,0 +1,131 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package validation
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/util"
+)
+
+const loginClick = `apiVersion: v1
+resourceType: data-structure
+meta:
+  hidden: false
+  schemaType: event
+  customData: {}
+data:
+  $schema: http://iglucentral.com/schemas/com.snowplowanalytics.self-desc/schema/jsonschema/1-0-0#
+  self:
+    vendor: com.acme
+    name: login_click
+    format: jsonschema
+    version: 1-0-0
+  type: object
+  properties:
+    method:
+      type: string
+      enum: [sso, password]
+    id:
+      type: string
+      format: uuid
+  required: [method]
+  additionalProperties: false
+`
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func Test_ValidateExamples(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"com.acme/login_click.yaml":                          loginClick,
+		"com.acme/login_click/examples/sso.json":             `{"method":"sso","id":"3f1c2a4e-6b7d-4c8e-9f0a-1b2c3d4e5f60"}`,
+		"com.acme/login_click/examples/wrapped.json":         `{"schema":"iglu:com.acme/login_click/jsonschema/1-0-0","data":{"method":"password"}}`,
+		"com.acme/login_click/examples/bad_uuid.json":        `{"method":"sso","id":"not-a-uuid"}`,
+		"com.acme/login_click/examples/other_schema.json":    `{"schema":"iglu:com.acme/logout/jsonschema/1-0-0","data":{"method":"sso"}}`,
+		"com.acme/login_click/counterexamples/missing.json":  `{}`,
+		"com.acme/login_click/counterexamples/accepted.json": `{"method":"password"}`,
+		"com.acme/login_click/examples/notes.txt":            "ignored",
+	})
+
+	dss, err := util.DataStructuresFromPaths([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dss) != 1 {
+		t.Fatalf("examples should not be read as data structures, got %d", len(dss))
+	}
+
+	results, err := ValidateExamples(dss)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results.Results) != 6 {
+		t.Fatalf("expected 6 results got %d", len(results.Results))
+	}
+
+	passed := map[string]bool{}
+	for _, r := range results.Results {
+		passed[filepath.Base(r.File)] = r.Passed
+	}
+	expected := map[string]bool{
+		"sso.json":          true,
+		"wrapped.json":      true,
+		"bad_uuid.json":     false,
+		"other_schema.json": false,
+		"missing.json":      true,
+		"accepted.json":     false,
+	}
+	for f, want := range expected {
+		if passed[f] != want {
+			t.Errorf("%s: expected passed %v got %v", f, want, passed[f])
+		}
+	}
+	if results.Failed() != 3 {
+		t.Errorf("expected 3 failures got %d", results.Failed())
+	}
+
+	for _, r := range results.Results {
+		if filepath.Base(r.File) == "bad_uuid.json" && !strings.Contains(strings.Join(r.Messages, ""), "/id") {
+			t.Errorf("expected error location in %v", r.Messages)
+		}
+	}
+}
+
+func Test_ValidateExamplesNone(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"com.acme/login_click.yaml": loginClick})
+
+	dss, err := util.DataStructuresFromPaths([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := ValidateExamples(dss)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results.Results) != 0 || results.Failed() != 0 {
+		t.Fatalf("expected no results got %v", results.Results)
+	}
+}
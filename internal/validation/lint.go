@@ -0,0 +1,112 @@
+/**
+ * Copyright (c) 2013-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+ * located at https://docs.snowplow.io/limited-use-license-1.0
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package validation
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Lint rule severities, SeverityOff disables a rule
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+	SeverityOff     = "off"
+)
+
+var Severities = []string{SeverityError, SeverityWarning, SeverityInfo, SeverityOff}
+
+// LintRule is a check of tracking plan quality, reported with Severity unless configured otherwise
+type LintRule struct {
+	Id          string
+	Severity    string
+	Description string
+}
+
+// LintConfig overrides the severity of rules by id
+type LintConfig map[string]string
+
+// LintRules lists every built in rule
+func LintRules() []LintRule {
+	rules := []LintRule{}
+	for _, r := range dpLintRules {
+		rules = append(rules, r.LintRule)
+	}
+	for _, r := range dsLintRules {
+		rules = append(rules, r.LintRule)
+	}
+	return rules
+}
+
+// Check rejects unknown rule ids and severities
+func (config LintConfig) Check() error {
+	ids := []string{}
+	for _, r := range LintRules() {
+		ids = append(ids, r.Id)
+	}
+	for id, severity := range config {
+		if !slices.Contains(ids, id) {
+			return fmt.Errorf("unknown lint rule %s, expected one of %s", id, strings.Join(ids, ", "))
+		}
+		if !slices.Contains(Severities, severity) {
+			return fmt.Errorf("unknown severity %s for lint rule %s, expected one of %s", severity, id, strings.Join(Severities, ", "))
+		}
+	}
+	return nil
+}
+
+func (config LintConfig) severity(rule LintRule) string {
+	if severity, ok := config[rule.Id]; ok {
+		return severity
+	}
+	return rule.Severity
+}
+
+var inlineDisable = regexp.MustCompile(`#\s*snowplow-cli-disable:?[ \t]+([a-z0-9, \t-]+)`)
+
+// inlineDisabledRules finds the rules a yaml file disables for itself with comments like
+// # snowplow-cli-disable trigger-description, event-spec-event
+func inlineDisabledRules(content []byte) []string {
+	rules := []string{}
+	for _, m := range inlineDisable.FindAllSubmatch(content, -1) {
+		for _, id := range strings.FieldsFunc(string(m[1]), func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+			rules = append(rules, id)
+		}
+	}
+	return rules
+}
+
+// lintReporter hands the findings of rules to add, except in files disabling them
+type lintReporter struct {
+	disabled map[string][]string
+	add      func(file string, severity string, path string, msg string)
+}
+
+func newLintReporter(add func(file string, severity string, path string, msg string)) *lintReporter {
+	return &lintReporter{disabled: map[string][]string{}, add: add}
+}
+
+func (r *lintReporter) report(rule LintRule, severity string, file string, path string, msg string) {
+	disabled, ok := r.disabled[file]
+	if !ok {
+		content, _ := os.ReadFile(file)
+		disabled = inlineDisabledRules(content)
+		r.disabled[file] = disabled
+	}
+	if slices.Contains(disabled, rule.Id) {
+		return
+	}
+	r.add(file, severity, path, fmt.Sprintf("%s (%s)", msg, rule.Id))
+}
@@ -0,0 +1,215 @@
+/**
+ * Copyright (c) 2013-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+ * located at https://docs.snowplow.io/limited-use-license-1.0
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package validation
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+type dpReport func(file string, path string, msg string)
+
+type dpLintRule struct {
+	LintRule
+	check func(lookup *DPLookup, report dpReport)
+}
+
+var dpLintRules = []dpLintRule{
+	{LintRule{"event-spec-name-unique", SeverityError, "event specification names are unique within a data product"}, lintEventSpecNamesUnique},
+	{LintRule{"data-product-metadata", SeverityWarning, "data products have an owner, a domain and a description"}, lintDataProductMetadata},
+	{LintRule{"source-app-app-ids-overlap", SeverityWarning, "source applications do not share app ids"}, lintAppIdsOverlap},
+	{LintRule{"source-app-unused", SeverityWarning, "source applications are used by a data product"}, lintUnusedSourceApps},
+	{LintRule{"entity-declared-twice", SeverityWarning, "entities tracked by every source application of a data product are not declared again by its event specifications"}, lintEntitiesDeclaredTwice},
+	{LintRule{"trigger-description", SeverityWarning, "event specification triggers have a description"}, lintTriggerDescriptions},
+	{LintRule{"event-spec-event", SeverityWarning, "event specifications define an event"}, lintEventSpecEvents},
+}
+
+// Lint runs the data product rules across every resource found, with severities from config
+func (lookup *DPLookup) Lint(config LintConfig) {
+	r := newLintReporter(lookup.addFinding)
+	for _, rule := range dpLintRules {
+		severity := config.severity(rule.LintRule)
+		if severity == SeverityOff {
+			continue
+		}
+		rule.check(lookup, func(file string, path string, msg string) {
+			r.report(rule.LintRule, severity, file, path, msg)
+		})
+	}
+}
+
+func (lookup *DPLookup) addFinding(file string, severity string, path string, msg string) {
+	v := lookup.Validations[file]
+	if v.ErrorsWithPaths == nil {
+		v.ErrorsWithPaths = map[string][]string{}
+	}
+	if v.WarningsWithPaths == nil {
+		v.WarningsWithPaths = map[string][]string{}
+	}
+	switch {
+	case severity == SeverityError && path != "":
+		v.ErrorsWithPaths[path] = append(v.ErrorsWithPaths[path], msg)
+	case severity == SeverityError:
+		v.Errors = append(v.Errors, msg)
+	case severity == SeverityWarning && path != "":
+		v.WarningsWithPaths[path] = append(v.WarningsWithPaths[path], msg)
+	case severity == SeverityWarning:
+		v.Warnings = append(v.Warnings, msg)
+	case path != "":
+		v.Info = append(v.Info, fmt.Sprintf("%s: %s", path, msg))
+	default:
+		v.Info = append(v.Info, msg)
+	}
+	lookup.Validations[file] = v
+}
+
+func sortedFiles[T any](resources map[string]T) []string {
+	files := []string{}
+	for f := range resources {
+		files = append(files, f)
+	}
+	slices.Sort(files)
+	return files
+}
+
+func lintEventSpecNamesUnique(lookup *DPLookup, report dpReport) {
+	for _, f := range sortedFiles(lookup.DataProducts) {
+		seen := map[string]int{}
+		for i, spec := range lookup.DataProducts[f].Data.EventSpecifications {
+			name := strings.ToLower(strings.TrimSpace(spec.Name))
+			if first, ok := seen[name]; ok {
+				report(f, fmt.Sprintf("/data/eventSpecifications/%d/name", i), fmt.Sprintf("event specification name %q is already used by event specification %d", spec.Name, first))
+				continue
+			}
+			seen[name] = i
+		}
+	}
+}
+
+func lintDataProductMetadata(lookup *DPLookup, report dpReport) {
+	for _, f := range sortedFiles(lookup.DataProducts) {
+		data := lookup.DataProducts[f].Data
+		for _, field := range []struct{ name, value string }{{"owner", data.Owner}, {"domain", data.Domain}, {"description", data.Description}} {
+			if strings.TrimSpace(field.value) == "" {
+				report(f, "/data", fmt.Sprintf("data product has no %s", field.name))
+			}
+		}
+	}
+}
+
+func lintAppIdsOverlap(lookup *DPLookup, report dpReport) {
+	files := sortedFiles(lookup.SourceApps)
+	for _, f := range files {
+		for i, appId := range lookup.SourceApps[f].Data.AppIds {
+			for _, other := range files {
+				if other != f && slices.Contains(lookup.SourceApps[other].Data.AppIds, appId) {
+					report(f, fmt.Sprintf("/data/appIds/%d", i), fmt.Sprintf("app id %s is also used by source application %s", appId, lookup.SourceApps[other].Data.Name))
+				}
+			}
+		}
+	}
+}
+
+func lintUnusedSourceApps(lookup *DPLookup, report dpReport) {
+	// validating source applications alone, their data products are elsewhere
+	if len(lookup.DataProducts) == 0 {
+		return
+	}
+	used := map[string]bool{}
+	for _, dp := range lookup.DataProducts {
+		for _, ref := range dp.Data.SourceApplications {
+			used[ref["$ref"]] = true
+		}
+	}
+	for _, f := range sortedFiles(lookup.SourceApps) {
+		if !used[f] {
+			report(f, "", "source application is not used by any data product")
+		}
+	}
+}
+
+func lintEntitiesDeclaredTwice(lookup *DPLookup, report dpReport) {
+	for _, f := range sortedFiles(lookup.DataProducts) {
+		dp := lookup.DataProducts[f]
+		for i, spec := range dp.Data.EventSpecifications {
+			excluded := []string{}
+			for _, ref := range spec.ExcludedSourceApplications {
+				excluded = append(excluded, ref["$ref"])
+			}
+			sourceApps := []model.SourceApp{}
+			for _, ref := range dp.Data.SourceApplications {
+				if sa, ok := lookup.SourceApps[ref["$ref"]]; ok && !slices.Contains(excluded, ref["$ref"]) {
+					sourceApps = append(sourceApps, sa)
+				}
+			}
+			if len(sourceApps) == 0 {
+				continue
+			}
+
+			for j, entity := range spec.Entities.Tracked {
+				// only entities every source application tracks make the declaration redundant
+				everywhere := true
+				for _, sa := range sourceApps {
+					if !tracksEntity(sa, entity.Source) {
+						everywhere = false
+						break
+					}
+				}
+				if everywhere {
+					report(f, fmt.Sprintf("/data/eventSpecifications/%d/entities/tracked/%d", i, j), fmt.Sprintf("entity %s is already tracked by the source applications of the data product", entity.Source))
+				}
+			}
+		}
+	}
+}
+
+// tracksEntity compares schemas without their version, a different version is still the same entity
+func tracksEntity(sa model.SourceApp, source string) bool {
+	if sa.Data.Entities == nil {
+		return false
+	}
+	for _, tracked := range sa.Data.Entities.Tracked {
+		if withoutVersion(tracked.Source) == withoutVersion(source) {
+			return true
+		}
+	}
+	return false
+}
+
+func withoutVersion(uri string) string {
+	return path.Dir(uri)
+}
+
+func lintTriggerDescriptions(lookup *DPLookup, report dpReport) {
+	for _, f := range sortedFiles(lookup.DataProducts) {
+		for i, spec := range lookup.DataProducts[f].Data.EventSpecifications {
+			for j, trigger := range spec.Triggers {
+				if strings.TrimSpace(trigger.Description) == "" {
+					report(f, fmt.Sprintf("/data/eventSpecifications/%d/triggers/%d", i, j), "trigger has no description")
+				}
+			}
+		}
+	}
+}
+
+func lintEventSpecEvents(lookup *DPLookup, report dpReport) {
+	for _, f := range sortedFiles(lookup.DataProducts) {
+		for i, spec := range lookup.DataProducts[f].Data.EventSpecifications {
+			if spec.Event.Source == "" {
+				report(f, fmt.Sprintf("/data/eventSpecifications/%d", i), fmt.Sprintf("event specification %q has no event", spec.Name))
+			}
+		}
+	}
+}
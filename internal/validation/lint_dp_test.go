@@ -0,0 +1,150 @@
+/**
+ * Copyright (c) 2013-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+ * located at https://docs.snowplow.io/limited-use-license-1.0
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package validation
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+func lintLookup(dir string) *DPLookup {
+	web := filepath.Join(dir, "web.yaml")
+	mobile := filepath.Join(dir, "mobile.yaml")
+	unused := filepath.Join(dir, "unused.yaml")
+	shop := filepath.Join(dir, "shop.yaml")
+	user := []model.SchemaRef{{Source: "iglu:com.acme/user/jsonschema/1-0-0"}}
+
+	return &DPLookup{
+		DataProducts: map[string]model.DataProduct{
+			shop: {Data: model.DataProductData{
+				Name:               "Shop",
+				Owner:              "shop@acme.com",
+				SourceApplications: []map[string]string{{"$ref": web}, {"$ref": mobile}},
+				EventSpecifications: []model.EventSpec{
+					{
+						Name:     "Checkout",
+						Event:    model.SchemaRef{Source: "iglu:com.acme/checkout/jsonschema/1-0-0"},
+						Entities: model.EntitiesDef{Tracked: []model.SchemaRef{{Source: "iglu:com.acme/user/jsonschema/1-0-1"}, {Source: "iglu:com.acme/cart/jsonschema/1-0-0"}}},
+						Triggers: []model.Trigger{{Description: "Pay clicked"}, {Url: "https://acme.com"}},
+					},
+					{Name: "checkout "},
+					{
+						Name:                       "Cart",
+						ExcludedSourceApplications: []map[string]string{{"$ref": web}},
+						Event:                      model.SchemaRef{Source: "iglu:com.acme/cart/jsonschema/1-0-0"},
+						Entities:                   model.EntitiesDef{Tracked: []model.SchemaRef{{Source: "iglu:com.acme/cart/jsonschema/1-0-0"}}},
+					},
+				},
+			}},
+		},
+		SourceApps: map[string]model.SourceApp{
+			web:    {Data: model.SourceAppData{Name: "Web", AppIds: []string{"web", "shared"}, Entities: &model.EntitiesDef{Tracked: user}}},
+			mobile: {Data: model.SourceAppData{Name: "Mobile", AppIds: []string{"ios", "shared"}, Entities: &model.EntitiesDef{Tracked: append(user, model.SchemaRef{Source: "iglu:com.acme/cart/jsonschema/1-0-0"})}}},
+			unused: {Data: model.SourceAppData{Name: "Unused", AppIds: []string{"admin"}}},
+		},
+		Validations: map[string]DPValidations{},
+	}
+}
+
+func Test_LintDataProducts(t *testing.T) {
+	dir := t.TempDir()
+	lookup := lintLookup(dir)
+	lookup.Lint(LintConfig{"trigger-description": SeverityError, "data-product-metadata": SeverityInfo})
+
+	shop := lookup.Validations[filepath.Join(dir, "shop.yaml")]
+	expectedErrors := map[string][]string{
+		"/data/eventSpecifications/1/name":       {`event specification name "checkout " is already used by event specification 0 (event-spec-name-unique)`},
+		"/data/eventSpecifications/0/triggers/1": {"trigger has no description (trigger-description)"},
+	}
+	if !reflect.DeepEqual(shop.ErrorsWithPaths, expectedErrors) {
+		t.Fatalf("unexpected errors got %v", shop.ErrorsWithPaths)
+	}
+	expectedWarnings := map[string][]string{
+		"/data/eventSpecifications/0/entities/tracked/0": {"entity iglu:com.acme/user/jsonschema/1-0-1 is already tracked by the source applications of the data product (entity-declared-twice)"},
+		"/data/eventSpecifications/2/entities/tracked/0": {"entity iglu:com.acme/cart/jsonschema/1-0-0 is already tracked by the source applications of the data product (entity-declared-twice)"},
+		"/data/eventSpecifications/1":                    {`event specification "checkout " has no event (event-spec-event)`},
+	}
+	if !reflect.DeepEqual(shop.WarningsWithPaths, expectedWarnings) {
+		t.Fatalf("unexpected warnings got %v", shop.WarningsWithPaths)
+	}
+	expectedInfo := []string{"/data: data product has no domain (data-product-metadata)", "/data: data product has no description (data-product-metadata)"}
+	if !reflect.DeepEqual(shop.Info, expectedInfo) {
+		t.Fatalf("unexpected info got %v", shop.Info)
+	}
+
+	web := lookup.Validations[filepath.Join(dir, "web.yaml")]
+	if !reflect.DeepEqual(web.WarningsWithPaths, map[string][]string{"/data/appIds/1": {"app id shared is also used by source application Mobile (source-app-app-ids-overlap)"}}) {
+		t.Fatalf("unexpected warnings got %v", web.WarningsWithPaths)
+	}
+
+	unused := lookup.Validations[filepath.Join(dir, "unused.yaml")]
+	if !reflect.DeepEqual(unused.Warnings, []string{"source application is not used by any data product (source-app-unused)"}) {
+		t.Fatalf("unexpected warnings got %v", unused.Warnings)
+	}
+
+	if lookup.ValidationErrorCount() != 2 {
+		t.Fatalf("expected 2 errors got %d", lookup.ValidationErrorCount())
+	}
+}
+
+func Test_LintRulesOff(t *testing.T) {
+	dir := t.TempDir()
+	config := LintConfig{}
+	for _, r := range LintRules() {
+		config[r.Id] = SeverityOff
+	}
+	lookup := lintLookup(dir)
+	lookup.Lint(config)
+
+	for f, v := range lookup.Validations {
+		if len(v.Errors)+len(v.ErrorsWithPaths)+len(v.Warnings)+len(v.WarningsWithPaths)+len(v.Info) > 0 {
+			t.Fatalf("expected no findings for %s got %+v", f, v)
+		}
+	}
+}
+
+func Test_LintInlineDisable(t *testing.T) {
+	dir := t.TempDir()
+	content := "# snowplow-cli-disable: event-spec-name-unique, entity-declared-twice\n# snowplow-cli-disable event-spec-event\napiVersion: v1\n"
+	if err := os.WriteFile(filepath.Join(dir, "shop.yaml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lookup := lintLookup(dir)
+	lookup.Lint(LintConfig{})
+
+	shop := lookup.Validations[filepath.Join(dir, "shop.yaml")]
+	for path, msgs := range shop.ErrorsWithPaths {
+		t.Fatalf("expected no errors got %s %v", path, msgs)
+	}
+	for path, msgs := range shop.WarningsWithPaths {
+		if strings.HasPrefix(path, "/data/eventSpecifications/") && !strings.HasSuffix(path, "/triggers/1") {
+			t.Fatalf("expected only trigger warnings for event specifications got %s %v", path, msgs)
+		}
+	}
+}
+
+func Test_LintConfigCheck(t *testing.T) {
+	if err := (LintConfig{"trigger-description": SeverityError}).Check(); err != nil {
+		t.Fatal(err)
+	}
+	if err := (LintConfig{"no-such-rule": SeverityError}).Check(); err == nil {
+		t.Fatal("expected unknown rules to be rejected")
+	}
+	if err := (LintConfig{"trigger-description": "fatal"}).Check(); err == nil {
+		t.Fatal("expected unknown severities to be rejected")
+	}
+}
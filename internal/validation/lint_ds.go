@@ -0,0 +1,236 @@
+/**
+ * Copyright (c) 2013-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+ * located at https://docs.snowplow.io/limited-use-license-1.0
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package validation
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+type dsReport func(path string, msg string)
+
+type dsLintRule struct {
+	LintRule
+	check func(data model.DataStructureData, vendors []string, report dsReport)
+}
+
+var dsLintRules = []dsLintRule{
+	{LintRule{"snake-case-names", SeverityWarning, "data structure and property names are snake_case"}, lintSnakeCase},
+	{LintRule{"vendor-allowed", SeverityError, "vendors are in the lint.vendors allow-list of the config file"}, lintVendorAllowed},
+	{LintRule{"property-description", SeverityWarning, "properties have a description"}, lintPropertyDescriptions},
+	{LintRule{"additional-properties-false", SeverityWarning, "objects set additionalProperties to false"}, lintAdditionalProperties},
+	{LintRule{"string-max-length", SeverityWarning, "strings have a maxLength so warehouses do not truncate them"}, lintStringMaxLength},
+}
+
+var snakeCase = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+// formats of strings with a bounded length, they need no maxLength
+var boundedFormats = []string{"date", "date-time", "time", "uuid", "ipv4", "ipv6"}
+
+// LintDataStructures checks dss with the data structure rules and severities from config. vendors are
+// names or patterns like com.acme.* the vendor-allowed rule accepts, it accepts any when there are none
+func LintDataStructures(dss map[string]model.DataStructure, config LintConfig, vendors []string) *ValidationResults {
+	findings := newDSFindings()
+	r := newLintReporter(findings.add)
+
+	files := sortedFiles(dss)
+	for _, f := range files {
+		data, err := dss[f].ParseData()
+		if err != nil {
+			// reported by the local validation
+			continue
+		}
+		for _, rule := range dsLintRules {
+			severity := config.severity(rule.LintRule)
+			if severity == SeverityOff {
+				continue
+			}
+			rule.check(data, vendors, func(path string, msg string) {
+				r.report(rule.LintRule, severity, f, path, msg)
+			})
+		}
+	}
+
+	return findings.results(files, "lint errors")
+}
+
+// dsFindings collects findings on data structure files
+type dsFindings struct {
+	byFile map[string]map[igluValidationLevel][]string
+	failed int
+}
+
+func newDSFindings() *dsFindings {
+	return &dsFindings{byFile: map[string]map[igluValidationLevel][]string{}}
+}
+
+func (d *dsFindings) add(file string, severity string, path string, msg string) {
+	level := igluValidationInfo
+	switch severity {
+	case SeverityError:
+		level = igluValidationError
+		d.failed++
+	case SeverityWarning:
+		level = igluValidationWarn
+	}
+	if path != "" {
+		msg = fmt.Sprintf("%s: %s", path, msg)
+	}
+	if d.byFile[file] == nil {
+		d.byFile[file] = map[igluValidationLevel][]string{}
+	}
+	d.byFile[file][level] = append(d.byFile[file][level], msg)
+}
+
+// results lists findings by file then level, errors are counted as failures in the message
+func (d *dsFindings) results(files []string, failures string) *ValidationResults {
+	vr := &ValidationResults{Valid: d.failed == 0}
+	if d.failed > 0 {
+		vr.Message = fmt.Sprintf("%d %s", d.failed, failures)
+	}
+	for _, f := range files {
+		for _, level := range []igluValidationLevel{igluValidationError, igluValidationWarn, igluValidationInfo} {
+			if msgs := d.byFile[f][level]; len(msgs) > 0 {
+				vr.Iglu = append(vr.Iglu, igluValidation{f, msgs, level})
+			}
+		}
+	}
+	return vr
+}
+
+// Merge adds the findings of other after the ones of vr
+func (vr *ValidationResults) Merge(other *ValidationResults) {
+	vr.Iglu = append(vr.Iglu, other.Iglu...)
+	vr.Migration = append(vr.Migration, other.Migration...)
+	switch {
+	case other.Valid:
+	case vr.Valid:
+		vr.Valid, vr.Message = false, other.Message
+	default:
+		vr.Message = fmt.Sprintf("%s, %s", vr.Message, other.Message)
+	}
+}
+
+// walkSchema calls fn with every object schema under schema, starting with schema itself at the empty path,
+// and prop with every property of those objects. Either can be nil
+func walkSchema(schema map[string]any, fn func(path string, object map[string]any), prop func(path string, name string, property map[string]any)) {
+	var walk func(prefix string, s map[string]any)
+	walk = func(prefix string, s map[string]any) {
+		if fn != nil && isObjectSchema(s) {
+			fn(prefix, s)
+		}
+		properties, _ := s["properties"].(map[string]any)
+		names := []string{}
+		for name := range properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			p, ok := properties[name].(map[string]any)
+			if !ok {
+				continue
+			}
+			key := path.Join(prefix, "properties", name)
+			if prop != nil {
+				prop(key, name, p)
+			}
+			walk(key, p)
+		}
+		if items, ok := s["items"].(map[string]any); ok {
+			walk(path.Join(prefix, "items"), items)
+		}
+	}
+	walk("", schema)
+}
+
+func isObjectSchema(s map[string]any) bool {
+	_, hasProperties := s["properties"]
+	return hasProperties || slices.Contains(schemaTypes(s), "object")
+}
+
+func schemaTypes(s map[string]any) []string {
+	switch t := s["type"].(type) {
+	case string:
+		return []string{t}
+	case []any:
+		return stringsOf(t)
+	}
+	return []string{}
+}
+
+func lintSnakeCase(data model.DataStructureData, _ []string, report dsReport) {
+	if !snakeCase.MatchString(data.Self.Name) {
+		report("self/name", fmt.Sprintf("name %s is not snake_case", data.Self.Name))
+	}
+	walkSchema(data.Other, nil, func(path string, name string, _ map[string]any) {
+		if !snakeCase.MatchString(name) {
+			report(path, fmt.Sprintf("property %s is not snake_case", name))
+		}
+	})
+}
+
+func lintVendorAllowed(data model.DataStructureData, vendors []string, report dsReport) {
+	if len(vendors) == 0 {
+		return
+	}
+	for _, pattern := range vendors {
+		if matched, _ := path.Match(pattern, data.Self.Vendor); matched {
+			return
+		}
+	}
+	report("self/vendor", fmt.Sprintf("vendor %s is not allowed", data.Self.Vendor))
+}
+
+func lintPropertyDescriptions(data model.DataStructureData, _ []string, report dsReport) {
+	walkSchema(data.Other, nil, func(path string, name string, property map[string]any) {
+		if d, _ := property["description"].(string); d == "" {
+			report(path, fmt.Sprintf("property %s has no description", name))
+		}
+	})
+}
+
+func lintAdditionalProperties(data model.DataStructureData, _ []string, report dsReport) {
+	walkSchema(data.Other, func(path string, object map[string]any) {
+		if object["additionalProperties"] != false {
+			report(path, "object allows additional properties")
+		}
+	}, nil)
+}
+
+func lintStringMaxLength(data model.DataStructureData, _ []string, report dsReport) {
+	unbounded := func(s map[string]any) bool {
+		if !slices.Contains(schemaTypes(s), "string") {
+			return false
+		}
+		_, hasMaxLength := s["maxLength"]
+		_, hasEnum := s["enum"]
+		_, hasConst := s["const"]
+		format, _ := s["format"].(string)
+		return !hasMaxLength && !hasEnum && !hasConst && !slices.Contains(boundedFormats, format)
+	}
+	walkSchema(data.Other, nil, func(path string, name string, property map[string]any) {
+		if unbounded(property) {
+			report(path, fmt.Sprintf("string property %s has no maxLength", name))
+		}
+		// items of arrays, nested ones included, are not properties of their own
+		for items, ok := property["items"].(map[string]any); ok; items, ok = items["items"].(map[string]any) {
+			path += "/items"
+			if unbounded(items) {
+				report(path, fmt.Sprintf("string items of %s have no maxLength", name))
+			}
+		}
+	})
+}
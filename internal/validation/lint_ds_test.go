@@ -0,0 +1,113 @@
+/**
+ * Copyright (c) 2013-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+ * located at https://docs.snowplow.io/limited-use-license-1.0
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package validation
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+func lintDataStructure(vendor string, name string, schema map[string]any) model.DataStructure {
+	data := map[string]any{
+		"$schema": "http://iglucentral.com/schemas/com.snowplowanalytics.self-desc/schema/jsonschema/1-0-0#",
+		"self":    map[string]any{"vendor": vendor, "name": name, "format": "jsonschema", "version": "1-0-0"},
+	}
+	for k, v := range schema {
+		data[k] = v
+	}
+	return model.DataStructure{ApiVersion: "v1", ResourceType: "data-structure", Data: data}
+}
+
+func Test_LintDataStructures(t *testing.T) {
+	dir := t.TempDir()
+	checkout := filepath.Join(dir, "checkout.yaml")
+	login := filepath.Join(dir, "login.yaml")
+
+	dss := map[string]model.DataStructure{
+		checkout: lintDataStructure("com.acme.shop", "checkout", map[string]any{
+			"type":                 "object",
+			"additionalProperties": false,
+			"properties": map[string]any{
+				"order_id": map[string]any{"type": "string", "description": "Order", "format": "uuid"},
+				"coupon":   map[string]any{"type": []any{"string", "null"}, "description": "Coupon code", "maxLength": 32},
+				"items": map[string]any{"type": "array", "description": "Items", "items": map[string]any{
+					"type":       "object",
+					"properties": map[string]any{"sku": map[string]any{"type": "string", "description": "Sku", "enum": []any{"a", "b"}}},
+				}},
+			},
+		}),
+		login: lintDataStructure("io.other", "LoginEvent", map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"userName": map[string]any{"type": "string"},
+				"tags":     map[string]any{"type": "array", "description": "Tags", "items": map[string]any{"type": "string"}},
+				"codes":    map[string]any{"type": "array", "description": "Codes", "items": map[string]any{"type": "string", "maxLength": 8}},
+			},
+		}),
+	}
+
+	vr := LintDataStructures(dss, LintConfig{"snake-case-names": SeverityInfo}, []string{"com.acme", "com.acme.*"})
+
+	expected := []igluValidation{
+		{checkout, []string{"properties/items/items: object allows additional properties (additional-properties-false)"}, igluValidationWarn},
+		{login, []string{"self/vendor: vendor io.other is not allowed (vendor-allowed)"}, igluValidationError},
+		{login, []string{
+			"properties/userName: property userName has no description (property-description)",
+			"object allows additional properties (additional-properties-false)",
+			"properties/tags/items: string items of tags have no maxLength (string-max-length)",
+			"properties/userName: string property userName has no maxLength (string-max-length)",
+		}, igluValidationWarn},
+		{login, []string{
+			"self/name: name LoginEvent is not snake_case (snake-case-names)",
+			"properties/userName: property userName is not snake_case (snake-case-names)",
+		}, igluValidationInfo},
+	}
+	if !reflect.DeepEqual(vr.Iglu, expected) {
+		t.Fatalf("unexpected findings got\n%v\nwant\n%v", vr.Iglu, expected)
+	}
+	if vr.Valid || vr.Message != "1 lint errors" {
+		t.Fatalf("expected lint errors to fail validation got %+v", vr)
+	}
+}
+
+func Test_LintDataStructuresInlineDisable(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "login.yaml")
+	if err := os.WriteFile(file, []byte("# snowplow-cli-disable vendor-allowed\napiVersion: v1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dss := map[string]model.DataStructure{
+		file: lintDataStructure("io.other", "login", map[string]any{"type": "object", "additionalProperties": false, "properties": map[string]any{}}),
+	}
+
+	vr := LintDataStructures(dss, LintConfig{}, []string{"com.acme"})
+	if !vr.Valid || len(vr.Iglu) != 0 {
+		t.Fatalf("expected no findings got %+v", vr)
+	}
+}
+
+func Test_ValidationResultsMerge(t *testing.T) {
+	vr := &ValidationResults{Valid: false, Message: "1 lint errors"}
+	vr.Merge(&ValidationResults{Valid: false, Message: "2 validation failures", Iglu: []igluValidation{{"a", []string{"x"}, igluValidationError}}})
+	if vr.Valid || vr.Message != "1 lint errors, 2 validation failures" || len(vr.Iglu) != 1 {
+		t.Fatalf("unexpected merge got %+v", vr)
+	}
+
+	vr = &ValidationResults{Valid: true}
+	vr.Merge(&ValidationResults{Valid: true})
+	if !vr.Valid || vr.Message != "" {
+		t.Fatalf("unexpected merge got %+v", vr)
+	}
+}
@@ -19,8 +19,8 @@ import (
 
 	"github.com/go-viper/mapstructure/v2"
 	"github.com/snowplow/snowplow-cli/internal/console"
-	snplog "github.com/snowplow/snowplow-cli/internal/logging"
 	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/util"
 )
 
 type DPLookup struct {
@@ -196,7 +196,7 @@ func (lookup *DPLookup) resolveRefs() error {
 						for _, absSaPath := range resolvedSas {
 							relPath, err := filepath.Rel(filepath.Dir(dpFile), absSaPath)
 							if err != nil {
-								snplog.LogFatal(err)
+								return err
 							}
 							relativeSas = append(relativeSas, relPath)
 						}
@@ -274,6 +274,63 @@ func (lookup *DPLookup) GhAnnotateValidations(basePath string) error {
 	return nil
 }
 
+func (lookup *DPLookup) MarkdownValidations(b *strings.Builder, basePath string) error {
+	b.WriteString("### Validation\n\n")
+
+	files := []string{}
+	for f := range lookup.Validations {
+		files = append(files, f)
+	}
+	slices.Sort(files)
+
+	rows := []string{}
+	for _, f := range files {
+		v := lookup.Validations[f]
+		rp, err := filepath.Rel(basePath, f)
+		if err != nil {
+			return err
+		}
+		row := func(level string, path string, msg string) {
+			rows = append(rows, fmt.Sprintf("| %s | `%s` | %s | %s |", level, rp, path, util.MarkdownCell(msg)))
+		}
+		for _, m := range v.Errors {
+			row("error", "", m)
+		}
+		for _, k := range sortedKeys(v.ErrorsWithPaths) {
+			row("error", "`"+k+"`", strings.Join(v.ErrorsWithPaths[k], "\n"))
+		}
+		for _, m := range v.Warnings {
+			row("warning", "", m)
+		}
+		for _, k := range sortedKeys(v.WarningsWithPaths) {
+			row("warning", "`"+k+"`", strings.Join(v.WarningsWithPaths[k], "\n"))
+		}
+		for _, m := range v.Info {
+			row("info", "", m)
+		}
+	}
+
+	if len(rows) == 0 {
+		b.WriteString("No findings.\n\n")
+		return nil
+	}
+
+	b.WriteString("| Level | File | Path | Message |\n|---|---|---|---|\n")
+	b.WriteString(strings.Join(rows, "\n"))
+	b.WriteString("\n\n")
+
+	return nil
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
 func (lookup *DPLookup) ValidationErrorCount() int {
 	count := 0
 	for _, v := range lookup.Validations {
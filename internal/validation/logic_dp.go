@@ -11,61 +11,57 @@
 package validation
 
 import (
-	"context"
 	"fmt"
 	"log/slog"
 
 	"github.com/snowplow/snowplow-cli/internal/console"
-	snplog "github.com/snowplow/snowplow-cli/internal/logging"
 )
 
-func Validate(cnx context.Context, c *console.ApiClient, files map[string]map[string]any, searchPaths []string, basePath string, ghOut bool, validateAll bool, changedIdToFile map[string]string) {
+// Validate checks files with sdc resolving referenced schemas and cc checking event specification compatibility,
+// then runs checks and drops the findings resources or the baseline accept
+func Validate(sdc console.SchemaDeployChecker, cc console.CompatChecker, files map[string]map[string]any, searchPaths []string, basePath string, ghOut bool, validateAll bool, changedIdToFile map[string]string, checks Checks) (*DPLookup, error) {
 	possibleFiles := []string{}
 	for n := range files {
 		possibleFiles = append(possibleFiles, n)
 	}
 
-	schemaResolver, err := console.NewSchemaDeployChecker(cnx, c)
+	lookup, err := NewDPLookup(cc, sdc, files, changedIdToFile, validateAll)
 	if err != nil {
-		snplog.LogFatal(err)
+		return nil, err
 	}
-
-	compatChecker := func(event console.CompatCheckable, entities []console.CompatCheckable) (*console.CompatResult, error) {
-		return console.CompatCheck(cnx, c, event, entities)
-	}
-
-	lookup, err := NewDPLookup(compatChecker, schemaResolver, files, changedIdToFile, validateAll)
+	lookup.Lint(checks.Lint)
+	lookup.Policies(checks.Policies, files)
+	err = lookup.Suppress(files, checks.Baseline, basePath)
 	if err != nil {
-		snplog.LogFatal(err)
+		return nil, err
 	}
 
 	slog.Debug("validation", "msg", "from", "paths", searchPaths, "files", possibleFiles)
 
 	err = lookup.SlogValidations(basePath)
 	if err != nil {
-		snplog.LogFatal(err)
+		return nil, err
 	}
 
 	if ghOut {
 		err := lookup.GhAnnotateValidations(basePath)
 		if err != nil {
-			snplog.LogFatal(err)
+			return nil, err
 		}
 	}
 
+	return lookup, nil
+}
+
+// Result returns an error when any validation errors were found, otherwise logs a summary
+func (lookup *DPLookup) Result() error {
 	numErrors := lookup.ValidationErrorCount()
 
 	if numErrors > 0 {
-		snplog.LogFatal(fmt.Errorf("validation failed %d errors", numErrors))
-	} else {
-		dpCount := 0
-		for range lookup.DataProducts {
-			dpCount++
-		}
-		saCount := 0
-		for range lookup.SourceApps {
-			saCount++
-		}
-		slog.Info("validation", "msg", "success", "data products found", dpCount, "source applications found", saCount)
+		return fmt.Errorf("validation failed %d errors", numErrors)
 	}
+
+	slog.Info("validation", "msg", "success", "data products found", len(lookup.DataProducts), "source applications found", len(lookup.SourceApps))
+
+	return nil
 }
@@ -0,0 +1,190 @@
+/**
+ * Copyright (c) 2013-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+ * located at https://docs.snowplow.io/limited-use-license-1.0
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/google/cel-go/cel"
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+// Resource types policies apply to
+const (
+	PolicyDataStructure     = "data-structure"
+	PolicyDataProduct       = "data-product"
+	PolicySourceApplication = "source-application"
+)
+
+var PolicyResourceTypes = []string{PolicyDataStructure, PolicyDataProduct, PolicySourceApplication}
+
+// Policy is a CEL expression every resource it applies to must satisfy, eg.
+// resource.data.owner.endsWith("@acme.com"). Resources are seen as they are written to files
+type Policy struct {
+	Id string `yaml:"id"`
+	// Resources are the resource types the policy applies to, all when empty
+	Resources  []string `yaml:"resources,omitempty"`
+	Expression string   `yaml:"expression"`
+	// Message is a text/template executed with the resource, eg. {{ .data.name }} has no owner
+	Message  string `yaml:"message,omitempty"`
+	Severity string `yaml:"severity,omitempty"`
+}
+
+type compiledPolicy struct {
+	Policy
+	program cel.Program
+	message *template.Template
+}
+
+// PolicyEngine evaluates policies without any remote service
+type PolicyEngine struct {
+	policies []compiledPolicy
+}
+
+type policyFinding struct {
+	rule     LintRule
+	severity string
+	msg      string
+}
+
+// NewPolicyEngine compiles policies, their expressions must be booleans. Severity defaults to error
+func NewPolicyEngine(policies []Policy) (*PolicyEngine, error) {
+	env, err := cel.NewEnv(cel.Variable("resource", cel.DynType))
+	if err != nil {
+		return nil, err
+	}
+
+	engine := &PolicyEngine{}
+	ids := []string{}
+	for i, p := range policies {
+		if p.Id == "" {
+			return nil, fmt.Errorf("policy %d has no id", i+1)
+		}
+		if slices.Contains(ids, p.Id) {
+			return nil, fmt.Errorf("policy %s is defined twice", p.Id)
+		}
+		ids = append(ids, p.Id)
+
+		if p.Severity == "" {
+			p.Severity = SeverityError
+		}
+		if !slices.Contains(Severities, p.Severity) {
+			return nil, fmt.Errorf("policy %s: unknown severity %s, expected one of %s", p.Id, p.Severity, strings.Join(Severities, ", "))
+		}
+		for _, r := range p.Resources {
+			if !slices.Contains(PolicyResourceTypes, r) {
+				return nil, fmt.Errorf("policy %s: unknown resource type %s, expected one of %s", p.Id, r, strings.Join(PolicyResourceTypes, ", "))
+			}
+		}
+
+		ast, issues := env.Compile(p.Expression)
+		if issues != nil && issues.Err() != nil {
+			return nil, fmt.Errorf("policy %s: %w", p.Id, issues.Err())
+		}
+		if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+			return nil, fmt.Errorf("policy %s: expression is a %s, expected a bool", p.Id, ast.OutputType())
+		}
+		program, err := env.Program(ast)
+		if err != nil {
+			return nil, fmt.Errorf("policy %s: %w", p.Id, err)
+		}
+
+		message := p.Message
+		if message == "" {
+			message = fmt.Sprintf("violates policy %s", p.Id)
+		}
+		tmpl, err := template.New(p.Id).Parse(message)
+		if err != nil {
+			return nil, fmt.Errorf("policy %s: %w", p.Id, err)
+		}
+
+		engine.policies = append(engine.policies, compiledPolicy{p, program, tmpl})
+	}
+	return engine, nil
+}
+
+// evaluate returns the policies resource of resourceType violates, expressions failing to evaluate,
+// eg. on a missing key, are findings too
+func (e *PolicyEngine) evaluate(resourceType string, resource map[string]any) []policyFinding {
+	if e == nil {
+		return nil
+	}
+	findings := []policyFinding{}
+	for _, p := range e.policies {
+		if p.Severity == SeverityOff || len(p.Resources) > 0 && !slices.Contains(p.Resources, resourceType) {
+			continue
+		}
+		rule := LintRule{Id: p.Id, Severity: p.Severity, Description: p.Expression}
+
+		out, _, err := p.program.Eval(map[string]any{"resource": resource})
+		if err != nil {
+			findings = append(findings, policyFinding{rule, p.Severity, fmt.Sprintf("could not evaluate policy: %s", err)})
+			continue
+		}
+		ok, isBool := out.Value().(bool)
+		if !isBool {
+			findings = append(findings, policyFinding{rule, p.Severity, fmt.Sprintf("policy evaluated to %v, expected a bool", out.Value())})
+			continue
+		}
+		if ok {
+			continue
+		}
+
+		var msg strings.Builder
+		if err := p.message.Execute(&msg, resource); err != nil {
+			msg.Reset()
+			fmt.Fprintf(&msg, "violates policy %s", p.Id)
+		}
+		findings = append(findings, policyFinding{rule, p.Severity, msg.String()})
+	}
+	return findings
+}
+
+// Policies evaluates engine against every data product and source application in files, the resources lookup was built from
+func (lookup *DPLookup) Policies(engine *PolicyEngine, files map[string]map[string]any) {
+	r := newLintReporter(lookup.addFinding)
+	for _, f := range sortedFiles(files) {
+		resourceType, _ := files[f]["resourceType"].(string)
+		if resourceType != PolicyDataProduct && resourceType != PolicySourceApplication {
+			continue
+		}
+		for _, finding := range engine.evaluate(resourceType, normalize(files[f])) {
+			r.report(finding.rule, finding.severity, f, "", finding.msg)
+		}
+	}
+}
+
+// CheckDSPolicies evaluates engine against every data structure
+func CheckDSPolicies(engine *PolicyEngine, dss map[string]model.DataStructure) (*ValidationResults, error) {
+	findings := newDSFindings()
+	r := newLintReporter(findings.add)
+
+	files := sortedFiles(dss)
+	for _, f := range files {
+		body, err := json.Marshal(dss[f])
+		if err != nil {
+			return nil, err
+		}
+		var resource map[string]any
+		if err := json.Unmarshal(body, &resource); err != nil {
+			return nil, err
+		}
+		for _, finding := range engine.evaluate(PolicyDataStructure, resource) {
+			r.report(finding.rule, finding.severity, f, "", finding.msg)
+		}
+	}
+
+	return findings.results(files, "policy violations"), nil
+}
@@ -0,0 +1,113 @@
+/**
+ * Copyright (c) 2013-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+ * located at https://docs.snowplow.io/limited-use-license-1.0
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package validation
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+func Test_NewPolicyEngineErrors(t *testing.T) {
+	cases := map[string][]Policy{
+		"no id":             {{Expression: "true"}},
+		"duplicate id":      {{Id: "a", Expression: "true"}, {Id: "a", Expression: "false"}},
+		"unknown severity":  {{Id: "a", Expression: "true", Severity: "fatal"}},
+		"unknown resource":  {{Id: "a", Expression: "true", Resources: []string{"event-spec"}}},
+		"syntax error":      {{Id: "a", Expression: "resource.data.name =="}},
+		"not a bool":        {{Id: "a", Expression: "'name'"}},
+		"template error":    {{Id: "a", Expression: "true", Message: "{{ .data.name"}},
+		"undeclared global": {{Id: "a", Expression: "data.name == 'x'"}},
+	}
+	for name, policies := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewPolicyEngine(policies); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func Test_DPPolicies(t *testing.T) {
+	engine, err := NewPolicyEngine([]Policy{
+		{
+			Id:         "team-owner",
+			Resources:  []string{PolicyDataProduct},
+			Expression: `has(resource.data.owner) && resource.data.owner.endsWith("@acme.com")`,
+			Message:    "data product {{ .data.name }} is not owned by a team",
+		},
+		{
+			Id:         "app-ids-prefixed",
+			Resources:  []string{PolicySourceApplication},
+			Expression: `resource.data.appIds.all(id, id.startsWith("acme-"))`,
+			Severity:   SeverityWarning,
+		},
+		{
+			Id:         "few-event-specs",
+			Resources:  []string{PolicyDataProduct},
+			Expression: `size(resource.data.eventSpecifications) < 2`,
+			Severity:   SeverityInfo,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	shop := filepath.Join(dir, "shop.yaml")
+	web := filepath.Join(dir, "web.yaml")
+	files := map[string]map[string]any{
+		shop: {"resourceType": "data-product", "data": map[string]any{"name": "Shop", "owner": "jane@gmail.com"}},
+		web:  {"resourceType": "source-application", "data": map[string]any{"name": "Web", "appIds": []any{"acme-web", "web"}}},
+	}
+	lookup := &DPLookup{Validations: map[string]DPValidations{}}
+	lookup.Policies(engine, files)
+
+	if !reflect.DeepEqual(lookup.Validations[shop].Errors, []string{"data product Shop is not owned by a team (team-owner)"}) {
+		t.Fatalf("unexpected errors got %v", lookup.Validations[shop].Errors)
+	}
+	info := lookup.Validations[shop].Info
+	if len(info) != 1 || info[0] != "could not evaluate policy: no such key: eventSpecifications (few-event-specs)" {
+		t.Fatalf("expected missing keys to be reported got %v", info)
+	}
+	if !reflect.DeepEqual(lookup.Validations[web].Warnings, []string{"violates policy app-ids-prefixed (app-ids-prefixed)"}) {
+		t.Fatalf("unexpected warnings got %v", lookup.Validations[web].Warnings)
+	}
+}
+
+func Test_DSPolicies(t *testing.T) {
+	engine, err := NewPolicyEngine([]Policy{
+		{Id: "entities-hidden", Resources: []string{PolicyDataStructure}, Expression: `resource.meta.schemaType != "entity" || resource.meta.hidden`, Message: "entity {{ .data.self.name }} must be hidden"},
+		{Id: "dp-only", Resources: []string{PolicyDataProduct}, Expression: `false`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entity := lintDataStructure("com.acme", "user", map[string]any{})
+	entity.Meta.SchemaType = "entity"
+	event := lintDataStructure("com.acme", "login", map[string]any{})
+	event.Meta.SchemaType = "event"
+
+	vr, err := CheckDSPolicies(engine, map[string]model.DataStructure{"user.yaml": entity, "login.yaml": event})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []igluValidation{{"user.yaml", []string{"entity user must be hidden (entities-hidden)"}, igluValidationError}}
+	if !reflect.DeepEqual(vr.Iglu, expected) {
+		t.Fatalf("unexpected findings got %v", vr.Iglu)
+	}
+	if vr.Valid || vr.Message != "1 policy violations" {
+		t.Fatalf("expected violations to fail validation got %+v", vr)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"fmt"
 	. "github.com/snowplow/snowplow-cli/internal/changes"
 	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/util"
 	"log/slog"
 	"strings"
 )
@@ -91,6 +92,36 @@ func (vr *ValidationResults) Slog() {
 	}
 }
 
+func (vr *ValidationResults) Markdown(b *strings.Builder) {
+	b.WriteString("### Validation\n\n")
+
+	if len(vr.Iglu) == 0 && len(vr.Migration) == 0 {
+		b.WriteString("No findings.\n\n")
+		return
+	}
+
+	b.WriteString("| Level | File | Message |\n|---|---|---|\n")
+	for _, iglu := range vr.Iglu {
+		var level string
+		switch iglu.Level {
+		case igluValidationError:
+			level = "error"
+		case igluValidationWarn:
+			level = "warning"
+		case igluValidationInfo:
+			level = "info"
+		}
+		for _, m := range iglu.Messages {
+			fmt.Fprintf(b, "| %s | `%s` | %s |\n", level, iglu.File, util.MarkdownCell(m))
+		}
+	}
+	for _, m := range vr.Migration {
+		msg := fmt.Sprintf("suggested version %s for %s: %s", m.Suggested, m.Destination, strings.Join(m.Messages, "; "))
+		fmt.Fprintf(b, "| error | `%s` | %s |\n", m.File, util.MarkdownCell(msg))
+	}
+	b.WriteString("\n")
+}
+
 func ValidateChanges(cnx context.Context, c *console.ApiClient, changes Changes) (*ValidationResults, error) {
 	var vr ValidationResults
 
@@ -0,0 +1,281 @@
+/**
+ * Copyright (c) 2013-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+ * located at https://docs.snowplow.io/limited-use-license-1.0
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+// Checks are the local checks Validate runs besides resolving schemas and checking compatibility
+type Checks struct {
+	Lint LintConfig
+	// Policies can be nil
+	Policies *PolicyEngine
+	// Baseline holds the findings accepted so far, can be nil
+	Baseline *Baseline
+}
+
+// BaselineFinding is a finding accepted in a baseline, files are relative and slash separated
+type BaselineFinding struct {
+	File    string `json:"file"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+// Baseline lists findings which no longer fail validation, so only new ones do
+type Baseline struct {
+	Findings []BaselineFinding `json:"findings"`
+}
+
+// ReadBaseline reads a baseline written by Write
+func ReadBaseline(path string) (*Baseline, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var b Baseline
+	if err := json.Unmarshal(content, &b); err != nil {
+		return nil, fmt.Errorf("baseline %s: %w", path, err)
+	}
+	return &b, nil
+}
+
+// Write saves b sorted so baselines diff well
+func (b *Baseline) Write(path string) error {
+	slices.SortFunc(b.Findings, func(x, y BaselineFinding) int {
+		return strings.Compare(x.File+"\x00"+x.Path+"\x00"+x.Message, y.File+"\x00"+y.Path+"\x00"+y.Message)
+	})
+	b.Findings = slices.Compact(b.Findings)
+	content, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(content, '\n'), 0644)
+}
+
+func (b *Baseline) contains(f BaselineFinding) bool {
+	return b != nil && slices.Contains(b.Findings, f)
+}
+
+func (b *Baseline) add(file string, path string, msgs ...string) {
+	for _, m := range msgs {
+		b.Findings = append(b.Findings, BaselineFinding{file, path, m})
+	}
+}
+
+// suppression is an x-snowplow-cli-ignore entry, matching the findings of a rule, findings with matching messages or both
+type suppression struct {
+	model.Ignore
+	message *regexp.Regexp
+}
+
+func (s suppression) matches(msg string) bool {
+	if s.Rule != "" && !strings.HasSuffix(msg, fmt.Sprintf("(%s)", s.Rule)) {
+		return false
+	}
+	return s.message == nil || s.message.MatchString(msg)
+}
+
+// suppressions compiles the x-snowplow-cli-ignore entries of a resource, entries without a reason suppress nothing
+func suppressions(ignores []model.Ignore) ([]suppression, error) {
+	res := []suppression{}
+	for _, i := range ignores {
+		if i.Reason == "" || i.Rule == "" && i.Message == "" {
+			continue
+		}
+		s := suppression{Ignore: i}
+		if i.Message != "" {
+			re, err := regexp.Compile(i.Message)
+			if err != nil {
+				return nil, fmt.Errorf("%s: message %s is not a valid regular expression: %w", model.IgnoreKey, i.Message, err)
+			}
+			s.message = re
+		}
+		res = append(res, s)
+	}
+	return res, nil
+}
+
+// suppressor decides which findings of a file are kept, counting the others
+type suppressor struct {
+	baseline *Baseline
+	count    int
+}
+
+func (s *suppressor) filter(file string, path string, ignores []suppression, msgs []string) []string {
+	kept := []string{}
+	for _, m := range msgs {
+		if reason, ok := s.suppressed(file, path, ignores, m); ok {
+			s.count++
+			slog.Debug("suppressed", "file", file, "path", path, "msg", m, "reason", reason)
+			continue
+		}
+		kept = append(kept, m)
+	}
+	return kept
+}
+
+func (s *suppressor) suppressed(file string, path string, ignores []suppression, msg string) (string, bool) {
+	for _, i := range ignores {
+		if i.matches(msg) {
+			return i.Reason, true
+		}
+	}
+	if s.baseline.contains(BaselineFinding{file, path, msg}) {
+		return "baseline", true
+	}
+	return "", false
+}
+
+func (s *suppressor) log() {
+	if s.count > 0 {
+		slog.Info("validation", "msg", "suppressed accepted findings, --debug lists them", "count", s.count)
+	}
+}
+
+// baselineFile is file relative to basePath and slash separated, so baselines match wherever files were found from
+func baselineFile(basePath string, file string) (string, error) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return "", err
+	}
+	rp, err := filepath.Rel(basePath, abs)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rp), nil
+}
+
+// Suppress drops the findings files accept with x-snowplow-cli-ignore annotations, then the ones in baseline
+func (lookup *DPLookup) Suppress(files map[string]map[string]any, baseline *Baseline, basePath string) error {
+	s := &suppressor{baseline: baseline}
+	for f, v := range lookup.Validations {
+		rp, err := baselineFile(basePath, f)
+		if err != nil {
+			return err
+		}
+
+		var ignores []suppression
+		var decoded []model.Ignore
+		err = mapstructure.Decode(files[f][model.IgnoreKey], &decoded)
+		if err == nil {
+			ignores, err = suppressions(decoded)
+		}
+
+		v.Errors = s.filter(rp, "", ignores, v.Errors)
+		v.Warnings = s.filter(rp, "", ignores, v.Warnings)
+		v.Info = s.filter(rp, "", ignores, v.Info)
+		for path, msgs := range v.ErrorsWithPaths {
+			if v.ErrorsWithPaths[path] = s.filter(rp, path, ignores, msgs); len(v.ErrorsWithPaths[path]) == 0 {
+				delete(v.ErrorsWithPaths, path)
+			}
+		}
+		for path, msgs := range v.WarningsWithPaths {
+			if v.WarningsWithPaths[path] = s.filter(rp, path, ignores, msgs); len(v.WarningsWithPaths[path]) == 0 {
+				delete(v.WarningsWithPaths, path)
+			}
+		}
+		if err != nil {
+			v.Errors = append(v.Errors, err.Error())
+		}
+		lookup.Validations[f] = v
+	}
+	s.log()
+	return nil
+}
+
+// Findings lists the findings of lookup as a baseline, files relative to basePath
+func (lookup *DPLookup) Findings(basePath string) (*Baseline, error) {
+	b := &Baseline{Findings: []BaselineFinding{}}
+	for f, v := range lookup.Validations {
+		rp, err := baselineFile(basePath, f)
+		if err != nil {
+			return nil, err
+		}
+		b.add(rp, "", v.Errors...)
+		b.add(rp, "", v.Warnings...)
+		b.add(rp, "", v.Info...)
+		for path, msgs := range v.ErrorsWithPaths {
+			b.add(rp, path, msgs...)
+		}
+		for path, msgs := range v.WarningsWithPaths {
+			b.add(rp, path, msgs...)
+		}
+	}
+	return b, nil
+}
+
+// Suppress drops the findings data structure files accept with the x-snowplow-cli-ignore annotations in annotations,
+// then the ones in baseline with files relative to basePath. Suggested migrations are never suppressed
+func (vr *ValidationResults) Suppress(annotations map[string][]model.Ignore, baseline *Baseline, basePath string) error {
+	s := &suppressor{baseline: baseline}
+	ignores := map[string][]suppression{}
+	invalid := []igluValidation{}
+	for _, f := range sortedFiles(annotations) {
+		i, err := suppressions(annotations[f])
+		if err != nil {
+			invalid = append(invalid, igluValidation{f, []string{err.Error()}, igluValidationError})
+		}
+		ignores[f] = i
+	}
+
+	kept := []igluValidation{}
+	for _, iglu := range vr.Iglu {
+		rp, err := baselineFile(basePath, iglu.File)
+		if err != nil {
+			return err
+		}
+		iglu.Messages = s.filter(rp, "", ignores[iglu.File], iglu.Messages)
+		if len(iglu.Messages) > 0 {
+			kept = append(kept, iglu)
+		}
+	}
+	s.log()
+	if s.count == 0 && len(invalid) == 0 {
+		return nil
+	}
+
+	vr.Iglu = append(kept, invalid...)
+	failed := len(vr.Migration)
+	for _, iglu := range vr.Iglu {
+		if iglu.Level == igluValidationError {
+			failed += len(iglu.Messages)
+		}
+	}
+	vr.Valid, vr.Message = failed == 0, ""
+	if failed > 0 {
+		vr.Message = fmt.Sprintf("%d validation failures", failed)
+	}
+	return nil
+}
+
+// Findings lists the findings of vr as a baseline, files relative to basePath and suggested migrations left out
+func (vr *ValidationResults) Findings(basePath string) (*Baseline, error) {
+	b := &Baseline{Findings: []BaselineFinding{}}
+	for _, iglu := range vr.Iglu {
+		rp, err := baselineFile(basePath, iglu.File)
+		if err != nil {
+			return nil, err
+		}
+		b.add(rp, "", iglu.Messages...)
+	}
+	return b, nil
+}
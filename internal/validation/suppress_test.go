@@ -0,0 +1,139 @@
+/**
+ * Copyright (c) 2013-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+ * located at https://docs.snowplow.io/limited-use-license-1.0
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package validation
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+func Test_DPLookupSuppress(t *testing.T) {
+	dir := t.TempDir()
+	shop := filepath.Join(dir, "data-products", "shop.yaml")
+	web := filepath.Join(dir, "source-apps", "web.yaml")
+	files := map[string]map[string]any{
+		shop: {model.IgnoreKey: []any{
+			map[string]any{"rule": "trigger-description", "reason": "described in the tracking plan"},
+			map[string]any{"message": "^entity .* is deprecated", "reason": "migrating next quarter"},
+			map[string]any{"rule": "event-spec-event"},
+		}},
+		web: {model.IgnoreKey: []any{map[string]any{"message": "(", "reason": "broken"}}},
+	}
+	lookup := &DPLookup{Validations: map[string]DPValidations{
+		shop: {
+			Errors:            []string{"event specification Checkout has no event (event-spec-event)"},
+			Warnings:          []string{"entity com.acme/user is deprecated", "owner is missing (data-product-metadata)"},
+			WarningsWithPaths: map[string][]string{"/data/eventSpecifications/0": {"trigger has no description (trigger-description)"}},
+		},
+		web: {Warnings: []string{"app id web is shared (source-app-app-ids-overlap)"}},
+	}}
+	baseline := &Baseline{Findings: []BaselineFinding{{File: "source-apps/web.yaml", Message: "app id web is shared (source-app-app-ids-overlap)"}}}
+
+	if err := lookup.Suppress(files, baseline, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := DPValidations{
+		Errors:            []string{"event specification Checkout has no event (event-spec-event)"},
+		Warnings:          []string{"owner is missing (data-product-metadata)"},
+		Info:              []string{},
+		WarningsWithPaths: map[string][]string{},
+	}
+	if !reflect.DeepEqual(lookup.Validations[shop], expected) {
+		t.Fatalf("unexpected findings, entries without a reason suppress nothing, got %+v", lookup.Validations[shop])
+	}
+	errs := lookup.Validations[web].Errors
+	if len(lookup.Validations[web].Warnings) != 0 || len(errs) != 1 || !strings.Contains(errs[0], "not a valid regular expression") {
+		t.Fatalf("expected the baseline finding dropped and the bad pattern reported got %+v", lookup.Validations[web])
+	}
+}
+
+func Test_ValidationResultsSuppress(t *testing.T) {
+	dir := t.TempDir()
+	loginFile := filepath.Join(dir, "data-structures", "login.yaml")
+	userFile := filepath.Join(dir, "data-structures", "user.yaml")
+	ignores := map[string][]model.Ignore{loginFile: {{Message: "property userName", Reason: "legacy tracking"}}}
+
+	vr := &ValidationResults{Valid: false, Message: "2 lint errors", Iglu: []igluValidation{
+		{loginFile, []string{"properties/userName: property userName is not snake_case (snake-case-names)"}, igluValidationError},
+		{userFile, []string{"Schema is missing a description"}, igluValidationWarn},
+		{userFile, []string{"vendor com.acme is not allowed (vendor-allowed)"}, igluValidationError},
+	}}
+	baseline, err := vr.Findings(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(baseline.Findings) != 3 || baseline.Findings[1].File != "data-structures/user.yaml" {
+		t.Fatalf("expected every finding in the baseline relative to the base path got %+v", baseline.Findings)
+	}
+	baseline.Findings = baseline.Findings[1:2]
+
+	if err := vr.Suppress(ignores, baseline, dir); err != nil {
+		t.Fatal(err)
+	}
+	expected := []igluValidation{{userFile, []string{"vendor com.acme is not allowed (vendor-allowed)"}, igluValidationError}}
+	if !reflect.DeepEqual(vr.Iglu, expected) {
+		t.Fatalf("unexpected findings got %+v", vr.Iglu)
+	}
+	if vr.Valid || vr.Message != "1 validation failures" {
+		t.Fatalf("expected the remaining error to fail validation got %+v", vr)
+	}
+
+	if err := vr.Suppress(ignores, &Baseline{Findings: []BaselineFinding{{File: "data-structures/user.yaml", Message: "vendor com.acme is not allowed (vendor-allowed)"}}}, dir); err != nil {
+		t.Fatal(err)
+	}
+	if !vr.Valid || vr.Message != "" || len(vr.Iglu) != 0 {
+		t.Fatalf("expected no findings left got %+v", vr)
+	}
+}
+
+func Test_ValidationResultsFindingsRelativeFiles(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vr := &ValidationResults{Iglu: []igluValidation{
+		{filepath.Join("data-structures", "login.yaml"), []string{"x"}, igluValidationError},
+		{filepath.Join(wd, "data-structures", "login.yaml"), []string{"x"}, igluValidationError},
+	}}
+	baseline, err := vr.Findings(wd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if baseline.Findings[0] != baseline.Findings[1] || baseline.Findings[0].File != "data-structures/login.yaml" {
+		t.Fatalf("expected walked and absolute files to give the same finding got %+v", baseline.Findings)
+	}
+}
+
+func Test_BaselineRoundTrip(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "baseline.json")
+	b := &Baseline{Findings: []BaselineFinding{
+		{File: "b.yaml", Message: "y"},
+		{File: "a.yaml", Path: "/data", Message: "x"},
+		{File: "b.yaml", Message: "y"},
+	}}
+	if err := b.Write(file); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadBaseline(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []BaselineFinding{{File: "a.yaml", Path: "/data", Message: "x"}, {File: "b.yaml", Message: "y"}}
+	if !reflect.DeepEqual(read.Findings, expected) {
+		t.Fatalf("expected sorted unique findings got %+v", read.Findings)
+	}
+}
@@ -0,0 +1,286 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+// Package console is a client for the Snowplow BDP Console API.
+//
+//	c, err := console.New(
+//		console.WithHost("https://console.snowplowanalytics.com"),
+//		console.WithOrgId(orgId),
+//		console.WithApiKey(apiKeyId, apiKeySecret),
+//	)
+//	if err != nil {
+//		return err
+//	}
+//	listing, err := c.GetDataStructureListing(ctx)
+//
+// Requests which are answered with an unexpected status return an *Error
+// carrying the status code and the message provided by Console.
+package console
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DefaultHost is the Console used when no host or base URL is provided
+const DefaultHost = "https://console.snowplowanalytics.com"
+
+// TokenSource provides the bearer token used to authenticate requests
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+type staticToken string
+
+func (t staticToken) Token(context.Context) (string, error) {
+	return string(t), nil
+}
+
+// StaticToken uses an already obtained access token for every request
+func StaticToken(token string) TokenSource {
+	return staticToken(token)
+}
+
+type apiKeyTokenSource struct {
+	client       *Client
+	apiKeyId     string
+	apiKeySecret string
+
+	mu    sync.Mutex
+	token string
+}
+
+type tokenResponse struct {
+	AccessToken string
+}
+
+func (s *apiKeyTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" {
+		return s.token, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", s.client.url("/credentials/v3/token"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Add("X-API-KEY-ID", s.apiKeyId)
+	req.Header.Add("X-API-KEY", s.apiKeySecret)
+	s.client.addClientInfo(req)
+
+	resp, err := s.client.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	body, err := io.ReadAll(resp.Body)
+	defer resp.Body.Close()
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", newError(resp, body)
+	}
+
+	var token tokenResponse
+	err = json.Unmarshal(body, &token)
+	if err != nil {
+		return "", err
+	}
+
+	s.token = token.AccessToken
+
+	return s.token, nil
+}
+
+// Client talks to the Console API of a single organization
+type Client struct {
+	http    *http.Client
+	host    string
+	orgId   string
+	baseUrl string
+	tokens  TokenSource
+	info    string
+
+	apiKeyId     string
+	apiKeySecret string
+}
+
+type Option func(*Client)
+
+// WithHost sets the Console host, eg. https://console.snowplowanalytics.com
+func WithHost(host string) Option {
+	return func(c *Client) {
+		c.host = host
+	}
+}
+
+// WithOrgId sets the organization all requests are made against
+func WithOrgId(orgId string) Option {
+	return func(c *Client) {
+		c.orgId = orgId
+	}
+}
+
+// WithBaseURL sets the organization scoped API base URL directly,
+// eg. https://console.snowplowanalytics.com/api/msc/v1/organizations/<org-id>.
+// It takes precedence over WithHost
+func WithBaseURL(baseUrl string) Option {
+	return func(c *Client) {
+		c.baseUrl = baseUrl
+	}
+}
+
+// WithHTTPClient sets the http.Client requests are sent with
+func WithHTTPClient(h *http.Client) Option {
+	return func(c *Client) {
+		if h != nil {
+			c.http = h
+		}
+	}
+}
+
+// WithTokenSource sets where bearer tokens come from
+func WithTokenSource(tokens TokenSource) Option {
+	return func(c *Client) {
+		c.tokens = tokens
+	}
+}
+
+// WithApiKey exchanges a Console API key for an access token on first use
+func WithApiKey(apiKeyId string, apiKeySecret string) Option {
+	return func(c *Client) {
+		c.apiKeyId = apiKeyId
+		c.apiKeySecret = apiKeySecret
+	}
+}
+
+// WithClientInfo identifies the calling tool and version to Console, eg. my-tool-1.2.0
+func WithClientInfo(info string) Option {
+	return func(c *Client) {
+		c.info = info
+	}
+}
+
+// New creates a client. No requests are made until a method is called
+func New(opts ...Option) (*Client, error) {
+	c := &Client{http: http.DefaultClient, host: DefaultHost}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.baseUrl == "" {
+		if c.orgId == "" {
+			return nil, errors.New("console: an org id or base url is required")
+		}
+		c.baseUrl = fmt.Sprintf("%s/api/msc/v1/organizations/%s", strings.TrimSuffix(c.host, "/"), c.orgId)
+	}
+
+	if c.tokens == nil {
+		if c.apiKeyId == "" && c.apiKeySecret == "" {
+			return nil, errors.New("console: an api key or token source is required")
+		}
+		c.tokens = &apiKeyTokenSource{client: c, apiKeyId: c.apiKeyId, apiKeySecret: c.apiKeySecret}
+	}
+
+	return c, nil
+}
+
+// BaseURL is the organization scoped API base URL
+func (c *Client) BaseURL() string {
+	return c.baseUrl
+}
+
+// OrgId is the organization requests are made against
+func (c *Client) OrgId() string {
+	return c.orgId
+}
+
+// HTTPClient is the http.Client requests are sent with
+func (c *Client) HTTPClient() *http.Client {
+	return c.http
+}
+
+// Token returns the bearer token from the configured TokenSource
+func (c *Client) Token(ctx context.Context) (string, error) {
+	return c.tokens.Token(ctx)
+}
+
+func (c *Client) addClientInfo(req *http.Request) {
+	if c.info != "" {
+		req.Header.Add("X-SNOWPLOW-CLI", c.info)
+	}
+}
+
+func (c *Client) url(path string) string {
+	return c.baseUrl + path
+}
+
+// NewRequest builds an authenticated request for a path relative to BaseURL
+func (c *Client) NewRequest(ctx context.Context, method string, path string, body io.Reader) (*http.Request, error) {
+	token, err := c.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.url(path), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("authorization", fmt.Sprintf("Bearer %s", token))
+	c.addClientInfo(req)
+	return req, nil
+}
+
+// Do sends an authenticated request for a path relative to BaseURL.
+// The caller is responsible for checking the status and closing the body
+func (c *Client) Do(ctx context.Context, method string, path string, body io.Reader) (*http.Response, error) {
+	req, err := c.NewRequest(ctx, method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.http.Do(req)
+}
+
+// doJson sends a request and decodes the response into out when the status
+// is one of expected, otherwise an *Error is returned
+func (c *Client) doJson(ctx context.Context, method string, path string, body io.Reader, out any, expected ...int) error {
+	resp, err := c.Do(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	rbody, err := io.ReadAll(resp.Body)
+	defer resp.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	ok := false
+	for _, s := range expected {
+		ok = ok || resp.StatusCode == s
+	}
+	if !ok {
+		return newError(resp, rbody)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	return json.Unmarshal(rbody, out)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+package console
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_New_Requirements(t *testing.T) {
+	if _, err := New(WithApiKey("id", "secret")); err == nil {
+		t.Error("expected failure without org id")
+	}
+	if _, err := New(WithOrgId("orgid")); err == nil {
+		t.Error("expected failure without credentials")
+	}
+
+	c, err := New(WithOrgId("orgid"), WithTokenSource(StaticToken("token")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.BaseURL() != DefaultHost+"/api/msc/v1/organizations/orgid" {
+		t.Errorf("unexpected base url %s", c.BaseURL())
+	}
+
+	c, err = New(WithBaseURL("http://proxy/org"), WithTokenSource(StaticToken("token")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.BaseURL() != "http://proxy/org" {
+		t.Errorf("unexpected base url %s", c.BaseURL())
+	}
+}
+
+func Test_ApiKey_ExchangedOnce(t *testing.T) {
+	exchanges := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/msc/v1/organizations/orgid/credentials/v3/token":
+			if r.Header.Get("X-API-KEY-ID") != "id" || r.Header.Get("X-API-KEY") != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			exchanges++
+			_, _ = io.WriteString(w, `{"accessToken":"token"}`)
+		case "/api/msc/v1/organizations/orgid/data-structures/v1":
+			if r.Header.Get("authorization") != "Bearer token" {
+				t.Errorf("bad auth token, got: %s", r.Header.Get("authorization"))
+			}
+			_, _ = io.WriteString(w, `[]`)
+		default:
+			t.Errorf("Unexpected request, got: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	c, err := New(WithHost(server.URL), WithOrgId("orgid"), WithApiKey("id", "secret"), WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetDataStructureListing(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if exchanges != 1 {
+		t.Errorf("expected a single token exchange, got %d", exchanges)
+	}
+}
+
+func Test_ApiKey_Rejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = io.WriteString(w, `{"message":"invalid key"}`)
+	}))
+	defer server.Close()
+
+	c, err := New(WithHost(server.URL), WithOrgId("orgid"), WithApiKey("id", "bad"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.Token(context.Background())
+	if StatusCode(err) != http.StatusUnauthorized {
+		t.Errorf("expected unauthorized, got %v", err)
+	}
+}
@@ -0,0 +1,32 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+// Package consoletest starts the in-memory fake of the BDP Console API from fakeconsole in tests:
+//
+//	fake, server := consoletest.NewServer(t)
+//	c, err := console.New(fake.Options(server.URL)...)
+package consoletest
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/pkg/console/fakeconsole"
+)
+
+type Console = fakeconsole.Console
+
+// NewServer starts a fake listening on a local port, closed when the test ends
+func NewServer(t testing.TB) (*Console, *httptest.Server) {
+	c := fakeconsole.New()
+	server := httptest.NewServer(c)
+	t.Cleanup(server.Close)
+	return c, server
+}
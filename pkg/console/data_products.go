@@ -0,0 +1,193 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package console
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+type DataProductsAndRelatedResources struct {
+	DataProducts      []RemoteDataProduct
+	EventSpecs        []RemoteEventSpec
+	SourceApplication []RemoteSourceApplication
+}
+
+type RemoteDataProduct struct {
+	Id                   string               `json:"id"`
+	Name                 string               `json:"name"`
+	Status               string               `json:"status"`
+	SourceApplicationIds []string             `json:"sourceApplications"`
+	Domain               string               `json:"domain,omitempty"`
+	Owner                string               `json:"owner,omitempty"`
+	Description          string               `json:"description,omitempty"`
+	EventSpecs           []EventSpecReference `json:"eventSpecs"`
+	LockStatus           string               `json:"lockStatus,omitempty"`
+	ManagedFrom          string               `json:"managedFrom,omitempty"`
+}
+
+type EventSpecReference struct {
+	Id string `json:"id"`
+}
+
+type RemoteTrigger struct {
+	Id          string            `json:"id,omitempty"`
+	Description string            `json:"description"`
+	AppIds      []string          `json:"appIds,omitempty"`
+	Url         string            `json:"url,omitempty"`
+	VariantUrls map[string]string `json:"variantUrls,omitempty"`
+}
+
+type RemoteEventSpec struct {
+	Id                   string          `json:"id"`
+	SourceApplicationIds []string        `json:"sourceApplications"`
+	Name                 string          `json:"name"`
+	Description          string          `json:"description"`
+	Triggers             []RemoteTrigger `json:"triggers,omitempty"`
+	Status               string          `json:"status"`
+	Version              int             `json:"version"`
+	Event                *EventWrapper   `json:"event,omitempty"`
+	Entities             Entities        `json:"entities"`
+	DataProductId        string          `json:"dataProductId"`
+	LockStatus           string          `json:"lockStatus,omitempty"`
+	ManagedFrom          string          `json:"managedFrom,omitempty"`
+}
+
+type Event struct {
+	Source string         `json:"source,omitempty"`
+	Schema map[string]any `json:"schema,omitempty"`
+}
+
+type EventWrapper struct {
+	Event
+}
+
+func (ew EventWrapper) MarshalJSON() ([]byte, error) {
+	if ew.Source == "" && (len(ew.Schema) == 0) {
+		return []byte("null"), nil
+	} else {
+		return json.Marshal(ew.Event)
+	}
+}
+
+type RemoteSourceApplication struct {
+	Id          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Owner       string   `json:"owner,omitempty"`
+	AppIds      []string `json:"appIds"`
+	Entities    Entities `json:"entities"`
+	LockStatus  string   `json:"lockStatus,omitempty"`
+	ManagedFrom string   `json:"managedFrom,omitempty"`
+}
+
+type Entities struct {
+	Tracked  []Entity `json:"tracked"`
+	Enriched []Entity `json:"enriched"`
+}
+
+type Entity struct {
+	Source         string         `json:"source"`
+	MinCardinality *int           `json:"minCardinality"`
+	MaxCardinality *int           `json:"maxCardinality"`
+	Schema         map[string]any `json:"schema,omitempty"`
+}
+
+type dataProductsResponse struct {
+	Data     []RemoteDataProduct `json:"data"`
+	Includes includes            `json:"includes"`
+}
+
+type includes struct {
+	EventSpecs []RemoteEventSpec `json:"eventSpecs"`
+}
+
+type saData struct {
+	Data []RemoteSourceApplication `json:"data"`
+}
+
+// GetDataProductsAndRelatedResources fetches all data products, their event specifications and all source applications
+func (c *Client) GetDataProductsAndRelatedResources(ctx context.Context) (*DataProductsAndRelatedResources, error) {
+	var dpResponse dataProductsResponse
+	err := c.doJson(ctx, "GET", "/data-products/v2", nil, &dpResponse, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+
+	var saResponse saData
+	err = c.doJson(ctx, "GET", "/source-apps/v1", nil, &saResponse, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+
+	res := DataProductsAndRelatedResources{
+		dpResponse.Data,
+		dpResponse.Includes.EventSpecs,
+		saResponse.Data,
+	}
+	return &res, nil
+}
+
+type CompatStatus = string
+
+const (
+	CompatCompatible   CompatStatus = "compatible"
+	CompatUndecidable  CompatStatus = "undecidable"
+	CompatIncompatible CompatStatus = "incompatible"
+)
+
+type CompatSource struct {
+	Source     string
+	Status     CompatStatus
+	Properties map[string]string
+}
+
+type CompatResult struct {
+	Status  string
+	Sources []CompatSource
+	Message string
+}
+
+type CompatCheckable struct {
+	Source string         `json:"source"`
+	Schema map[string]any `json:"schema"`
+}
+
+// CompatCheck asks Console whether an event and entities are compatible with the data already collected
+func (c *Client) CompatCheck(ctx context.Context, event CompatCheckable, entities []CompatCheckable) (*CompatResult, error) {
+	realArgs := map[string]any{
+		"spec": map[string]any{
+			"event": event,
+			"entities": map[string]any{
+				"tracked": entities,
+			},
+			// this uuid does not reference any existing event spec, I made it up
+			"id":      "312d3987-4874-498d-af6c-162ce0da39d7",
+			"name":    "cli-compat-check",
+			"status":  "draft",
+			"version": "0",
+		},
+	}
+
+	body, err := json.Marshal(realArgs)
+	if err != nil {
+		return nil, err
+	}
+	var cresp CompatResult
+	err = c.doJson(ctx, "POST", "/event-specs/v1/compatibility", bytes.NewBuffer(body), &cresp, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cresp, nil
+}
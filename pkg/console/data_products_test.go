@@ -0,0 +1,77 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+package console
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_GetDataProductsAndRelatedResources_Ok(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/msc/v1/organizations/orgid/data-products/v2":
+			_, _ = io.WriteString(w, `{"data":[{"id":"dp1","name":"dp"}],"includes":{"eventSpecs":[{"id":"es1","name":"es"}]}}`)
+		case "/api/msc/v1/organizations/orgid/source-apps/v1":
+			_, _ = io.WriteString(w, `{"data":[{"id":"sa1","name":"sa"}]}`)
+		default:
+			t.Errorf("Unexpected request, got: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	res, err := testClient(t, server).GetDataProductsAndRelatedResources(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.DataProducts) != 1 || len(res.EventSpecs) != 1 || len(res.SourceApplication) != 1 {
+		t.Errorf("unexpected result %+v", res)
+	}
+}
+
+func Test_GetDataProductsAndRelatedResources_SourceAppsFail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/msc/v1/organizations/orgid/data-products/v2" {
+			_, _ = io.WriteString(w, `{"data":[]}`)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	_, err := testClient(t, server).GetDataProductsAndRelatedResources(context.Background())
+	if !IsNotFound(err) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
+
+func Test_CompatCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/msc/v1/organizations/orgid/event-specs/v1/compatibility" {
+			_, _ = io.WriteString(w, `{"status":"compatible","sources":[{"source":"iglu:com.acme/login/jsonschema/1-0-0","status":"compatible"}]}`)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = io.WriteString(w, `{"message":"bad spec"}`)
+	}))
+	defer server.Close()
+
+	res, err := testClient(t, server).CompatCheck(context.Background(), CompatCheckable{Source: "iglu:com.acme/login/jsonschema/1-0-0"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != CompatCompatible || len(res.Sources) != 1 {
+		t.Errorf("unexpected result %+v", res)
+	}
+}
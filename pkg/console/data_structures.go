@@ -0,0 +1,274 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package console
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+)
+
+type DataStructureMeta struct {
+	Hidden     bool              `yaml:"hidden" json:"hidden"`
+	SchemaType string            `yaml:"schemaType" json:"schemaType" validate:"required,oneof=event entity"`
+	CustomData map[string]string `yaml:"customData" json:"customData" validate:"required"`
+}
+
+// DataStructure is a data structure as snowplow-cli keeps it in files, Data is the json schema
+type DataStructure struct {
+	ApiVersion   string            `yaml:"apiVersion" json:"apiVersion" validate:"required,oneof=v1"`
+	ResourceType string            `yaml:"resourceType" json:"resourceType" validate:"required,oneof=data-structure"`
+	Meta         DataStructureMeta `yaml:"meta" json:"meta" validate:"required"`
+	Data         map[string]any    `yaml:"data" json:"data" validate:"required"`
+}
+
+// GetContentHash hashes Data the way Console does
+func (ds DataStructure) GetContentHash() (string, error) {
+	byteBuffer := new(bytes.Buffer)
+	e := json.NewEncoder(byteBuffer)
+	e.SetEscapeHTML(false)
+	err := e.Encode(ds.Data)
+	if err != nil {
+		return "", err
+	}
+	// Encode adds a line feed at the end, scala does not
+	b := byteBuffer.Bytes()[:len(byteBuffer.Bytes())-1]
+	hasher := crypto.SHA256.New()
+	hasher.Write(b)
+	hash := hasher.Sum(nil)
+	// render bytes as base-16
+	return fmt.Sprintf("%x", hash), nil
+}
+
+// ParseData reads the self describing parts of Data
+func (d DataStructure) ParseData() (DataStructureData, error) {
+	var data DataStructureData
+	err := mapstructure.Decode(d.Data, &data)
+	return data, err
+}
+
+type DataStructureSelf struct {
+	Vendor  string `mapstructure:"vendor" json:"vendor" validate:"required"`
+	Name    string `mapstructure:"name" json:"name" validate:"required"`
+	Format  string `mapstructure:"format" json:"format" validate:"required,oneof=jsonschema"`
+	Version string `mapstructure:"version" json:"version" validate:"required"`
+}
+
+// IgluUri is the schema reference self describing payloads use, eg. iglu:com.acme/login/jsonschema/1-0-0
+func (s DataStructureSelf) IgluUri() string {
+	return fmt.Sprintf("iglu:%s/%s/%s/%s", s.Vendor, s.Name, s.Format, s.Version)
+}
+
+type DataStructureData struct {
+	Self   DataStructureSelf `mapstructure:"self" json:"self" validate:"required"`
+	Schema string            `mapstructure:"$schema" json:"$schema" validate:"required"`
+	Other  map[string]any    `mapstructure:",remain"`
+}
+
+type DataStructureEnv string
+
+const (
+	DEV       DataStructureEnv = "DEV"
+	PROD      DataStructureEnv = "PROD"
+	VALIDATED DataStructureEnv = "VALIDATED"
+)
+
+type Deployment struct {
+	Version     string           `json:"version"`
+	Env         DataStructureEnv `json:"env"`
+	ContentHash string           `json:"contentHash"`
+}
+
+type ListResponse struct {
+	Hash        string            `json:"hash"`
+	Vendor      string            `json:"vendor"`
+	Format      string            `json:"format"`
+	Name        string            `json:"name"`
+	Meta        DataStructureMeta `json:"meta"`
+	Deployments []Deployment      `json:"deployments"`
+}
+
+type PublishResponse struct {
+	Success  bool
+	Errors   []string
+	Warnings []string
+	Info     []string
+	Message  string
+}
+
+// PublishError is returned when Console accepted a deployment request but refused the deployment
+type PublishError struct {
+	Messages []string
+}
+
+func (e *PublishError) Error() string {
+	return strings.Join(e.Messages, "\n")
+}
+
+// Metadata holds the unversioned parts of a data structure. Empty fields are left unchanged
+type Metadata struct {
+	Hidden      *bool              `json:"hidden,omitempty"`
+	SchemaType  string             `json:"schemaType,omitempty"`
+	CustomData  *map[string]string `json:"customData,omitempty"`
+	LockStatus  string             `json:"lockStatus,omitempty"`
+	ManagedFrom string             `json:"managedFrom,omitempty"`
+}
+
+type publishRequest struct {
+	Format  string           `json:"format"`
+	Message string           `json:"message"`
+	Name    string           `json:"name"`
+	Source  DataStructureEnv `json:"source"`
+	Target  DataStructureEnv `json:"target"`
+	Vendor  string           `json:"vendor"`
+	Version string           `json:"version"`
+}
+
+// DataStructureHash is the identifier Console uses for a data structure within an organization
+func DataStructureHash(orgId string, self DataStructureSelf) string {
+	toHash := fmt.Sprintf("%s-%s-%s-%s", orgId, self.Vendor, self.Name, self.Format)
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(toHash)))
+}
+
+// Validate asks Console to validate ds, Success is false when it is invalid
+func (c *Client) Validate(ctx context.Context, ds DataStructure) (*PublishResponse, error) {
+	body, err := json.Marshal(ds)
+	if err != nil {
+		return nil, err
+	}
+	var vresp PublishResponse
+	err = c.doJson(ctx, "POST", "/data-structures/v1/validation-requests", bytes.NewBuffer(body), &vresp, http.StatusCreated)
+	if err != nil {
+		return nil, err
+	}
+	return &vresp, nil
+}
+
+// GetDataStructureDeployments lists every deployment of the data structure with hash
+func (c *Client) GetDataStructureDeployments(ctx context.Context, hash string) ([]Deployment, error) {
+	var deploys []Deployment
+	path := fmt.Sprintf("/data-structures/v1/%s/deployments?from=0&size=1000000000", url.PathEscape(hash))
+	err := c.doJson(ctx, "GET", path, nil, &deploys, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return deploys, nil
+}
+
+// GetDataStructureVersion fetches the schema of a version of the data structure with hash,
+// IsNotFound tells when the version does not exist
+func (c *Client) GetDataStructureVersion(ctx context.Context, hash string, version string) (map[string]any, error) {
+	var data map[string]any
+	path := fmt.Sprintf("/data-structures/v1/%s/versions/%s", url.PathEscape(hash), url.PathEscape(version))
+	err := c.doJson(ctx, "GET", path, nil, &data, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// GetDataStructureListing lists all data structures of the organization with their deployments
+func (c *Client) GetDataStructureListing(ctx context.Context) ([]ListResponse, error) {
+	var listResp []ListResponse
+	err := c.doJson(ctx, "GET", "/data-structures/v1", nil, &listResp, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return listResp, nil
+}
+
+// PublishDev deploys a validated data structure to the development environment and locks it
+func (c *Client) PublishDev(ctx context.Context, ds DataStructure, isPatch bool, managedFrom string) (*PublishResponse, error) {
+	// during first creation we have to publish first, otherwise metatdata patch fails with 404
+	res, err := c.publish(ctx, VALIDATED, DEV, ds, isPatch)
+	if err != nil {
+		return nil, err
+	}
+	err = c.lock(ctx, ds, managedFrom)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// PublishProd locks a data structure and promotes it from development to production
+func (c *Client) PublishProd(ctx context.Context, ds DataStructure, managedFrom string) (*PublishResponse, error) {
+	err := c.lock(ctx, ds, managedFrom)
+	if err != nil {
+		return nil, err
+	}
+	return c.publish(ctx, DEV, PROD, ds, false)
+}
+
+// PatchMetadata updates the metadata of an existing data structure
+func (c *Client) PatchMetadata(ctx context.Context, self DataStructureSelf, meta Metadata) error {
+	body, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("/data-structures/v1/%s/meta", DataStructureHash(c.orgId, self))
+	return c.doJson(ctx, "PATCH", path, bytes.NewBuffer(body), nil, http.StatusOK)
+}
+
+func (c *Client) lock(ctx context.Context, ds DataStructure, managedFrom string) error {
+	data, err := ds.ParseData()
+	if err != nil {
+		return err
+	}
+	return c.PatchMetadata(ctx, data.Self, Metadata{LockStatus: "locked", ManagedFrom: managedFrom})
+}
+
+func (c *Client) publish(ctx context.Context, from DataStructureEnv, to DataStructureEnv, ds DataStructure, isPatch bool) (*PublishResponse, error) {
+	dsData, err := ds.ParseData()
+	if err != nil {
+		return nil, err
+	}
+
+	pr := &publishRequest{
+		Message: "",
+		Source:  from,
+		Target:  to,
+		Vendor:  dsData.Self.Vendor,
+		Name:    dsData.Self.Name,
+		Format:  dsData.Self.Format,
+		Version: dsData.Self.Version,
+	}
+
+	body, err := json.Marshal(pr)
+	if err != nil {
+		return nil, err
+	}
+
+	path := "/data-structures/v1/deployment-requests"
+	if isPatch {
+		path += "?patch=true"
+	}
+
+	var dresp PublishResponse
+	err = c.doJson(ctx, "POST", path, bytes.NewBuffer(body), &dresp, http.StatusCreated)
+	if err != nil {
+		return nil, err
+	}
+
+	if !dresp.Success {
+		return nil, &PublishError{Messages: dresp.Errors}
+	}
+
+	return &dresp, nil
+}
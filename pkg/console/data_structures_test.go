@@ -0,0 +1,256 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package console
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func testClient(t *testing.T, server *httptest.Server) *Client {
+	c, err := New(WithHost(server.URL), WithOrgId("orgid"), WithTokenSource(StaticToken("token")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func Test_GetDataStructureListing_Ok(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/msc/v1/organizations/orgid/data-structures/v1" {
+			if r.Header.Get("authorization") != "Bearer token" {
+				t.Errorf("bad auth token, got: %s", r.Header.Get("authorization"))
+			}
+			resp, _ := json.Marshal([]ListResponse{{Hash: "abc", Vendor: "com.acme", Name: "login", Format: "jsonschema", Deployments: []Deployment{{Version: "1-0-0", Env: DEV}}}})
+			_, _ = w.Write(resp)
+			return
+		}
+
+		t.Errorf("Unexpected request, got: %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	res, err := testClient(t, server).GetDataStructureListing(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res) != 1 || res[0].Hash != "abc" || res[0].Deployments[0].Env != DEV {
+		t.Errorf("unexpected listing %+v", res)
+	}
+}
+
+func Test_GetDataStructureListing_Fail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = io.WriteString(w, `{"message":"not allowed"}`)
+	}))
+	defer server.Close()
+
+	_, err := testClient(t, server).GetDataStructureListing(context.Background())
+
+	var consoleErr *Error
+	if !errors.As(err, &consoleErr) {
+		t.Fatalf("expected *Error, got %v", err)
+	}
+	if consoleErr.StatusCode != http.StatusForbidden || consoleErr.Message != "not allowed" {
+		t.Errorf("unexpected error %+v", consoleErr)
+	}
+}
+
+func Test_PublishDev_LocksAfterPublish(t *testing.T) {
+	self := DataStructureSelf{Vendor: "com.acme", Name: "login", Format: "jsonschema", Version: "1-0-0"}
+	calls := []string{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/api/msc/v1/organizations/orgid/data-structures/v1/deployment-requests":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = io.WriteString(w, `{"success":true}`)
+		case "/api/msc/v1/organizations/orgid/data-structures/v1/" + DataStructureHash("orgid", self) + "/meta":
+			var meta Metadata
+			_ = json.NewDecoder(r.Body).Decode(&meta)
+			if meta.LockStatus != "locked" || meta.ManagedFrom != "repo" {
+				t.Errorf("unexpected meta %+v", meta)
+			}
+		default:
+			t.Errorf("Unexpected request, got: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	ds := DataStructure{Data: map[string]any{"self": map[string]any{
+		"vendor": self.Vendor, "name": self.Name, "format": self.Format, "version": self.Version,
+	}}}
+
+	_, err := testClient(t, server).PublishDev(context.Background(), ds, false, "repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(calls) != 2 || calls[0][:4] != "POST" || calls[1][:5] != "PATCH" {
+		t.Errorf("unexpected calls %v", calls)
+	}
+}
+
+func Test_publish_Ok(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/msc/v1/organizations/orgid/data-structures/v1/deployment-requests" {
+			w.WriteHeader(http.StatusCreated)
+			_, _ = io.WriteString(w, `{"success":true}`)
+			return
+		}
+
+		t.Errorf("Unexpected request, got: %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	cnx := context.Background()
+	client := testClient(t, server)
+
+	result, err := client.publish(cnx, VALIDATED, DEV, DataStructure{}, false)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if !result.Success {
+		t.Error("expected success, got failure")
+	}
+}
+
+func Test_publish_Fail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/msc/v1/organizations/orgid/data-structures/v1/deployment-requests" {
+			w.WriteHeader(http.StatusCreated)
+			_, _ = io.WriteString(w, `{"success":false, "errors": ["error1"]}`)
+			return
+		}
+
+		t.Errorf("Unexpected request, got: %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	cnx := context.Background()
+	client := testClient(t, server)
+
+	result, err := client.publish(cnx, VALIDATED, DEV, DataStructure{}, false)
+
+	if result != nil {
+		t.Error(result)
+	}
+
+	if err == nil || err.Error() != "error1" {
+		t.Error("expected failure, got success")
+	}
+}
+
+func Test_publish_FailCompletely(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/msc/v1/organizations/orgid/data-structures/v1/deployment-requests" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"message":"very bad"}`)
+			return
+		}
+
+		t.Errorf("Unexpected request, got: %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	cnx := context.Background()
+	client := testClient(t, server)
+
+	result, err := client.publish(cnx, VALIDATED, DEV, DataStructure{}, false)
+
+	if result != nil {
+		t.Error(result)
+	}
+
+	if StatusCode(err) != http.StatusBadRequest {
+		t.Errorf("expected typed bad request error, got %v", err)
+	}
+}
+
+func Test_Patch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/msc/v1/organizations/orgid/data-structures/v1/deployment-requests" && r.URL.Query().Get("patch") == "true" {
+			w.WriteHeader(http.StatusCreated)
+			_, _ = io.WriteString(w, `{"success":true}`)
+			return
+		}
+		t.Errorf("Unexpected request, got: %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	cnx := context.Background()
+	client := testClient(t, server)
+
+	result, err := client.publish(cnx, VALIDATED, DEV, DataStructure{}, true)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if !result.Success {
+		t.Error("expected success, got failure")
+	}
+}
+
+func Test_Validate_Invalid(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/msc/v1/organizations/orgid/data-structures/v1/validation-requests" {
+			t.Errorf("Unexpected request, got: %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, `{"success":false,"errors":["bad schema"]}`)
+	}))
+	defer server.Close()
+
+	ds := DataStructure{ApiVersion: "v1", ResourceType: "data-structure", Data: map[string]any{}}
+	res, err := testClient(t, server).Validate(context.Background(), ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Success || len(res.Errors) != 1 {
+		t.Errorf("unexpected response %+v", res)
+	}
+}
+
+func Test_GetDataStructureVersion_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/msc/v1/organizations/orgid/data-structures/v1/abc/versions/1-0-0":
+			_, _ = io.WriteString(w, `{"self":{"vendor":"com.acme"}}`)
+		case "/api/msc/v1/organizations/orgid/data-structures/v1/abc/deployments":
+			_, _ = io.WriteString(w, `[{"version":"1-0-0","env":"DEV","contentHash":"h"}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	c := testClient(t, server)
+
+	data, err := c.GetDataStructureVersion(context.Background(), "abc", "1-0-0")
+	if err != nil || data["self"] == nil {
+		t.Fatalf("unexpected version %v %v", data, err)
+	}
+	if _, err := c.GetDataStructureVersion(context.Background(), "abc", "2-0-0"); !IsNotFound(err) {
+		t.Errorf("expected not found got %v", err)
+	}
+	deploys, err := c.GetDataStructureDeployments(context.Background(), "abc")
+	if err != nil || len(deploys) != 1 || deploys[0].Env != DEV {
+		t.Errorf("unexpected deployments %v %v", deploys, err)
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package console
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Error is returned when Console answers with an unexpected status
+type Error struct {
+	StatusCode int
+	Message    string
+	Method     string
+	Url        string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("console: %s %s: not expected response code %d", e.Method, e.Url, e.StatusCode)
+	}
+	return fmt.Sprintf("console: %s %s: %d %s", e.Method, e.Url, e.StatusCode, e.Message)
+}
+
+type msgResponse struct {
+	Message string
+}
+
+func newError(resp *http.Response, body []byte) *Error {
+	e := &Error{StatusCode: resp.StatusCode}
+	if resp.Request != nil {
+		e.Method = resp.Request.Method
+		e.Url = resp.Request.URL.String()
+	}
+
+	var msg msgResponse
+	if err := json.Unmarshal(body, &msg); err == nil {
+		e.Message = msg.Message
+	}
+
+	return e
+}
+
+// StatusCode extracts the Console status code from err, or 0 when err is not an *Error
+func StatusCode(err error) int {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.StatusCode
+	}
+	return 0
+}
+
+// IsNotFound reports whether Console answered with 404
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
@@ -0,0 +1,257 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package fakeconsole
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/snowplow/snowplow-cli/pkg/console"
+)
+
+// AddSourceApp stores a source application
+func (c *Console) AddSourceApp(sa console.RemoteSourceApplication) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sourceApps[sa.Id] = sa
+}
+
+// AddDataProduct stores a data product, event specifications are added separately
+func (c *Console) AddDataProduct(dp console.RemoteDataProduct) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.products[dp.Id] = dp
+}
+
+// AddEventSpec stores an event specification belonging to es.DataProductId
+func (c *Console) AddEventSpec(es console.RemoteEventSpec) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.eventSpecs[es.Id] = es
+}
+
+// DataProducts returns the stored data products, event specifications and source applications
+func (c *Console) DataProducts() console.DataProductsAndRelatedResources {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return console.DataProductsAndRelatedResources{
+		DataProducts:      c.dataProducts(),
+		EventSpecs:        values(c.eventSpecs),
+		SourceApplication: values(c.sourceApps),
+	}
+}
+
+func values[V any](m map[string]V) []V {
+	res := []V{}
+	for _, k := range sortedKeys(m) {
+		res = append(res, m[k])
+	}
+	return res
+}
+
+// dataProducts fills in event spec references the way Console derives them
+func (c *Console) dataProducts() []console.RemoteDataProduct {
+	res := []console.RemoteDataProduct{}
+	for _, dp := range values(c.products) {
+		dp.EventSpecs = []console.EventSpecReference{}
+		for _, es := range values(c.eventSpecs) {
+			if es.DataProductId == dp.Id {
+				dp.EventSpecs = append(dp.EventSpecs, console.EventSpecReference{Id: es.Id})
+			}
+		}
+		res = append(res, dp)
+	}
+	return res
+}
+
+func (c *Console) listDataProducts(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, http.StatusOK, map[string]any{
+		"data":     c.dataProducts(),
+		"includes": map[string]any{"eventSpecs": values(c.eventSpecs)},
+	})
+}
+
+func (c *Console) createDataProduct(w http.ResponseWriter, r *http.Request) {
+	var dp console.RemoteDataProduct
+	if !readJson(w, r, &dp) {
+		return
+	}
+	if dp.Id == "" {
+		dp.Id = uuid.NewString()
+	}
+	if _, exists := c.products[dp.Id]; exists {
+		writeMessage(w, http.StatusConflict, "data product already exists")
+		return
+	}
+	c.products[dp.Id] = dp
+	writeJson(w, http.StatusCreated, dp)
+}
+
+func (c *Console) updateDataProduct(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, exists := c.products[id]; !exists {
+		writeMessage(w, http.StatusNotFound, "data product not found")
+		return
+	}
+	var dp console.RemoteDataProduct
+	if !readJson(w, r, &dp) {
+		return
+	}
+	dp.Id = id
+	c.products[id] = dp
+	writeJson(w, http.StatusOK, dp)
+}
+
+func (c *Console) deleteDataProduct(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, exists := c.products[id]; !exists {
+		writeMessage(w, http.StatusNotFound, "data product not found")
+		return
+	}
+	delete(c.products, id)
+	for esId, es := range c.eventSpecs {
+		if es.DataProductId == id {
+			delete(c.eventSpecs, esId)
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type eventSpecPost struct {
+	Spec    console.RemoteEventSpec `json:"spec"`
+	Message string                  `json:"message"`
+}
+
+func (c *Console) createEventSpec(w http.ResponseWriter, r *http.Request) {
+	var req eventSpecPost
+	if !readJson(w, r, &req) {
+		return
+	}
+	es := req.Spec
+	if es.Id == "" {
+		es.Id = uuid.NewString()
+	}
+	if _, exists := c.eventSpecs[es.Id]; exists {
+		writeMessage(w, http.StatusConflict, "event specification already exists")
+		return
+	}
+	if _, exists := c.products[es.DataProductId]; es.DataProductId != "" && !exists {
+		writeMessage(w, http.StatusBadRequest, "data product not found")
+		return
+	}
+	c.eventSpecs[es.Id] = es
+	writeJson(w, http.StatusCreated, map[string]any{"data": []console.RemoteEventSpec{es}})
+}
+
+func (c *Console) getEventSpec(w http.ResponseWriter, r *http.Request) {
+	es, exists := c.eventSpecs[r.PathValue("id")]
+	if !exists {
+		writeMessage(w, http.StatusNotFound, "event specification not found")
+		return
+	}
+	writeJson(w, http.StatusOK, map[string]any{"data": []console.RemoteEventSpec{es}})
+}
+
+func (c *Console) updateEventSpec(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	existing, exists := c.eventSpecs[id]
+	if !exists {
+		writeMessage(w, http.StatusNotFound, "event specification not found")
+		return
+	}
+	var req eventSpecPost
+	if !readJson(w, r, &req) {
+		return
+	}
+	es := req.Spec
+	es.Id = id
+	es.Version = existing.Version + 1
+	c.eventSpecs[id] = es
+	writeJson(w, http.StatusOK, map[string]any{"data": []console.RemoteEventSpec{es}})
+}
+
+func (c *Console) deleteEventSpec(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, exists := c.eventSpecs[id]; !exists {
+		writeMessage(w, http.StatusNotFound, "event specification not found")
+		return
+	}
+	delete(c.eventSpecs, id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *Console) compatibility(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Spec struct {
+			Event    console.CompatCheckable `json:"event"`
+			Entities struct {
+				Tracked []console.CompatCheckable `json:"tracked"`
+			} `json:"entities"`
+		} `json:"spec"`
+	}
+	if !readJson(w, r, &req) {
+		return
+	}
+
+	sources := []console.CompatSource{}
+	for _, s := range append([]console.CompatCheckable{req.Spec.Event}, req.Spec.Entities.Tracked...) {
+		if s.Source != "" {
+			sources = append(sources, console.CompatSource{Source: s.Source, Status: console.CompatCompatible, Properties: map[string]string{}})
+		}
+	}
+
+	writeJson(w, http.StatusOK, console.CompatResult{Status: console.CompatCompatible, Sources: sources})
+}
+
+func (c *Console) listSourceApps(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, http.StatusOK, map[string]any{"data": values(c.sourceApps)})
+}
+
+func (c *Console) createSourceApp(w http.ResponseWriter, r *http.Request) {
+	var sa console.RemoteSourceApplication
+	if !readJson(w, r, &sa) {
+		return
+	}
+	if sa.Id == "" {
+		sa.Id = uuid.NewString()
+	}
+	if _, exists := c.sourceApps[sa.Id]; exists {
+		writeMessage(w, http.StatusConflict, "source application already exists")
+		return
+	}
+	c.sourceApps[sa.Id] = sa
+	writeJson(w, http.StatusCreated, sa)
+}
+
+func (c *Console) updateSourceApp(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, exists := c.sourceApps[id]; !exists {
+		writeMessage(w, http.StatusNotFound, "source application not found")
+		return
+	}
+	var sa console.RemoteSourceApplication
+	if !readJson(w, r, &sa) {
+		return
+	}
+	sa.Id = id
+	c.sourceApps[id] = sa
+	writeJson(w, http.StatusOK, sa)
+}
+
+func (c *Console) deleteSourceApp(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, exists := c.sourceApps[id]; !exists {
+		writeMessage(w, http.StatusNotFound, "source application not found")
+		return
+	}
+	delete(c.sourceApps, id)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,284 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package fakeconsole
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/snowplow/snowplow-cli/pkg/console"
+)
+
+func (c *Console) structure(self console.DataStructureSelf, meta console.DataStructureMeta) *dataStructure {
+	hash := console.DataStructureHash(c.OrgId, self)
+	ds, ok := c.structures[hash]
+	if !ok {
+		ds = &dataStructure{
+			hash:      hash,
+			vendor:    self.Vendor,
+			name:      self.Name,
+			format:    self.Format,
+			meta:      meta,
+			validated: map[string]map[string]any{},
+		}
+		c.structures[hash] = ds
+	}
+	return ds
+}
+
+func (ds *dataStructure) deploy(version string, env console.DataStructureEnv, data map[string]any) error {
+	hash, err := console.DataStructure{Data: data}.GetContentHash()
+	if err != nil {
+		return err
+	}
+	ds.deployments = append(ds.deployments, deployment{
+		Deployment: console.Deployment{Version: version, Env: env, ContentHash: hash},
+		data:       data,
+	})
+	return nil
+}
+
+// latest finds the most recent deployment to env, optionally of a specific version
+func (ds *dataStructure) latest(env console.DataStructureEnv, version string) *deployment {
+	for i := len(ds.deployments) - 1; i >= 0; i-- {
+		d := ds.deployments[i]
+		if d.Env == env && (version == "" || d.Version == version) {
+			return &d
+		}
+	}
+	return nil
+}
+
+func (ds *dataStructure) listing() console.ListResponse {
+	res := console.ListResponse{
+		Hash:        ds.hash,
+		Vendor:      ds.vendor,
+		Name:        ds.name,
+		Format:      ds.format,
+		Meta:        ds.meta,
+		Deployments: []console.Deployment{},
+	}
+	for _, env := range []console.DataStructureEnv{console.DEV, console.PROD} {
+		if d := ds.latest(env, ""); d != nil {
+			res.Deployments = append(res.Deployments, d.Deployment)
+		}
+	}
+	return res
+}
+
+// AddDataStructure stores a data structure as already deployed to envs, DEV when none are given
+func (c *Console) AddDataStructure(ds console.DataStructure, envs ...console.DataStructureEnv) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := ds.ParseData()
+	if err != nil {
+		return err
+	}
+
+	if len(envs) == 0 {
+		envs = []console.DataStructureEnv{console.DEV}
+	}
+
+	stored := c.structure(data.Self, ds.Meta)
+	for _, env := range envs {
+		err := stored.deploy(data.Self.Version, env, ds.Data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DataStructures lists deployed data structures as Console would
+func (c *Console) DataStructures() []console.ListResponse {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.dataStructureListing()
+}
+
+func (c *Console) dataStructureListing() []console.ListResponse {
+	res := []console.ListResponse{}
+	for _, hash := range sortedKeys(c.structures) {
+		ds := c.structures[hash]
+		if len(ds.deployments) > 0 {
+			res = append(res, ds.listing())
+		}
+	}
+	return res
+}
+
+func (c *Console) listDataStructures(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, http.StatusOK, c.dataStructureListing())
+}
+
+func (c *Console) getDataStructureVersion(w http.ResponseWriter, r *http.Request) {
+	ds, ok := c.structures[r.PathValue("hash")]
+	if !ok {
+		writeMessage(w, http.StatusNotFound, "data structure not found")
+		return
+	}
+	version := r.PathValue("version")
+	for i := len(ds.deployments) - 1; i >= 0; i-- {
+		if ds.deployments[i].Version == version {
+			writeJson(w, http.StatusOK, ds.deployments[i].data)
+			return
+		}
+	}
+	writeMessage(w, http.StatusNotFound, "version not found")
+}
+
+func (c *Console) getDataStructureDeployments(w http.ResponseWriter, r *http.Request) {
+	ds, ok := c.structures[r.PathValue("hash")]
+	if !ok {
+		writeMessage(w, http.StatusNotFound, "data structure not found")
+		return
+	}
+	res := []console.Deployment{}
+	for _, d := range ds.deployments {
+		res = append(res, d.Deployment)
+	}
+	writeJson(w, http.StatusOK, res)
+}
+
+type publishResponse struct {
+	Success  bool     `json:"success"`
+	Errors   []string `json:"errors"`
+	Warnings []string `json:"warnings"`
+	Info     []string `json:"info"`
+	Message  string   `json:"message"`
+}
+
+func publishFailure(w http.ResponseWriter, errs ...string) {
+	writeJson(w, http.StatusCreated, publishResponse{Errors: errs, Warnings: []string{}, Info: []string{}, Message: errs[0]})
+}
+
+func publishSuccess(w http.ResponseWriter, message string) {
+	writeJson(w, http.StatusCreated, publishResponse{Success: true, Errors: []string{}, Warnings: []string{}, Info: []string{}, Message: message})
+}
+
+func (c *Console) validateDataStructure(w http.ResponseWriter, r *http.Request) {
+	var ds console.DataStructure
+	if !readJson(w, r, &ds) {
+		return
+	}
+
+	data, err := ds.ParseData()
+	if err != nil {
+		publishFailure(w, err.Error())
+		return
+	}
+
+	errs := []string{}
+	if data.Schema == "" {
+		errs = append(errs, "$schema is required")
+	}
+	if data.Self.Vendor == "" || data.Self.Name == "" || data.Self.Format == "" || data.Self.Version == "" {
+		errs = append(errs, "self must include vendor, name, format and version")
+	}
+	if len(errs) > 0 {
+		publishFailure(w, errs...)
+		return
+	}
+
+	stored := c.structure(data.Self, ds.Meta)
+	stored.validated[data.Self.Version] = ds.Data
+
+	publishSuccess(w, "valid")
+}
+
+type publishRequest struct {
+	Format  string                   `json:"format"`
+	Name    string                   `json:"name"`
+	Source  console.DataStructureEnv `json:"source"`
+	Target  console.DataStructureEnv `json:"target"`
+	Vendor  string                   `json:"vendor"`
+	Version string                   `json:"version"`
+}
+
+func (c *Console) deployDataStructure(w http.ResponseWriter, r *http.Request) {
+	var req publishRequest
+	if !readJson(w, r, &req) {
+		return
+	}
+
+	hash := console.DataStructureHash(c.OrgId, console.DataStructureSelf{Vendor: req.Vendor, Name: req.Name, Format: req.Format})
+	ds, ok := c.structures[hash]
+	if !ok {
+		publishFailure(w, fmt.Sprintf("data structure %s/%s/%s not found", req.Vendor, req.Name, req.Format))
+		return
+	}
+
+	switch {
+	case req.Source == console.VALIDATED && req.Target == console.DEV:
+		data, ok := ds.validated[req.Version]
+		if !ok {
+			publishFailure(w, fmt.Sprintf("version %s has not been validated", req.Version))
+			return
+		}
+		if existing := ds.latest(console.DEV, req.Version); existing != nil && r.URL.Query().Get("patch") != "true" {
+			publishFailure(w, fmt.Sprintf("version %s is already deployed, it can only be patched", req.Version))
+			return
+		}
+		delete(ds.validated, req.Version)
+		if err := ds.deploy(req.Version, console.DEV, data); err != nil {
+			writeMessage(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	case req.Source == console.DEV && req.Target == console.PROD:
+		existing := ds.latest(console.DEV, req.Version)
+		if existing == nil {
+			publishFailure(w, fmt.Sprintf("version %s is not deployed to DEV", req.Version))
+			return
+		}
+		if err := ds.deploy(req.Version, console.PROD, existing.data); err != nil {
+			writeMessage(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	default:
+		writeMessage(w, http.StatusBadRequest, fmt.Sprintf("cannot deploy from %s to %s", req.Source, req.Target))
+		return
+	}
+
+	publishSuccess(w, "deployed")
+}
+
+func (c *Console) patchDataStructureMeta(w http.ResponseWriter, r *http.Request) {
+	ds, ok := c.structures[r.PathValue("hash")]
+	if !ok {
+		writeMessage(w, http.StatusNotFound, "data structure not found")
+		return
+	}
+
+	var meta console.Metadata
+	if !readJson(w, r, &meta) {
+		return
+	}
+
+	if meta.Hidden != nil {
+		ds.meta.Hidden = *meta.Hidden
+	}
+	if meta.SchemaType != "" {
+		ds.meta.SchemaType = meta.SchemaType
+	}
+	if meta.CustomData != nil {
+		ds.meta.CustomData = *meta.CustomData
+	}
+	if meta.LockStatus != "" {
+		ds.lockStatus = meta.LockStatus
+	}
+	if meta.ManagedFrom != "" {
+		ds.managedFrom = meta.ManagedFrom
+	}
+
+	writeJson(w, http.StatusOK, map[string]string{})
+}
@@ -0,0 +1,322 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+// Package fakeconsole provides an in-memory fake of the BDP Console API.
+//
+// It implements the endpoints used by snowplow-cli well enough to run
+// publish and download round trips without a real organization:
+//
+//	fake := fakeconsole.New()
+//	go http.Serve(listener, fake)
+//	c, err := console.New(fake.Options("http://" + listener.Addr().String())...)
+//
+// Tests use consoletest.NewServer, which serves it until they end
+package fakeconsole
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/snowplow/snowplow-cli/pkg/console"
+)
+
+const (
+	DefaultOrgId    = "00000000-0000-4000-8000-000000000000"
+	DefaultApiKeyId = "fake-api-key-id"
+	DefaultApiKey   = "fake-api-key"
+	DefaultToken    = "fake-token"
+)
+
+type deployment struct {
+	console.Deployment
+	data map[string]any
+}
+
+type dataStructure struct {
+	hash        string
+	vendor      string
+	name        string
+	format      string
+	meta        console.DataStructureMeta
+	lockStatus  string
+	managedFrom string
+	validated   map[string]map[string]any
+	deployments []deployment
+}
+
+type image struct {
+	hash        string
+	data        []byte
+	contentType string
+}
+
+// Console is an in-memory Console for a single organization
+type Console struct {
+	OrgId    string
+	ApiKeyId string
+	ApiKey   string
+	Token    string
+
+	mu           sync.Mutex
+	structures   map[string]*dataStructure
+	products     map[string]console.RemoteDataProduct
+	eventSpecs   map[string]console.RemoteEventSpec
+	sourceApps   map[string]console.RemoteSourceApplication
+	images       map[string]*image
+	destinations []string
+
+	mux *http.ServeMux
+}
+
+// New creates an empty fake using the default credentials
+func New() *Console {
+	c := &Console{
+		OrgId:      DefaultOrgId,
+		ApiKeyId:   DefaultApiKeyId,
+		ApiKey:     DefaultApiKey,
+		Token:      DefaultToken,
+		structures: map[string]*dataStructure{},
+		products:   map[string]console.RemoteDataProduct{},
+		eventSpecs: map[string]console.RemoteEventSpec{},
+		sourceApps: map[string]console.RemoteSourceApplication{},
+		images:     map[string]*image{},
+	}
+	c.routes()
+	return c
+}
+
+// Options configures a console.Client to talk to this fake served at host
+func (c *Console) Options(host string) []console.Option {
+	return []console.Option{
+		console.WithHost(host),
+		console.WithOrgId(c.OrgId),
+		console.WithApiKey(c.ApiKeyId, c.ApiKey),
+	}
+}
+
+func (c *Console) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mux.ServeHTTP(w, r)
+}
+
+const orgPath = "/api/msc/v1/organizations/{org}"
+
+func (c *Console) routes() {
+	c.mux = http.NewServeMux()
+
+	c.mux.HandleFunc("GET "+orgPath+"/credentials/v3/token", c.token)
+
+	c.handle("GET /data-structures/v1", c.listDataStructures)
+	c.handle("GET /data-structures/v1/{hash}/versions/{version}", c.getDataStructureVersion)
+	c.handle("GET /data-structures/v1/{hash}/deployments", c.getDataStructureDeployments)
+	c.handle("POST /data-structures/v1/validation-requests", c.validateDataStructure)
+	c.handle("POST /data-structures/v1/deployment-requests", c.deployDataStructure)
+	c.handle("PATCH /data-structures/v1/{hash}/meta", c.patchDataStructureMeta)
+	c.handle("POST /data-structures/v1/schema-migrations", c.schemaMigrations)
+	c.handle("GET /destinations/v3", c.listDestinations)
+
+	c.handle("GET /data-products/v2", c.listDataProducts)
+	c.handle("POST /data-products/v2", c.createDataProduct)
+	c.handle("PUT /data-products/v2/{id}", c.updateDataProduct)
+	c.handle("DELETE /data-products/v2/{id}", c.deleteDataProduct)
+
+	c.handle("POST /event-specs/v1", c.createEventSpec)
+	c.handle("POST /event-specs/v1/compatibility", c.compatibility)
+	c.handle("GET /event-specs/v1/{id}", c.getEventSpec)
+	c.handle("PUT /event-specs/v1/{id}", c.updateEventSpec)
+	c.handle("DELETE /event-specs/v1/{id}", c.deleteEventSpec)
+
+	c.handle("GET /source-apps/v1", c.listSourceApps)
+	c.handle("POST /source-apps/v1", c.createSourceApp)
+	c.handle("PUT /source-apps/v1/{id}", c.updateSourceApp)
+	c.handle("DELETE /source-apps/v1/{id}", c.deleteSourceApp)
+
+	c.handle("GET /images/v1", c.listImages)
+	c.handle("POST /images/v1", c.createImageUploadLink)
+	c.handle("POST /images/v1/{id}/confirm-upload", c.confirmImage)
+
+	// stand-ins for the image storage provider, not behind Console auth
+	c.mux.HandleFunc("POST /fake-uploads/{id}", c.uploadImage)
+	c.mux.HandleFunc("GET /fake-images/{id}/{variant}", c.getImage)
+}
+
+// handle registers an organization scoped route behind bearer authentication
+func (c *Console) handle(pattern string, h http.HandlerFunc) {
+	var method, path string
+	_, _ = fmt.Sscanf(pattern, "%s %s", &method, &path)
+	c.mux.HandleFunc(method+" "+orgPath+path, func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("org") != c.OrgId {
+			writeMessage(w, http.StatusForbidden, "unknown organization")
+			return
+		}
+		if r.Header.Get("authorization") != "Bearer "+c.Token {
+			writeMessage(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		h(w, r)
+	})
+}
+
+func writeJson(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeMessage(w http.ResponseWriter, status int, message string) {
+	writeJson(w, status, map[string]string{"message": message})
+}
+
+func readJson(w http.ResponseWriter, r *http.Request, into any) bool {
+	err := json.NewDecoder(r.Body).Decode(into)
+	if err != nil {
+		writeMessage(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+	return true
+}
+
+func (c *Console) token(w http.ResponseWriter, r *http.Request) {
+	if r.PathValue("org") != c.OrgId || r.Header.Get("X-API-KEY-ID") != c.ApiKeyId || r.Header.Get("X-API-KEY") != c.ApiKey {
+		writeMessage(w, http.StatusUnauthorized, "invalid api key")
+		return
+	}
+	writeJson(w, http.StatusOK, map[string]string{"accessToken": c.Token})
+}
+
+func (c *Console) readImageBody(r *http.Request) ([]byte, string, error) {
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, "", err
+	}
+	contentType := http.DetectContentType(data)
+	if ct := header.Header.Get("content-type"); ct != "" && ct != "application/octet-stream" {
+		contentType = ct
+	}
+	return data, contentType, nil
+}
+
+func (c *Console) uploadImage(w http.ResponseWriter, r *http.Request) {
+	data, contentType, err := c.readImageBody(r)
+	if err != nil {
+		writeJson(w, http.StatusBadRequest, map[string]any{"success": false, "errors": []map[string]any{{"code": 400, "message": err.Error()}}})
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	img, ok := c.images[r.PathValue("id")]
+	if !ok {
+		writeJson(w, http.StatusNotFound, map[string]any{"success": false, "errors": []map[string]any{{"code": 404, "message": "unknown upload"}}})
+		return
+	}
+	img.data = data
+	img.contentType = contentType
+
+	writeJson(w, http.StatusOK, map[string]any{"success": true})
+}
+
+func (c *Console) getImage(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	img, ok := c.images[r.PathValue("id")]
+	if !ok || img.data == nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("content-type", img.contentType)
+	_, _ = w.Write(img.data)
+}
+
+func (c *Console) listImages(w http.ResponseWriter, r *http.Request) {
+	items := []map[string]string{}
+	for _, id := range sortedKeys(c.images) {
+		items = append(items, map[string]string{"id": id, "hash": c.images[id].hash})
+	}
+	writeJson(w, http.StatusOK, map[string]any{"items": items})
+}
+
+func (c *Console) createImageUploadLink(w http.ResponseWriter, r *http.Request) {
+	id := uuid.NewString()
+	c.images[id] = &image{}
+	writeJson(w, http.StatusOK, map[string]string{
+		"id":        id,
+		"uploadURL": fmt.Sprintf("%s/fake-uploads/%s", baseUrl(r), id),
+	})
+}
+
+func (c *Console) confirmImage(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	img, ok := c.images[id]
+	if !ok || img.data == nil {
+		writeMessage(w, http.StatusNotFound, "image not uploaded")
+		return
+	}
+	var req struct{ Hash string }
+	if !readJson(w, r, &req) {
+		return
+	}
+	img.hash = req.Hash
+	writeJson(w, http.StatusOK, map[string]any{"variantUrls": map[string]string{
+		"original": fmt.Sprintf("%s/fake-images/%s/original", baseUrl(r), id),
+	}})
+}
+
+func baseUrl(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s", scheme, r.Host)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// AddDestination registers a loader destination, enabling schema migration checks
+func (c *Console) AddDestination(destinationType string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !slices.Contains(c.destinations, destinationType) {
+		c.destinations = append(c.destinations, destinationType)
+	}
+}
+
+func (c *Console) listDestinations(w http.ResponseWriter, r *http.Request) {
+	res := []map[string]string{}
+	for _, d := range c.destinations {
+		res = append(res, map[string]string{"destinationType": d})
+	}
+	writeJson(w, http.StatusOK, res)
+}
+
+func (c *Console) schemaMigrations(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, http.StatusOK, map[string]any{"changeType": "no-change", "migrations": []any{}})
+}
@@ -0,0 +1,177 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package fakeconsole
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/pkg/console"
+)
+
+func testDs(version string, description string) console.DataStructure {
+	return console.DataStructure{
+		ApiVersion:   "v1",
+		ResourceType: "data-structure",
+		Meta:         console.DataStructureMeta{SchemaType: "event", CustomData: map[string]string{}},
+		Data: map[string]any{
+			"$schema":     "http://iglucentral.com/schemas/com.snowplowanalytics.self-desc/schema/jsonschema/1-0-0#",
+			"self":        map[string]any{"vendor": "com.acme", "name": "login", "format": "jsonschema", "version": version},
+			"description": description,
+			"type":        "object",
+		},
+	}
+}
+
+func client(t *testing.T) (*Console, *console.Client) {
+	fake := New()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	c, err := console.New(fake.Options(server.URL)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fake, c
+}
+
+func Test_Auth(t *testing.T) {
+	fake := New()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	c, err := console.New(console.WithHost(server.URL), console.WithOrgId(fake.OrgId), console.WithApiKey(fake.ApiKeyId, "wrong"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetDataStructureListing(context.Background())
+	if console.StatusCode(err) != http.StatusUnauthorized {
+		t.Errorf("expected unauthorized, got %v", err)
+	}
+
+	c, err = console.New(console.WithHost(server.URL), console.WithOrgId(fake.OrgId), console.WithTokenSource(console.StaticToken("wrong")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetDataStructureListing(context.Background())
+	if console.StatusCode(err) != http.StatusUnauthorized {
+		t.Errorf("expected unauthorized, got %v", err)
+	}
+}
+
+func Test_PublishDevThenProd(t *testing.T) {
+	fake, c := client(t)
+	cnx := context.Background()
+	ds := testDs("1-0-0", "first")
+
+	_, err := c.PublishDev(cnx, ds, false, "")
+	if _, ok := err.(*console.PublishError); !ok {
+		t.Fatalf("expected publish of an unvalidated version to fail, got %v", err)
+	}
+
+	validate(t, c, ds)
+	_, err = c.PublishDev(cnx, ds, false, "repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listing := fake.DataStructures()
+	if len(listing) != 1 || len(listing[0].Deployments) != 1 || listing[0].Deployments[0].Env != console.DEV {
+		t.Fatalf("unexpected listing %+v", listing)
+	}
+	hash, _ := ds.GetContentHash()
+	if listing[0].Deployments[0].ContentHash != hash {
+		t.Errorf("content hash did not match local hash")
+	}
+
+	patched := testDs("1-0-0", "patched")
+	validate(t, c, patched)
+	_, err = c.PublishDev(cnx, patched, false, "repo")
+	if err == nil {
+		t.Fatal("expected redeploy of the same version without patch to fail")
+	}
+	validate(t, c, patched)
+	_, err = c.PublishDev(cnx, patched, true, "repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.PublishProd(cnx, patched, "repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listing, err = c.GetDataStructureListing(cnx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listing[0].Deployments) != 2 || listing[0].Deployments[1].Env != console.PROD {
+		t.Fatalf("unexpected deployments %+v", listing[0].Deployments)
+	}
+}
+
+func validate(t *testing.T, c *console.Client, ds console.DataStructure) {
+	t.Helper()
+	resp, err := c.Do(context.Background(), "POST", "/data-structures/v1/validation-requests", jsonBody(t, ds))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("validation failed with %d", resp.StatusCode)
+	}
+}
+
+func Test_DataProducts(t *testing.T) {
+	fake, c := client(t)
+	cnx := context.Background()
+
+	fake.AddSourceApp(console.RemoteSourceApplication{Id: "sa1", Name: "web"})
+	fake.AddDataProduct(console.RemoteDataProduct{Id: "dp1", Name: "signup", SourceApplicationIds: []string{"sa1"}})
+	fake.AddEventSpec(console.RemoteEventSpec{Id: "es1", Name: "click", DataProductId: "dp1"})
+
+	res, err := c.GetDataProductsAndRelatedResources(cnx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.DataProducts) != 1 || len(res.DataProducts[0].EventSpecs) != 1 || res.DataProducts[0].EventSpecs[0].Id != "es1" {
+		t.Fatalf("unexpected data products %+v", res.DataProducts)
+	}
+	if len(res.SourceApplication) != 1 || len(res.EventSpecs) != 1 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+
+	resp, err := c.Do(cnx, "DELETE", "/data-products/v2/dp1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("unexpected status %d", resp.StatusCode)
+	}
+
+	if remaining := fake.DataProducts(); len(remaining.DataProducts) != 0 || len(remaining.EventSpecs) != 0 {
+		t.Errorf("expected data product and its event specs to be removed, got %+v", remaining)
+	}
+}
+
+func jsonBody(t *testing.T, v any) io.Reader {
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(b)
+}